@@ -0,0 +1,83 @@
+package main
+
+import (
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newAICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ai",
+		Short: "Show what drift's AI calls have used and cost",
+		Long: `Every call drift diagnose, drift fix, drift review, and the dashboard make
+to the AI provider is recorded in ` + history.UsageFile + ` with the tokens it used
+and its cost at the model's price (see ai.input_price and ai.output_price).
+Add .drift/ to your .gitignore.`,
+	}
+	cmd.AddCommand(newAIUsageCmd())
+	return cmd
+}
+
+func newAIUsageCmd() *cobra.Command {
+	var since string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Total the tokens and cost of recorded AI calls, by command and model",
+		Example: `  drift ai usage
+  drift ai usage --since 30d
+  drift ai usage --since 2026-01-01 --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			calls, err := history.LoadCalls(cfg.Root)
+			if err != nil {
+				return err
+			}
+			period := "in total"
+			if since != "" {
+				start, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				kept := calls[:0]
+				for _, c := range calls {
+					if !c.Time.Before(start) {
+						kept = append(kept, c)
+					}
+				}
+				calls = kept
+				period = "since " + start.Format(time.DateOnly)
+			}
+
+			if asJSON {
+				var total history.Usage
+				for _, c := range calls {
+					total.Add(c)
+				}
+				return printJSON(struct {
+					Total     history.Usage             `json:"total"`
+					ByCommand map[string]*history.Usage `json:"by_command"`
+					ByModel   map[string]*history.Usage `json:"by_model"`
+				}{
+					Total:     total,
+					ByCommand: history.UsageBy(calls, func(c history.Call) string { return c.Command }),
+					ByModel:   history.UsageBy(calls, history.Call.ModelLabel),
+				})
+			}
+			tui.PrintUsage(calls, period)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "only calls since a duration back from now (e.g. 30d, 12w) or a date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output the totals as JSON")
+	return cmd
+}
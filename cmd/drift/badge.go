@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/spf13/cobra"
+)
+
+func newBadgeCmd() *cobra.Command {
+	var format, output, label string
+
+	cmd := &cobra.Command{
+		Use:   "badge",
+		Short: "Write a README badge with the health score and letter grade",
+		Long: `Badge writes the health score and its letter grade (A from 90, B from 80,
+C from 70, D from 60, F below) as a badge. The svg format is a ready-made
+image; the json format is a shields.io endpoint to serve from a URL and
+render with https://img.shields.io/endpoint?url=<url>.
+
+Example:
+  drift badge --format svg -o .github/drift.svg`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			results, err = applyBaseline(cfg, results)
+			if err != nil {
+				return err
+			}
+			score := health.NewScorer(cfg).Calculate(results)
+
+			var data []byte
+			switch format {
+			case "json":
+				if data, err = health.BadgeJSON(label, score); err != nil {
+					return err
+				}
+			case "svg":
+				data = health.BadgeSVG(label, score)
+			default:
+				return fmt.Errorf("unknown --format %q (want json or svg)", format)
+			}
+			if output == "" {
+				output = "drift-badge." + format
+			}
+			if output == "-" {
+				_, err := os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return fmt.Errorf("writing badge: %w", err)
+			}
+			fmt.Printf("Wrote %s (%.0f, grade %s)\n", output, score.Total, score.Grade())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "json", "Badge format: json (shields.io endpoint) or svg")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write, or - for stdout (default: drift-badge.<format>)")
+	cmd.Flags().StringVar(&label, "label", "drift", "Text on the left of the badge")
+	return cmd
+}
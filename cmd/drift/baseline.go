@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/spf13/cobra"
+)
+
+func newBaselineCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "baseline",
+		Short: "Record current issues so check and report only count new ones",
+		Long: `Baseline writes every current issue to ` + analyzer.BaselineFile + ` in the project
+root. From then on, check and report leave those issues out of the score and
+only count ones introduced later, so drift can be adopted on an existing
+codebase and quality ratcheted forward. Run it again to accept the current
+state, or delete the file to count everything.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			b := analyzer.NewBaseline(results, cfg.Thresholds)
+			b.Score = health.NewScorer(cfg).Calculate(b.Apply(results, cfg.Thresholds)).Categories()
+			if err := b.Save(cfg.Root); err != nil {
+				return fmt.Errorf("writing baseline: %w", err)
+			}
+			total := 0
+			for _, n := range b.Issues {
+				total += n
+			}
+			fmt.Printf("Recorded %d issues in %s\n", total, filepath.Join(cfg.Root, analyzer.BaselineFile))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newBisectCmd() *cobra.Command {
+	var metric, from, to string
+	var threshold float64
+	var all, asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the commit where a metric regressed past a threshold",
+		Long: `Bisect walks the first-parent history from --from to --to and finds the
+commit after which a metric was past --threshold, reporting it with the
+source files it changed.
+
+--metric is a score (total, complexity, deps, ...), which regresses by
+dropping below the threshold, or a count (violations, complex_functions,
+dead_code, ...) or avg_complexity, which regress by rising above it. Without
+--threshold any regression from the value at --from counts.
+
+Like git bisect it assumes the metric went bad once, analyzing about log2
+of the commits in the range; --all analyzes every commit and reports each
+time it went bad. Analyzed commits are cached like drift trend's.
+
+Example:
+  drift bisect --metric complexity --from v1.0 --to HEAD --threshold 70
+  drift bisect --metric violations --from main~50 --threshold 0 --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				return fmt.Errorf("--from is required")
+			}
+			if !slices.Contains(history.Metrics(), metric) {
+				return fmt.Errorf("unknown --metric %q (want one of %s)", metric, strings.Join(history.Metrics(), ", "))
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			repo, err := history.New(cfg)
+			if err != nil {
+				return err
+			}
+
+			limited := cmd.Flags().Changed("threshold")
+			bad := func(rec, start history.Record) bool {
+				value, higherIsBetter, _ := history.Metric(rec, metric)
+				limit := threshold
+				if !limited {
+					limit, _, _ = history.Metric(start, metric)
+				}
+				if higherIsBetter {
+					return value < limit
+				}
+				return value > limit
+			}
+			result, err := repo.Bisect(from, to, bad, all)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(result)
+			}
+			tui.PrintBisection(result, metric, from, to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&metric, "metric", "total", "score, count, or avg_complexity to track")
+	cmd.Flags().StringVar(&from, "from", "", "last known good revision, e.g. a tag")
+	cmd.Flags().StringVar(&to, "to", "HEAD", "revision where the metric is bad")
+	cmd.Flags().Float64Var(&threshold, "threshold", 0, "value past which the metric counts as regressed (default: its value at --from)")
+	cmd.Flags().BoolVar(&all, "all", false, "analyze every commit and report each regression")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON")
+	return cmd
+}
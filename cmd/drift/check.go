@@ -0,0 +1,413 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/ci"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of drift check, so scripts can tell a failed check from a
+// broken run.
+const (
+	exitCheckFailed   = 1 // below --fail-under, a score regressed, or a denied license
+	exitAnalysisError = 2
+	exitConfigError   = 3 // bad config, flags, baseline, or --against file
+)
+
+// exitError carries the code drift exits with once err is printed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func newCheckCmd() *cobra.Command {
+	var o checkOptions
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check health score and exit with error if below threshold (for CI)",
+		Long: `Check runs analysis and fails if the health score is below the threshold
+or a dependency uses a license on the licenses.deny list. Issues recorded by
+` + "`drift baseline`" + ` are not counted.
+Useful for CI pipelines to enforce code health standards.
+
+With --no-regression, the fixed threshold is replaced by the scores recorded
+by ` + "`drift baseline`" + ` (or a ` + "`drift snapshot`" + ` file given with --against): check fails
+if the total or any category score dropped.
+
+Exit codes: 0 passed, 1 check failed, 2 analysis error, 3 configuration error.
+With --changed-only, the findings reported are limited to the files changed
+since --base (committed since HEAD branched off it, or not committed yet), so
+they cover just what a pull request touches. The whole tree is still
+analyzed and scored, so cross-file findings stay accurate and scores compare
+with the baseline's.
+
+With --github-check, the result is also posted as a GitHub check run that
+annotates each complex function, boundary violation, dead declaration, and
+vulnerable dependency in the pull request's changed files. It reads the
+GitHub Actions environment and needs GITHUB_TOKEN with checks: write.
+
+With --gitlab-note, the result and findings are posted as a note on the
+merge request, updated in place on later runs. It reads the GitLab CI
+environment and needs GITLAB_TOKEN with the api scope.
+
+--format line prints one key=value line for scripts, e.g.
+  result=fail reason=below-threshold score=64.2 threshold=70.0 baselined=0
+
+--format github also prints each finding as a GitHub Actions workflow
+command, so a plain drift check step annotates the files in the workflow
+run and pull request with no token needed.
+
+Example:
+  drift check --fail-under 70
+  drift check --no-regression --against main-snapshot.json
+  drift check --quiet --format line
+  drift check --format github
+  drift check --changed-only --base origin/main`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return silenceCheckFailure(cmd, runCheck(o))
+		},
+	}
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{exitConfigError, err}
+	})
+
+	cmd.Flags().Float64Var(&o.failUnder, "fail-under", 70.0, "Minimum health score required (0-100)")
+	cmd.Flags().BoolVar(&o.noRegression, "no-regression", false, "Fail only if the total or a category score dropped, instead of using --fail-under")
+	cmd.Flags().StringVar(&o.against, "against", "", "Snapshot JSON to compare with for --no-regression (default: "+analyzer.BaselineFile+")")
+	cmd.Flags().BoolVarP(&o.quiet, "quiet", "q", false, "Print nothing when the check passes")
+	cmd.Flags().StringVar(&o.format, "format", "text", "Output format: text, line for a single key=value line, or github for Actions annotations")
+	cmd.Flags().BoolVar(&o.githubCheck, "github-check", false, "Also post the result as a GitHub check run with inline annotations")
+	cmd.Flags().BoolVar(&o.gitlabNote, "gitlab-note", false, "Also post the result as a note on the GitLab merge request")
+	cmd.Flags().BoolVar(&o.changedOnly, "changed-only", false, "Report only findings in the files changed since --base")
+	cmd.Flags().StringVar(&o.base, "base", "main", "Git revision --changed-only compares with")
+
+	return cmd
+}
+
+// checkOptions are drift check's flags, which drift hook run shares.
+type checkOptions struct {
+	failUnder    float64
+	noRegression bool
+	against      string
+	quiet        bool
+	format       string
+	githubCheck  bool
+	gitlabNote   bool
+
+	// With changedOnly, only findings in the files changed since base are
+	// reported.
+	changedOnly bool
+	base        string
+	// files, when set, are the only files findings are reported for, as
+	// named by a hook manager.
+	files []string
+	// warnOnly prints a failed check without exiting with an error.
+	warnOnly bool
+}
+
+// silenceCheckFailure keeps cobra from printing why a check failed, as
+// runCheck has printed the result already, while err still sets the exit
+// code.
+func silenceCheckFailure(cmd *cobra.Command, err error) error {
+	var ee *exitError
+	if errors.As(err, &ee) && ee.code == exitCheckFailed {
+		cmd.SilenceErrors = true
+	}
+	return err
+}
+
+// runCheck is drift check.
+func runCheck(o checkOptions) error {
+	if o.format != "text" && o.format != "line" && o.format != "github" {
+		return &exitError{exitConfigError, fmt.Errorf("unknown --format %q (want text, line, or github)", o.format)}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return &exitError{exitConfigError, err}
+	}
+	var gh *ci.GitHub
+	if o.githubCheck {
+		if gh, err = ci.GitHubFromEnv(); err != nil {
+			return &exitError{exitConfigError, err}
+		}
+	}
+	var gl *ci.GitLab
+	if o.gitlabNote {
+		if gl, err = ci.GitLabFromEnv(); err != nil {
+			return &exitError{exitConfigError, err}
+		}
+	}
+
+	var changed []string
+	switch {
+	case len(o.files) > 0:
+		if changed = sourceFiles(cfg, o.files); len(changed) == 0 {
+			return nil
+		}
+	case o.changedOnly:
+		if changed, err = changedFiles(cfg, o.base); err != nil {
+			return &exitError{exitConfigError, err}
+		}
+		if len(changed) == 0 {
+			if !o.quiet {
+				fmt.Printf("No source files changed since %s\n", o.base)
+			}
+			return nil
+		}
+	}
+
+	a := analyzer.New(cfg)
+	results, err := a.Run()
+	if err != nil {
+		return &exitError{exitAnalysisError, err}
+	}
+	results, err = applyBaseline(cfg, results)
+	if err != nil {
+		return &exitError{exitConfigError, err}
+	}
+
+	scorer := health.NewScorer(cfg)
+	res := checkResult{
+		score:     scorer.Calculate(results),
+		failUnder: o.failUnder,
+		licenses:  results.LicenseViolations,
+		baselined: results.Baselined,
+	}
+	if o.changedOnly && len(o.files) == 0 {
+		res.changedSince = o.base
+		res.changed = len(changed)
+	}
+	if !o.changedOnly {
+		// Changed-file checks run on uncommitted changes too, which the
+		// recorded commit wouldn't match.
+		recordRun(cfg, "check", res.score, results)
+	}
+	if o.noRegression {
+		res.against = o.against
+		if res.against == "" {
+			res.against = filepath.Join(cfg.Root, analyzer.BaselineFile)
+		}
+		before, err := readScores(res.against)
+		if err != nil {
+			return &exitError{exitConfigError, err}
+		}
+		res.regressions = health.Regressions(before, res.score)
+	}
+
+	findings := changedFindings(cfg, results, changed)
+	if gh != nil {
+		url, err := gh.CreateCheckRun(res.checkRun(findings))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: GitHub check run:", err)
+		} else if !o.quiet {
+			fmt.Println("GitHub check run:", url)
+		}
+	}
+	if gl != nil {
+		if err := gl.UpsertNote(res.note(findings)); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: GitLab merge request note:", err)
+		}
+	}
+
+	if o.quiet && res.reason() == "" {
+		return nil
+	}
+	switch o.format {
+	case "line":
+		fmt.Println(res.line())
+	case "github":
+		if err := ci.WriteAnnotations(os.Stdout, findings); err != nil {
+			return err
+		}
+		if res.reason() != "" {
+			fmt.Printf("::error title=drift check failed::%s\n", res.title())
+		}
+		res.print()
+	default:
+		res.print()
+	}
+	if res.reason() != "" {
+		if o.warnOnly {
+			fmt.Println("(not blocking: --warn-only)")
+			return nil
+		}
+		return &exitError{exitCheckFailed, errors.New(res.reason())}
+	}
+	return nil
+}
+
+// checkResult is what drift check found. With against set, it compared
+// scores with that file instead of the failUnder threshold.
+type checkResult struct {
+	score       health.Score
+	failUnder   float64
+	against     string
+	regressions []health.Regression
+	licenses    []analyzer.LicenseViolation
+	baselined   int
+	// With --changed-only, findings are limited to the changed files since
+	// changedSince.
+	changedSince string
+	changed      int
+}
+
+// reason names why the check failed, or returns "" when it passed.
+func (c checkResult) reason() string {
+	switch {
+	case c.against != "" && len(c.regressions) > 0:
+		return "regression"
+	case c.against == "" && c.score.Total < c.failUnder:
+		return "below-threshold"
+	case len(c.licenses) > 0:
+		return "denied-license"
+	}
+	return ""
+}
+
+// line renders the result as space-separated key=value pairs.
+func (c checkResult) line() string {
+	fields := []string{"result=pass"}
+	if reason := c.reason(); reason != "" {
+		fields = []string{"result=fail", "reason=" + reason}
+	}
+	fields = append(fields, fmt.Sprintf("score=%.1f", c.score.Total))
+	if c.against != "" {
+		fields = append(fields, "against="+c.against)
+		if len(c.regressions) > 0 {
+			regressed := make([]string, len(c.regressions))
+			for i, r := range c.regressions {
+				regressed[i] = r.Category
+			}
+			fields = append(fields, "regressed="+strings.Join(regressed, ","))
+		}
+	} else {
+		fields = append(fields, fmt.Sprintf("threshold=%.1f", c.failUnder))
+	}
+	if len(c.licenses) > 0 {
+		fields = append(fields, fmt.Sprintf("denied_licenses=%d", len(c.licenses)))
+	}
+	fields = append(fields, fmt.Sprintf("baselined=%d", c.baselined))
+	if c.changedSince != "" {
+		fields = append(fields, fmt.Sprintf("changed=%d", c.changed), "base="+c.changedSince)
+	}
+	return strings.Join(fields, " ")
+}
+
+// title headlines the result for a check run or merge request note.
+func (c checkResult) title() string {
+	return fmt.Sprintf("Health score %.1f/100 (%s)", c.score.Total, c.score.Grade())
+}
+
+// summary explains the result in markdown for a check run or merge request
+// note.
+func (c checkResult) summary(findings int) string {
+	var summary string
+	switch c.reason() {
+	case "regression":
+		summary = "❌ Score regressed since " + c.against + ":\n"
+		for _, r := range c.regressions {
+			summary += fmt.Sprintf("\n- %s: %.1f → %.1f", r.Category, r.Before, r.After)
+		}
+	case "below-threshold":
+		summary = fmt.Sprintf("❌ Score %.1f is below threshold %.1f.", c.score.Total, c.failUnder)
+	case "denied-license":
+		summary = fmt.Sprintf("❌ %d dependencies use a denied license.", len(c.licenses))
+	default:
+		summary = "✅ Check passed."
+	}
+	summary += fmt.Sprintf("\n\n%d findings.", findings)
+	if c.changedSince != "" {
+		summary += fmt.Sprintf(" Only findings in the %d files changed since %s are listed.", c.changed, c.changedSince)
+	}
+	return summary
+}
+
+// checkRun describes the result as a GitHub check run annotating findings.
+func (c checkResult) checkRun(findings []health.Finding) ci.CheckRun {
+	return ci.CheckRun{Name: "drift", Title: c.title(), Summary: c.summary(len(findings)), Passed: c.reason() == "", Findings: findings}
+}
+
+// maxNoteFindings caps the findings a merge request note lists; the Code
+// Quality report has them all.
+const maxNoteFindings = 20
+
+// note renders the result as a merge request note listing findings.
+func (c checkResult) note(findings []health.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 📊 Drift Health Check\n\n**%s**\n\n%s\n", c.title(), c.summary(len(findings)))
+	for i, f := range findings {
+		if i == maxNoteFindings {
+			fmt.Fprintf(&b, "- … and %d more\n", len(findings)-i)
+			break
+		}
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "- `%s:%d` %s\n", f.Path, max(1, f.Line), f.Message)
+	}
+	b.WriteString("\n---\n*Powered by [drift](https://github.com/greatnessinabox/drift)*\n")
+	return b.String()
+}
+
+func (c checkResult) print() {
+	score := c.score
+	fmt.Printf("Health Score: %.1f/100\n", score.Total)
+	if c.baselined > 0 {
+		fmt.Printf("(%d baselined issues not counted)\n", c.baselined)
+	}
+	if c.changedSince != "" {
+		fmt.Printf("(findings limited to the %d files changed since %s)\n", c.changed, c.changedSince)
+	}
+
+	switch c.reason() {
+	case "regression":
+		fmt.Printf("❌ Score regressed since %s\n", c.against)
+		for _, r := range c.regressions {
+			fmt.Printf("  %-16s %.1f → %.1f\n", r.Category+":", r.Before, r.After)
+		}
+		return
+	case "below-threshold":
+		fmt.Printf("❌ Score %.1f is below threshold %.1f\n", score.Total, c.failUnder)
+		fmt.Printf("\nBreakdown:\n")
+		fmt.Printf("  Complexity:  %.1f/100\n", score.Complexity)
+		fmt.Printf("  Dependencies: %.1f/100\n", score.Deps)
+		fmt.Printf("  Security:     %.1f/100\n", score.Security)
+		fmt.Printf("  Boundaries:   %.1f/100\n", score.Boundaries)
+		fmt.Printf("  Dead Code:    %.1f/100\n", score.DeadCode)
+		fmt.Printf("  Duplication:  %.1f/100\n", score.Duplication)
+		fmt.Printf("  Debt:         %.1f/100\n", score.Debt)
+		fmt.Printf("  Testing:      %.1f/100\n", score.Testing)
+		fmt.Printf("  Coupling:     %.1f/100\n", score.Coupling)
+		if score.CoverageMeasured {
+			fmt.Printf("  Coverage:     %.1f/100\n", score.Coverage)
+		}
+		if score.MaintainabilityMeasured {
+			fmt.Printf("  Maintainability: %.1f/100\n", score.Maintainability)
+		}
+		return
+	case "denied-license":
+		fmt.Printf("❌ %d dependency(ies) use a denied license\n", len(c.licenses))
+		for _, v := range c.licenses {
+			fmt.Printf("  %s %s: %s (denied: %s)\n", v.Module, v.Version, v.License, v.Denied)
+		}
+		return
+	}
+
+	if c.against != "" {
+		fmt.Printf("✅ No score dropped since %s\n", c.against)
+	} else {
+		fmt.Printf("✅ Score %.1f meets threshold %.1f\n", score.Total, c.failUnder)
+	}
+}
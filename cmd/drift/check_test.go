@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestCheckResult_Line(t *testing.T) {
+	tests := []struct {
+		name string
+		res  checkResult
+		want string
+	}{
+		{
+			"pass",
+			checkResult{score: health.Score{Total: 82.5}, failUnder: 70, baselined: 3},
+			"result=pass score=82.5 threshold=70.0 baselined=3",
+		},
+		{
+			"below threshold",
+			checkResult{score: health.Score{Total: 64.2}, failUnder: 70},
+			"result=fail reason=below-threshold score=64.2 threshold=70.0 baselined=0",
+		},
+		{
+			"regression",
+			checkResult{
+				score:       health.Score{Total: 64.2},
+				failUnder:   70,
+				against:     "main.json",
+				regressions: []health.Regression{{Category: "debt"}, {Category: "total"}},
+			},
+			"result=fail reason=regression score=64.2 against=main.json regressed=debt,total baselined=0",
+		},
+		{
+			"denied license",
+			checkResult{
+				score:     health.Score{Total: 90},
+				failUnder: 70,
+				licenses:  []analyzer.LicenseViolation{{Module: "gpl-thing"}},
+			},
+			"result=fail reason=denied-license score=90.0 threshold=70.0 denied_licenses=1 baselined=0",
+		},
+		{
+			"changed only",
+			checkResult{score: health.Score{Total: 75}, failUnder: 70, changedSince: "main", changed: 4},
+			"result=pass score=75.0 threshold=70.0 baselined=0 changed=4 base=main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.res.line(); got != tt.want {
+				t.Errorf("line() = %q\nwant      %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedFindings(t *testing.T) {
+	cfg := config.Defaults()
+	results := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{
+		{File: "a.go", Path: "pkg/a.go", Name: "A", Line: 3, Complexity: 40},
+		{File: "b.go", Path: "pkg/b.go", Name: "B", Line: 7, Complexity: 40},
+	}}
+
+	if got := changedFindings(cfg, results, nil); len(got) != 2 {
+		t.Errorf("without changed files: %d findings, want 2", len(got))
+	}
+	got := changedFindings(cfg, results, []string{"pkg/b.go"})
+	if len(got) != 1 || got[0].Path != "pkg/b.go" {
+		t.Errorf("changed pkg/b.go: findings = %+v", got)
+	}
+}
+
+func TestCheckCmd_Failed(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def f():\n    return 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rootDir = root
+	t.Cleanup(func() { rootDir = "" })
+
+	cmd := newCheckCmd()
+	cmd.SetArgs([]string{"--fail-under", "101", "--format", "line"})
+	err := cmd.Execute()
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCheckFailed {
+		t.Fatalf("err = %v, want an exit with code %d", err, exitCheckFailed)
+	}
+	if !cmd.SilenceErrors {
+		t.Error("cobra would print the failure the result already shows")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newCompareCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "compare <base> [head]",
+		Short: "Diff the issues and scores of two git refs",
+		Long: `Compare analyzes two revisions, such as branches or tags, and prints each
+category's score at both with the change, then every issue head adds and
+resolves. Without head it compares base with the working tree, so a branch
+can be checked against main before opening a pull request. Both sides leave
+out baselined issues, like drift report.
+
+Example:
+  drift compare main feature-branch
+  drift compare v1.2.0 v1.3.0 --json
+  drift compare main`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			repo, err := history.New(cfg)
+			if err != nil {
+				return err
+			}
+
+			base, err := repo.AnalyzeRef(args[0])
+			if err != nil {
+				return fmt.Errorf("analyzing %s: %w", args[0], err)
+			}
+			headName := "working tree"
+			var head *analyzer.Results
+			if len(args) == 2 {
+				headName = args[1]
+				head, err = repo.AnalyzeRef(args[1])
+			} else {
+				head, err = analyzer.New(cfg).Run()
+			}
+			if err != nil {
+				return fmt.Errorf("analyzing %s: %w", headName, err)
+			}
+			if base, err = applyBaseline(cfg, base); err != nil {
+				return err
+			}
+			if head, err = applyBaseline(cfg, head); err != nil {
+				return err
+			}
+
+			c := tui.Compare(cfg, args[0], base, headName, head)
+			if asJSON {
+				return printJSON(compareJSON(c))
+			}
+			tui.PrintComparison(c)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON")
+	return cmd
+}
+
+// compareJSON is the JSON form of c: every category's score at both
+// revisions, and the issues added and resolved.
+func compareJSON(c tui.Comparison) map[string]interface{} {
+	before, after := c.Before.Categories(), c.After.Categories()
+	scores := make(map[string]map[string]float64, len(after))
+	for cat, v := range after {
+		if b, ok := before[cat]; ok {
+			scores[cat] = map[string]float64{"base": b, "head": v, "delta": v - b}
+		}
+	}
+	added, resolved := c.Added, c.Resolved
+	if added == nil {
+		added = []string{}
+	}
+	if resolved == nil {
+		resolved = []string{}
+	}
+	return map[string]interface{}{
+		"base":     c.Base,
+		"head":     c.Head,
+		"scores":   scores,
+		"added":    added,
+		"resolved": resolved,
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/tui"
+)
+
+func TestCompareJSON(t *testing.T) {
+	c := tui.Comparison{
+		Base:   "main",
+		Head:   "feature",
+		Before: health.Score{Total: 80, Complexity: 90},
+		After:  health.Score{Total: 75, Complexity: 70},
+		Added:  []string{"F (f.go) over complexity 15"},
+	}
+	data, err := json.Marshal(compareJSON(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Base, Head      string
+		Scores          map[string]map[string]float64
+		Added, Resolved []string
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Base != "main" || got.Head != "feature" {
+		t.Errorf("base, head = %q, %q", got.Base, got.Head)
+	}
+	if d := got.Scores["complexity"]["delta"]; d != -20 {
+		t.Errorf("complexity delta = %v, want -20", d)
+	}
+	if len(got.Added) != 1 || got.Resolved == nil || len(got.Resolved) != 0 {
+		t.Errorf("added = %v, resolved = %v; want one added and an empty list resolved", got.Added, got.Resolved)
+	}
+}
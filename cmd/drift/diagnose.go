@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/ai"
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newDiagnoseCmd() *cobra.Command {
+	var function, at string
+
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Ask the AI provider about the codebase, one function, or one violation",
+		Long: `Diagnose sends the health report to the configured AI provider (see the ai
+section of the config) and prints its recommendations, like d in the
+dashboard.
+
+--function asks for a refactoring plan for one function instead, sending its
+full source and the limits it exceeds. Methods can be named with or without
+their type, e.g. Update or model.Update. --at path:line picks the function
+or boundary violation at that line, e.g. when several functions share a
+name.
+
+Example:
+  drift diagnose
+  drift diagnose --function runCheck
+  drift diagnose --at internal/tui/app.go:371`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if function != "" && at != "" {
+				return fmt.Errorf("--function and --at can't be combined")
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			var title, prompt string
+			switch {
+			case function != "":
+				fc, err := results.FindFunction(function, "", 0, "pick one with --at")
+				if err != nil {
+					return err
+				}
+				title = fmt.Sprintf("%s() in %s:%d", fc.Name, fc.Path, fc.Line)
+				prompt = ai.BuildFunctionPrompt(cfg, fc)
+			case at != "":
+				path, line, err := parseLocation(at)
+				if err != nil {
+					return err
+				}
+				if v, ok := violationAt(results, path, line); ok {
+					title = fmt.Sprintf("%s:%d imports %s", path, line, v.Import)
+					prompt = ai.BuildViolationPrompt(cfg, v, path)
+				} else if fc, err := results.FindFunction("", path, line, ""); err == nil {
+					title = fmt.Sprintf("%s() in %s:%d", fc.Name, fc.Path, fc.Line)
+					prompt = ai.BuildFunctionPrompt(cfg, fc)
+				} else {
+					return fmt.Errorf("no function or boundary violation at %s", at)
+				}
+			default:
+				if results, err = applyBaseline(cfg, results); err != nil {
+					return err
+				}
+				title = "the codebase"
+				prompt = ai.BuildDiagnosisPrompt(cfg, health.NewScorer(cfg).Calculate(results), results)
+			}
+
+			fmt.Fprintf(os.Stderr, "Asking %s: %s\n", cfg.AI.Provider, ai.EstimatePrompt(cfg.AI, prompt))
+			text, err := ai.Ask(cmd.Context(), cfg, "diagnose", prompt)
+			if err != nil {
+				return err
+			}
+			tui.PrintDiagnosis(title, cfg.AI.Provider, text)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&function, "function", "", "diagnose one function by name")
+	cmd.Flags().StringVar(&at, "at", "", "diagnose the function or boundary violation at path:line")
+	return cmd
+}
+
+// parseLocation splits path:line.
+func parseLocation(s string) (string, int, error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("%q is not path:line", s)
+	}
+	line, err := strconv.Atoi(s[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("%q is not path:line", s)
+	}
+	return filepath.ToSlash(filepath.Clean(s[:i])), line, nil
+}
+
+// violationAt finds the boundary violation on line of path.
+func violationAt(results *analyzer.Results, path string, line int) (analyzer.BoundaryViolation, bool) {
+	for _, v := range results.Violations {
+		if v.Line == line && results.FindFile(v.File) == path {
+			return v, true
+		}
+	}
+	return analyzer.BoundaryViolation{}, false
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestParseLocation(t *testing.T) {
+	path, line, err := parseLocation("./internal/tui/app.go:371")
+	if err != nil || path != "internal/tui/app.go" || line != 371 {
+		t.Errorf("parseLocation = %q, %d, %v", path, line, err)
+	}
+	for _, bad := range []string{"app.go", "app.go:", ":3", "app.go:0", "app.go:x"} {
+		if _, _, err := parseLocation(bad); err == nil {
+			t.Errorf("parseLocation(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,703 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/ai"
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newFixCmd() *cobra.Command {
+	var o fixOptions
+
+	cmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Interactively fix code health issues with AI-generated patches",
+		Long: `Fix analyzes your codebase and asks the configured AI provider for a patch for
+each function over the complexity, length, parameter or nesting limits. Each patch
+is previewed as a colored diff and, once you confirm it, applied to the
+working tree. With --branch the patches go on a new git branch, one commit
+per fix.
+
+Uses the ai section of .drift.yaml like diagnose: anthropic (ANTHROPIC_API_KEY),
+openai (OPENAI_API_KEY), or copilot, which runs the GitHub Copilot CLI.
+Patches need the code as it is, so fix refuses to run when ai.redact strips
+strings or comments or sends metrics only; masked paths are fine.
+
+Example:
+  drift fix                    # Interactive mode
+  drift fix --limit 3          # Fix top 3 issues only
+  drift fix --batch            # Generate all suggestions, write one review plan
+  drift fix --branch drift/fixes  # Commit each applied patch on a new branch
+  drift fix --non-interactive  # Show suggestions without prompting`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if cfg.AI.Redact.AltersCode() {
+				return fmt.Errorf("drift fix needs the exact code for its patches; it can't run with ai.redact.strings, comments, or metrics_only set")
+			}
+			tui.SetTheme(cfg.Theme)
+
+			a := analyzer.New(cfg)
+			results, err := a.Run()
+			if err != nil {
+				return err
+			}
+
+			scorer := health.NewScorer(cfg)
+			score := scorer.Calculate(results)
+
+			// --batch plans the full issue list unless an explicit limit was set.
+			if o.batch && !cmd.Flags().Changed("limit") {
+				o.limit = 0
+			}
+
+			return runFixWorkflow(cmd.Context(), cfg, score, results, o)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.interactive, "interactive", "i", true, "Prompt for each fix (use --non-interactive to disable)")
+	cmd.Flags().IntVarP(&o.limit, "limit", "n", 5, "Maximum number of issues to fix")
+	cmd.Flags().BoolVar(&o.batch, "batch", false, "Generate all suggestions up front and write a single review plan")
+	cmd.Flags().StringVar(&o.branch, "branch", "", "Create this git branch and commit each applied patch on it")
+
+	return cmd
+}
+
+type fixOptions struct {
+	interactive bool
+	limit       int
+	batch       bool
+	branch      string // created on the first applied patch; empty = apply without committing
+}
+
+func runFixWorkflow(ctx context.Context, cfg *config.Config, score health.Score, results *analyzer.Results, o fixOptions) error {
+	limit := o.limit
+	if cfg.Offline {
+		return ai.ErrOffline
+	}
+	provider, err := ai.NewProvider(fixAIConfig(cfg.AI))
+	if err != nil {
+		fmt.Printf("❌ No AI provider: %v\n", err)
+		fmt.Println("\nSet ai.provider to anthropic, openai, or copilot in .drift.yaml,")
+		fmt.Println("or run 'drift report' for analysis without AI suggestions")
+		return err
+	}
+
+	fmt.Printf("🔍 Analyzing codebase... (Score: %.1f/100)\n\n", score.Total)
+
+	// Collect issues to fix
+	var issues []fixIssue
+
+	// Add complexity issues
+	for i, fc := range results.Complexity {
+		if limit > 0 && i >= limit {
+			break
+		}
+		maxComplexity := cfg.Thresholds.For(fc.Path).MaxComplexity
+		if fc.Complexity > maxComplexity {
+			issues = append(issues, fixIssue{
+				Type:        "complexity",
+				Description: fmt.Sprintf("%s() in %s:%d (complexity: %d)", fc.Name, fc.File, fc.Line, fc.Complexity),
+				File:        fc.File,
+				Path:        fc.Path,
+				Line:        fc.Line,
+				Lines:       fc.Lines,
+				Function:    fc.Name,
+				Value:       fc.Complexity,
+				Severity:    getSeverity(fc.Complexity, maxComplexity),
+			})
+		}
+	}
+
+	// Add function length and parameter count issues
+	if limit <= 0 || len(issues) < limit {
+		issues = append(issues, sizeIssues(cfg, results.Complexity, limit-len(issues))...)
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("✅ No issues found! Your codebase is healthy.")
+		return nil
+	}
+
+	fmt.Printf("Found %d issue(s) to fix:\n\n", len(issues))
+
+	for i, issue := range issues {
+		fmt.Printf("%d. [%s] %s\n", i+1, issue.Severity, issue.Description)
+	}
+
+	fmt.Println()
+
+	if o.batch {
+		return runBatchFix(ctx, provider, cfg, issues)
+	}
+
+	f := &fixer{cfg: cfg, branch: o.branch}
+	// Process each issue
+	for i, issue := range issues {
+		if !o.interactive {
+			// Just show what would be fixed
+			fmt.Printf("\n[%d/%d] %s\n", i+1, len(issues), issue.Description)
+			fmt.Println("  (non-interactive mode: skipping)")
+			continue
+		}
+
+		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		fmt.Printf("[%d/%d] %s\n", i+1, len(issues), issue.Description)
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+		prompt := buildFixPrompt(cfg, issue)
+		fmt.Printf("\n🤖 Asking %s for a patch (%s)...\n", provider.Name(), ai.EstimatePrompt(fixAIConfig(cfg.AI), prompt).Short())
+		suggestion, err := getFixSuggestion(ctx, provider, cfg, prompt)
+		if err != nil {
+			fmt.Printf("❌ Error getting suggestion: %v\n", err)
+			continue
+		}
+
+		explanation, diff, ok := extractPatch(suggestion)
+		if !ok {
+			fmt.Println("\n" + suggestion)
+			fmt.Println("\n⚠️  The answer has no patch to apply; apply it by hand")
+			continue
+		}
+		if explanation != "" {
+			fmt.Println("\n" + explanation)
+		}
+		tui.PrintPatch(diff)
+		if err := applyPatch(cfg.Root, diff, "--check"); err != nil {
+			fmt.Printf("❌ The patch doesn't apply to the working tree: %v\n", err)
+			continue
+		}
+
+		// Ask user what to do
+		fmt.Print("Apply this patch? [y/N/s(kip rest)] ")
+		var response string
+		fmt.Scanln(&response)
+
+		switch response {
+		case "y", "Y", "yes":
+			if err := f.apply(ctx, issue, explanation, diff); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			}
+		case "s", "S", "skip":
+			fmt.Println("⏭️  Skipping remaining issues")
+			return nil
+		default:
+			fmt.Println("⏭️  Skipped")
+		}
+	}
+
+	fmt.Println("\n✨ Fix workflow complete!")
+	fmt.Println("💡 Run 'drift report' to see updated health metrics")
+
+	return nil
+}
+
+// extractPatch splits an answer into its explanation and the unified diff in
+// its diff code block, or, without one, the diff starting at the first
+// "--- " header. ok is false when the answer holds no diff.
+func extractPatch(answer string) (explanation, diff string, ok bool) {
+	lines := strings.Split(strings.ReplaceAll(answer, "\r\n", "\n"), "\n")
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		fence := strings.TrimSpace(line)
+		if fence == "```diff" || fence == "```patch" {
+			start = i
+			break
+		}
+	}
+	if start >= 0 {
+		for i := start + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "```" {
+				end = i + 1
+				break
+			}
+		}
+		diff = strings.Join(lines[start+1:end-1], "\n")
+	} else {
+		for i := 0; i+1 < len(lines); i++ {
+			if strings.HasPrefix(lines[i], "--- ") && strings.HasPrefix(lines[i+1], "+++ ") {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return strings.TrimSpace(answer), "", false
+		}
+		for i := start; i < len(lines); i++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				end = i
+				break
+			}
+		}
+		diff = strings.Join(lines[start:end], "\n")
+	}
+	if len(patchFiles(diff)) == 0 {
+		return strings.TrimSpace(answer), "", false
+	}
+	rest := append(append([]string{}, lines[:start]...), lines[end:]...)
+	return strings.TrimSpace(strings.Join(rest, "\n")), strings.TrimRight(diff, "\n") + "\n", true
+}
+
+// patchFiles lists the files a unified diff changes, in order, without their
+// a/ or b/ prefixes.
+func patchFiles(diff string) []string {
+	var files []string
+	seen := map[string]bool{}
+	var from string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			from = diffPath(line[4:], "a/")
+		case strings.HasPrefix(line, "+++ "):
+			name := diffPath(line[4:], "b/")
+			if name == "/dev/null" {
+				name = from // deleted file
+			}
+			if name != "" && name != "/dev/null" && !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	return files
+}
+
+// diffPath is the path in a diff header, without a trailing timestamp or
+// the prefix.
+func diffPath(header, prefix string) string {
+	name, _, _ := strings.Cut(header, "\t")
+	return strings.TrimPrefix(strings.TrimSpace(name), prefix)
+}
+
+// applyPatch runs git apply with args on a unified diff in the working
+// tree under root: "--check" tests that it would apply and "-R" reverses
+// it. Hunk line counts are recounted, since suggested patches often get them
+// wrong.
+func applyPatch(root, diff string, args ...string) error {
+	args = append([]string{"apply", "--recount"}, args...)
+	cmd := exec.Command("git", append(args, "-")...)
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(diff)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// fixer applies the patches runFixWorkflow accepts.
+type fixer struct {
+	cfg      *config.Config
+	branch   string // empty = apply without committing
+	onBranch bool   // the branch has been created
+}
+
+// apply applies the patch for issue, verifies it, and rolls it back if the
+// verification fails. With a branch, it first creates the branch unless
+// that's done, and commits the patched files on it; it won't patch files
+// that have uncommitted changes, which would end up in the commit.
+func (f *fixer) apply(ctx context.Context, issue fixIssue, explanation, diff string) error {
+	root := f.cfg.Root
+	files := patchFiles(diff)
+	if f.branch != "" {
+		out, err := git(root, append([]string{"status", "--porcelain", "--"}, files...)...)
+		if err != nil {
+			return err
+		}
+		if out != "" {
+			return fmt.Errorf("not applied: %s has uncommitted changes", strings.Join(files, ", "))
+		}
+		if !f.onBranch {
+			if _, err := git(root, "switch", "-c", f.branch); err != nil {
+				return err
+			}
+			f.onBranch = true
+			fmt.Printf("🌿 Switched to a new branch %s\n", f.branch)
+		}
+	}
+
+	if err := applyPatch(root, diff); err != nil {
+		return fmt.Errorf("applying the patch: %w", err)
+	}
+	if err := verifyFix(ctx, f.cfg, issue, files); err != nil {
+		if undo := applyPatch(root, diff, "-R"); undo != nil {
+			return fmt.Errorf("%w; rolling back failed too: %v", err, undo)
+		}
+		return fmt.Errorf("%w; rolled back", err)
+	}
+	if f.branch == "" {
+		fmt.Printf("✅ Patched %s\n", strings.Join(files, ", "))
+		return nil
+	}
+
+	if _, err := git(root, append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	args := []string{"commit", "-q", "-m", fixCommitMessage(issue, explanation), "--"}
+	if _, err := git(root, append(args, files...)...); err != nil {
+		return err
+	}
+	hash, err := git(root, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Committed %s on %s\n", hash, f.branch)
+	return nil
+}
+
+// verifyFix re-analyzes the files a patch for issue changed and checks that
+// they still parse and that the function's measure went down, then runs
+// fix.test if it's configured. A function the patch renamed or removed
+// counts as fixed.
+func verifyFix(ctx context.Context, cfg *config.Config, issue fixIssue, files []string) error {
+	a := analyzer.New(cfg)
+	var funcs []analyzer.FunctionComplexity
+	for _, file := range files {
+		path := filepath.Join(cfg.Root, file)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue // deleted
+		}
+		single, err := a.RunSingle(path)
+		if err != nil {
+			return err
+		}
+		if len(single.Notices) > 0 {
+			return fmt.Errorf("the patched code doesn't parse: %s", single.Notices[0])
+		}
+		funcs = append(funcs, single.Complexity...)
+	}
+
+	measure, limit := issueMeasure(cfg.Thresholds.For(issue.Path), issue.Type)
+	found := false
+	for _, fc := range funcs {
+		if fc.Name != issue.Function || (issue.Path != "" && fc.Path != issue.Path) {
+			continue
+		}
+		found = true
+		after := measure(fc)
+		if after >= issue.Value {
+			return fmt.Errorf("%s of %s didn't drop: %d, was %d", issue.Type, issue.Function, after, issue.Value)
+		}
+		note := ""
+		if after > limit {
+			note = fmt.Sprintf(", still over the limit of %d", limit)
+		}
+		fmt.Printf("🔬 %s of %s: %d → %d%s\n", issue.Type, issue.Function, issue.Value, after, note)
+	}
+	if !found {
+		fmt.Printf("🔬 %s is gone from %s\n", issue.Function, strings.Join(files, ", "))
+	}
+
+	if cfg.Fix.Test == "" {
+		return nil
+	}
+	fmt.Printf("🧪 Running %s...\n", cfg.Fix.Test)
+	if cfg.Fix.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Fix.Timeout)*time.Second)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", cfg.Fix.Test)
+	cmd.Dir = cfg.Root
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s didn't finish within %ds", cfg.Fix.Test, cfg.Fix.Timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w\n%s", cfg.Fix.Test, err, tailLines(string(out), 20))
+	}
+	fmt.Println("🧪 Tests pass")
+	return nil
+}
+
+// issueMeasure returns how a fix issue of type kind is measured, and its
+// limit in t.
+func issueMeasure(t config.ThresholdConfig, kind string) (func(analyzer.FunctionComplexity) int, int) {
+	switch kind {
+	case "length":
+		return func(fc analyzer.FunctionComplexity) int { return fc.Lines }, t.MaxFuncLines
+	case "params":
+		return func(fc analyzer.FunctionComplexity) int { return fc.Params }, t.MaxParams
+	case "nesting":
+		return func(fc analyzer.FunctionComplexity) int { return fc.Nesting }, t.MaxNesting
+	default:
+		return func(fc analyzer.FunctionComplexity) int { return fc.Complexity }, t.MaxComplexity
+	}
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixCommitMessage describes the fix for issue, with the suggestion's
+// explanation as the body.
+func fixCommitMessage(issue fixIssue, explanation string) string {
+	var subject string
+	switch issue.Type {
+	case "length":
+		subject = fmt.Sprintf("Shorten %s from %d lines", issue.Function, issue.Value)
+	case "params":
+		subject = fmt.Sprintf("Reduce the parameters of %s from %d", issue.Function, issue.Value)
+	case "nesting":
+		subject = fmt.Sprintf("Flatten %s from nesting depth %d", issue.Function, issue.Value)
+	default:
+		subject = fmt.Sprintf("Reduce the complexity of %s from %d", issue.Function, issue.Value)
+	}
+	msg := subject
+	if explanation != "" {
+		msg += "\n\n" + explanation
+	}
+	return msg + "\n\nApplied by drift fix.\n"
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+type fixSuggestion struct {
+	issue      fixIssue
+	suggestion string
+	err        error
+}
+
+// runBatchFix generates every suggestion up front, then writes one consolidated
+// review surface instead of prompting per issue — the plan-then-apply flow for
+// reviewing many AI runs at once.
+func runBatchFix(ctx context.Context, provider ai.Provider, cfg *config.Config, issues []fixIssue) error {
+	fmt.Printf("🤖 Generating %d suggestion(s) up front...\n\n", len(issues))
+
+	// ponytail: serial fetch; parallelize with bounded goroutines if latency bites.
+	plan := make([]fixSuggestion, len(issues))
+	for i, issue := range issues {
+		prompt := buildFixPrompt(cfg, issue)
+		fmt.Printf("  [%d/%d] %s (%s)\n", i+1, len(issues), issue.Description, ai.EstimatePrompt(fixAIConfig(cfg.AI), prompt).Short())
+		s, err := getFixSuggestion(ctx, provider, cfg, prompt)
+		plan[i] = fixSuggestion{issue: issue, suggestion: s, err: err}
+	}
+
+	md := renderFixPlan(plan)
+
+	outFile := filepath.Join(cfg.Root, "drift-fixes.md")
+	if err := os.WriteFile(outFile, []byte(md), 0o644); err != nil {
+		return fmt.Errorf("writing fix plan: %w", err)
+	}
+
+	fmt.Print("\n" + md)
+	fmt.Printf("📄 Fix plan written to %s — review all suggestions, then apply.\n", outFile)
+	return nil
+}
+
+// renderFixPlan turns the batch results into a single Markdown review surface.
+func renderFixPlan(plan []fixSuggestion) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# drift fix plan (%d issue(s))\n\n", len(plan))
+	for i, p := range plan {
+		fmt.Fprintf(&b, "## %d. %s\n\n", i+1, p.issue.Description)
+		if p.err != nil {
+			fmt.Fprintf(&b, "_Could not generate a suggestion: %v_\n\n", p.err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n\n", p.suggestion)
+	}
+	return b.String()
+}
+
+type fixIssue struct {
+	Type        string
+	Description string
+	File        string
+	Path        string // File relative to the analysis root
+	Line        int
+	Lines       int // source lines of the function
+	Function    string
+	Value       int // measured complexity, line count, or parameter count
+	Severity    string
+}
+
+// sizeIssues flags functions over the max_func_lines, max_params, or
+// max_nesting limits, with an issue for each limit a function exceeds.
+// A non-positive room means no limit on the number of issues returned.
+func sizeIssues(cfg *config.Config, funcs []analyzer.FunctionComplexity, room int) []fixIssue {
+	var issues []fixIssue
+	for _, fc := range funcs {
+		t := cfg.Thresholds.For(fc.Path)
+		for _, kind := range []string{"length", "params", "nesting"} {
+			measure, limit := issueMeasure(t, kind)
+			value := measure(fc)
+			if limit <= 0 || value <= limit {
+				continue
+			}
+			if room > 0 && len(issues) >= room {
+				return issues
+			}
+			issues = append(issues, fixIssue{
+				Type:        kind,
+				Description: fmt.Sprintf("%s() in %s:%d (%s)", fc.Name, fc.File, fc.Line, sizeLabels[kind](value)),
+				File:        fc.File,
+				Path:        fc.Path,
+				Line:        fc.Line,
+				Lines:       fc.Lines,
+				Function:    fc.Name,
+				Value:       value,
+				Severity:    getSeverity(value, limit),
+			})
+		}
+	}
+	return issues
+}
+
+// sizeLabels describe a size issue's measurement by its type.
+var sizeLabels = map[string]func(int) string{
+	"length":  func(n int) string { return fmt.Sprintf("length: %d lines", n) },
+	"params":  func(n int) string { return fmt.Sprintf("parameters: %d", n) },
+	"nesting": func(n int) string { return fmt.Sprintf("nesting depth: %d", n) },
+}
+
+func getSeverity(complexity, threshold int) string {
+	if complexity > threshold*2 {
+		return "🔴 HIGH"
+	} else if float64(complexity) > float64(threshold)*1.5 {
+		return "🟡 MEDIUM"
+	}
+	return "🟢 LOW"
+}
+
+// fixMaxTokens is the response budget for a patch when ai.max_tokens isn't
+// set; the default budget cuts off the diff of a long function.
+const fixMaxTokens = 4096
+
+// fixAIConfig is the ai config with room for a patch.
+func fixAIConfig(cfg config.AIConfig) config.AIConfig {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = fixMaxTokens
+	}
+	return cfg
+}
+
+// getFixSuggestion asks provider for a patch, recording the call's usage.
+func getFixSuggestion(ctx context.Context, provider ai.Provider, cfg *config.Config, prompt string) (string, error) {
+	text, usage, err := provider.Diagnose(ctx, prompt)
+	ai.RecordUsage(cfg.Root, fixAIConfig(cfg.AI), "fix", usage)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func buildFixPrompt(cfg *config.Config, issue fixIssue) string {
+	path := issue.Path
+	if path == "" {
+		path = issue.File
+	}
+	lines := issue.Lines
+	if lines <= 0 {
+		lines = 30
+	}
+	sourceCode := readFunctionSource(filepath.Join(cfg.Root, path), issue.Line, lines)
+
+	t := cfg.Thresholds.For(path)
+	var goal string
+	switch issue.Type {
+	case "length":
+		goal = fmt.Sprintf(`to shorten it from %d lines to below %d.
+Focus on extracting cohesive helper functions and removing duplication.`,
+			issue.Value, t.MaxFuncLines)
+	case "params":
+		goal = fmt.Sprintf(`to reduce its parameter count from %d to at most %d.
+Focus on grouping related parameters into a struct or options object.`,
+			issue.Value, t.MaxParams)
+	case "nesting":
+		goal = fmt.Sprintf(`to flatten it from nesting depth %d to at most %d.
+Focus on early returns, guard clauses, and extracting inner loops into helpers.`,
+			issue.Value, t.MaxNesting)
+	default:
+		goal = fmt.Sprintf(`to reduce its cyclomatic complexity from %d to below %d.
+Focus on extracting methods, simplifying conditionals, and improving readability.`,
+			extractComplexity(issue.Description), t.MaxComplexity)
+	}
+
+	prompt := fmt.Sprintf(`Refactor the function %s() in %s (starting at line %d) %s
+Do NOT modify files. Reply with a brief explanation, then the change as a
+unified diff in a single diff code block: the path with a/ and b/ prefixes
+(--- a/%s, +++ b/%s), three unchanged context lines around each hunk, and
+context lines copied exactly from the current code. Keep the explanation
+short; the diff may be as long as it needs to be.
+
+Current code (lines %d-%d of %s):
+%s`,
+		issue.Function,
+		path,
+		issue.Line,
+		goal,
+		path, path,
+		issue.Line, issue.Line+lines-1, path,
+		sourceCode)
+
+	return prompt
+}
+
+func readFunctionSource(filePath string, startLine, numLines int) string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Sprintf("// Could not read file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 1
+	var lines []string
+
+	for scanner.Scan() {
+		if lineNum >= startLine && len(lines) < numLines {
+			lines = append(lines, scanner.Text())
+		}
+		if len(lines) >= numLines {
+			break
+		}
+		lineNum++
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func extractComplexity(description string) int {
+	// Extract complexity number from description like "model.Update() (complexity: 25)"
+	re := regexp.MustCompile(`complexity:\s*(\d+)`)
+	matches := re.FindStringSubmatch(description)
+	if len(matches) > 1 {
+		complexity, _ := strconv.Atoi(matches[1])
+		return complexity
+	}
+	return 15 // default
+}
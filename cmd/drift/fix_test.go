@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
 )
 
 func TestRenderFixPlan(t *testing.T) {
@@ -26,3 +33,221 @@ func TestRenderFixPlan(t *testing.T) {
 		}
 	}
 }
+
+func TestSizeIssues(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Thresholds.MaxFuncLines = 50
+	cfg.Thresholds.MaxParams = 4
+
+	funcs := []analyzer.FunctionComplexity{
+		{Name: "long", Lines: 120, Params: 1},
+		{Name: "wide", Lines: 10, Params: 7},
+		{Name: "fine", Lines: 10, Params: 2},
+	}
+
+	issues := sizeIssues(cfg, funcs, 0)
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2: %+v", len(issues), issues)
+	}
+	if issues[0].Type != "length" || issues[0].Value != 120 {
+		t.Errorf("first issue = %+v, want length 120", issues[0])
+	}
+	if issues[1].Type != "params" || issues[1].Value != 7 {
+		t.Errorf("second issue = %+v, want params 7", issues[1])
+	}
+	if !strings.Contains(buildFixPrompt(cfg, issues[1]), "parameter count from 7 to at most 4") {
+		t.Error("params prompt does not state the parameter goal")
+	}
+
+	deep := sizeIssues(cfg, []analyzer.FunctionComplexity{{Name: "deep", Nesting: 7}}, 0)
+	if len(deep) != 1 || deep[0].Type != "nesting" || deep[0].Value != 7 {
+		t.Errorf("nesting issue = %+v, want nesting 7", deep)
+	} else if !strings.Contains(buildFixPrompt(cfg, deep[0]), "nesting depth 7 to at most 4") {
+		t.Error("nesting prompt does not state the depth goal")
+	}
+
+	if got := sizeIssues(cfg, funcs, 1); len(got) != 1 {
+		t.Errorf("room 1: got %d issues, want 1", len(got))
+	}
+
+	both := sizeIssues(cfg, []analyzer.FunctionComplexity{{Name: "both", Lines: 60, Params: 6, Nesting: 5}}, 0)
+	if len(both) != 3 || both[0].Type != "length" || both[1].Type != "params" || both[2].Type != "nesting" {
+		t.Errorf("issues = %+v, want one per exceeded limit", both)
+	}
+
+	// Length and parameter limits are off unless configured.
+	if got := sizeIssues(config.Defaults(), funcs, 0); len(got) != 0 {
+		t.Errorf("default limits flagged %+v", got)
+	}
+}
+
+func TestSizeIssues_Areas(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "legacy"), 0o755)
+	os.WriteFile(filepath.Join(root, ".drift.yaml"), []byte("thresholds:\n  max_params: 4\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "legacy", ".drift.yaml"), []byte("thresholds:\n  max_params: 8\n"), 0o644)
+	cfg, err := config.Load(filepath.Join(root, ".drift.yaml"), config.Override{Key: "root", Value: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := sizeIssues(cfg, []analyzer.FunctionComplexity{
+		{Name: "wide", Path: "api/h.go", Params: 7},
+		{Name: "old", Path: "legacy/h.go", Params: 7},
+		{Name: "older", Path: "legacy/x.go", Params: 9},
+	}, 0)
+	if len(issues) != 2 || issues[0].Function != "wide" || issues[1].Function != "older" {
+		t.Fatalf("issues = %+v, want wide and older, judged by their directories' limits", issues)
+	}
+	if !strings.Contains(buildFixPrompt(cfg, issues[1]), "parameter count from 9 to at most 8") {
+		t.Error("prompt does not state the subdirectory's limit")
+	}
+	if _, limit := issueMeasure(cfg.Thresholds.For(issues[1].Path), issues[1].Type); limit != 8 {
+		t.Errorf("fix verified against limit %d, want 8", limit)
+	}
+}
+
+const fixDiff = `--- a/pkg/calc.go
++++ b/pkg/calc.go
+@@ -1,3 +1,3 @@
+ package pkg
+ 
+-func add(a, b int) int { return a - b }
++func add(a, b int) int { return a + b }
+`
+
+func TestExtractPatch(t *testing.T) {
+	answer := "Flip the operator.\n\n```diff\n" + fixDiff + "```\n\nThat's all."
+	explanation, diff, ok := extractPatch(answer)
+	if !ok || diff != fixDiff {
+		t.Fatalf("fenced: ok = %v, diff =\n%s", ok, diff)
+	}
+	if explanation != "Flip the operator.\n\n\nThat's all." {
+		t.Errorf("explanation = %q", explanation)
+	}
+
+	if _, diff, ok := extractPatch("Flip the operator.\n\n" + fixDiff); !ok || diff != fixDiff {
+		t.Errorf("unfenced: ok = %v, diff =\n%s", ok, diff)
+	}
+	if _, _, ok := extractPatch("Extract a helper.\n\n```go\nfunc add() {}\n```"); ok {
+		t.Error("found a patch in an answer without one")
+	}
+}
+
+func TestPatchFiles(t *testing.T) {
+	diff := fixDiff + "--- a/old.go\t2024-01-01\n+++ /dev/null\n@@ -1 +0,0 @@\n-package pkg\n"
+	got := strings.Join(patchFiles(diff), " ")
+	if got != "pkg/calc.go old.go" {
+		t.Errorf("patchFiles = %q, want pkg/calc.go old.go", got)
+	}
+}
+
+func TestFixer_Branch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package pkg\n\nfunc add(a, b int) int { return a - b }\n"
+	if err := os.WriteFile(filepath.Join(root, "pkg", "calc.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "dev@example.com"},
+		{"config", "user.name", "dev"},
+		{"add", "."},
+		{"commit", "-qm", "initial"},
+	} {
+		if _, err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := applyPatch(root, fixDiff, "--check"); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	cfg := config.Defaults()
+	cfg.Root = root
+	issue := fixIssue{Type: "complexity", Path: "pkg/calc.go", Function: "add", Value: 18}
+	f := &fixer{cfg: cfg, branch: "drift/fixes"}
+	if err := f.apply(context.Background(), issue, "Flip the operator.", fixDiff); err != nil {
+		t.Fatal(err)
+	}
+	if !f.onBranch {
+		t.Error("onBranch not set after creating the branch")
+	}
+
+	if branch, _ := git(root, "branch", "--show-current"); branch != "drift/fixes" {
+		t.Errorf("branch = %q, want drift/fixes", branch)
+	}
+	msg, _ := git(root, "log", "-1", "--format=%B")
+	if !strings.HasPrefix(msg, "Reduce the complexity of add from 18\n\nFlip the operator.") {
+		t.Errorf("commit message = %q", msg)
+	}
+	if status, _ := git(root, "status", "--porcelain"); status != "" {
+		t.Errorf("uncommitted changes after the fix: %q", status)
+	}
+
+	// The same patch no longer applies.
+	if err := f.apply(context.Background(), issue, "", fixDiff); err == nil {
+		t.Error("applied a patch twice")
+	}
+}
+
+func TestFixer_RollsBack(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := "package pkg\n\nfunc add(a, b int) int { return a - b }\n"
+	for name, tc := range map[string]struct {
+		issue fixIssue
+		test  string
+		diff  string
+		want  string
+	}{
+		"no drop": {
+			issue: fixIssue{Type: "complexity", Path: "pkg/calc.go", Function: "add", Value: 1},
+			diff:  fixDiff,
+			want:  "complexity of add didn't drop: 1, was 1",
+		},
+		"tests fail": {
+			issue: fixIssue{Type: "complexity", Path: "pkg/calc.go", Function: "add", Value: 18},
+			test:  "echo 1 failed; exit 1",
+			diff:  fixDiff,
+			want:  "1 failed",
+		},
+		"syntax error": {
+			issue: fixIssue{Type: "complexity", Path: "pkg/calc.go", Function: "add", Value: 18},
+			diff:  strings.Replace(fixDiff, "a + b }", "a + b", 1),
+			want:  "doesn't parse",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "pkg", "calc.go"), []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := git(root, "init", "-q"); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.Defaults()
+			cfg.Root = root
+			cfg.Fix.Test = tc.test
+			f := &fixer{cfg: cfg}
+			err := f.apply(context.Background(), tc.issue, "", tc.diff)
+			if err == nil || !strings.Contains(err.Error(), tc.want) || !strings.Contains(err.Error(), "rolled back") {
+				t.Fatalf("apply error = %v, want %q and a rollback", err, tc.want)
+			}
+			got, _ := os.ReadFile(filepath.Join(root, "pkg", "calc.go"))
+			if string(got) != src {
+				t.Errorf("file after rollback =\n%s", got)
+			}
+		})
+	}
+}
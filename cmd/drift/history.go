@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the scores of past report, check, and snapshot runs",
+		Long: `History reads the runs recorded in ` + history.StoreFile + `: every drift report,
+check, and snapshot appends its scores, issue counts, and commit there. The
+dashboard's sparklines chart the last ten runs once two are recorded,
+instead of re-analyzing past commits. Add .drift/ to your .gitignore.`,
+	}
+	cmd.AddCommand(newHistoryListCmd())
+	cmd.AddCommand(newHistoryShowCmd())
+	return cmd
+}
+
+func newHistoryListCmd() *cobra.Command {
+	var limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded runs, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			records, err := history.Load(cfg.Root)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				if limit > 0 && len(records) > limit {
+					records = records[len(records)-limit:]
+				}
+				return printJSON(records)
+			}
+			tui.PrintHistory(records, limit)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of runs to list (0 = all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON instead of a table, oldest first")
+	return cmd
+}
+
+func newHistoryShowCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "show [id]",
+		Short: "Show every score and count of a recorded run (default: the latest)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			records, err := history.Load(cfg.Root)
+			if err != nil {
+				return err
+			}
+			id, err := recordID(records, args)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(records[id-1])
+			}
+			tui.PrintRecord(id, records[id-1])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON")
+	return cmd
+}
+
+// recordID is the ID of the recorded run args name, or the latest run's
+// without one.
+func recordID(records []history.Record, args []string) (int, error) {
+	if len(records) == 0 {
+		return 0, fmt.Errorf("no runs recorded in %s yet", history.StoreFile)
+	}
+	if len(args) == 0 {
+		return len(records), nil
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 || id > len(records) {
+		return 0, fmt.Errorf("no run %q; IDs go from 1 to %d", args[0], len(records))
+	}
+	return id, nil
+}
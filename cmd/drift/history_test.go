@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+func TestRecordID(t *testing.T) {
+	if _, err := recordID(nil, nil); err == nil || !strings.Contains(err.Error(), "no runs recorded") {
+		t.Errorf("no records: err = %v", err)
+	}
+
+	records := make([]history.Record, 3)
+	for _, tt := range []struct {
+		args []string
+		want int // 0 for an error
+	}{
+		{nil, 3},
+		{[]string{"1"}, 1},
+		{[]string{"3"}, 3},
+		{[]string{"0"}, 0},
+		{[]string{"4"}, 0},
+		{[]string{"-1"}, 0},
+		{[]string{"latest"}, 0},
+	} {
+		id, err := recordID(records, tt.args)
+		if tt.want == 0 {
+			if err == nil || !strings.Contains(err.Error(), "IDs go from 1 to 3") {
+				t.Errorf("recordID(%q) = %d, %v; want an error naming the range", tt.args, id, err)
+			}
+		} else if err != nil || id != tt.want {
+			t.Errorf("recordID(%q) = %d, %v; want %d", tt.args, id, err, tt.want)
+		}
+	}
+}
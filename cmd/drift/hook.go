@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies hooks drift wrote, which install may replace.
+const hookMarker = "# Installed by drift hook install."
+
+func newHookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Check changed files before they are committed or pushed",
+	}
+	cmd.AddCommand(newHookInstallCmd())
+	cmd.AddCommand(newHookRunCmd())
+	return cmd
+}
+
+func newHookInstallCmd() *cobra.Command {
+	var hook string
+	var force bool
+	var o checkOptions
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install a git hook that runs drift on changed files",
+		Long: `Install writes a git pre-commit or pre-push hook that runs ` + "`drift hook run`" + `,
+checking only the files changed in the commit or the pushed branch. The
+strictness flags are written into the hook; install again to change them.
+A hook drift didn't write is left alone unless --force is given.
+
+Example:
+  drift hook install
+  drift hook install --type pre-push --no-regression
+  drift hook install --warn-only`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base := o.base
+			switch hook {
+			case "pre-commit":
+				// What is staged or modified, since the last commit.
+				base = "HEAD"
+			case "pre-push":
+			default:
+				return fmt.Errorf("unknown --type %q (want pre-commit or pre-push)", hook)
+			}
+			out, err := exec.Command("git", "rev-parse", "--git-path", "hooks/"+hook).Output()
+			if err != nil {
+				return fmt.Errorf("finding the git hooks directory (is this a git repository?): %w", err)
+			}
+			path := strings.TrimSpace(string(out))
+			if existing, err := os.ReadFile(path); err == nil && !force && !bytes.Contains(existing, []byte(hookMarker)) {
+				return fmt.Errorf("%s already exists; pass --force to replace it", path)
+			}
+
+			run := []string{"drift", "hook", "run", "--base", base}
+			if o.noRegression {
+				run = append(run, "--no-regression")
+				if o.against != "" {
+					run = append(run, "--against", o.against)
+				}
+			} else {
+				run = append(run, "--fail-under", strconv.FormatFloat(o.failUnder, 'f', -1, 64))
+			}
+			if o.warnOnly {
+				run = append(run, "--warn-only")
+			}
+			script := "#!/bin/sh\n" + hookMarker + "\n" +
+				"command -v drift >/dev/null 2>&1 || { echo \"drift not found on PATH; skipping the health check\" >&2; exit 0; }\n" +
+				"exec " + strings.Join(run, " ") + "\n"
+
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+				return err
+			}
+			fmt.Printf("Installed %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&hook, "type", "pre-commit", "Hook to install: pre-commit or pre-push")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing hook drift didn't write")
+	hookFlags(cmd, &o)
+	return cmd
+}
+
+func newHookRunCmd() *cobra.Command {
+	var o checkOptions
+
+	cmd := &cobra.Command{
+		Use:   "run [files...]",
+		Short: "Check the given or changed files, as a git hook",
+		Long: `Run checks the files a hook is about to commit or push: those named as
+arguments, as the pre-commit framework passes them, or else those changed
+since --base. It fails like ` + "`drift check`" + ` unless --warn-only is given, and
+passes when no source files changed.
+
+For the pre-commit framework, add to .pre-commit-config.yaml:
+
+  - repo: https://github.com/greatnessinabox/drift
+    rev: <version>
+    hooks:
+      - id: drift`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.files = args
+			o.changedOnly = true
+			o.quiet = true
+			o.format = "text"
+			return silenceCheckFailure(cmd, runCheck(o))
+		},
+	}
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{exitConfigError, err}
+	})
+
+	hookFlags(cmd, &o)
+	return cmd
+}
+
+// hookFlags adds the strictness flags hook install writes into the hook and
+// hook run reads.
+func hookFlags(cmd *cobra.Command, o *checkOptions) {
+	cmd.Flags().Float64Var(&o.failUnder, "fail-under", 70.0, "Minimum health score required (0-100)")
+	cmd.Flags().BoolVar(&o.noRegression, "no-regression", false, "Fail only if a score dropped below the baseline's, instead of using --fail-under")
+	cmd.Flags().StringVar(&o.against, "against", "", "Snapshot JSON to compare with for --no-regression (default: "+analyzer.BaselineFile+")")
+	cmd.Flags().BoolVar(&o.warnOnly, "warn-only", false, "Print a failed check without blocking the commit or push")
+	cmd.Flags().StringVar(&o.base, "base", "main", "Git revision to find changed files since")
+}
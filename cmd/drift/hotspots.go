@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newHotspotsCmd() *cobra.Command {
+	var commits, limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "hotspots",
+		Short: "Rank files that are both complex and frequently changed",
+		Long: `Hotspots combines git churn (how many recent commits touched a file) with
+the file's total cyclomatic complexity. Files high on both are where
+refactoring pays off most.
+
+Example:
+  drift hotspots --commits 200 --limit 20`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			histAna, err := history.New(cfg)
+			if err != nil {
+				return err
+			}
+			churn, err := histAna.Churn(commits)
+			if err != nil {
+				return err
+			}
+
+			spots := history.Hotspots(churn, results.Files)
+			if limit > 0 && len(spots) > limit {
+				spots = spots[:limit]
+			}
+			if asJSON {
+				return tui.PrintHotspotsJSON(spots)
+			}
+			tui.PrintHotspots(spots, commits)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&commits, "commits", 100, "number of recent commits to measure churn over")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "maximum number of files to list (0 = all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON instead of a table")
+	return cmd
+}
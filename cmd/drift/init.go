@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newInitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "Initialize drift configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return config.RunInitWizard()
+		},
+	}
+}
@@ -3,18 +3,19 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/analyzer"
 	"github.com/greatnessinabox/drift/internal/config"
 	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
 	"github.com/greatnessinabox/drift/internal/tui"
 	"github.com/greatnessinabox/drift/internal/watcher"
 	"github.com/spf13/cobra"
@@ -23,6 +24,19 @@ import (
 var (
 	version = "dev"
 	cfgFile string
+
+	// Per-run settings, overriding the config file and DRIFT_* variables.
+	profile    string
+	rootDir    string
+	language   string
+	exclude    []string
+	aiProvider string
+	settings   []string
+
+	// Dashboard flags, overriding the watch config.
+	noWatch bool
+	refresh time.Duration
+	compare string
 )
 
 func main() {
@@ -34,434 +48,215 @@ func main() {
 		RunE:    runDashboard,
 	}
 
-	root.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default: .drift.yaml)")
+	root.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default: $DRIFT_CONFIG or .drift.yaml)")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "apply this profile from the config's profiles (default: $DRIFT_PROFILE)")
+	root.PersistentFlags().StringVar(&rootDir, "root", "", "directory to analyze, overriding root")
+	root.PersistentFlags().StringVar(&language, "language", "", "language to analyze instead of detecting it or those in languages (e.g. go, typescript)")
+	root.PersistentFlags().StringSliceVar(&exclude, "exclude", nil, "directories or globs to exclude, replacing exclude (repeatable or comma-separated)")
+	root.PersistentFlags().StringVar(&aiProvider, "ai-provider", "", "AI provider, overriding ai.provider (anthropic, openai, copilot)")
+	root.PersistentFlags().StringArrayVar(&settings, "set", nil, "override any setting by its key in the config file, e.g. --set thresholds.max_complexity=20 (repeatable)")
+	root.Flags().BoolVar(&noWatch, "no-watch", false, "don't re-analyze files as they are saved")
+	root.Flags().DurationVar(&refresh, "refresh", 0, "re-analyze everything at this interval when not watching (e.g. 5m)")
+	root.Flags().StringVar(&compare, "compare", "", "open on a comparison of the working tree with this git ref (e.g. main)")
 
 	root.AddCommand(newReportCmd())
 	root.AddCommand(newSnapshotCmd())
 	root.AddCommand(newInitCmd())
 	root.AddCommand(newCheckCmd())
 	root.AddCommand(newFixCmd())
+	root.AddCommand(newHotspotsCmd())
+	root.AddCommand(newBaselineCmd())
+	root.AddCommand(newBadgeCmd())
+	root.AddCommand(newExportCmd())
+	root.AddCommand(newServeCmd())
+	root.AddCommand(newMCPCmd())
+	root.AddCommand(newNotifyCmd())
+	root.AddCommand(newHookCmd())
+	root.AddCommand(newHistoryCmd())
+	root.AddCommand(newTrendCmd())
+	root.AddCommand(newOwnersCmd())
+	root.AddCommand(newBisectCmd())
+	root.AddCommand(newCompareCmd())
+	root.AddCommand(newDiagnoseCmd())
+	root.AddCommand(newReviewCmd())
+	root.AddCommand(newAICmd())
 
 	if err := root.Execute(); err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
 		os.Exit(1)
 	}
 }
 
-func runDashboard(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load(cfgFile)
-	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
-	}
-
-	a := analyzer.New(cfg)
-
-	results, err := a.Run()
-	if err != nil {
-		return fmt.Errorf("initial analysis: %w", err)
+// loadConfig loads the config with the per-run flags applied over it.
+func loadConfig() (*config.Config, error) {
+	var overrides []config.Override
+	for _, set := range settings {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %s: want key=value", set)
+		}
+		overrides = append(overrides, config.Override{Key: strings.TrimSpace(key), Value: value})
 	}
-
-	scorer := health.NewScorer(cfg)
-	score := scorer.Calculate(results)
-
-	w, err := watcher.New(cfg.Root, cfg.Exclude, a.Extensions())
-	if err != nil {
-		return fmt.Errorf("creating watcher: %w", err)
+	if rootDir != "" {
+		overrides = append(overrides, config.Override{Key: "root", Value: rootDir})
 	}
-
-	app := tui.New(cfg, a, scorer, score, results, w)
-	return app.Run()
-}
-
-func newReportCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "report",
-		Short: "Generate a terminal-formatted health report",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(cfgFile)
-			if err != nil {
-				return err
-			}
-			a := analyzer.New(cfg)
-			results, err := a.Run()
-			if err != nil {
-				return err
-			}
-			scorer := health.NewScorer(cfg)
-			score := scorer.Calculate(results)
-			tui.PrintReport(cfg, score, results)
-			return nil
-		},
+	if language != "" {
+		// One language, in place of any the config lists.
+		overrides = append(overrides, config.Override{Key: "language", Value: language}, config.Override{Key: "languages", Value: ""})
 	}
-}
-
-func newSnapshotCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "snapshot",
-		Short: "Output a JSON health snapshot for CI",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(cfgFile)
-			if err != nil {
-				return err
-			}
-			a := analyzer.New(cfg)
-			results, err := a.Run()
-			if err != nil {
-				return err
-			}
-			scorer := health.NewScorer(cfg)
-			score := scorer.Calculate(results)
-			return tui.PrintSnapshot(score, results)
-		},
+	if exclude != nil {
+		overrides = append(overrides, config.Override{Key: "exclude", Value: strings.Join(exclude, ",")})
 	}
-}
-
-func newInitCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "init",
-		Short: "Initialize drift configuration",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return config.RunInitWizard()
-		},
+	if aiProvider != "" {
+		overrides = append(overrides, config.Override{Key: "ai.provider", Value: aiProvider})
 	}
+	return config.LoadProfile(cfgFile, profile, overrides...)
 }
 
-func newCheckCmd() *cobra.Command {
-	var failUnder float64
-
-	cmd := &cobra.Command{
-		Use:   "check",
-		Short: "Check health score and exit with error if below threshold (for CI)",
-		Long: `Check runs analysis and exits with code 1 if the health score is below the threshold.
-Useful for CI pipelines to enforce code health standards.
-
-Example:
-  drift check --fail-under 70`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(cfgFile)
-			if err != nil {
-				return err
-			}
-
-			a := analyzer.New(cfg)
-			results, err := a.Run()
-			if err != nil {
-				return err
-			}
-
-			scorer := health.NewScorer(cfg)
-			score := scorer.Calculate(results)
-
-			fmt.Printf("Health Score: %.1f/100\n", score.Total)
-
-			if score.Total < failUnder {
-				fmt.Printf("❌ Score %.1f is below threshold %.1f\n", score.Total, failUnder)
-				fmt.Printf("\nBreakdown:\n")
-				fmt.Printf("  Complexity:  %.1f/100\n", score.Complexity)
-				fmt.Printf("  Dependencies: %.1f/100\n", score.Deps)
-				fmt.Printf("  Boundaries:   %.1f/100\n", score.Boundaries)
-				fmt.Printf("  Dead Code:    %.1f/100\n", score.DeadCode)
-				os.Exit(1)
-			}
-
-			fmt.Printf("✅ Score %.1f meets threshold %.1f\n", score.Total, failUnder)
-			return nil
-		},
-	}
-
-	cmd.Flags().Float64Var(&failUnder, "fail-under", 70.0, "Minimum health score required (0-100)")
-
-	return cmd
-}
-
-func newFixCmd() *cobra.Command {
-	var interactive bool
-	var limit int
-	var batch bool
-
-	cmd := &cobra.Command{
-		Use:   "fix",
-		Short: "Interactively fix code health issues using GitHub Copilot CLI",
-		Long: `Fix analyzes your codebase and uses GitHub Copilot CLI to suggest improvements
-for code health issues like high complexity, outdated dependencies, and more.
-
-Requires GitHub Copilot CLI to be installed (any one of):
-  brew install copilot-cli
-  npm install -g @github/copilot
-  curl -fsSL https://gh.io/copilot-install | bash
-
-Example:
-  drift fix                    # Interactive mode
-  drift fix --limit 3          # Fix top 3 issues only
-  drift fix --batch            # Generate all suggestions, write one review plan
-  drift fix --non-interactive  # Show suggestions without prompting`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(cfgFile)
-			if err != nil {
-				return err
-			}
-
-			a := analyzer.New(cfg)
-			results, err := a.Run()
-			if err != nil {
-				return err
-			}
-
-			scorer := health.NewScorer(cfg)
-			score := scorer.Calculate(results)
-
-			// --batch plans the full issue list unless an explicit limit was set.
-			if batch && !cmd.Flags().Changed("limit") {
-				limit = 0
-			}
-
-			return runFixWorkflow(cfg, score, results, interactive, limit, batch)
-		},
-	}
-
-	cmd.Flags().BoolVarP(&interactive, "interactive", "i", true, "Prompt for each fix (use --non-interactive to disable)")
-	cmd.Flags().IntVarP(&limit, "limit", "n", 5, "Maximum number of issues to fix")
-	cmd.Flags().BoolVar(&batch, "batch", false, "Generate all suggestions up front and write a single review plan")
-
-	return cmd
-}
-
-func runFixWorkflow(cfg *config.Config, score health.Score, results *analyzer.Results, interactive bool, limit int, batch bool) error {
-	// Check if copilot CLI is available
-	if !isCopilotAvailable() {
-		fmt.Println("❌ GitHub Copilot CLI not found")
-		fmt.Println("\nInstall it with one of:")
-		fmt.Println("  brew install copilot-cli")
-		fmt.Println("  npm install -g @github/copilot")
-		fmt.Println("  curl -fsSL https://gh.io/copilot-install | bash")
-		fmt.Println("\nAlternatively, run 'drift report' for analysis without AI suggestions")
-		return fmt.Errorf("copilot CLI not available")
+func runDashboard(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
 	}
-
-	fmt.Printf("🔍 Analyzing codebase... (Score: %.1f/100)\n\n", score.Total)
-
-	// Collect issues to fix
-	var issues []fixIssue
-
-	// Add complexity issues
-	for i, fc := range results.Complexity {
-		if limit > 0 && i >= limit {
-			break
-		}
-		if fc.Complexity > cfg.Thresholds.MaxComplexity {
-			issues = append(issues, fixIssue{
-				Type:        "complexity",
-				Description: fmt.Sprintf("%s() in %s:%d (complexity: %d)", fc.Name, fc.File, fc.Line, fc.Complexity),
-				File:        fc.File,
-				Line:        fc.Line,
-				Function:    fc.Name,
-				Severity:    getSeverity(fc.Complexity, cfg.Thresholds.MaxComplexity),
-			})
-		}
+	tui.SetTheme(cfg.Theme)
+	if noWatch {
+		cfg.Watch.Disabled = true
 	}
-
-	if len(issues) == 0 {
-		fmt.Println("✅ No issues found! Your codebase is healthy.")
-		return nil
+	if refresh > 0 {
+		cfg.Watch.Refresh = refresh.String()
 	}
 
-	fmt.Printf("Found %d issue(s) to fix:\n\n", len(issues))
+	a := analyzer.New(cfg)
 
-	for i, issue := range issues {
-		fmt.Printf("%d. [%s] %s\n", i+1, issue.Severity, issue.Description)
+	results, err := a.Run()
+	if err != nil {
+		return fmt.Errorf("initial analysis: %w", err)
 	}
 
-	fmt.Println()
-
-	if batch {
-		return runBatchFix(cfg, issues)
+	baseline, err := analyzer.LoadBaseline(cfg.Root)
+	if err != nil {
+		return fmt.Errorf("reading baseline: %w", err)
 	}
 
-	// Process each issue
-	for i, issue := range issues {
-		if !interactive {
-			// Just show what would be fixed
-			fmt.Printf("\n[%d/%d] %s\n", i+1, len(issues), issue.Description)
-			fmt.Println("  (non-interactive mode: skipping)")
-			continue
-		}
-
-		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("[%d/%d] %s\n", i+1, len(issues), issue.Description)
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	scorer := health.NewScorer(cfg)
+	scorer.LoadState(cfg.Root)
 
-		// Get Copilot suggestion
-		fmt.Println("\n🤖 Asking GitHub Copilot for suggestions...")
-		suggestion, err := getCopilotSuggestion(cfg, issue)
+	var w *watcher.Watcher
+	if !cfg.Watch.Disabled {
+		w, err = watcher.Open(cfg, a.Extensions())
 		if err != nil {
-			fmt.Printf("❌ Error getting suggestion: %v\n", err)
-			continue
-		}
-
-		fmt.Println("\n" + suggestion)
-		fmt.Println()
-
-		// Ask user what to do
-		fmt.Print("Apply this suggestion? [y/N/s(kip rest)] ")
-		var response string
-		fmt.Scanln(&response)
-
-		switch response {
-		case "y", "Y", "yes":
-			fmt.Println("✅ Note: Copy the suggestion above and apply manually")
-			fmt.Println("   (Automatic application coming in a future update)")
-		case "s", "S", "skip":
-			fmt.Println("⏭️  Skipping remaining issues")
-			return nil
-		default:
-			fmt.Println("⏭️  Skipped")
+			return fmt.Errorf("creating watcher: %w", err)
 		}
 	}
 
-	fmt.Println("\n✨ Fix workflow complete!")
-	fmt.Println("💡 Run 'drift report' to see updated health metrics")
-
+	app := tui.New(cfg, a, scorer, baseline, results, w)
+	if compare != "" {
+		app.CompareWith(compare)
+	}
+	if err := app.Run(); err != nil {
+		return err
+	}
+	scorer.SaveState(app.Score().Total)
 	return nil
 }
 
-type fixSuggestion struct {
-	issue      fixIssue
-	suggestion string
-	err        error
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
-// runBatchFix generates every suggestion up front, then writes one consolidated
-// review surface instead of prompting per issue — the plan-then-apply flow for
-// reviewing many AI runs at once.
-func runBatchFix(cfg *config.Config, issues []fixIssue) error {
-	fmt.Printf("🤖 Generating %d suggestion(s) up front...\n\n", len(issues))
-
-	// ponytail: serial fetch; parallelize with bounded goroutines if latency bites.
-	plan := make([]fixSuggestion, len(issues))
-	for i, issue := range issues {
-		fmt.Printf("  [%d/%d] %s\n", i+1, len(issues), issue.Description)
-		s, err := getCopilotSuggestion(cfg, issue)
-		plan[i] = fixSuggestion{issue: issue, suggestion: s, err: err}
+// changedFiles lists the source files changed since base that the
+// configuration would analyze.
+func changedFiles(cfg *config.Config, base string) ([]string, error) {
+	repo, err := history.New(cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	md := renderFixPlan(plan)
-
-	outFile := filepath.Join(cfg.Root, "drift-fixes.md")
-	if err := os.WriteFile(outFile, []byte(md), 0o644); err != nil {
-		return fmt.Errorf("writing fix plan: %w", err)
+	files, err := repo.ChangedFiles(base)
+	if err != nil {
+		return nil, err
 	}
-
-	fmt.Print("\n" + md)
-	fmt.Printf("📄 Fix plan written to %s — review all suggestions, then apply.\n", outFile)
-	return nil
+	return sourceFiles(cfg, files), nil
 }
 
-// renderFixPlan turns the batch results into a single Markdown review surface.
-func renderFixPlan(plan []fixSuggestion) string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "# drift fix plan (%d issue(s))\n\n", len(plan))
-	for i, p := range plan {
-		fmt.Fprintf(&b, "## %d. %s\n\n", i+1, p.issue.Description)
-		if p.err != nil {
-			fmt.Fprintf(&b, "_Could not generate a suggestion: %v_\n\n", p.err)
-			continue
+// changedFindings lists the findings in results that are in the changed
+// files, relative to the root, or every finding when changed is nil. The
+// results should cover the whole tree, so that cross-file findings such as
+// dead code and duplication see every caller and copy.
+func changedFindings(cfg *config.Config, results *analyzer.Results, changed []string) []health.Finding {
+	findings := health.Findings(cfg, results)
+	if changed == nil {
+		return findings
+	}
+	var kept []health.Finding
+	for _, f := range findings {
+		if slices.Contains(changed, f.Path) {
+			kept = append(kept, f)
 		}
-		fmt.Fprintf(&b, "%s\n\n", p.suggestion)
 	}
-	return b.String()
+	return kept
 }
 
-type fixIssue struct {
-	Type        string
-	Description string
-	File        string
-	Line        int
-	Function    string
-	Severity    string
-}
-
-func getSeverity(complexity, threshold int) string {
-	if complexity > threshold*2 {
-		return "🔴 HIGH"
-	} else if float64(complexity) > float64(threshold)*1.5 {
-		return "🟡 MEDIUM"
+// sourceFiles keeps the files, relative to the root, that the configuration
+// would analyze.
+func sourceFiles(cfg *config.Config, files []string) []string {
+	exts := analyzer.New(cfg).Extensions()
+	var kept []string
+	for _, f := range files {
+		f = filepath.ToSlash(filepath.Clean(f))
+		if slices.Contains(exts, filepath.Ext(f)) && config.Included(cfg.Include, f) && !config.Excluded(cfg.Exclude, f) {
+			kept = append(kept, f)
+		}
 	}
-	return "🟢 LOW"
+	return kept
 }
 
-func isCopilotAvailable() bool {
-	_, err := exec.LookPath("copilot")
-	return err == nil
+// recordRun appends the run to the history store with the commit it
+// analyzed. Failing to record is not worth failing the run over, so errors
+// are dropped.
+func recordRun(cfg *config.Config, command string, score health.Score, results *analyzer.Results) {
+	rec := history.NewRecord(command, score, results, cfg.Thresholds)
+	if repo, err := history.New(cfg); err == nil {
+		if head, err := repo.Head(); err == nil {
+			rec.Commit, rec.Branch = head.Hash, head.Branch
+		}
+	}
+	_ = history.Append(cfg.Root, rec)
 }
 
-func getCopilotSuggestion(cfg *config.Config, issue fixIssue) (string, error) {
-	prompt := buildCopilotPrompt(cfg, issue)
-
-	// Use copilot CLI in non-interactive mode with silent output
-	cmd := exec.Command("copilot",
-		"-p", prompt,
-		"-s",
-		"--add-dir", cfg.Root,
-		"--no-auto-update",
-	)
-	output, err := cmd.CombinedOutput()
+// applyBaseline leaves the issues grandfathered by the project's baseline,
+// if it has one, out of results.
+func applyBaseline(cfg *config.Config, results *analyzer.Results) (*analyzer.Results, error) {
+	b, err := analyzer.LoadBaseline(cfg.Root)
 	if err != nil {
-		return "", fmt.Errorf("copilot CLI failed: %w\n%s", err, string(output))
+		return nil, fmt.Errorf("reading baseline: %w", err)
 	}
-
-	return strings.TrimSpace(string(output)), nil
-}
-
-func buildCopilotPrompt(cfg *config.Config, issue fixIssue) string {
-	filePath := filepath.Join(cfg.Root, issue.File)
-	sourceCode := readFunctionSource(filePath, issue.Line, 30)
-
-	prompt := fmt.Sprintf(`Refactor the function %s() in %s (starting at line %d) to reduce its cyclomatic complexity from %d to below %d.
-Focus on extracting methods, simplifying conditionals, and improving readability.
-Do NOT modify files — only show the refactored code with a brief explanation.
-
-Current code:
-%s`,
-		issue.Function,
-		issue.File,
-		issue.Line,
-		extractComplexity(issue.Description),
-		cfg.Thresholds.MaxComplexity,
-		sourceCode)
-
-	return prompt
+	if b == nil {
+		return results, nil
+	}
+	return b.Apply(results, cfg.Thresholds), nil
 }
 
-func readFunctionSource(filePath string, startLine, numLines int) string {
-	file, err := os.Open(filePath)
+// readScores loads the "score" object of a `drift snapshot` file or a
+// baseline.
+func readScores(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Sprintf("// Could not read file: %v", err)
+		return nil, fmt.Errorf("reading scores to compare with: %w", err)
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lineNum := 1
-	var lines []string
-
-	for scanner.Scan() {
-		if lineNum >= startLine && len(lines) < numLines {
-			lines = append(lines, scanner.Text())
-		}
-		if len(lines) >= numLines {
-			break
-		}
-		lineNum++
+	var recorded struct {
+		Score map[string]float64 `json:"score"`
 	}
-
-	return strings.Join(lines, "\n")
-}
-
-func extractComplexity(description string) int {
-	// Extract complexity number from description like "model.Update() (complexity: 25)"
-	re := regexp.MustCompile(`complexity:\s*(\d+)`)
-	matches := re.FindStringSubmatch(description)
-	if len(matches) > 1 {
-		complexity, _ := strconv.Atoi(matches[1])
-		return complexity
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(recorded.Score) == 0 {
+		return nil, fmt.Errorf("%s records no scores; re-run drift baseline or pass a drift snapshot file", path)
 	}
-	return 15 // default
+	return recorded.Score, nil
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/mcp"
+	"github.com/greatnessinabox/drift/internal/watcher"
+	"github.com/spf13/cobra"
+)
+
+func newMCPCmd() *cobra.Command {
+	var noWatch bool
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve the analysis to AI agents over the Model Context Protocol",
+		Long: `MCP runs a Model Context Protocol server on stdin and stdout, so agents such
+as Claude Desktop can query the codebase's health and drive refactors. Its
+tools are get_health_score, list_issues (filtered by rule or path), and
+get_function_source, which returns a function's source with its metrics
+and limits. Results follow edits as files are saved, with the same watch
+settings as the dashboard; baselined issues are left out.
+
+Agents start servers from their own directory, so set root in the project's
+config to its absolute path, and register it with the agent as a command,
+e.g. for Claude Desktop:
+  {"mcpServers": {"drift": {"command": "drift", "args": ["mcp", "--config", "/path/to/project/.drift.yaml"]}}}`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			if noWatch {
+				cfg.Watch.Disabled = true
+			}
+			if refresh > 0 {
+				cfg.Watch.Refresh = refresh.String()
+			}
+
+			a := analyzer.New(cfg)
+			results, err := a.Run()
+			if err != nil {
+				return fmt.Errorf("initial analysis: %w", err)
+			}
+			baseline, err := analyzer.LoadBaseline(cfg.Root)
+			if err != nil {
+				return fmt.Errorf("reading baseline: %w", err)
+			}
+			server := mcp.New(cfg, a, health.NewScorer(cfg), baseline, results, version)
+
+			var w *watcher.Watcher
+			var interval time.Duration
+			if cfg.Watch.Disabled {
+				interval, _ = cfg.Watch.Interval()
+			} else {
+				w, err = watcher.Open(cfg, a.Extensions())
+				if err != nil {
+					return fmt.Errorf("creating watcher: %w", err)
+				}
+				defer w.Close()
+				go func() {
+					// stdout carries the protocol.
+					for err := range w.Errors {
+						fmt.Fprintln(os.Stderr, "drift: watching:", err)
+					}
+				}()
+			}
+			go server.Watch(w, interval)
+			return server.Serve(os.Stdin, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noWatch, "no-watch", false, "don't re-analyze files as they are saved")
+	cmd.Flags().DurationVar(&refresh, "refresh", 0, "re-analyze everything at this interval when not watching (e.g. 5m)")
+	return cmd
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/ci"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/spf13/cobra"
+)
+
+func newNotifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Post the report summary to a chat service",
+	}
+	cmd.AddCommand(newNotifySlackCmd())
+	return cmd
+}
+
+func newNotifySlackCmd() *cobra.Command {
+	var channel, against string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "slack",
+		Short: "Post the report summary to a Slack channel",
+		Long: `Slack posts the health score, its change since the last run, each category
+score, the largest regressions, and the first findings to a Slack channel,
+for a daily job or the end of a CI run.
+
+The channel comes from notify.slack.channel in the config or --channel, and
+the bot token, which needs the chat:write scope, from SLACK_BOT_TOKEN.
+Regressions compare the scores with those recorded by ` + "`drift baseline`" + `, or a
+` + "`drift snapshot`" + ` file given with --against.
+
+Example:
+  drift notify slack --channel '#code-health'
+  drift notify slack --against main-snapshot.json --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if channel == "" {
+				channel = cfg.Notify.Slack.Channel
+			}
+			var slack *ci.Slack
+			if !dryRun {
+				if slack, err = ci.SlackFromEnv(channel); err != nil {
+					return err
+				}
+			}
+
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			baseline, err := analyzer.LoadBaseline(cfg.Root)
+			if err != nil {
+				return fmt.Errorf("reading baseline: %w", err)
+			}
+			if baseline != nil {
+				results = baseline.Apply(results, cfg.Thresholds)
+			}
+			scorer := health.NewScorer(cfg)
+			scorer.LoadState(cfg.Root)
+			report := ci.SlackReport{
+				Project:  filepath.Base(cfg.Root),
+				Score:    scorer.Calculate(results),
+				Findings: health.Findings(cfg, results),
+				RunURL:   ci.RunURL(),
+			}
+			scorer.SaveState(report.Score.Total)
+
+			var before map[string]float64
+			switch {
+			case against != "":
+				if before, err = readScores(against); err != nil {
+					return err
+				}
+				report.Against = against
+			case baseline != nil && len(baseline.Score) > 0:
+				before = baseline.Score
+				report.Against = "the baseline"
+			}
+			report.Regressions = health.Regressions(before, report.Score)
+
+			if dryRun {
+				payload, err := ci.SlackPayload(channel, report)
+				if err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(payload)
+				return err
+			}
+			if err := slack.Post(report); err != nil {
+				return err
+			}
+			fmt.Printf("Posted to %s\n", channel)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&channel, "channel", "", "Channel to post to (default: notify.slack.channel)")
+	cmd.Flags().StringVar(&against, "against", "", "Snapshot JSON to list regressions against (default: "+analyzer.BaselineFile+")")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the message's JSON instead of posting it")
+	return cmd
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newOwnersCmd() *cobra.Command {
+	var by, codeowners, owner string
+	var limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "owners",
+		Short: "Attribute issues to authors or teams and show health by owner",
+		Long: `Owners attributes each complex function, boundary violation, dead
+declaration, and vulnerable dependency to an owner, so remediation can be
+split up.
+
+--by author blames the lines involved as of HEAD and credits whoever changed
+them last; a function goes to the latest author of any of its lines. --by
+team looks the file up in CODEOWNERS instead (.github/CODEOWNERS, CODEOWNERS,
+docs/CODEOWNERS, or --codeowners), last matching rule winning. Both default
+to the owners section of the config.
+
+--owner lists one owner's issues.
+
+Example:
+  drift owners
+  drift owners --by team --limit 5
+  drift owners --owner @acme/payments`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			if !cmd.Flags().Changed("by") && cfg.Owners.By != "" {
+				by = cfg.Owners.By
+			}
+			if codeowners == "" {
+				codeowners = cfg.Owners.CodeOwners
+			}
+
+			var ownerOf func(health.Finding) string
+			switch by {
+			case "author":
+			case "team":
+				rules, err := history.LoadCodeOwners(cfg.Root, codeowners)
+				if err != nil {
+					return err
+				}
+				ownerOf = func(f health.Finding) string {
+					if owners := rules.Owners(f.Path); len(owners) > 0 {
+						return strings.Join(owners, " ")
+					}
+					return history.Unowned
+				}
+			default:
+				return fmt.Errorf("unknown --by %q (want author or team)", by)
+			}
+
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			if results, err = applyBaseline(cfg, results); err != nil {
+				return err
+			}
+
+			if ownerOf == nil {
+				repo, err := history.New(cfg)
+				if err != nil {
+					return err
+				}
+				blamer, err := repo.Blamer()
+				if err != nil {
+					return err
+				}
+				// Blame a complex function's whole body, not just its
+				// first line.
+				spans := make(map[string]int)
+				for _, fc := range results.Complexity {
+					spans[fmt.Sprintf("%s:%d", fc.Path, fc.Line)] = fc.Lines
+				}
+				ownerOf = func(f health.Finding) string {
+					end := f.Line + max(spans[fmt.Sprintf("%s:%d", f.Path, f.Line)], 1) - 1
+					return blamer.Author(f.Path, f.Line, end)
+				}
+			}
+
+			owners := history.Attribute(health.Findings(cfg, results), ownerOf)
+			if owner != "" {
+				i := slices.IndexFunc(owners, func(o history.OwnerHealth) bool { return o.Owner == owner })
+				if i < 0 {
+					return fmt.Errorf("no issues attributed to %q", owner)
+				}
+				owners = owners[i : i+1]
+			} else if limit > 0 && len(owners) > limit {
+				owners = owners[:limit]
+			}
+			if asJSON {
+				return tui.PrintOwnersJSON(owners)
+			}
+			if owner != "" {
+				tui.PrintOwner(owners[0])
+				return nil
+			}
+			tui.PrintOwners(owners, by)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "author", "attribute issues to: author or team")
+	cmd.Flags().StringVar(&codeowners, "codeowners", "", "CODEOWNERS file for --by team (default: the usual locations)")
+	cmd.Flags().StringVar(&owner, "owner", "", "list the issues attributed to this author or team")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "maximum number of owners to list (0 = all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output JSON instead of a table")
+	return cmd
+}
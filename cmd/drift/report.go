@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd() *cobra.Command {
+	var explain bool
+	var ref string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a terminal-formatted health report",
+		Long: `Report prints the health score, each category, and the issues behind them.
+
+--ref reports on a git revision, such as a release tag, instead of the
+working tree. Such reports aren't recorded and don't move the last-run
+delta.
+
+Example:
+  drift report --explain
+  drift report --ref v1.2.0`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			var results *analyzer.Results
+			if ref != "" {
+				repo, err := history.New(cfg)
+				if err != nil {
+					return err
+				}
+				if results, err = repo.AnalyzeRef(ref); err != nil {
+					return fmt.Errorf("analyzing %s: %w", ref, err)
+				}
+			} else if results, err = analyzer.New(cfg).Run(); err != nil {
+				return err
+			}
+			results, err = applyBaseline(cfg, results)
+			if err != nil {
+				return err
+			}
+			scorer := health.NewScorer(cfg)
+			if ref == "" {
+				scorer.LoadState(cfg.Root)
+			}
+			score := scorer.Calculate(results)
+			if ref == "" {
+				scorer.SaveState(score.Total)
+				recordRun(cfg, "report", score, results)
+			} else {
+				// Goals track the working tree's progress, not a past release's.
+				score.Goal = nil
+			}
+			tui.PrintReport(cfg, score, results, ref)
+			if explain {
+				tui.PrintExplanation(scorer.Explain(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&explain, "explain", false, "List the findings behind each category score and what fixing each is worth")
+	cmd.Flags().StringVar(&ref, "ref", "", "Report on a git revision, such as a release tag, instead of the working tree")
+	return cmd
+}
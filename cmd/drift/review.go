@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/ai"
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/ci"
+	"github.com/spf13/cobra"
+)
+
+func newReviewCmd() *cobra.Command {
+	var pr int
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Post an AI code health review of a GitHub pull request",
+		Long: `Review analyzes the repository, sends the diffs of the files a pull
+request changes and drift's findings in them to the configured AI provider,
+and posts its review of the changes' health (new complexity, broken
+boundaries, dead code) as a pull request review with comments on the lines
+concerned. Comments on lines outside the diff, which GitHub won't take, go in
+the review's summary.
+
+The working tree should be the pull request's head, as it is in a
+pull_request workflow after actions/checkout. It reads GITHUB_REPOSITORY
+and GITHUB_TOKEN, which needs pull-requests: write; --dry-run prints the
+review instead of posting it.
+
+Example:
+  drift review --pr 42
+  drift review --pr 42 --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pr <= 0 {
+				return fmt.Errorf("--pr is required")
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			gh, err := ci.GitHubAPIFromEnv()
+			if err != nil {
+				return err
+			}
+			prFiles, err := gh.PullRequestFiles(pr)
+			if err != nil {
+				return err
+			}
+			var paths []string
+			for _, f := range prFiles {
+				if f.Status != "removed" {
+					paths = append(paths, f.Path)
+				}
+			}
+			changed := sourceFiles(cfg, paths)
+			if len(changed) == 0 {
+				fmt.Printf("No source files changed in #%d\n", pr)
+				return nil
+			}
+
+			results, err := analyzer.New(cfg).Run()
+			if err != nil {
+				return err
+			}
+			if results, err = applyBaseline(cfg, results); err != nil {
+				return err
+			}
+			byPath := map[string]ci.PullRequestFile{}
+			var files []ai.ReviewFile
+			for _, f := range prFiles {
+				if slices.Contains(changed, f.Path) {
+					byPath[f.Path] = f
+					files = append(files, ai.ReviewFile{Path: f.Path, Patch: f.Patch})
+				}
+			}
+			findings := changedFindings(cfg, results, changed)
+			prompt := ai.BuildReviewPrompt(cfg, files, findings)
+
+			fmt.Fprintf(os.Stderr, "Asking %s: %s\n", cfg.AI.Provider, ai.EstimatePrompt(cfg.AI, prompt))
+			answer, err := ai.Ask(cmd.Context(), cfg, "review", prompt)
+			if err != nil {
+				return err
+			}
+			parsed, err := ai.ParseReview(answer)
+			if err != nil {
+				return err
+			}
+			review := reviewFor(parsed, byPath, len(findings))
+
+			if dryRun {
+				fmt.Println(review.Body)
+				for _, c := range review.Comments {
+					fmt.Printf("\n%s:%d\n%s\n", c.Path, c.Line, c.Body)
+				}
+				return nil
+			}
+			url, err := gh.CreateReview(pr, review)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Posted review with %d comments: %s\n", len(review.Comments), url)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&pr, "pr", 0, "Number of the pull request to review")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the review instead of posting it")
+	return cmd
+}
+
+// reviewFor turns the provider's review into a pull request review. Comments
+// on lines the diff doesn't show, which GitHub rejects, are listed in the
+// body instead.
+func reviewFor(r ai.Review, files map[string]ci.PullRequestFile, findings int) ci.Review {
+	var review ci.Review
+	var outside []string
+	for _, c := range r.Comments {
+		if f, ok := files[c.Path]; ok && f.Commentable(c.Line) {
+			review.Comments = append(review.Comments, ci.ReviewComment{Path: c.Path, Line: c.Line, Body: c.Body})
+		} else {
+			outside = append(outside, fmt.Sprintf("- `%s:%d` %s", c.Path, max(1, c.Line), c.Body))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 📊 Drift Review\n\n%s\n\n%d findings in the changed files.\n", strings.TrimSpace(r.Summary), findings)
+	if len(outside) > 0 {
+		b.WriteString("\n" + strings.Join(outside, "\n") + "\n")
+	}
+	b.WriteString("\n---\n*Powered by [drift](https://github.com/greatnessinabox/drift)*\n")
+	review.Body = b.String()
+	return review
+}
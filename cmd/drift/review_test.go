@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/ai"
+	"github.com/greatnessinabox/drift/internal/ci"
+)
+
+func TestReviewFor(t *testing.T) {
+	files := map[string]ci.PullRequestFile{
+		"a.go": {Path: "a.go", Patch: "@@ -1,2 +1,3 @@\n package a\n+func A() {}\n \n"},
+	}
+	review := reviewFor(ai.Review{
+		Summary: "One new function.",
+		Comments: []ai.ReviewComment{
+			{Path: "a.go", Line: 2, Body: "in the diff"},
+			{Path: "a.go", Line: 40, Body: "outside the diff"},
+			{Path: "b.go", Line: 1, Body: "not in the pull request"},
+		},
+	}, files, 2)
+
+	if len(review.Comments) != 1 || review.Comments[0].Body != "in the diff" {
+		t.Errorf("comments = %+v", review.Comments)
+	}
+	for _, want := range []string{"One new function.", "2 findings", "`a.go:40` outside the diff", "`b.go:1` not in the pull request"} {
+		if !strings.Contains(review.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, review.Body)
+		}
+	}
+}
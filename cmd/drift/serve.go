@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/watcher"
+	"github.com/greatnessinabox/drift/internal/web"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	var addr string
+	var noWatch bool
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the dashboard as a web page",
+		Long: `Serve shows the dashboard's panels on a web page that updates live as files
+are saved, for a wall monitor or a URL the team can share. It honors the
+same watch settings as the dashboard.
+
+Example:
+  drift serve --addr :8080`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			if noWatch {
+				cfg.Watch.Disabled = true
+			}
+			if refresh > 0 {
+				cfg.Watch.Refresh = refresh.String()
+			}
+
+			a := analyzer.New(cfg)
+			results, err := a.Run()
+			if err != nil {
+				return fmt.Errorf("initial analysis: %w", err)
+			}
+			baseline, err := analyzer.LoadBaseline(cfg.Root)
+			if err != nil {
+				return fmt.Errorf("reading baseline: %w", err)
+			}
+			server, err := web.New(cfg, a, health.NewScorer(cfg), baseline, results)
+			if err != nil {
+				return err
+			}
+
+			var w *watcher.Watcher
+			var interval time.Duration
+			if cfg.Watch.Disabled {
+				// Load has checked the interval.
+				interval, _ = cfg.Watch.Interval()
+			} else {
+				w, err = watcher.Open(cfg, a.Extensions())
+				if err != nil {
+					return fmt.Errorf("creating watcher: %w", err)
+				}
+				defer w.Close()
+				go func() {
+					for err := range w.Errors {
+						fmt.Fprintln(os.Stderr, "drift: watching:", err)
+					}
+				}()
+			}
+			go server.Watch(w, interval)
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Serving the dashboard at http://%s\n", listener.Addr())
+			return http.Serve(listener, server.Handler())
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "localhost:8080", "Address to listen on (e.g. :8080 for every interface)")
+	cmd.Flags().BoolVar(&noWatch, "no-watch", false, "don't re-analyze files as they are saved")
+	cmd.Flags().DurationVar(&refresh, "refresh", 0, "re-analyze everything at this interval when not watching (e.g. 5m)")
+	return cmd
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/ci"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotCmd() *cobra.Command {
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Output a JSON health snapshot for CI",
+		Long: `Snapshot prints the health scores, finding counts, and every function,
+dependency, boundary violation, and dead declaration as JSON for CI. Its
+schema_version goes up when a field is removed or changes meaning.
+
+--format sarif prints complex functions, boundary violations, dead code, and
+vulnerable dependencies as SARIF 2.1.0 instead, for GitHub code scanning to
+annotate pull requests with. --format codequality prints them as a GitLab
+Code Quality report, for merge requests to show.
+
+Example:
+  drift snapshot -o snapshot.json
+  drift snapshot --format sarif -o drift.sarif
+  drift snapshot --format codequality -o gl-code-quality-report.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "sarif" && format != "codequality" {
+				return fmt.Errorf("unknown --format %q (want json, sarif, or codequality)", format)
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+			a := analyzer.New(cfg)
+			results, err := a.Run()
+			if err != nil {
+				return err
+			}
+
+			score := health.NewScorer(cfg).Calculate(results)
+			recordRun(cfg, "snapshot", score, results)
+
+			var buf bytes.Buffer
+			switch format {
+			case "sarif":
+				data, err := health.SARIF(cfg, results, version)
+				if err != nil {
+					return err
+				}
+				buf.Write(data)
+			case "codequality":
+				data, err := ci.CodeQuality(health.Findings(cfg, results))
+				if err != nil {
+					return err
+				}
+				buf.Write(data)
+			default:
+				if err := tui.WriteSnapshot(&buf, cfg, score, results); err != nil {
+					return err
+				}
+			}
+			if output == "" || output == "-" {
+				_, err := os.Stdout.Write(buf.Bytes())
+				return err
+			}
+			if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+				return fmt.Errorf("writing snapshot: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json, sarif for code scanning, or codequality for GitLab")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write instead of stdout")
+	return cmd
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/history"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newTrendCmd() *cobra.Command {
+	var since, source, format string
+	var commits int
+	var tags bool
+
+	cmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Chart the health score and each category over time",
+		Long: `Trend charts the total score over a time range, then each category's trend
+and how much it changed, to show whether health is improving.
+
+The scores come from the runs recorded in ` + history.StoreFile + ` (see drift history)
+or, with --source git, from analyzing commits on HEAD in the range, at most
+--commits of them spread evenly. The default, auto, uses recorded runs when
+at least two fall in the range and commits otherwise.
+
+--tags analyzes the tagged commits on HEAD instead and prints a changelog of
+each release's score and what changed since the one before. It covers every
+tag unless --since or --commits (the last N releases) is given.
+
+--format json or csv prints the data points instead, oldest first.
+
+Example:
+  drift trend --since 90d
+  drift trend --tags --commits 10
+  drift trend --since 2026-01-01 --source git --commits 50
+  drift trend --since 12w --format csv > trend.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" && format != "csv" {
+				return fmt.Errorf("unknown --format %q (want text, json, or csv)", format)
+			}
+			if source != "auto" && source != "runs" && source != "git" {
+				return fmt.Errorf("unknown --source %q (want auto, runs, or git)", source)
+			}
+			start, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			tui.SetTheme(cfg.Theme)
+
+			if tags {
+				if cmd.Flags().Changed("source") {
+					return fmt.Errorf("--tags analyzes tagged commits; it can't be combined with --source")
+				}
+				if !cmd.Flags().Changed("since") {
+					start = time.Time{}
+				}
+				if !cmd.Flags().Changed("commits") {
+					commits = 0
+				}
+				repo, err := history.New(cfg)
+				if err != nil {
+					return err
+				}
+				records, err := repo.Releases(start, commits)
+				if err != nil {
+					return err
+				}
+				switch format {
+				case "json":
+					if records == nil {
+						records = []history.Record{}
+					}
+					return printJSON(records)
+				case "csv":
+					return writeTrendCSV(os.Stdout, records)
+				}
+				tui.PrintReleases(records)
+				return nil
+			}
+
+			var records []history.Record
+			label := "recorded runs"
+			if source == "auto" || source == "runs" {
+				all, err := history.Load(cfg.Root)
+				if err != nil {
+					return err
+				}
+				for _, rec := range all {
+					if !rec.Time.Before(start) {
+						records = append(records, rec)
+					}
+				}
+			}
+			if source == "git" || (source == "auto" && len(records) < 2) {
+				repo, err := history.New(cfg)
+				if err != nil {
+					return err
+				}
+				if records, err = repo.WalkSince(start, commits); err != nil {
+					return err
+				}
+				label = "commits"
+			}
+
+			switch format {
+			case "json":
+				if records == nil {
+					records = []history.Record{}
+				}
+				return printJSON(records)
+			case "csv":
+				return writeTrendCSV(os.Stdout, records)
+			}
+			tui.PrintTrend(records, start, label)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "Start of the range: a duration back from now (e.g. 30d, 12w, 72h) or a date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&source, "source", "auto", "Where the scores come from: auto, runs, or git")
+	cmd.Flags().IntVar(&commits, "commits", 30, "Maximum number of commits to analyze with --source git")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text for charts, json, or csv")
+	cmd.Flags().BoolVar(&tags, "tags", false, "Analyze each tagged release and print a health changelog (all tags unless --since or --commits is given)")
+	return cmd
+}
+
+// parseSince reads --since as a date or a duration before now, which may
+// count days ("30d") or weeks ("12w") as well as what time.ParseDuration
+// accepts.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	var d time.Duration
+	if n, err := strconv.Atoi(strings.TrimRight(s, "dw")); err == nil && n > 0 && len(s) > 1 {
+		switch s[len(s)-1] {
+		case 'd':
+			d = time.Duration(n) * 24 * time.Hour
+		case 'w':
+			d = time.Duration(n) * 7 * 24 * time.Hour
+		}
+	} else if parsed, err := time.ParseDuration(s); err == nil && parsed > 0 {
+		d = parsed
+	}
+	if d == 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q (want e.g. 30d, 12w, 72h, or YYYY-MM-DD)", s)
+	}
+	return now.Add(-d), nil
+}
+
+// writeTrendCSV writes one row per record: when, what, the commit, its tag
+// for releases, and each score, with categories a record lacks left empty.
+func writeTrendCSV(w io.Writer, records []history.Record) error {
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		for c := range rec.Score {
+			if c != "total" {
+				seen[c] = true
+			}
+		}
+	}
+	categories := make([]string, 0, len(seen))
+	for c := range seen {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	categories = append([]string{"total"}, categories...)
+
+	// Releases get a tag column; other trends keep their columns.
+	tagged := slices.ContainsFunc(records, func(rec history.Record) bool { return rec.Tag != "" })
+	header := []string{"time", "command", "commit"}
+	if tagged {
+		header = append(header, "tag")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(append(header, categories...))
+	for _, rec := range records {
+		row := []string{rec.Time.Format(time.RFC3339), rec.Command, rec.Commit}
+		if tagged {
+			row = append(row, rec.Tag)
+		}
+		for _, c := range categories {
+			if v, ok := rec.Score[c]; ok {
+				row = append(row, strconv.FormatFloat(v, 'f', 1, 64))
+			} else {
+				row = append(row, "")
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30d", now.AddDate(0, 0, -30)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2026-01-15", time.Date(2026, 1, 15, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "0d", "-3d", "soon", "-1h"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestWriteTrendCSV_Tags(t *testing.T) {
+	when := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Time: when, Command: "commit", Commit: "abc1234", Score: map[string]float64{"total": 80, "deps": 90}},
+	}
+	var buf bytes.Buffer
+	if err := writeTrendCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	if want := "time,command,commit,total,deps\n2026-03-01T09:00:00Z,commit,abc1234,80.0,90.0\n"; buf.String() != want {
+		t.Errorf("untagged CSV = %q, want %q", buf.String(), want)
+	}
+
+	records[0].Tag = "v1.2.0"
+	buf.Reset()
+	if err := writeTrendCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	if want := "time,command,commit,tag,total,deps\n2026-03-01T09:00:00Z,commit,abc1234,v1.2.0,80.0,90.0\n"; buf.String() != want {
+		t.Errorf("release CSV = %q, want %q", buf.String(), want)
+	}
+}
@@ -3,9 +3,9 @@ package ai
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/greatnessinabox/drift/internal/config"
 )
 
@@ -16,21 +16,16 @@ type AnthropicProvider struct {
 }
 
 func NewAnthropicProvider(cfg config.AIConfig) (*AnthropicProvider, error) {
-	key := os.Getenv("ANTHROPIC_API_KEY")
-	if key == "" {
-		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
+	key, err := apiKey(cfg, "ANTHROPIC_API_KEY")
+	if err != nil {
+		return nil, err
 	}
 
-	client := anthropic.NewClient()
-
-	model := anthropic.Model(cfg.Model)
-	if cfg.Model == "" {
-		model = anthropic.ModelClaudeSonnet4_5_20250929
-	}
+	client := anthropic.NewClient(option.WithAPIKey(key), option.WithMaxRetries(0)) // NewProvider retries
 
 	return &AnthropicProvider{
 		client:    &client,
-		model:     model,
+		model:     anthropic.Model(modelName(cfg)),
 		maxTokens: maxTokensOrDefault(cfg.MaxTokens),
 	}, nil
 }
@@ -39,7 +34,7 @@ func (p *AnthropicProvider) Name() string {
 	return "Anthropic Claude"
 }
 
-func (p *AnthropicProvider) Diagnose(ctx context.Context, prompt string) (string, error) {
+func (p *AnthropicProvider) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
 	resp, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     p.model,
 		MaxTokens: p.maxTokens,
@@ -53,14 +48,15 @@ func (p *AnthropicProvider) Diagnose(ctx context.Context, prompt string) (string
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("anthropic API error: %w", err)
+		return "", Usage{}, fmt.Errorf("anthropic API error: %w", err)
 	}
 
+	usage := Usage{InputTokens: int(resp.Usage.InputTokens), OutputTokens: int(resp.Usage.OutputTokens)}
 	for _, block := range resp.Content {
 		if block.Type == "text" {
-			return block.Text, nil
+			return block.Text, usage, nil
 		}
 	}
 
-	return "", fmt.Errorf("no text response from Anthropic")
+	return "", usage, fmt.Errorf("no text response from Anthropic")
 }
@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// CopilotProvider asks the GitHub Copilot CLI, run non-interactively in the
+// working directory. It needs no API key, only a signed-in copilot.
+type CopilotProvider struct {
+	bin   string
+	model string
+}
+
+func NewCopilotProvider(cfg config.AIConfig) (*CopilotProvider, error) {
+	bin, err := exec.LookPath("copilot")
+	if err != nil {
+		return nil, fmt.Errorf("GitHub Copilot CLI not found; install it with brew install copilot-cli, npm install -g @github/copilot, or curl -fsSL https://gh.io/copilot-install | bash")
+	}
+	return &CopilotProvider{bin: bin, model: cfg.Model}, nil
+}
+
+func (p *CopilotProvider) Name() string {
+	return "GitHub Copilot"
+}
+
+// Diagnose estimates the tokens used, since the CLI doesn't report them.
+func (p *CopilotProvider) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
+	args := []string{"-p", prompt, "-s", "--no-auto-update"}
+	if p.model != "" {
+		args = append(args, "--model", p.model)
+	}
+	out, err := exec.CommandContext(ctx, p.bin, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		return "", Usage{}, ctx.Err()
+	}
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("copilot CLI failed: %w\n%s", err, out)
+	}
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return "", Usage{}, fmt.Errorf("no response from GitHub Copilot")
+	}
+	return text, Usage{InputTokens: EstimateTokens(prompt), OutputTokens: EstimateTokens(text), Estimated: true}, nil
+}
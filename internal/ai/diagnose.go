@@ -3,76 +3,299 @@ package ai
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/analyzer"
 	"github.com/greatnessinabox/drift/internal/config"
 	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
 )
 
+// topFunctions is how many of the most complex functions a codebase prompt
+// lists, and snippetFunctions how many of those it shows code for.
+const (
+	topFunctions     = 10
+	snippetFunctions = 3
+)
+
+// BuildDiagnosisPrompt describes the health report for a diagnosis of the
+// whole codebase, within ai.max_prompt_tokens. Code is the first to go: the
+// worst functions' snippets are added only while they fit in what the rest
+// of the prompt leaves.
 func BuildDiagnosisPrompt(cfg *config.Config, score health.Score, results *analyzer.Results) string {
-	var sb strings.Builder
+	base := diagnosisPrompt(cfg, score, results, nil)
+	budget := cfg.AI.MaxPromptTokens
+	left := budget - EstimateTokens(base)
+
+	lang := promptLanguage(results)
+	snippets := map[int]string{}
+	for i := 0; i < min(snippetFunctions, len(results.Complexity)); i++ {
+		fc := results.Complexity[i]
+		snippet := codeSnippet(cfg, fc.File, fc.Line, 10)
+		if snippet == "" {
+			continue
+		}
+		block := fmt.Sprintf("\n```%s\n%s\n```\n\n", lang, snippet)
+		if budget > 0 && EstimateTokens(block) > left {
+			continue
+		}
+		snippets[i] = block
+		left -= EstimateTokens(block)
+	}
+	if len(snippets) == 0 {
+		return base
+	}
+	return diagnosisPrompt(cfg, score, results, snippets)
+}
 
-	lang := string(results.Language)
-	if lang == "" {
-		lang = "Go"
+func promptLanguage(results *analyzer.Results) string {
+	if lang := results.LanguageLabel(); lang != "" {
+		return lang
 	}
-	sb.WriteString(fmt.Sprintf("Analyze this %s codebase health report and provide actionable recommendations:\n\n", lang))
+	return "Go"
+}
 
-	sb.WriteString(fmt.Sprintf("Overall Health Score: %.0f/100\n", score.Total))
-	sb.WriteString(fmt.Sprintf("  Complexity Score: %.0f/100\n", score.Complexity))
-	sb.WriteString(fmt.Sprintf("  Dependencies Score: %.0f/100\n", score.Deps))
-	sb.WriteString(fmt.Sprintf("  Boundaries Score: %.0f/100\n\n", score.Boundaries))
+// diagnosisPrompt writes the report with the snippets given for the
+// functions at those indexes of results.Complexity. Snippets don't count
+// toward the budget, so each list is cut at the same item with or without
+// them.
+func diagnosisPrompt(cfg *config.Config, score health.Score, results *analyzer.Results, snippets map[int]string) string {
+	w := &promptWriter{budget: cfg.AI.MaxPromptTokens}
+	lang := promptLanguage(results)
+	w.write(fmt.Sprintf("Analyze this %s codebase health report and provide actionable recommendations:\n\n", lang))
 
-	sb.WriteString(fmt.Sprintf("Codebase: %d files, %d functions\n\n", results.FileCount, results.FuncCount))
+	w.write(fmt.Sprintf("Overall Health Score: %.0f/100\n", score.Total))
+	w.write(fmt.Sprintf("  Complexity Score: %.0f/100\n", score.Complexity))
+	w.write(fmt.Sprintf("  Dependencies Score: %.0f/100\n", score.Deps))
+	w.write(fmt.Sprintf("  Security Score: %.0f/100\n", score.Security))
+	w.write(fmt.Sprintf("  Boundaries Score: %.0f/100\n", score.Boundaries))
+	w.write(fmt.Sprintf("  Duplication Score: %.0f/100\n\n", score.Duplication))
+
+	w.write(fmt.Sprintf("Codebase: %d files, %d functions\n\n", results.FileCount, results.FuncCount))
 
 	if len(results.Complexity) > 0 {
-		sb.WriteString("Top Complex Functions:\n")
-		count := 3
-		if len(results.Complexity) < count {
-			count = len(results.Complexity)
-		}
+		w.write("Top Complex Functions:\n")
+		count := min(topFunctions, len(results.Complexity))
 		for i := 0; i < count; i++ {
 			fc := results.Complexity[i]
-			sb.WriteString(fmt.Sprintf("  - %s() in %s:%d — cyclomatic complexity %d\n",
-				fc.Name, fc.File, fc.Line, fc.Complexity))
-
-			// Include code snippet for worst functions
-			if snippet := getCodeSnippet(cfg.Root, fc.File, fc.Line, 10); snippet != "" {
-				sb.WriteString(fmt.Sprintf("\n```%s\n%s\n```\n\n", lang, snippet))
+			line := fmt.Sprintf("  - %s() in %s:%d — cyclomatic complexity %d\n", fc.Name, fc.File, fc.Line, fc.Complexity)
+			if !w.fits(line) {
+				break
+			}
+			w.write(line)
+			if snippet, ok := snippets[i]; ok {
+				w.free(snippet)
 			}
 		}
-		sb.WriteString("\n")
+		w.write("\n")
 	}
 
-	staleCount := 0
+	if god := analyzer.GodObjects(results.Types, cfg.Thresholds.MaxStructFields, cfg.Thresholds.MaxMethods); len(god) > 0 {
+		var items []string
+		for _, ts := range god {
+			items = append(items, fmt.Sprintf("%s in %s:%d — %d fields, %d methods", ts.Name, ts.Path, ts.Line, ts.Fields, ts.Methods))
+		}
+		w.list("God Objects", items)
+	}
+
+	var stale []analyzer.DepStatus
 	for _, dep := range results.Dependencies {
 		if dep.Status != "current" {
-			staleCount++
+			stale = append(stale, dep)
 		}
 	}
-	if staleCount > 0 {
-		sb.WriteString(fmt.Sprintf("Stale Dependencies (%d):\n", staleCount))
-		for _, dep := range results.Dependencies {
-			if dep.Status != "current" {
-				sb.WriteString(fmt.Sprintf("  - %s: current %s, latest %s (%d days behind)\n",
-					dep.Module, dep.CurrentVersion, dep.LatestVersion, dep.StaleDays))
-			}
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].StaleDays > stale[j].StaleDays })
+	var items []string
+	for _, dep := range stale {
+		gap := ""
+		if label := dep.BehindLabel(); label != "" {
+			gap = ", " + label
+		}
+		items = append(items, fmt.Sprintf("%s: current %s, latest %s (%d days behind%s)",
+			dep.Module, dep.CurrentVersion, dep.LatestVersion, dep.StaleDays, gap))
+	}
+	w.list("Stale Dependencies", items)
+
+	items = nil
+	for _, v := range results.Vulnerabilities {
+		items = append(items, fmt.Sprintf("%s %s: %s [%s] %s", v.Module, v.Version, v.Label(), v.Severity, v.Summary))
+	}
+	w.list("Known Vulnerabilities", items)
+
+	items = nil
+	for _, v := range results.Violations {
+		item := fmt.Sprintf("%s imports %s (%s:%d) — violates %s → %s boundary", v.File, v.Import, v.File, v.Line, v.From, v.To)
+		if v.Rule != "" {
+			item += fmt.Sprintf(" (rule %q)", v.Rule)
+		}
+		if v.Description != "" {
+			item += ": " + v.Description
+		}
+		items = append(items, item)
+	}
+	w.list("Boundary Violations", items)
+	if n := len(results.SuppressedViolations); n > 0 {
+		w.write(fmt.Sprintf("Suppressed Boundary Violations: %d (silenced with drift:ignore comments)\n", n))
+	}
+
+	items = nil
+	for _, c := range results.Cycles {
+		items = append(items, fmt.Sprint(c))
+	}
+	w.list("Import Cycles", items)
+
+	return w.String()
+}
+
+// promptWriter writes a prompt within a token budget.
+type promptWriter struct {
+	sb        strings.Builder
+	budget    int // estimated tokens; 0 = no limit
+	freeBytes int // written without counting toward the budget
+}
+
+func (w *promptWriter) write(s string) { w.sb.WriteString(s) }
+
+// free writes s without counting it toward the budget.
+func (w *promptWriter) free(s string) {
+	w.sb.WriteString(s)
+	w.freeBytes += len(s)
+}
+
+// fits reports whether s can be written within the budget.
+func (w *promptWriter) fits(s string) bool {
+	return w.budget <= 0 || bytesToTokens(w.sb.Len()-w.freeBytes+len(s)) <= w.budget
+}
+
+// list writes a titled list, leaving out the items from the first that
+// doesn't fit and saying how many that was. Items should come worst first.
+func (w *promptWriter) list(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	w.write(fmt.Sprintf("%s (%d):\n", title, len(items)))
+	for i, item := range items {
+		line := "  - " + item + "\n"
+		more := fmt.Sprintf("  - ... and %d more\n", len(items)-i)
+		if i < len(items)-1 && !w.fits(line+more) || !w.fits(line) {
+			w.write(more)
+			break
+		}
+		w.write(line)
+	}
+	w.write("\n")
+}
+
+func (w *promptWriter) String() string { return w.sb.String() }
+
+// BuildFindingPrompt asks about a single finding, such as a complex function
+// or a boundary violation, including the code at path:line when there is a
+// path.
+func BuildFindingPrompt(cfg *config.Config, finding, path string, line int) string {
+	var sb strings.Builder
+	sb.WriteString("Explain this code health finding and how to fix it, with a concrete refactoring:\n\n")
+	sb.WriteString(finding + "\n")
+	if path != "" {
+		if snippet := codeSnippet(cfg, path, max(1, line), 30); snippet != "" {
+			sb.WriteString(fmt.Sprintf("\n%s:%d\n```\n%s\n```\n", path, line, snippet))
+		}
+	}
+	return sb.String()
+}
+
+// maxFunctionLines caps the source a function prompt includes, so a
+// sprawling function doesn't blow the provider's context.
+const maxFunctionLines = 400
+
+// BuildFunctionPrompt asks for a refactoring plan for one function,
+// including its full source and the thresholds it exceeds.
+func BuildFunctionPrompt(cfg *config.Config, fc analyzer.FunctionComplexity) string {
+	var sb strings.Builder
+	t := cfg.Thresholds.For(fc.Path)
+	sb.WriteString(fmt.Sprintf("Plan a targeted refactoring of %s() in %s:%d.\n\n", fc.Name, fc.Path, fc.Line))
+	sb.WriteString("Its metrics, with the project's limits:\n")
+	for _, m := range []struct {
+		name         string
+		value, limit int
+	}{
+		{"Cyclomatic complexity", fc.Complexity, t.MaxComplexity},
+		{"Length in lines", fc.Lines, t.MaxFuncLines},
+		{"Parameters", fc.Params, t.MaxParams},
+		{"Nesting depth", fc.Nesting, t.MaxNesting},
+	} {
+		sb.WriteString(fmt.Sprintf("  - %s: %d", m.name, m.value))
+		if m.limit > 0 {
+			sb.WriteString(fmt.Sprintf(" (limit %d)", m.limit))
 		}
 		sb.WriteString("\n")
 	}
 
-	if len(results.Violations) > 0 {
-		sb.WriteString(fmt.Sprintf("Boundary Violations (%d):\n", len(results.Violations)))
-		for _, v := range results.Violations {
-			sb.WriteString(fmt.Sprintf("  - %s imports %s (%s:%d) — violates %s → %s boundary\n",
-				v.File, v.Import, v.File, v.Line, v.From, v.To))
+	ask := "\nGive a step-by-step plan that brings it within the limits without changing its behavior: " +
+		"name each function to extract and what it takes and returns, show the rewritten code for the most important step, " +
+		"and estimate its complexity afterwards. Point out anything that needs new tests first.\n"
+
+	lines := fc.Lines
+	if lines <= 0 || lines > maxFunctionLines {
+		lines = maxFunctionLines
+	}
+	if src := codeSnippet(cfg, fc.Path, fc.Line, lines); src != "" {
+		// Leave room for the rest of the prompt and the truncation note.
+		room := 0
+		if budget := cfg.AI.MaxPromptTokens; budget > 0 {
+			room = max(budget-EstimateTokens(sb.String()+ask)-30, 1)
+		}
+		src, shown := fitLines(src, room)
+		sb.WriteString(fmt.Sprintf("\n```%s\n%s\n```\n", fc.Language, src))
+		if fc.Lines > shown {
+			sb.WriteString(fmt.Sprintf("(only the first %d of its %d lines are shown)\n", shown, fc.Lines))
 		}
 	}
 
+	sb.WriteString(ask)
+	return sb.String()
+}
+
+// fitLines cuts src to the lines that fit in tokens, keeping at least one;
+// 0 = no limit. It returns the lines kept and how many they are.
+func fitLines(src string, tokens int) (string, int) {
+	lines := strings.Split(src, "\n")
+	if tokens <= 0 || EstimateTokens(src) <= tokens {
+		return src, len(lines)
+	}
+	size, n := 0, 0
+	for n < len(lines) && (n == 0 || bytesToTokens(size+len(lines[n])+1) <= tokens) {
+		size += len(lines[n]) + 1
+		n++
+	}
+	return strings.Join(lines[:n], "\n"), n
+}
+
+// BuildViolationPrompt asks how to remove a boundary violation, including
+// the code around the offending import.
+func BuildViolationPrompt(cfg *config.Config, v analyzer.BoundaryViolation, path string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s:%d imports %s, breaking the architecture boundary that %s must not depend on %s.\n",
+		path, v.Line, v.Import, v.From, v.To))
+	if v.Rule != "" {
+		sb.WriteString(fmt.Sprintf("The rule is %q", v.Rule))
+		if v.Description != "" {
+			sb.WriteString(": " + v.Description)
+		}
+		sb.WriteString(".\n")
+	} else if v.Description != "" {
+		sb.WriteString(v.Description + "\n")
+	}
+	if snippet := codeSnippet(cfg, path, max(1, v.Line-10), 30); snippet != "" {
+		sb.WriteString(fmt.Sprintf("\n%s from line %d:\n```\n%s\n```\n", path, max(1, v.Line-10), snippet))
+	}
+	sb.WriteString("\nPlan how to remove the dependency: what the importing code uses from " + v.Import +
+		", where that belongs instead (an interface on the " + v.From + " side, a move, or a shared package), and the concrete changes.\n")
 	return sb.String()
 }
 
@@ -103,7 +326,7 @@ func getCodeSnippet(root, filename string, startLine, numLines int) string {
 	var lines []string
 
 	// Read up to the start line
-	for scanner.Scan() && lineNum < startLine {
+	for lineNum < startLine && scanner.Scan() {
 		lineNum++
 	}
 
@@ -120,12 +343,45 @@ func getCodeSnippet(root, filename string, startLine, numLines int) string {
 	return strings.Join(lines, "\n")
 }
 
-func RunDiagnosis(cfg *config.Config, score health.Score, results *analyzer.Results) (string, error) {
+// ErrOffline is returned instead of asking a provider while offline is set.
+var ErrOffline = errors.New("offline is set, so AI providers can't be reached")
+
+// Ask sends prompt, built by one of the Build*Prompt functions, to the
+// configured provider and records its usage for command; see RecordUsage.
+// Canceling ctx aborts the request.
+func Ask(ctx context.Context, cfg *config.Config, command, prompt string) (string, error) {
+	if cfg.Offline {
+		return "", ErrOffline
+	}
 	provider, err := NewProvider(cfg.AI)
 	if err != nil {
 		return "", err
 	}
+	text, usage, err := provider.Diagnose(ctx, prompt)
+	RecordUsage(cfg.Root, cfg.AI, command, usage)
+	return text, err
+}
 
-	prompt := BuildDiagnosisPrompt(cfg, score, results)
-	return provider.Diagnose(context.Background(), prompt)
+// RecordUsage appends a call by command that used u to the usage log under
+// root, priced for cfg's model. Calls that used no tokens, such as ones that
+// never reached the provider, aren't recorded. Failing to record is not
+// worth failing the call over, so errors are dropped.
+func RecordUsage(root string, cfg config.AIConfig, command string, u Usage) {
+	if u.InputTokens == 0 && u.OutputTokens == 0 {
+		return
+	}
+	call := history.Call{
+		Time:         time.Now().UTC(),
+		Command:      command,
+		Provider:     cfg.Provider,
+		Model:        modelName(cfg),
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		Estimated:    u.Estimated,
+	}
+	if p, ok := priceOf(cfg); ok {
+		call.Cost = (float64(u.InputTokens)*p.input + float64(u.OutputTokens)*p.output) / 1e6
+		call.Priced = true
+	}
+	_ = history.AppendCall(root, call)
 }
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestBuildFindingPrompt(t *testing.T) {
+	root := t.TempDir()
+	src := "package a\n\nfunc Tangled() {\n\tif x {\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Defaults()
+	cfg.Root = root
+
+	prompt := BuildFindingPrompt(cfg, "Tangled() in a.go:3 has cyclomatic complexity 31", "a.go", 3)
+	for _, want := range []string{"complexity 31", "a.go:3", "func Tangled() {"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	if prompt := BuildFindingPrompt(cfg, "cycle a -> b -> a", "", 0); strings.Contains(prompt, "```") {
+		t.Errorf("prompt without a path has a snippet:\n%s", prompt)
+	}
+}
+
+func TestBuildFunctionPrompt(t *testing.T) {
+	root := t.TempDir()
+	src := "package a\n\n// Tangled does too much.\nfunc Tangled(a, b int) int {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}\n\nfunc Next() {}\n"
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Defaults()
+	cfg.Root = root
+
+	fc := analyzer.FunctionComplexity{Path: "a.go", Name: "Tangled", Line: 4, Lines: 6, Complexity: 31, Params: 2, Nesting: 1, Language: analyzer.LangGo}
+	prompt := BuildFunctionPrompt(cfg, fc)
+	for _, want := range []string{
+		"Tangled() in a.go:4",
+		fmt.Sprintf("Cyclomatic complexity: 31 (limit %d)", cfg.Thresholds.MaxComplexity),
+		"```go\nfunc Tangled(a, b int) int {",
+		"\treturn b\n}\n```",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "func Next") {
+		t.Errorf("prompt runs past the function:\n%s", prompt)
+	}
+}
+
+func TestBuildDiagnosisPrompt_Budget(t *testing.T) {
+	root := t.TempDir()
+	src := "package a\n\nfunc Worst() {\n\t// " + strings.Repeat("x", 400) + "\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{{Name: "Worst", File: "a.go", Line: 3, Complexity: 40}}}
+	for i := 0; i < 200; i++ {
+		results.Violations = append(results.Violations, analyzer.BoundaryViolation{
+			File: fmt.Sprintf("f%03d.go", i), Import: "db", From: "api", To: "db", Line: 1,
+		})
+	}
+	cfg := config.Defaults()
+	cfg.Root = root
+
+	cfg.AI.MaxPromptTokens = 0
+	full := BuildDiagnosisPrompt(cfg, health.Score{}, results)
+	if !strings.Contains(full, "f199.go") || !strings.Contains(full, "func Worst()") {
+		t.Fatalf("unlimited prompt is missing items:\n%s", full)
+	}
+
+	// Room for some violations but not the snippet.
+	cfg.AI.MaxPromptTokens = 1000
+	prompt := BuildDiagnosisPrompt(cfg, health.Score{}, results)
+	if got := EstimateTokens(prompt); got > 1000 {
+		t.Errorf("prompt is ~%d tokens, over the budget of 1000", got)
+	}
+	if strings.Contains(prompt, "func Worst()") {
+		t.Error("snippet kept over the budget")
+	}
+	if !strings.Contains(prompt, "Worst() in a.go:3") || !strings.Contains(prompt, "f000.go imports db") {
+		t.Errorf("the worst items were dropped:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "more\n") || strings.Contains(prompt, "f199.go") {
+		t.Errorf("violations not cut:\n%s", prompt)
+	}
+}
+
+func TestFitLines(t *testing.T) {
+	src := "0123456789\n0123456789\n0123456789"
+	if got, n := fitLines(src, 0); got != src || n != 3 {
+		t.Errorf("no limit: %d lines", n)
+	}
+	if got, n := fitLines(src, 6); got != "0123456789\n0123456789" || n != 2 {
+		t.Errorf("6 tokens: %q, %d lines", got, n)
+	}
+	if _, n := fitLines(src, 1); n != 1 {
+		t.Errorf("1 token: %d lines, want at least one", n)
+	}
+}
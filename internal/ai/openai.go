@@ -3,10 +3,10 @@ package ai
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/greatnessinabox/drift/internal/config"
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
 )
 
 type OpenAIProvider struct {
@@ -16,21 +16,16 @@ type OpenAIProvider struct {
 }
 
 func NewOpenAIProvider(cfg config.AIConfig) (*OpenAIProvider, error) {
-	key := os.Getenv("OPENAI_API_KEY")
-	if key == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+	key, err := apiKey(cfg, "OPENAI_API_KEY")
+	if err != nil {
+		return nil, err
 	}
 
-	client := openai.NewClient()
-
-	model := cfg.Model
-	if model == "" {
-		model = "gpt-4o"
-	}
+	client := openai.NewClient(option.WithAPIKey(key), option.WithMaxRetries(0)) // NewProvider retries
 
 	return &OpenAIProvider{
 		client:    &client,
-		model:     model,
+		model:     modelName(cfg),
 		maxTokens: maxTokensOrDefault(cfg.MaxTokens),
 	}, nil
 }
@@ -39,7 +34,7 @@ func (p *OpenAIProvider) Name() string {
 	return "OpenAI"
 }
 
-func (p *OpenAIProvider) Diagnose(ctx context.Context, prompt string) (string, error) {
+func (p *OpenAIProvider) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
 	resp, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Model: p.model,
 		Messages: []openai.ChatCompletionMessageParamUnion{
@@ -49,12 +44,13 @@ func (p *OpenAIProvider) Diagnose(ctx context.Context, prompt string) (string, e
 		MaxCompletionTokens: openai.Int(p.maxTokens),
 	})
 	if err != nil {
-		return "", fmt.Errorf("openai API error: %w", err)
+		return "", Usage{}, fmt.Errorf("openai API error: %w", err)
 	}
 
+	usage := Usage{InputTokens: int(resp.Usage.PromptTokens), OutputTokens: int(resp.Usage.CompletionTokens)}
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+		return "", usage, fmt.Errorf("no response from OpenAI")
 	}
 
-	return resp.Choices[0].Message.Content, nil
+	return resp.Choices[0].Message.Content, usage, nil
 }
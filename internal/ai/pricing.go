@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// defaultModels is the model each provider uses when ai.model is empty.
+// The Copilot CLI picks its own.
+var defaultModels = map[string]string{
+	"anthropic": string(anthropic.ModelClaudeSonnet4_5_20250929),
+	"openai":    "gpt-4o",
+}
+
+// modelName is the model cfg asks for, or the provider's default.
+func modelName(cfg config.AIConfig) string {
+	if cfg.Model != "" {
+		return cfg.Model
+	}
+	return defaultModels[cfg.Provider]
+}
+
+// price is what a model costs in US dollars per million tokens.
+type price struct {
+	input, output float64
+}
+
+// prices are list prices by model name prefix; the longest matching prefix
+// wins.
+var prices = map[string]price{
+	"claude-opus-4-5":   {5, 25},
+	"claude-opus-4":     {15, 75},
+	"claude-sonnet-4":   {3, 15},
+	"claude-3-7-sonnet": {3, 15},
+	"claude-3-5-sonnet": {3, 15},
+	"claude-haiku-4-5":  {1, 5},
+	"claude-3-5-haiku":  {0.8, 4},
+	"claude-3-haiku":    {0.25, 1.25},
+	"gpt-5-nano":        {0.05, 0.4},
+	"gpt-5-mini":        {0.25, 2},
+	"gpt-5":             {1.25, 10},
+	"gpt-4.1-nano":      {0.1, 0.4},
+	"gpt-4.1-mini":      {0.4, 1.6},
+	"gpt-4.1":           {2, 8},
+	"gpt-4o-mini":       {0.15, 0.6},
+	"gpt-4o":            {2.5, 10},
+	"o4-mini":           {1.1, 4.4},
+	"o3-mini":           {1.1, 4.4},
+}
+
+// priceOf is what cfg's model costs: ai.input_price and ai.output_price
+// when either is set, else the list price. ok is false for a model without
+// a known price, such as any through the Copilot CLI, which is billed by
+// subscription.
+func priceOf(cfg config.AIConfig) (p price, ok bool) {
+	if cfg.InputPrice > 0 || cfg.OutputPrice > 0 {
+		return price{cfg.InputPrice, cfg.OutputPrice}, true
+	}
+	if cfg.Provider == "copilot" {
+		return price{}, false
+	}
+	model, best := modelName(cfg), ""
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	p, ok = prices[best]
+	return p, ok
+}
+
+// EstimateTokens approximates how many tokens text takes, at the usual four
+// characters a token. Providers' tokenizers differ, so it's a guide for
+// budgeting, not a count.
+func EstimateTokens(text string) int {
+	return bytesToTokens(len(text))
+}
+
+func bytesToTokens(n int) int {
+	return (n + 3) / 4
+}
+
+// Estimate is what a prompt is expected to use, reported before it's sent.
+type Estimate struct {
+	PromptTokens   int
+	ResponseTokens int     // the most the answer may use
+	Cost           float64 // the most the call may cost, in US dollars
+	Priced         bool    // false when the model's price is unknown
+}
+
+// EstimatePrompt estimates what sending prompt with cfg uses and costs.
+func EstimatePrompt(cfg config.AIConfig, prompt string) Estimate {
+	e := Estimate{PromptTokens: EstimateTokens(prompt), ResponseTokens: int(maxTokensOrDefault(cfg.MaxTokens))}
+	if p, ok := priceOf(cfg); ok {
+		e.Cost = (float64(e.PromptTokens)*p.input + float64(e.ResponseTokens)*p.output) / 1e6
+		e.Priced = true
+	}
+	return e
+}
+
+// String reads like "~2.1k prompt tokens + up to 1k response tokens, at
+// most $0.022".
+func (e Estimate) String() string {
+	s := fmt.Sprintf("~%s prompt tokens + up to %s response tokens", formatTokens(e.PromptTokens), formatTokens(e.ResponseTokens))
+	if e.Priced {
+		s += ", at most " + formatCost(e.Cost)
+	}
+	return s
+}
+
+// Short reads like "~2.1k tokens, ≤ $0.022", for a status line.
+func (e Estimate) Short() string {
+	s := "~" + formatTokens(e.PromptTokens) + " tokens"
+	if e.Priced {
+		s += ", ≤ " + formatCost(e.Cost)
+	}
+	return s
+}
+
+// formatTokens abbreviates thousands: 850, 2.1k, 12k.
+func formatTokens(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 10000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+	default:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	}
+}
+
+// formatCost shows dollars with more decimals for small amounts: $0.0031,
+// $0.022, $1.40.
+func formatCost(usd float64) string {
+	switch {
+	case usd >= 1:
+		return fmt.Sprintf("$%.2f", usd)
+	case usd >= 0.01:
+		return fmt.Sprintf("$%.3f", usd)
+	default:
+		return fmt.Sprintf("$%.4f", usd)
+	}
+}
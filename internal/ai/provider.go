@@ -4,24 +4,82 @@ package ai
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
 
 type Provider interface {
-	Diagnose(ctx context.Context, prompt string) (string, error)
+	// Diagnose answers prompt, reporting the tokens the call used.
+	Diagnose(ctx context.Context, prompt string) (string, Usage, error)
 	Name() string
 }
 
+// Usage is the tokens a call used.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+	Estimated    bool // counted by drift; the provider doesn't report them
+}
+
+// NewProvider returns the configured provider, each call limited to
+// ai.timeout and retried up to ai.retries times on rate limits and server
+// errors. Canceling a call's context aborts it, including any wait between
+// attempts. With ai.redact.paths, file paths are masked in prompts.
 func NewProvider(cfg config.AIConfig) (Provider, error) {
+	timeout, err := cfg.RequestTimeout()
+	if err != nil {
+		return nil, fmt.Errorf("ai.timeout: %w", err)
+	}
+	var p Provider
 	switch cfg.Provider {
 	case "anthropic":
-		return NewAnthropicProvider(cfg)
+		p, err = NewAnthropicProvider(cfg)
 	case "openai":
-		return NewOpenAIProvider(cfg)
+		p, err = NewOpenAIProvider(cfg)
+	case "copilot":
+		p, err = NewCopilotProvider(cfg)
 	default:
-		return nil, fmt.Errorf("unknown AI provider: %q (supported: anthropic, openai)", cfg.Provider)
+		return nil, fmt.Errorf("unknown AI provider: %q (supported: anthropic, openai, copilot)", cfg.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p = &resilient{Provider: p, timeout: timeout, retries: max(cfg.Retries, 0), backoff: firstBackoff}
+	if cfg.Redact.Paths {
+		p = masked{p}
+	}
+	return p, nil
+}
+
+// apiKey is the provider's API key: the environment variable env, or the
+// contents of ai.key_file.
+func apiKey(cfg config.AIConfig, env string) (string, error) {
+	if key := os.Getenv(env); key != "" {
+		return key, nil
+	}
+	if cfg.KeyFile == "" {
+		return "", fmt.Errorf("%s environment variable not set", env)
+	}
+	path := cfg.KeyFile
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("ai.key_file: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s not set, and reading ai.key_file: %w", env, err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("%s not set, and %s is empty", env, cfg.KeyFile)
 	}
+	return key, nil
 }
 
 // maxTokensOrDefault falls back to a sane response budget when none is configured.
@@ -1,9 +1,15 @@
 package ai
 
 import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/history"
 )
 
 func TestMaxTokensOrDefault(t *testing.T) {
@@ -49,3 +55,114 @@ func TestNewOpenAIProvider_TokenBudget(t *testing.T) {
 		t.Errorf("configured budget = %d, want 4096", p.maxTokens)
 	}
 }
+
+func TestCopilotProvider(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	if _, err := NewProvider(config.AIConfig{Provider: "copilot"}); err == nil {
+		t.Fatal("NewProvider succeeded without a copilot binary")
+	}
+
+	// A stand-in copilot that echoes its arguments.
+	script := "#!/bin/sh\necho \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "copilot"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProvider(config.AIConfig{Provider: "copilot", Model: "gpt-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "GitHub Copilot" {
+		t.Errorf("Name = %q", p.Name())
+	}
+	text, usage, err := p.Diagnose(context.Background(), "fix it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "-p fix it -s --no-auto-update --model gpt-5" {
+		t.Errorf("copilot ran with %q", text)
+	}
+	if !usage.Estimated || usage.InputTokens != 2 || usage.OutputTokens != EstimateTokens(text) {
+		t.Errorf("usage = %+v, want an estimate", usage)
+	}
+}
+
+func TestEstimatePrompt(t *testing.T) {
+	prompt := strings.Repeat("x", 4000) // ~1000 tokens
+	e := EstimatePrompt(config.AIConfig{Provider: "anthropic", MaxTokens: 1000}, prompt)
+	if e.PromptTokens != 1000 || e.ResponseTokens != 1000 || !e.Priced {
+		t.Fatalf("estimate = %+v", e)
+	}
+	// Sonnet: $3 in and $15 out per million tokens.
+	if want := 0.018; math.Abs(e.Cost-want) > 1e-9 {
+		t.Errorf("cost = %v, want %v", e.Cost, want)
+	}
+	if got := e.String(); got != "~1k prompt tokens + up to 1k response tokens, at most $0.018" {
+		t.Errorf("String = %q", got)
+	}
+
+	mini := EstimatePrompt(config.AIConfig{Provider: "openai", Model: "gpt-4o-mini-2024-07-18"}, prompt)
+	if want := (1000*0.15 + 1024*0.6) / 1e6; math.Abs(mini.Cost-want) > 1e-9 {
+		t.Errorf("gpt-4o-mini cost = %v, want %v (not gpt-4o's price)", mini.Cost, want)
+	}
+
+	if e := EstimatePrompt(config.AIConfig{Provider: "copilot"}, prompt); e.Priced {
+		t.Error("copilot priced")
+	}
+	if e := EstimatePrompt(config.AIConfig{Provider: "openai", Model: "local-llama"}, prompt); e.Priced || strings.Contains(e.String(), "$") {
+		t.Errorf("unknown model priced: %s", e)
+	}
+	custom := EstimatePrompt(config.AIConfig{Provider: "openai", Model: "local-llama", InputPrice: 1, OutputPrice: 2}, prompt)
+	if want := (1000*1.0 + 1024*2.0) / 1e6; !custom.Priced || math.Abs(custom.Cost-want) > 1e-9 {
+		t.Errorf("configured price: %+v, want cost %v", custom, want)
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.AIConfig{Provider: "anthropic"}
+	RecordUsage(root, cfg, "diagnose", Usage{InputTokens: 2000, OutputTokens: 500})
+	RecordUsage(root, cfg, "diagnose", Usage{}) // never reached the provider
+	RecordUsage(root, config.AIConfig{Provider: "copilot"}, "fix", Usage{InputTokens: 10, OutputTokens: 10, Estimated: true})
+
+	calls, err := history.LoadCalls(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("recorded %d calls, want 2", len(calls))
+	}
+	c := calls[0]
+	if c.Command != "diagnose" || c.Model != defaultModels["anthropic"] || c.InputTokens != 2000 || c.OutputTokens != 500 {
+		t.Errorf("call = %+v", c)
+	}
+	// Sonnet: $3 in and $15 out per million tokens.
+	if want := 0.0135; !c.Priced || math.Abs(c.Cost-want) > 1e-9 {
+		t.Errorf("cost = %v (priced %v), want %v", c.Cost, c.Priced, want)
+	}
+	if fix := calls[1]; fix.Priced || !fix.Estimated || fix.ModelLabel() != "copilot" {
+		t.Errorf("copilot call = %+v", fix)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := apiKey(config.AIConfig{}, "ANTHROPIC_API_KEY"); err == nil {
+		t.Error("apiKey succeeded with neither the variable nor a key file")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "anthropic.key"), []byte("sk-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.AIConfig{KeyFile: "~/anthropic.key"}
+	if key, err := apiKey(cfg, "ANTHROPIC_API_KEY"); err != nil || key != "sk-file" {
+		t.Errorf("apiKey = %q, %v; want the key file's", key, err)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+	if key, _ := apiKey(cfg, "ANTHROPIC_API_KEY"); key != "sk-env" {
+		t.Errorf("apiKey = %q, want the variable's", key)
+	}
+}
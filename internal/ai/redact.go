@@ -0,0 +1,221 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// syntax is what redaction needs to know of a language: how its comments
+// and string literals are delimited.
+type syntax struct {
+	line   []string  // line comment markers
+	block  [2]string // block comment delimiters; empty if none
+	quotes string    // string delimiters; a backtick delimits raw strings
+	triple bool      // tripled quotes delimit multi-line strings
+}
+
+var (
+	cSyntax        = syntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"'`}
+	backtickSyntax = syntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: "\"'`"}
+	scriptSyntax   = syntax{line: []string{"#"}, quotes: `"'`, triple: true}
+)
+
+// syntaxes map source file extensions to their syntax.
+var syntaxes = map[string]syntax{
+	".go":    backtickSyntax,
+	".ts":    backtickSyntax,
+	".tsx":   backtickSyntax,
+	".js":    backtickSyntax,
+	".jsx":   backtickSyntax,
+	".java":  {line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"'`, triple: true},
+	".swift": {line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"`, triple: true},
+	".rs":    {line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"`}, // ' starts lifetimes too
+	".cs":    cSyntax,
+	".php":   {line: []string{"//", "#"}, block: [2]string{"/*", "*/"}, quotes: `"'`},
+	".py":    scriptSyntax,
+	".rb":    scriptSyntax,
+	".ex":    scriptSyntax,
+	".exs":   scriptSyntax,
+}
+
+// RedactCode strips what r asks for from code from the file at path:
+// string literals are emptied and comments dropped, keeping their line
+// breaks so line numbers still hold.
+func RedactCode(r config.RedactConfig, path, code string) string {
+	if !r.Strings && !r.Comments {
+		return code
+	}
+	syn, ok := syntaxes[filepath.Ext(path)]
+	if !ok {
+		syn = cSyntax
+	}
+	var b strings.Builder
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		switch {
+		case hasAnyPrefix(rest, syn.line):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			if !r.Comments {
+				b.WriteString(rest[:end])
+			}
+			i += end
+		case syn.block[0] != "" && strings.HasPrefix(rest, syn.block[0]):
+			end := strings.Index(rest[2:], syn.block[1])
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2 + len(syn.block[1])
+			}
+			if r.Comments {
+				b.WriteString(strings.Repeat("\n", strings.Count(rest[:end], "\n")))
+			} else {
+				b.WriteString(rest[:end])
+			}
+			i += end
+		case strings.IndexByte(syn.quotes, rest[0]) >= 0:
+			open := rest[:1]
+			if syn.triple && strings.HasPrefix(rest, strings.Repeat(open, 3)) {
+				open = rest[:3]
+			}
+			end := stringEnd(rest, open)
+			if r.Strings {
+				b.WriteString(open + strings.Repeat("\n", strings.Count(rest[:end], "\n")) + open)
+			} else {
+				b.WriteString(rest[:end])
+			}
+			i += end
+		default:
+			b.WriteByte(code[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// stringEnd is where the string literal s starts with, opened by open,
+// ends. Single-quoted strings end at a line break if not closed before;
+// backticks and tripled quotes span lines and take no escapes.
+func stringEnd(s, open string) int {
+	raw := open == "`" || len(open) == 3
+	for i := len(open); i < len(s); i++ {
+		switch {
+		case !raw && s[i] == '\\':
+			i++
+		case !raw && s[i] == '\n':
+			return i
+		case strings.HasPrefix(s[i:], open):
+			return i + len(open)
+		}
+	}
+	return len(s)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPatch strips what r asks for from the lines of a diff's hunks,
+// keeping each line's +, -, or space prefix.
+func redactPatch(r config.RedactConfig, path, patch string) string {
+	if !r.Strings && !r.Comments {
+		return patch
+	}
+	lines := strings.Split(patch, "\n")
+	prefixes := make([]string, len(lines))
+	for i, l := range lines {
+		if l != "" && !strings.HasPrefix(l, "@@") && !strings.HasPrefix(l, "\\") {
+			prefixes[i], lines[i] = l[:1], l[1:]
+		} else {
+			prefixes[i], lines[i] = l, ""
+		}
+	}
+	lines = strings.Split(RedactCode(r, path, strings.Join(lines, "\n")), "\n")
+	for i := range lines {
+		lines[i] = prefixes[i] + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeSnippet is getCodeSnippet with ai.redact applied; it is "" when no
+// code may be sent.
+func codeSnippet(cfg *config.Config, path string, start, n int) string {
+	if cfg.AI.Redact.MetricsOnly {
+		return ""
+	}
+	return RedactCode(cfg.AI.Redact, path, getCodeSnippet(cfg.Root, path, start, n))
+}
+
+// sourcePath matches the source file paths in a prompt, by extension.
+var sourcePath = regexp.MustCompile(`[\w.\-/]*[\w\-]\.(?:` + extensionPattern() + `)\b`)
+
+// aliasPath matches the aliases masked gives paths.
+var aliasPath = regexp.MustCompile(`\bfile\d+\.(?:` + extensionPattern() + `)\b`)
+
+func extensionPattern() string {
+	exts := make([]string, 0, len(syntaxes))
+	for ext := range syntaxes {
+		exts = append(exts, regexp.QuoteMeta(ext[1:]))
+	}
+	sort.Slice(exts, func(i, j int) bool { return len(exts[i]) > len(exts[j]) }) // .tsx before .ts
+	return strings.Join(exts, "|")
+}
+
+// MaskPaths replaces the source file paths in text with aliases, such as
+// file1.go, as ai.redact.paths has prompts sent.
+func MaskPaths(text string) string {
+	text, _ = maskPaths(text)
+	return text
+}
+
+// maskPaths is MaskPaths also returning the path of each alias.
+func maskPaths(text string) (string, map[string]string) {
+	aliases := map[string]string{} // path → alias
+	paths := map[string]string{}   // alias → path
+	text = sourcePath.ReplaceAllStringFunc(text, func(path string) string {
+		// Keep a diff's a/ and b/ prefixes, so both sides share an alias.
+		prefix := ""
+		if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+			prefix, path = path[:2], path[2:]
+		}
+		alias, ok := aliases[path]
+		if !ok {
+			alias = fmt.Sprintf("file%d%s", len(aliases)+1, filepath.Ext(path))
+			aliases[path], paths[alias] = alias, path
+		}
+		return prefix + alias
+	})
+	return text, paths
+}
+
+// masked replaces the source file paths in prompts with aliases, such as
+// file1.go, and the aliases in answers with the paths again, so the
+// provider never sees the project's layout.
+type masked struct {
+	Provider
+}
+
+func (m masked) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
+	prompt, paths := maskPaths(prompt)
+	text, usage, err := m.Provider.Diagnose(ctx, prompt)
+	text = aliasPath.ReplaceAllStringFunc(text, func(alias string) string {
+		if path, ok := paths[alias]; ok {
+			return path
+		}
+		return alias
+	})
+	return text, usage, err
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+func TestRedactCode(t *testing.T) {
+	src := "// Package a talks to the billing API.\nfunc A() {\n\turl := \"https://billing.internal/v1\" // prod\n\tq := `SELECT *\nFROM accounts`\n\t/* keep\n\tout */ r := 'x'\n}"
+	tests := []struct {
+		name string
+		r    config.RedactConfig
+		want string
+	}{
+		{"nothing", config.RedactConfig{}, src},
+		{"strings", config.RedactConfig{Strings: true},
+			"// Package a talks to the billing API.\nfunc A() {\n\turl := \"\" // prod\n\tq := `\n`\n\t/* keep\n\tout */ r := ''\n}"},
+		{"comments", config.RedactConfig{Comments: true},
+			"\nfunc A() {\n\turl := \"https://billing.internal/v1\" \n\tq := `SELECT *\nFROM accounts`\n\t\n r := 'x'\n}"},
+	}
+	for _, tt := range tests {
+		if got := RedactCode(tt.r, "a.go", src); got != tt.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+
+	py := "def f():\n    \"\"\"Docs\n    here.\"\"\"\n    s = 'it''s # not a comment'  # secret\n"
+	want := "def f():\n    \"\"\"\n\"\"\"\n    s = ''''  \n"
+	if got := RedactCode(config.RedactConfig{Strings: true, Comments: true}, "a.py", py); got != want {
+		t.Errorf("python: got\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRedactPatch(t *testing.T) {
+	patch := "@@ -1,2 +1,2 @@\n func A() {\n-\tlog(\"old secret\")\n+\tlog(\"new secret\") // why\n\\ No newline at end of file"
+	want := "@@ -1,2 +1,2 @@\n func A() {\n-\tlog(\"\")\n+\tlog(\"\") \n\\ No newline at end of file"
+	if got := redactPatch(config.RedactConfig{Strings: true, Comments: true}, "a.go", patch); got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCodeSnippet_MetricsOnly(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Root = "."
+	if snippet := codeSnippet(cfg, "redact.go", 1, 5); snippet == "" {
+		t.Fatal("no snippet without redaction")
+	}
+	cfg.AI.Redact.MetricsOnly = true
+	if snippet := codeSnippet(cfg, "redact.go", 1, 5); snippet != "" {
+		t.Errorf("metrics-only snippet = %q", snippet)
+	}
+}
+
+// echoProvider records its prompt and answers with answer.
+type echoProvider struct {
+	prompt, answer string
+}
+
+func (e *echoProvider) Name() string { return "echo" }
+
+func (e *echoProvider) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
+	e.prompt = prompt
+	return e.answer, Usage{}, nil
+}
+
+func TestMasked(t *testing.T) {
+	echo := &echoProvider{answer: "Split Run in file1.go:40.\n--- a/file2.tsx\n+++ b/file2.tsx\nfile9.go is unknown."}
+	text, _, err := masked{echo}.Diagnose(context.Background(),
+		"Run() in internal/billing/charge.go:40\n--- a/web/src/Invoice.tsx\n+++ b/web/src/Invoice.tsx\nagain internal/billing/charge.go, module gopkg.in/yaml.v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Run() in file1.go:40\n--- a/file2.tsx\n+++ b/file2.tsx\nagain file1.go, module gopkg.in/yaml.v3"; echo.prompt != want {
+		t.Errorf("prompt = %q, want %q", echo.prompt, want)
+	}
+	if want := "Split Run in internal/billing/charge.go:40.\n--- a/web/src/Invoice.tsx\n+++ b/web/src/Invoice.tsx\nfile9.go is unknown."; text != want {
+		t.Errorf("answer = %q, want %q", text, want)
+	}
+	if strings.Contains(echo.prompt, "billing") {
+		t.Errorf("prompt leaks a path: %q", echo.prompt)
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+)
+
+// firstBackoff is how long the first retry waits; each one after waits
+// twice as long as the last.
+const firstBackoff = time.Second
+
+// resilient limits each call of a provider to a timeout and retries calls
+// that hit a rate limit or server error, backing off exponentially. The SDK
+// clients' own retries are turned off so the configured count holds.
+type resilient struct {
+	Provider
+	timeout time.Duration // per attempt; 0 = no limit
+	retries int
+	backoff time.Duration
+}
+
+// Diagnose reports the tokens all attempts used.
+func (r *resilient) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
+	wait := r.backoff
+	var total Usage
+	for attempt := 0; ; attempt++ {
+		text, usage, err := r.attempt(ctx, prompt)
+		total.InputTokens += usage.InputTokens
+		total.OutputTokens += usage.OutputTokens
+		total.Estimated = total.Estimated || usage.Estimated
+		if err == nil || ctx.Err() != nil || attempt >= r.retries || !retryable(err) {
+			return text, total, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", total, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// attempt calls the provider once, within the timeout.
+func (r *resilient) attempt(ctx context.Context, prompt string) (string, Usage, error) {
+	if r.timeout <= 0 {
+		return r.Provider.Diagnose(ctx, prompt)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	text, usage, err := r.Provider.Diagnose(attemptCtx, prompt)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return "", usage, fmt.Errorf("%s didn't answer within %s", r.Name(), r.timeout)
+	}
+	return text, usage, err
+}
+
+// retryable reports whether err is an API error worth trying again: a rate
+// limit or a server error.
+func retryable(err error) bool {
+	status := 0
+	var anthropicErr *anthropic.Error
+	var openaiErr *openai.Error
+	switch {
+	case errors.As(err, &anthropicErr):
+		status = anthropicErr.StatusCode
+	case errors.As(err, &openaiErr):
+		status = openaiErr.StatusCode
+	}
+	return status == http.StatusTooManyRequests || status >= 500
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+)
+
+// fakeProvider answers with errs in turn, then "ok".
+type fakeProvider struct {
+	errs  []error
+	calls int
+	block bool // wait for the context to end instead
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Diagnose(ctx context.Context, prompt string) (string, Usage, error) {
+	f.calls++
+	if f.block {
+		<-ctx.Done()
+		return "", Usage{}, ctx.Err()
+	}
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return "", Usage{}, err
+	}
+	return "ok", Usage{InputTokens: 10, OutputTokens: 2}, nil
+}
+
+func TestResilient_RetriesRateLimitsAndServerErrors(t *testing.T) {
+	fake := &fakeProvider{errs: []error{&anthropic.Error{StatusCode: 429}, &openai.Error{StatusCode: 503}}}
+	r := &resilient{Provider: fake, retries: 2, backoff: time.Millisecond}
+	text, usage, err := r.Diagnose(context.Background(), "prompt")
+	if err != nil || text != "ok" {
+		t.Fatalf("Diagnose = %q, %v; want ok after two retries", text, err != nil)
+	}
+	if fake.calls != 3 {
+		t.Errorf("calls = %d, want 3", fake.calls)
+	}
+	if usage.InputTokens != 10 || usage.OutputTokens != 2 {
+		t.Errorf("usage = %+v, want the successful attempt's", usage)
+	}
+}
+
+func TestResilient_GivesUp(t *testing.T) {
+	fake := &fakeProvider{errs: []error{&anthropic.Error{StatusCode: 500}, &anthropic.Error{StatusCode: 500}}}
+	r := &resilient{Provider: fake, retries: 1, backoff: time.Millisecond}
+	if _, _, err := r.Diagnose(context.Background(), "prompt"); err == nil {
+		t.Error("Diagnose succeeded after running out of retries")
+	}
+	if fake.calls != 2 {
+		t.Errorf("calls = %d, want 2", fake.calls)
+	}
+
+	// Client errors won't go away by asking again.
+	fake = &fakeProvider{errs: []error{&anthropic.Error{StatusCode: 400}}}
+	r = &resilient{Provider: fake, retries: 3, backoff: time.Millisecond}
+	if _, _, err := r.Diagnose(context.Background(), "prompt"); err == nil || fake.calls != 1 {
+		t.Errorf("bad request: calls = %d, failed = %v; want one failed call", fake.calls, err != nil)
+	}
+}
+
+func TestResilient_Timeout(t *testing.T) {
+	r := &resilient{Provider: &fakeProvider{block: true}, timeout: 10 * time.Millisecond, retries: 2, backoff: time.Millisecond}
+	_, _, err := r.Diagnose(context.Background(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), "didn't answer within 10ms") {
+		t.Errorf("Diagnose error = %v, want a timeout", err)
+	}
+}
+
+func TestResilient_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := &fakeProvider{errs: []error{&anthropic.Error{StatusCode: 429}}}
+	r := &resilient{Provider: fake, retries: 5, backoff: time.Hour}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, _, err := r.Diagnose(ctx, "prompt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Diagnose error = %v, want context.Canceled while backing off", err)
+	}
+	if fake.calls != 1 {
+		t.Errorf("calls = %d, want 1", fake.calls)
+	}
+}
@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// ReviewFile is a file a pull request changes, with its diff hunks.
+type ReviewFile struct {
+	Path  string
+	Patch string
+}
+
+// Review is the provider's review of a pull request: a summary and
+// comments on lines of the new versions of its files.
+type Review struct {
+	Summary  string          `json:"summary"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// ReviewComment is a review comment on one line.
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// reviewAsk is the instruction ending a review prompt, including the JSON
+// shape ParseReview reads.
+const reviewAsk = `
+Review the diff for code health only, not style or correctness: new or grown complexity, broken architecture boundaries, dead code, and duplication. Use drift's findings above where they apply. Comment only on lines the diff adds or keeps, giving each its line number in the new version of the file, and say concretely how to fix each issue. Leave out anything minor; no comments is a fine answer.
+
+Answer with only a JSON object, no other text:
+{"summary": "a short markdown overview", "comments": [{"path": "file", "line": 12, "body": "markdown"}]}
+`
+
+// BuildReviewPrompt asks for a health-focused review of a pull request's
+// diff, given drift's findings in the files it changes, within
+// ai.max_prompt_tokens and ai.redact. Findings come first; diffs that no
+// longer fit are left out, and the prompt says which.
+func BuildReviewPrompt(cfg *config.Config, files []ReviewFile, findings []health.Finding) string {
+	w := &promptWriter{budget: cfg.AI.MaxPromptTokens}
+	if w.budget > 0 {
+		w.budget = max(w.budget-EstimateTokens(reviewAsk), 1)
+	}
+	w.write("Review this pull request for code health.\n\n")
+
+	items := make([]string, len(findings))
+	for i, f := range findings {
+		items[i] = fmt.Sprintf("%s:%d [%s] %s", f.Path, max(1, f.Line), f.Rule, f.Message)
+	}
+	if len(items) > 0 {
+		w.list("drift findings in the changed files", items)
+	} else {
+		w.write("drift found no issues in the changed files.\n\n")
+	}
+
+	var skipped []string
+	for _, f := range files {
+		if f.Patch == "" || cfg.AI.Redact.MetricsOnly {
+			continue
+		}
+		patch := redactPatch(cfg.AI.Redact, f.Path, strings.TrimRight(f.Patch, "\n"))
+		block := fmt.Sprintf("%s\n```diff\n%s\n```\n\n", f.Path, patch)
+		if !w.fits(block) {
+			skipped = append(skipped, f.Path)
+			continue
+		}
+		w.write(block)
+	}
+	if len(skipped) > 0 {
+		w.write(fmt.Sprintf("(diffs of %s left out for length)\n", strings.Join(skipped, ", ")))
+	}
+	w.write(reviewAsk)
+	return w.String()
+}
+
+// ParseReview reads the JSON review BuildReviewPrompt asks for from answer,
+// ignoring any text or code fence around it.
+func ParseReview(answer string) (Review, error) {
+	var r Review
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return r, fmt.Errorf("no JSON review in the answer")
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &r); err != nil {
+		return r, fmt.Errorf("reading review: %w", err)
+	}
+	return r, nil
+}
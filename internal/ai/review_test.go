@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestBuildReviewPrompt(t *testing.T) {
+	cfg := config.Defaults()
+	files := []ReviewFile{
+		{Path: "a.go", Patch: "@@ -1 +1 @@\n-func A() {}\n+func A() { if x {} }"},
+		{Path: "big.go", Patch: "@@ -1 +1,400 @@\n" + strings.Repeat("+x := 1\n", 400)},
+	}
+	findings := []health.Finding{{Rule: "complexity", Level: "warning", Path: "a.go", Line: 1, Message: "A has cyclomatic complexity 16 (limit 15)."}}
+
+	prompt := BuildReviewPrompt(cfg, files, findings)
+	for _, want := range []string{"a.go:1 [complexity] A has cyclomatic complexity 16", "+func A() { if x {} }", "big.go", `"comments"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+
+	cfg.AI.MaxPromptTokens = 300
+	prompt = BuildReviewPrompt(cfg, files, findings)
+	if strings.Contains(prompt, "+x := 1") || !strings.Contains(prompt, "diffs of big.go left out") {
+		t.Errorf("over-budget diff not left out:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "+func A()") {
+		t.Errorf("diff that fits left out:\n%s", prompt)
+	}
+}
+
+func TestParseReview(t *testing.T) {
+	answer := "Here it is:\n```json\n{\"summary\": \"Adds complexity.\", \"comments\": [{\"path\": \"a.go\", \"line\": 3, \"body\": \"Extract {this}.\"}]}\n```"
+	r, err := ParseReview(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Summary != "Adds complexity." || len(r.Comments) != 1 || r.Comments[0].Line != 3 || r.Comments[0].Body != "Extract {this}." {
+		t.Errorf("review = %+v", r)
+	}
+	if _, err := ParseReview("Looks fine to me."); err == nil {
+		t.Error("answer without JSON parsed")
+	}
+}
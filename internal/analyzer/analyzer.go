@@ -2,6 +2,10 @@
 package analyzer
 
 import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/greatnessinabox/drift/internal/config"
@@ -12,93 +16,493 @@ type Results struct {
 	Dependencies []DepStatus
 	Violations   []BoundaryViolation
 	DeadCode     []DeadFunction
-	Coverage     Coverage
-	FileCount    int
-	FuncCount    int
-	Language     Language
+	Files        []FileMetrics
+	Duplicates   []DuplicateBlock
+	Debt         []DebtMarker
+	Tests        []PackageTests
+	Coupling     []PackageCoupling
+	Cycles       []ImportCycle
+	// SuppressedViolations are boundary violations silenced with an inline
+	// "drift:ignore boundary" comment. They are listed but not scored.
+	SuppressedViolations []BoundaryViolation
+	// Vulnerabilities lists known advisories for the dependency versions in
+	// use, most severe first.
+	Vulnerabilities []Vulnerability
+	// LicenseViolations lists dependencies whose license is on the
+	// configured deny list.
+	LicenseViolations []LicenseViolation
+	// Notices lists files left out of the analysis because they couldn't
+	// be read or parsed.
+	Notices []Notice
+	// OverExported lists exported Go identifiers referenced only from their
+	// own package. Unlike dead code, they are listed but not scored.
+	OverExported []OverExported
+	// Baselined counts the issues left out because the baseline
+	// grandfathers them; see Baseline.Apply.
+	Baselined int
+	// ExportedFuncs counts the functions and methods that are part of their
+	// package's API; see countExported.
+	ExportedFuncs int
+	// TransitiveDeps counts the distinct packages pulled in by the direct
+	// dependencies, where the dependency graph is known.
+	TransitiveDeps int
+	Naming         []NamingIssue
+	Types          []TypeSize
+	Coverage       Coverage
+	FileCount      int
+	FuncCount      int
+	Language       Language
+	// Languages lists every analyzed language, primary first. It has more
+	// than one entry only in multi-language (monorepo) mode.
+	Languages []Language
+	// Projects holds per-project results in monorepo mode; the fields above
+	// then aggregate every project.
+	Projects []ProjectResults
+
+	// single is the file a RunSingle result is for, relative to the root.
+	single string
+}
+
+// MultiLanguage reports whether results merge more than one language.
+func (r *Results) MultiLanguage() bool {
+	return len(r.Languages) > 1
+}
+
+// LanguageLabel names the analyzed languages for display, e.g. "go+typescript".
+func (r *Results) LanguageLabel() string {
+	if len(r.Languages) == 0 {
+		return string(r.Language)
+	}
+	names := make([]string, len(r.Languages))
+	for i, l := range r.Languages {
+		names[i] = string(l)
+	}
+	return strings.Join(names, "+")
 }
 
 type Analyzer struct {
-	cfg  *config.Config
-	lang LanguageAnalyzer
-	mu   sync.Mutex
+	cfg      *config.Config
+	langs    []LanguageAnalyzer
+	projects []Project
+	skipDirs []string
+	// includeRoot is the directory include globs are relative to: the
+	// configured root, also for the sub-analyzers of a monorepo.
+	includeRoot string
+	registry    *registryClient
+	mu          sync.Mutex
+
+	progress func(Progress)
+}
+
+// Progress tells how far a Run has got: the phase it is in and, while it
+// analyzes files, how many of them are done.
+type Progress struct {
+	Phase string // e.g. "analyzing go files", "checking dependencies"
+	Done  int    // files analyzed so far
+	Total int    // files to analyze; 0 in phases not counted in files
+}
+
+// OnProgress makes Run report its progress to fn, on the goroutine calling
+// Run, so fn should return quickly.
+func (a *Analyzer) OnProgress(fn func(Progress)) {
+	a.progress = fn
+}
+
+// report passes progress to the OnProgress callback, if any.
+func (a *Analyzer) report(phase string, done, total int) {
+	if a.progress != nil {
+		a.progress(Progress{Phase: phase, Done: done, Total: total})
+	}
 }
 
 func New(cfg *config.Config) *Analyzer {
-	lang := detectOrConfiguredLanguage(cfg)
-	return &Analyzer{cfg: cfg, lang: lang}
+	a := &Analyzer{cfg: cfg, projects: configuredProjects(cfg), includeRoot: cfg.Root, registry: newRegistry(cfg)}
+	if len(a.projects) == 0 {
+		a.langs = configuredLanguages(cfg)
+		return a
+	}
+
+	seen := make(map[Language]bool)
+	for _, p := range a.projects {
+		if !seen[p.Language] {
+			seen[p.Language] = true
+			a.langs = append(a.langs, NewLanguageAnalyzer(p.Language))
+		}
+	}
+	return a
 }
 
 func (a *Analyzer) Extensions() []string {
-	return a.lang.Extensions()
+	var exts []string
+	for _, l := range a.langs {
+		exts = append(exts, l.Extensions()...)
+	}
+	return exts
 }
 
 func (a *Analyzer) DetectedLanguage() Language {
-	return a.lang.Language()
+	return a.langs[0].Language()
 }
 
-func (a *Analyzer) Run() (*Results, error) {
-	results := &Results{
-		Language: a.lang.Language(),
+// Languages lists the languages a analyzes, primary first, as Results
+// reports them.
+func (a *Analyzer) Languages() []Language {
+	langs := make([]Language, len(a.langs))
+	for i, l := range a.langs {
+		langs[i] = l.Language()
 	}
+	return langs
+}
 
-	files, err := a.lang.FindFiles(a.cfg.Root, a.cfg.Exclude)
-	if err != nil {
-		return nil, err
+func (a *Analyzer) Run() (*Results, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.projects) > 0 {
+		return a.runProjects()
 	}
-	results.FileCount = len(files)
 
-	complexity, funcCount := a.lang.AnalyzeComplexity(files)
-	results.Complexity = complexity
-	results.FuncCount = funcCount
+	results := a.newResults()
 
-	deps, err := a.lang.AnalyzeDeps(a.cfg.Root)
-	if err == nil {
-		results.Dependencies = deps
+	for _, lang := range a.langs {
+		if err := a.runLanguage(lang, results); err != nil {
+			return nil, err
+		}
 	}
-
-	results.Violations = a.lang.AnalyzeImports(files, a.cfg.Boundaries, a.cfg.Root)
-	results.DeadCode = a.lang.AnalyzeDeadCode(files)
-	results.Coverage = readCoverage(a.cfg.Root)
+	a.report("checking advisories", 0, 0)
+	results.Vulnerabilities = scanVulnerabilities(a.registry, results.Dependencies)
+	results.LicenseViolations = checkLicenses(a.registry, results.Dependencies, a.cfg.Licenses.Deny)
+	a.report("reading coverage", 0, 0)
+	results.Coverage = loadCoverage(a.cfg.Root, a.cfg.Coverage)
+	a.registry.save() // best effort; lookups simply repeat next run if it fails
 
 	sortComplexityDesc(results.Complexity)
 
 	return results, nil
 }
 
-func (a *Analyzer) RunSingle(path string) (*Results, error) {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+// runLanguage analyzes the files of a single language and merges its
+// findings into results, tagging complexity and dependency entries.
+func (a *Analyzer) runLanguage(lang LanguageAnalyzer, results *Results) error {
+	files, err := lang.FindFiles(a.cfg.Root, a.cfg.Exclude)
+	if err != nil {
+		return err
+	}
+	files = a.included(a.withoutSkipped(files))
+	results.FileCount += len(files)
 
-	results := &Results{
-		Language: a.lang.Language(),
+	phase := "analyzing " + string(lang.Language()) + " files"
+	a.report(phase, 0, len(files))
+	complexity, funcCount, fileMetrics, notices := analyzeFiles(lang, a.cfg.Root, files, func(done int) {
+		a.report(phase, done, len(files))
+	})
+	results.Notices = append(results.Notices, notices...)
+	for i := range complexity {
+		complexity[i].Language = lang.Language()
+	}
+	results.Complexity = append(results.Complexity, complexity...)
+	results.FuncCount += funcCount
+	results.ExportedFuncs += countExported(lang.Language(), complexity)
+	if a.cfg.Naming.Enabled {
+		rule, err := namingRule(a.cfg.Naming, lang.Language())
+		if err != nil {
+			return err
+		}
+		if rule != nil {
+			results.Naming = append(results.Naming, checkNaming(complexity, lang.Language(), rule)...)
+		}
 	}
+	results.Debt = append(results.Debt, scanDebt(lang.Language(), files, fileMetrics)...)
+	results.Files = append(results.Files, fileMetrics...)
 
-	matched := false
-	for _, ext := range a.lang.Extensions() {
-		if len(path) > len(ext) && path[len(path)-len(ext):] == ext {
-			matched = true
-			break
+	a.report("checking dependencies", 0, 0)
+	deps, err := lang.AnalyzeDeps(a.registry, a.cfg.Root)
+	switch {
+	case errors.Is(err, errNoManifest) || errors.Is(err, fs.ErrNotExist):
+		// nothing declares dependencies
+	case err != nil:
+		results.Notices = append(results.Notices, Notice{Path: ".", Message: "dependencies not checked: " + err.Error()})
+	default:
+		var kept []DepStatus
+		for _, dep := range deps {
+			if dep.Scope == "dev" && !a.cfg.Deps.IncludeDev {
+				continue
+			}
+			dep.Language = lang.Language()
+			if dep.Status != "current" {
+				dep.Behind, dep.MajorsBehind = versionGap(dep.CurrentVersion, dep.LatestVersion)
+			}
+			kept = append(kept, dep)
+		}
+		if read := depGraphReaders[lang.Language()]; read != nil && len(kept) > 0 {
+			if graph := read(a.cfg.Root); graph != nil {
+				results.TransitiveDeps += addTransitiveWeights(kept, graph)
+			}
+		}
+		results.Dependencies = append(results.Dependencies, kept...)
+	}
+
+	a.report("checking imports", 0, 0)
+	for _, v := range lang.AnalyzeImports(files, a.cfg.BoundaryRules(), a.cfg.Root) {
+		if v.Suppressed {
+			results.SuppressedViolations = append(results.SuppressedViolations, v)
+		} else {
+			results.Violations = append(results.Violations, v)
+		}
+	}
+	a.report("finding dead code", 0, 0)
+	for _, d := range lang.AnalyzeDeadCode(files) {
+		d.Path = relPath(a.cfg.Root, d.Path)
+		if !a.cfg.DeadCode.Ignores(d.Name, d.File) {
+			results.DeadCode = append(results.DeadCode, d)
 		}
 	}
-	if !matched {
+	if oa, ok := lang.(overExportAnalyzer); ok {
+		results.OverExported = append(results.OverExported, oa.analyzeOverExported(files)...)
+	}
+	a.report("finding duplication", 0, 0)
+	results.Duplicates = append(results.Duplicates, findDuplicates(a.cfg.Root, files, a.cfg.Thresholds.MinDuplicateLines)...)
+	results.Types = append(results.Types, analyzeTypeSizes(lang.Language(), a.cfg.Root, files)...)
+	results.Tests = append(results.Tests, analyzeTests(lang, a.cfg.Root, a.cfg.Exclude, files)...)
+
+	a.report("measuring coupling", 0, 0)
+	graph := buildImportGraph(lang.Language(), a.cfg.Root, files)
+	results.Coupling = append(results.Coupling, couplingMetrics(graph, lang.Language())...)
+	results.Cycles = append(results.Cycles, findImportCycles(graph, lang.Language())...)
+	return nil
+}
+
+// RunSingle analyzes the per-file findings of the file at path. A file Run
+// would leave out, such as a test, an excluded file, or one belonging to
+// another project, yields no findings.
+func (a *Analyzer) RunSingle(path string) (*Results, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	results := a.newResults()
+	results.single = relPath(a.cfg.Root, path)
+
+	lang := a.ownerOf(path)
+	if lang == nil {
 		return results, nil
 	}
 
 	files := []string{path}
-	complexity, funcCount := a.lang.AnalyzeComplexity(files)
+	complexity, funcCount, fileMetrics, notices := analyzeFiles(lang, a.cfg.Root, files, nil)
+	for i := range complexity {
+		complexity[i].Language = lang.Language()
+	}
 	results.Complexity = complexity
 	results.FuncCount = funcCount
 	results.FileCount = 1
+	results.Debt = scanDebt(lang.Language(), files, fileMetrics)
+	results.Files = fileMetrics
+	results.Notices = notices
 
 	return results, nil
 }
 
+// ReplaceFile returns a copy of r with the per-file findings (complexity,
+// file metrics, debt markers, and notices) for the file analyzed in single, a
+// RunSingle result, swapped in, or dropped if RunSingle left the file out.
+// Project-wide findings such as dependencies, duplication, and dead code are
+// kept as they were until the next full Run.
+func (r *Results) ReplaceFile(single *Results) *Results {
+	if len(single.Files) == 0 && single.single != "" {
+		return r.RemovePath(single.single)
+	}
+	if len(single.Files) != 1 {
+		return r
+	}
+	fm := single.Files[0]
+
+	merged := *r
+	merged.Files = make([]FileMetrics, 0, len(r.Files)+1)
+	found := false
+	for _, f := range r.Files {
+		if f.Path == fm.Path {
+			merged.FuncCount -= f.Functions
+			found = true
+			continue
+		}
+		merged.Files = append(merged.Files, f)
+	}
+	merged.Files = append(merged.Files, fm)
+	merged.FuncCount += fm.Functions
+	if !found {
+		merged.FileCount++
+	}
+
+	merged.Complexity = make([]FunctionComplexity, 0, len(r.Complexity)+len(single.Complexity))
+	for _, fc := range r.Complexity {
+		if fc.Path != fm.Path {
+			merged.Complexity = append(merged.Complexity, fc)
+		}
+	}
+	merged.Complexity = append(merged.Complexity, single.Complexity...)
+	sortComplexityDesc(merged.Complexity)
+
+	merged.Debt = make([]DebtMarker, 0, len(r.Debt)+len(single.Debt))
+	for _, d := range r.Debt {
+		if d.Path != fm.Path {
+			merged.Debt = append(merged.Debt, d)
+		}
+	}
+	merged.Debt = append(merged.Debt, single.Debt...)
+
+	merged.Notices = make([]Notice, 0, len(r.Notices)+len(single.Notices))
+	for _, n := range r.Notices {
+		if n.Path != fm.Path {
+			merged.Notices = append(merged.Notices, n)
+		}
+	}
+	merged.Notices = append(merged.Notices, single.Notices...)
+	return &merged
+}
+
+// RemovePath returns a copy of r without the per-file findings (complexity,
+// file metrics, debt markers, notices, and duplicated blocks) of the file at
+// path, relative to the root, or of every file under it if it was a
+// directory. Like ReplaceFile, project-wide findings are kept as they were
+// until the next full Run.
+func (r *Results) RemovePath(path string) *Results {
+	path = filepath.ToSlash(path)
+	under := func(p string) bool { return p == path || strings.HasPrefix(p, path+"/") }
+
+	merged := *r
+	merged.Files = make([]FileMetrics, 0, len(r.Files))
+	for _, f := range r.Files {
+		if under(f.Path) {
+			merged.FileCount--
+			merged.FuncCount -= f.Functions
+			continue
+		}
+		merged.Files = append(merged.Files, f)
+	}
+
+	merged.Complexity = make([]FunctionComplexity, 0, len(r.Complexity))
+	for _, fc := range r.Complexity {
+		if !under(fc.Path) {
+			merged.Complexity = append(merged.Complexity, fc)
+		}
+	}
+
+	merged.Debt = make([]DebtMarker, 0, len(r.Debt))
+	for _, d := range r.Debt {
+		if !under(d.Path) {
+			merged.Debt = append(merged.Debt, d)
+		}
+	}
+
+	merged.Notices = make([]Notice, 0, len(r.Notices))
+	for _, n := range r.Notices {
+		if !under(n.Path) {
+			merged.Notices = append(merged.Notices, n)
+		}
+	}
+
+	merged.Duplicates = make([]DuplicateBlock, 0, len(r.Duplicates))
+	for _, d := range r.Duplicates {
+		var kept []DuplicateLocation
+		for _, loc := range d.Locations {
+			if !under(loc.Path) {
+				kept = append(kept, loc)
+			}
+		}
+		if len(kept) > 1 { // a block left in one place is no longer a copy
+			d.Locations = kept
+			merged.Duplicates = append(merged.Duplicates, d)
+		}
+	}
+	return &merged
+}
+
+func (a *Analyzer) newResults() *Results {
+	return &Results{Language: a.langs[0].Language(), Languages: a.Languages()}
+}
+
+// languageFor returns the analyzer that owns path's extension, or nil.
+func (a *Analyzer) languageFor(path string) LanguageAnalyzer {
+	for _, l := range a.langs {
+		if ownsExtension(l, path) {
+			return l
+		}
+	}
+	return nil
+}
+
+func ownsExtension(lang LanguageAnalyzer, path string) bool {
+	for _, ext := range lang.Extensions() {
+		if len(path) > len(ext) && path[len(path)-len(ext):] == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerOf returns the analyzer Run would analyze path with, or nil if Run
+// would leave the file out. In monorepo mode the file belongs to the
+// innermost project containing it and is matched against that project's
+// root.
+func (a *Analyzer) ownerOf(path string) LanguageAnalyzer {
+	lang, root := a.languageFor(path), a.cfg.Root
+	if len(a.projects) > 0 {
+		lang = nil
+		var owner *Project
+		for i, p := range a.projects {
+			if isWithin(path, p.Path) && (owner == nil || isWithin(p.Path, owner.Path)) {
+				owner = &a.projects[i]
+			}
+		}
+		if owner != nil {
+			root = owner.Path
+			for _, l := range a.langs {
+				if l.Language() == owner.Language && ownsExtension(l, path) {
+					lang = l
+				}
+			}
+		}
+	}
+	if lang == nil || !acceptsFile(lang, root, a.cfg.Exclude, path) {
+		return nil
+	}
+	if lang.Language() == LangGo && generatedGoFile(path) {
+		return nil
+	}
+	if len(a.included(a.withoutSkipped([]string{path}))) == 0 {
+		return nil
+	}
+	return lang
+}
+
+// configuredLanguages resolves the analyzers to run: the explicit
+// `languages` list for monorepos, else the single `language` setting, else
+// auto-detection. Duplicate entries are ignored.
+func configuredLanguages(cfg *config.Config) []LanguageAnalyzer {
+	var langs []LanguageAnalyzer
+	seen := make(map[Language]bool)
+	for _, name := range cfg.Languages {
+		lang := Language(name)
+		if seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		langs = append(langs, NewLanguageAnalyzer(lang))
+	}
+	if len(langs) > 0 {
+		return langs
+	}
+	return []LanguageAnalyzer{detectOrConfiguredLanguage(cfg)}
+}
+
 func detectOrConfiguredLanguage(cfg *config.Config) LanguageAnalyzer {
 	if cfg.Language != "" {
 		return NewLanguageAnalyzer(Language(cfg.Language))
 	}
 	detected := DetectLanguage(cfg.Root)
+	if detected == LangUnknown {
+		detected = DetectSources(cfg.Root, cfg.Exclude)
+	}
 	return NewLanguageAnalyzer(detected)
 }
 
@@ -0,0 +1,383 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestRun_MultiLanguage(t *testing.T) {
+	root := t.TempDir()
+	for name, src := range map[string]string{"h.go": goFixture, "h.py": pyFixture} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Languages = []string{"go", "python", "go"}
+
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := results.LanguageLabel(); got != "go+python" {
+		t.Errorf("LanguageLabel = %q, want go+python", got)
+	}
+	if results.FileCount != 2 {
+		t.Errorf("FileCount = %d, want 2", results.FileCount)
+	}
+	seen := make(map[Language]bool)
+	for _, fc := range results.Complexity {
+		seen[fc.Language] = true
+	}
+	if !seen[LangGo] || !seen[LangPython] {
+		t.Errorf("complexity not tagged with both languages: %+v", results.Complexity)
+	}
+}
+
+func TestRun_ConfiguredLanguage(t *testing.T) {
+	root := t.TempDir()
+	// go.mod would make this a Go project, but language says Python.
+	for name, src := range map[string]string{"go.mod": "module x\n", "h.go": goFixture, "h.py": pyFixture} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "python"
+
+	a := New(cfg)
+	if got := a.Extensions(); len(got) != 1 || got[0] != ".py" {
+		t.Errorf("Extensions = %v, want [.py]", got)
+	}
+	results, err := a.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Language != LangPython || results.FileCount != 1 {
+		t.Errorf("language %q over %d files, want python over 1", results.Language, results.FileCount)
+	}
+}
+
+func TestRun_NoManifest(t *testing.T) {
+	root := t.TempDir()
+	// No manifest: the language with the most source files is analyzed.
+	for name, src := range map[string]string{"a.py": pyFixture, "b.py": pyFixture, "tool.go": goFixture, "vendor/c.go": goFixture, "vendor/d.go": goFixture} {
+		os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755)
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Language != LangPython || results.FileCount != 2 {
+		t.Errorf("language %q over %d files, want python over 2", results.Language, results.FileCount)
+	}
+	if len(results.Notices) != 0 {
+		t.Errorf("a missing manifest should go unremarked: %v", results.Notices)
+	}
+}
+
+func TestRun_DependencyNotice(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"package.json": "{not json", "index.ts": "export function f() {}\n"})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Offline = true
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Notices) != 1 || !strings.HasPrefix(results.Notices[0].Message, "dependencies not checked: parsing package.json") {
+		t.Errorf("notices = %v, want one for the unreadable package.json", results.Notices)
+	}
+}
+
+func TestRun_DiscoveredProjects(t *testing.T) {
+	root := t.TempDir()
+	for name, src := range map[string]string{
+		"go.mod":           "module example.com/root\n",
+		"main.go":          goFixture,
+		"web/package.json": "{}",
+		"web/h.ts":         tsFixture,
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.DiscoverProjects = true
+
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.Projects) != 2 {
+		t.Fatalf("discovered %d projects, want 2: %+v", len(results.Projects), results.Projects)
+	}
+	if p := results.Projects[1].Project; p.Name != "web" || p.Language != LangTypeScript {
+		t.Errorf("nested project = %+v, want web/typescript", p)
+	}
+	// The root Go project must not also count the nested project's files.
+	if got := results.Projects[0].Results.FileCount; got != 1 {
+		t.Errorf("root project FileCount = %d, want 1", got)
+	}
+	if results.FileCount != 2 {
+		t.Errorf("aggregate FileCount = %d, want 2", results.FileCount)
+	}
+}
+
+func TestRun_IncludeExcludeGlobs(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"src/app.py":          pyFixture,
+		"src/models_gen.py":   pyFixture,
+		"src/legacy/old.py":   pyFixture,
+		"scripts/release.py":  pyFixture,
+		"src/vendor/thing.py": pyFixture,
+	})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "python"
+	cfg.Include = []string{"src/**"}
+	cfg.Exclude = append(cfg.Exclude, "**/*_gen.py", "src/legacy/**")
+
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, f := range results.Files {
+		paths = append(paths, f.Path)
+	}
+	if len(paths) != 1 || paths[0] != "src/app.py" {
+		t.Errorf("analyzed %v, want only src/app.py", paths)
+	}
+}
+
+func TestRun_Progress(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"a.py": pyFixture, "b.py": pyFixture})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "python"
+
+	a := New(cfg)
+	var got []Progress
+	a.OnProgress(func(p Progress) { got = append(got, p) })
+	if _, err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []int
+	phases := make(map[string]bool)
+	for _, p := range got {
+		phases[p.Phase] = true
+		if p.Phase == "analyzing python files" {
+			if p.Total != 2 {
+				t.Errorf("Total = %d, want 2", p.Total)
+			}
+			files = append(files, p.Done)
+		}
+	}
+	if len(files) != 3 || files[2] != 2 {
+		t.Errorf("files done = %v, want [0 1 2]", files)
+	}
+	for _, phase := range []string{"checking dependencies", "finding dead code", "reading coverage"} {
+		if !phases[phase] {
+			t.Errorf("no %q progress in %+v", phase, got)
+		}
+	}
+}
+
+func TestRun_DeadCodeIgnore(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"main.go": `package main
+
+import "example.com/m/plugin"
+
+func main() { _ = plugin.Point{} }
+`,
+		"plugin/point.go": `package plugin
+
+type Point struct{ X int }
+
+func (p Point) MarshalJSON() ([]byte, error) { return nil, nil }
+
+func Stale() {}
+`,
+		"plugin/hooks_plugin.go": "package plugin\n\nfunc Register() {}\n",
+	})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "go"
+	cfg.DeadCode.Ignore = []string{"MarshalJSON", "*_plugin.go"}
+
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dead []string
+	for _, d := range results.DeadCode {
+		dead = append(dead, d.Name)
+	}
+	if len(dead) != 1 || dead[0] != "Stale" {
+		t.Errorf("dead code = %v, want only Stale", dead)
+	}
+}
+
+func TestResults_ReplaceFile(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"a/util.py": pyFixture,
+		"b/util.py": pyFixture,
+	})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "python"
+	ana := New(cfg)
+	full, err := ana.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := filepath.Join(root, "a", "util.py")
+	testutil.WriteTree(t, root, map[string]string{
+		"a/util.py": pyFixture + "\n# TODO: split\ndef extra():\n    return 2\n",
+	})
+	single, err := ana.RunSingle(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := full.ReplaceFile(single)
+
+	if merged.FileCount != 2 || merged.FuncCount != 3 {
+		t.Errorf("FileCount, FuncCount = %d, %d, want 2, 3", merged.FileCount, merged.FuncCount)
+	}
+	perPath := make(map[string]int)
+	for _, fc := range merged.Complexity {
+		perPath[fc.Path]++
+	}
+	if perPath["a/util.py"] != 2 || perPath["b/util.py"] != 1 {
+		t.Errorf("functions per path = %v", perPath)
+	}
+	if len(merged.Debt) != 1 || merged.Debt[0].Path != "a/util.py" {
+		t.Errorf("Debt = %+v", merged.Debt)
+	}
+	if full.FuncCount != 2 || len(full.Complexity) != 2 {
+		t.Error("ReplaceFile modified the original results")
+	}
+}
+
+func TestRunSingle_LeftOut(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod":       "module example.com/root\n",
+		"main.go":      goFixture,
+		"main_test.go": goFixture,
+		"gen/api.go":   goFixture,
+		"web/h.ts":     tsFixture,
+		"web/tool.go":  goFixture,
+	})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Exclude = append(cfg.Exclude, "gen")
+	cfg.Projects = []config.ProjectConfig{{Path: ".", Language: "go"}, {Path: "web", Language: "typescript"}}
+	ana := New(cfg)
+
+	tests := []struct {
+		path  string
+		files int
+	}{
+		{"main.go", 1},
+		{"web/h.ts", 1},
+		{"main_test.go", 0},
+		{"gen/api.go", 0},
+		{"web/tool.go", 0}, // inside the TypeScript project
+	}
+	for _, tt := range tests {
+		single, err := ana.RunSingle(filepath.Join(root, tt.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(single.Files) != tt.files {
+			t.Errorf("RunSingle(%s) analyzed %d files, want %d", tt.path, len(single.Files), tt.files)
+			continue
+		}
+		if tt.files == 1 && single.Files[0].Path != tt.path {
+			t.Errorf("RunSingle(%s) path = %q", tt.path, single.Files[0].Path)
+		}
+		if tt.files == 0 {
+			r := &Results{FileCount: 1, Files: []FileMetrics{{Path: tt.path}}, Complexity: []FunctionComplexity{{Path: tt.path}}}
+			if merged := r.ReplaceFile(single); merged.FileCount != 0 || len(merged.Files) != 0 || len(merged.Complexity) != 0 {
+				t.Errorf("ReplaceFile kept %s: %+v", tt.path, merged)
+			}
+		}
+	}
+}
+
+func TestResults_RemovePath(t *testing.T) {
+	r := &Results{
+		FileCount: 3,
+		FuncCount: 4,
+		Files: []FileMetrics{
+			{Path: "a/one.py", Functions: 1},
+			{Path: "a/sub/two.py", Functions: 2},
+			{Path: "ab/three.py", Functions: 1},
+		},
+		Complexity: []FunctionComplexity{{Path: "a/one.py"}, {Path: "a/sub/two.py"}, {Path: "ab/three.py"}},
+		Debt:       []DebtMarker{{Path: "a/sub/two.py"}},
+		Duplicates: []DuplicateBlock{
+			{Lines: 6, Locations: []DuplicateLocation{{Path: "a/one.py"}, {Path: "ab/three.py"}}},
+			{Lines: 8, Locations: []DuplicateLocation{{Path: "a/one.py"}, {Path: "ab/three.py"}, {Path: "ab/three.py", Line: 40}}},
+		},
+	}
+
+	gone := r.RemovePath("a")
+	if gone.FileCount != 1 || gone.FuncCount != 1 || len(gone.Files) != 1 || gone.Files[0].Path != "ab/three.py" {
+		t.Errorf("files = %d, funcs = %d, %+v", gone.FileCount, gone.FuncCount, gone.Files)
+	}
+	if len(gone.Complexity) != 1 || len(gone.Debt) != 0 {
+		t.Errorf("Complexity = %+v, Debt = %+v", gone.Complexity, gone.Debt)
+	}
+	if len(gone.Duplicates) != 1 || len(gone.Duplicates[0].Locations) != 2 {
+		t.Errorf("Duplicates = %+v", gone.Duplicates)
+	}
+
+	if one := r.RemovePath("a/one.py"); one.FileCount != 2 || len(one.Complexity) != 2 {
+		t.Errorf("removing a file: files = %d, %+v", one.FileCount, one.Complexity)
+	}
+	if r.FileCount != 3 || len(r.Complexity) != 3 {
+		t.Error("RemovePath modified the original results")
+	}
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// BaselineFile is where `drift baseline` records existing issues, relative
+// to the analysis root.
+const BaselineFile = ".drift-baseline.json"
+
+// Baseline records the issues a codebase had when it was taken, so later
+// runs can grandfather them and count only new ones. Issues are keyed by
+// fingerprints that leave out line numbers, so unrelated edits that shift
+// code around don't bring them back; a fingerprint that occurred twice
+// grandfathers two matching issues.
+//
+// Grandfathered are complex or oversized functions, boundary violations,
+// import cycles, dead code, duplicated blocks, debt markers, naming issues,
+// vulnerabilities, and license violations. Dependency freshness, coverage,
+// and the other ratio-based scores aren't made of discrete issues and always
+// count in full.
+type Baseline struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Issues    map[string]int `json:"issues"` // fingerprint → occurrences
+
+	// Score holds the total and category scores with the baseline applied,
+	// keyed as in `drift snapshot`, for `drift check --no-regression`.
+	Score map[string]float64 `json:"score,omitempty"`
+}
+
+// NewBaseline records the issues in r, judging functions against t.
+func NewBaseline(r *Results, t config.ThresholdConfig) *Baseline {
+	b := &Baseline{CreatedAt: time.Now().UTC(), Issues: make(map[string]int)}
+	for _, key := range issueKeys(r, t) {
+		if key != "" {
+			b.Issues[key]++
+		}
+	}
+	return b
+}
+
+// LoadBaseline reads the baseline under root. It returns nil and no error
+// when there is none.
+func LoadBaseline(root string) (*Baseline, error) {
+	data, err := os.ReadFile(filepath.Join(root, BaselineFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Save writes the baseline under root.
+func (b *Baseline) Save(root string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, BaselineFile), append(data, '\n'), 0o644)
+}
+
+// Grandfather adds one occurrence of issue to the baseline, to ignore a
+// single finding. issue is a FunctionComplexity (judged against t),
+// BoundaryViolation, ImportCycle, DeadFunction, DuplicateBlock, DebtMarker,
+// NamingIssue, Vulnerability, or LicenseViolation. It reports false, leaving
+// the baseline alone, for anything else and for functions within the limits.
+func (b *Baseline) Grandfather(issue any, t config.ThresholdConfig) bool {
+	var key string
+	switch i := issue.(type) {
+	case FunctionComplexity:
+		key = complexityKey(i, t)
+	case BoundaryViolation:
+		key = violationKey(i)
+	case ImportCycle:
+		key = cycleKey(i)
+	case DeadFunction:
+		key = deadCodeKey(i)
+	case DuplicateBlock:
+		key = duplicateKey(i)
+	case DebtMarker:
+		key = debtKey(i)
+	case NamingIssue:
+		key = namingKey(i)
+	case Vulnerability:
+		key = vulnerabilityKey(i)
+	case LicenseViolation:
+		key = licenseKey(i)
+	}
+	if key == "" {
+		return false
+	}
+	if b.Issues == nil {
+		b.Issues = make(map[string]int)
+	}
+	b.Issues[key]++
+	return true
+}
+
+// Apply returns a copy of r without the issues the baseline grandfathers,
+// with Baselined set to how many were left out.
+func (b *Baseline) Apply(r *Results, t config.ThresholdConfig) *Results {
+	remaining := make(map[string]int, len(b.Issues))
+	for k, n := range b.Issues {
+		remaining[k] = n
+	}
+	out := *r
+	out.Complexity = withoutBaselined(&out, r.Complexity, func(fc FunctionComplexity) string { return complexityKey(fc, t) }, remaining)
+	out.Violations = withoutBaselined(&out, r.Violations, violationKey, remaining)
+	out.Cycles = withoutBaselined(&out, r.Cycles, cycleKey, remaining)
+	out.DeadCode = withoutBaselined(&out, r.DeadCode, deadCodeKey, remaining)
+	out.Duplicates = withoutBaselined(&out, r.Duplicates, duplicateKey, remaining)
+	out.Debt = withoutBaselined(&out, r.Debt, debtKey, remaining)
+	out.Naming = withoutBaselined(&out, r.Naming, namingKey, remaining)
+	out.Vulnerabilities = withoutBaselined(&out, r.Vulnerabilities, vulnerabilityKey, remaining)
+	out.LicenseViolations = withoutBaselined(&out, r.LicenseViolations, licenseKey, remaining)
+	return &out
+}
+
+// NewIssues returns a copy of r holding only the issues that base doesn't
+// have, matched by the same fingerprints a baseline uses. Swapping the
+// arguments gives the issues r resolved.
+func NewIssues(base, r *Results, t config.ThresholdConfig) *Results {
+	return NewBaseline(base, t).Apply(r, t)
+}
+
+// withoutBaselined drops the items whose fingerprint still has grandfathered
+// occurrences left, counting them in r.Baselined. An empty fingerprint
+// marks an item that isn't an issue.
+func withoutBaselined[T any](r *Results, items []T, key func(T) string, remaining map[string]int) []T {
+	var kept []T
+	for _, item := range items {
+		if k := key(item); k != "" && remaining[k] > 0 {
+			remaining[k]--
+			r.Baselined++
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+func issueKeys(r *Results, t config.ThresholdConfig) []string {
+	var keys []string
+	for _, fc := range r.Complexity {
+		keys = append(keys, complexityKey(fc, t))
+	}
+	for _, v := range r.Violations {
+		keys = append(keys, violationKey(v))
+	}
+	for _, c := range r.Cycles {
+		keys = append(keys, cycleKey(c))
+	}
+	for _, d := range r.DeadCode {
+		keys = append(keys, deadCodeKey(d))
+	}
+	for _, d := range r.Duplicates {
+		keys = append(keys, duplicateKey(d))
+	}
+	for _, d := range r.Debt {
+		keys = append(keys, debtKey(d))
+	}
+	for _, n := range r.Naming {
+		keys = append(keys, namingKey(n))
+	}
+	for _, v := range r.Vulnerabilities {
+		keys = append(keys, vulnerabilityKey(v))
+	}
+	for _, l := range r.LicenseViolations {
+		keys = append(keys, licenseKey(l))
+	}
+	return keys
+}
+
+// complexityKey fingerprints a function that is over one of the limits t
+// sets for its file, and returns "" for any other.
+func complexityKey(fc FunctionComplexity, t config.ThresholdConfig) string {
+	t = t.For(fc.Path)
+	maxComplexity := t.MaxComplexity
+	if maxComplexity == 0 {
+		maxComplexity = 15
+	}
+	over := fc.Complexity > maxComplexity ||
+		(t.MaxFuncLines > 0 && fc.Lines > t.MaxFuncLines) ||
+		(t.MaxParams > 0 && fc.Params > t.MaxParams) ||
+		(t.MaxNesting > 0 && fc.Nesting > t.MaxNesting)
+	if !over {
+		return ""
+	}
+	return "function " + fc.Path + " " + fc.Name
+}
+
+func violationKey(v BoundaryViolation) string {
+	return "boundary " + v.File + " " + v.Import
+}
+
+func cycleKey(c ImportCycle) string {
+	return "cycle " + strings.Join(c.Packages, " -> ")
+}
+
+func deadCodeKey(d DeadFunction) string {
+	return "dead " + d.File + " " + d.Name
+}
+
+func duplicateKey(d DuplicateBlock) string {
+	paths := make([]string, len(d.Locations))
+	for i, loc := range d.Locations {
+		paths[i] = loc.Path
+	}
+	return "duplicate " + strings.Join(paths, " ")
+}
+
+func debtKey(d DebtMarker) string {
+	return "debt " + d.Path + " " + d.Kind + " " + d.Text
+}
+
+func namingKey(n NamingIssue) string {
+	return "naming " + n.File + " " + n.Name
+}
+
+func vulnerabilityKey(v Vulnerability) string {
+	return "vulnerability " + v.Module + " " + v.ID
+}
+
+func licenseKey(l LicenseViolation) string {
+	return "license " + l.Module + " " + l.License
+}
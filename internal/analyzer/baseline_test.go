@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+func TestBaseline_Apply(t *testing.T) {
+	thresholds := config.Defaults().Thresholds
+	before := &Results{
+		Complexity: []FunctionComplexity{
+			{Path: "a.go", Name: "Tangled", Line: 10, Complexity: 30},
+			{Path: "a.go", Name: "Simple", Line: 50, Complexity: 2},
+		},
+		DeadCode: []DeadFunction{{File: "a.go", Name: "Old", Line: 70}},
+		Debt: []DebtMarker{
+			{Path: "a.go", Line: 3, Kind: "TODO", Text: "split this"},
+			{Path: "a.go", Line: 4, Kind: "TODO", Text: "split this"},
+		},
+	}
+	dir := t.TempDir()
+	if err := NewBaseline(before, thresholds).Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadBaseline(dir)
+	if err != nil || b == nil {
+		t.Fatalf("LoadBaseline = %v, %v", b, err)
+	}
+	if len(b.Issues) != 3 || b.Issues["debt a.go TODO split this"] != 2 {
+		t.Errorf("issues = %v, want Tangled, Old, and the TODO twice", b.Issues)
+	}
+
+	// Lines moved, a third identical TODO and a new dead function appeared.
+	after := &Results{
+		Complexity: []FunctionComplexity{
+			{Path: "a.go", Name: "Tangled", Line: 12, Complexity: 31},
+			{Path: "a.go", Name: "Simple", Line: 52, Complexity: 2},
+		},
+		DeadCode: []DeadFunction{{File: "a.go", Name: "Old", Line: 72}, {File: "a.go", Name: "New", Line: 90}},
+		Debt: []DebtMarker{
+			{Path: "a.go", Line: 5, Kind: "TODO", Text: "split this"},
+			{Path: "a.go", Line: 6, Kind: "TODO", Text: "split this"},
+			{Path: "a.go", Line: 7, Kind: "TODO", Text: "split this"},
+		},
+	}
+	got := b.Apply(after, thresholds)
+	if got.Baselined != 4 {
+		t.Errorf("Baselined = %d, want 4", got.Baselined)
+	}
+	if len(got.Complexity) != 1 || got.Complexity[0].Name != "Simple" {
+		t.Errorf("complexity = %+v, want only Simple", got.Complexity)
+	}
+	if len(got.DeadCode) != 1 || got.DeadCode[0].Name != "New" {
+		t.Errorf("dead code = %+v, want only New", got.DeadCode)
+	}
+	if len(got.Debt) != 1 || got.Debt[0].Line != 7 {
+		t.Errorf("debt = %+v, want the third TODO", got.Debt)
+	}
+	if len(after.DeadCode) != 2 {
+		t.Error("Apply modified its input")
+	}
+}
+
+func TestNewIssues(t *testing.T) {
+	thresholds := config.Defaults().Thresholds
+	base := &Results{
+		DeadCode: []DeadFunction{{File: "a.go", Name: "Old", Line: 70}},
+		Debt:     []DebtMarker{{Path: "a.go", Line: 3, Kind: "TODO", Text: "split this"}},
+	}
+	head := &Results{
+		DeadCode: []DeadFunction{{File: "a.go", Name: "Old", Line: 75}, {File: "b.go", Name: "New", Line: 9}},
+	}
+
+	added := NewIssues(base, head, thresholds)
+	if len(added.DeadCode) != 1 || added.DeadCode[0].Name != "New" || len(added.Debt) != 0 {
+		t.Errorf("added = %+v, %+v, want only New", added.DeadCode, added.Debt)
+	}
+	resolved := NewIssues(head, base, thresholds)
+	if len(resolved.DeadCode) != 0 || len(resolved.Debt) != 1 {
+		t.Errorf("resolved = %+v, %+v, want only the TODO", resolved.DeadCode, resolved.Debt)
+	}
+}
+
+func TestLoadBaseline_Missing(t *testing.T) {
+	if b, err := LoadBaseline(t.TempDir()); b != nil || err != nil {
+		t.Errorf("LoadBaseline = %v, %v, want nil, nil", b, err)
+	}
+}
+
+func TestBaseline_Grandfather(t *testing.T) {
+	thresholds := config.Defaults().Thresholds
+	var b Baseline
+	if b.Grandfather(FunctionComplexity{Path: "a.go", Name: "Simple", Complexity: 2}, thresholds) {
+		t.Error("grandfathered a function within the limits")
+	}
+	if b.Grandfather(DepStatus{Module: "x"}, thresholds) {
+		t.Error("grandfathered a dependency, which isn't made of discrete issues")
+	}
+	v := BoundaryViolation{File: "a.go", Line: 3, Import: "x/db"}
+	if !b.Grandfather(v, thresholds) {
+		t.Fatal("boundary violation not grandfathered")
+	}
+	got := b.Apply(&Results{Violations: []BoundaryViolation{v, {File: "b.go", Import: "x/db"}}}, thresholds)
+	if len(got.Violations) != 1 || got.Violations[0].File != "b.go" {
+		t.Errorf("violations = %+v, want only b.go's", got.Violations)
+	}
+}
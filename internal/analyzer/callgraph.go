@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// stdlibCallbacks are methods the standard library calls through anonymous
+// interfaces inside function bodies (errors.Is, errors.As, errors.Unwrap), so
+// they don't show up among its declared interfaces.
+var stdlibCallbacks = map[string]bool{"Unwrap": true, "Is": true, "As": true}
+
+// analyzeCallGraphDeadCode reports exported functions and methods declared in
+// files that no main package among pkgs can reach. Reachability comes from
+// Rapid Type Analysis over the SSA form of the module: calls through
+// interfaces and function values resolve only to the types actually
+// instantiated and the functions actually taken as values, so same-named
+// methods on unrelated types are told apart, and a function handed around as
+// a value counts as called.
+//
+// Dependencies are known by their types only, so RTA can't see them call back
+// into the module (fmt calling String, sort calling Less). A method of a type
+// that ends up in an interface value therefore also counts as called when a
+// dependency declares an interface with a method of that name.
+//
+// It reports false when there is no main package to root the call graph at
+// (a library, whose exported API is its entry point); callers then fall back
+// to analyzeTypedDeadCode.
+func analyzeCallGraphDeadCode(pkgs []*packages.Package, files []string) ([]DeadFunction, bool) {
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+
+	var roots []*ssa.Function
+	for _, p := range ssaPkgs {
+		if p == nil || p.Pkg.Name() != "main" || p.Func("main") == nil {
+			continue
+		}
+		roots = append(roots, p.Func("main"))
+		if init := p.Func("init"); init != nil {
+			roots = append(roots, init)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, false
+	}
+	prog.Build()
+	res := rta.Analyze(roots, false)
+
+	reached := make(map[*types.Func]bool)
+	for fn := range res.Reachable {
+		if origin := fn.Origin(); origin != nil {
+			fn = origin
+		}
+		if obj, ok := fn.Object().(*types.Func); ok {
+			reached[obj.Origin()] = true
+		}
+	}
+
+	external := externalInterfaceMethods(prog, pkgs)
+	res.RuntimeTypes.Iterate(func(t types.Type, _ any) {
+		mset := prog.MethodSets.MethodSet(t)
+		for i := 0; i < mset.Len(); i++ {
+			if fn, ok := mset.At(i).Obj().(*types.Func); ok && external[fn.Name()] {
+				reached[fn.Origin()] = true
+			}
+		}
+	})
+
+	return unusedDeclarations(pkgs, files, func(fn *types.Func) bool { return reached[fn] }), true
+}
+
+// externalInterfaceMethods collects the method names of every interface
+// declared by a package outside pkgs, plus stdlibCallbacks.
+func externalInterfaceMethods(prog *ssa.Program, pkgs []*packages.Package) map[string]bool {
+	internal := make(map[*types.Package]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		internal[pkg.Types] = true
+	}
+
+	names := make(map[string]bool, len(stdlibCallbacks))
+	for name := range stdlibCallbacks {
+		names[name] = true
+	}
+	for _, p := range prog.AllPackages() {
+		if internal[p.Pkg] {
+			continue
+		}
+		scope := p.Pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+				for i := 0; i < iface.NumMethods(); i++ {
+					names[iface.Method(i).Name()] = true
+				}
+			}
+		}
+	}
+	return names
+}
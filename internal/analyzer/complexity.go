@@ -1,16 +1,58 @@
 package analyzer
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"path/filepath"
+	"strings"
 )
 
 type FunctionComplexity struct {
 	File       string
+	Path       string // File relative to the analysis root
 	Name       string
 	Line       int
 	Complexity int
+	Lines      int // source lines spanned by the declaration
+	Params     int // declared parameters, excluding receivers
+	Nesting    int // deepest nested control-flow level in the body
+	Language   Language
+}
+
+// FindFunction finds the one function named name, a plain or Type.Method
+// name, in path if that is set, or the one spanning line of path. When
+// several match, the error lists them after hint, which tells how to pick
+// one.
+func (r *Results) FindFunction(name, path string, line int, hint string) (FunctionComplexity, error) {
+	var found []FunctionComplexity
+	for _, fc := range r.Complexity {
+		switch {
+		case path != "" && fc.Path != path:
+		case name != "" && fc.Name != name && !strings.HasSuffix(fc.Name, "."+name):
+		case line > 0 && (line < fc.Line || line >= fc.Line+max(fc.Lines, 1)):
+		case name == "" && line == 0:
+		default:
+			found = append(found, fc)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if name == "" && (path == "" || line == 0) {
+			return FunctionComplexity{}, fmt.Errorf("give a name, or a path and line")
+		}
+		if name == "" {
+			return FunctionComplexity{}, fmt.Errorf("no function at %s:%d", path, line)
+		}
+		return FunctionComplexity{}, fmt.Errorf("no function named %s", name)
+	case 1:
+		return found[0], nil
+	}
+	locations := make([]string, len(found))
+	for i, fc := range found {
+		locations[i] = fmt.Sprintf("%s (%s:%d)", fc.Name, fc.Path, fc.Line)
+	}
+	return FunctionComplexity{}, fmt.Errorf("%d functions match; %s: %s", len(found), hint, strings.Join(locations, ", "))
 }
 
 func analyzeComplexity(fset *token.FileSet, file *ast.File, path string) []FunctionComplexity {
@@ -30,6 +72,9 @@ func analyzeComplexity(fset *token.FileSet, file *ast.File, path string) []Funct
 				Name:       name,
 				Line:       pos.Line,
 				Complexity: complexity,
+				Lines:      fset.Position(fn.End()).Line - pos.Line + 1,
+				Params:     countGoParams(fn.Type),
+				Nesting:    calcNesting(fn.Body),
 			})
 		}
 		return true
@@ -78,6 +123,21 @@ func calcComplexity(body *ast.BlockStmt) int {
 	return complexity
 }
 
+func countGoParams(ft *ast.FuncType) int {
+	if ft == nil || ft.Params == nil {
+		return 0
+	}
+	n := 0
+	for _, field := range ft.Params.List {
+		if len(field.Names) == 0 {
+			n++
+		} else {
+			n += len(field.Names)
+		}
+	}
+	return n
+}
+
 func receiverName(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
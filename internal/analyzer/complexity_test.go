@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"strings"
 	"testing"
 )
 
@@ -429,3 +430,79 @@ func parseAndAnalyzeComplexity(t *testing.T, src, path string) []FunctionComplex
 
 	return analyzeComplexity(fset, file, path)
 }
+
+func TestAnalyzeComplexity_LinesAndParams(t *testing.T) {
+	src := `package main
+
+func (s *server) handle(a, b int, c string, _ bool) {
+	println(a)
+	println(b)
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := analyzeComplexity(fset, f, "test.go")
+	if len(funcs) != 1 {
+		t.Fatalf("got %d functions, want 1", len(funcs))
+	}
+	if funcs[0].Lines != 4 || funcs[0].Params != 4 {
+		t.Errorf("Lines = %d, Params = %d; want 4 and 4", funcs[0].Lines, funcs[0].Params)
+	}
+}
+
+func TestCountParams(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want int
+	}{
+		{"function handle(x: number): number {", 1},
+		{"def handle(self, a, b=1):", 2},
+		{"pub fn handle(&mut self, m: HashMap<K, V>, f: fn(i32) -> i32, n: u8) {", 3},
+		{"int handle() {", 0},
+		{"def handle", 0},
+	}
+	for _, tt := range tests {
+		if got := countParams(tt.sig); got != tt.want {
+			t.Errorf("countParams(%q) = %d, want %d", tt.sig, got, tt.want)
+		}
+	}
+}
+
+func TestResults_FindFunction(t *testing.T) {
+	results := &Results{Complexity: []FunctionComplexity{
+		{Name: "runCheck", Path: "main.go", Line: 10},
+		{Name: "model.Update", Path: "app.go", Line: 20, Lines: 5},
+		{Name: "cache.Update", Path: "cache.go", Line: 30, Lines: 2},
+	}}
+	if fc, err := results.FindFunction("runCheck", "", 0, ""); err != nil || fc.Line != 10 {
+		t.Errorf("runCheck: got %v, %v", fc, err)
+	}
+	if fc, err := results.FindFunction("model.Update", "", 0, ""); err != nil || fc.Path != "app.go" {
+		t.Errorf("model.Update: got %v, %v", fc, err)
+	}
+	if _, err := results.FindFunction("Update", "", 0, "pick one"); err == nil || !strings.Contains(err.Error(), "pick one") || !strings.Contains(err.Error(), "cache.go:30") {
+		t.Errorf("ambiguous Update: error %v, want both listed", err)
+	}
+	if fc, err := results.FindFunction("Update", "cache.go", 0, ""); err != nil || fc.Name != "cache.Update" {
+		t.Errorf("Update in cache.go: got %v, %v", fc, err)
+	}
+	if _, err := results.FindFunction("date", "", 0, ""); err == nil {
+		t.Error("partial name date matched a function")
+	}
+
+	for line, want := range map[int]string{20: "model.Update", 24: "model.Update", 31: "cache.Update"} {
+		path := "app.go"
+		if line > 30 {
+			path = "cache.go"
+		}
+		if fc, err := results.FindFunction("", path, line, ""); err != nil || fc.Name != want {
+			t.Errorf("function at %s:%d = %s, %v; want %s", path, line, fc.Name, err, want)
+		}
+	}
+	if _, err := results.FindFunction("", "app.go", 25, ""); err == nil {
+		t.Error("found a function past the end of app.go's declaration")
+	}
+}
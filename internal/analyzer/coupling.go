@@ -0,0 +1,274 @@
+package analyzer
+
+import (
+	"bufio"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PackageCoupling describes how a package (a Go package, or a directory for
+// other languages) sits in the project's internal import graph.
+type PackageCoupling struct {
+	Package     string // directory relative to the analysis root
+	Afferent    int    // packages that import this one (Ca)
+	Efferent    int    // packages this one imports (Ce)
+	Instability float64
+	Language    Language
+}
+
+// importGraph maps each package directory to the set of internal package
+// directories it imports. External imports are dropped.
+type importGraph map[string]map[string]bool
+
+// languageImportPatterns are the same patterns each analyzer uses for
+// boundary checks, keyed by language for graph building.
+var languageImportPatterns = map[Language][]*regexp.Regexp{
+	LangTypeScript: tsImportPatterns,
+	LangPython:     pyImportPatterns,
+	LangRust:       rsImportPatterns,
+	LangJava:       javaImportPatterns,
+	LangRuby:       rbImportPatterns,
+	LangPHP:        phpImportPatterns,
+	LangCSharp:     csImportPatterns,
+	LangSwift:      swiftImportPatterns,
+	LangElixir:     exImportPatterns,
+}
+
+// buildImportGraph resolves every import in files to a package directory
+// under root where possible. Go imports are resolved through the module
+// path; other languages by relative path or by matching the import's
+// segments against the directories that hold source files.
+func buildImportGraph(lang Language, root string, files []string) importGraph {
+	graph := make(importGraph)
+	for _, f := range files {
+		graph[relPath(root, filepath.Dir(f))] = make(map[string]bool)
+	}
+
+	modPath := ""
+	if lang == LangGo {
+		modPath = goModulePath(root)
+	}
+
+	for _, f := range files {
+		from := relPath(root, filepath.Dir(f))
+		for _, imp := range listImports(lang, f) {
+			var to string
+			if lang == LangGo {
+				to = resolveGoImport(modPath, imp)
+			} else {
+				to = resolveImport(graph, from, imp)
+			}
+			if to != "" && to != from {
+				if _, ok := graph[to]; ok {
+					graph[from][to] = true
+				}
+			}
+		}
+	}
+	return graph
+}
+
+func listImports(lang Language, path string) []string {
+	if lang == LangGo {
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		var out []string
+		for _, imp := range f.Imports {
+			out = append(out, strings.Trim(imp.Path.Value, `"`))
+		}
+		return out
+	}
+
+	var out []string
+	for _, m := range extractImports(path, languageImportPatterns[lang], 1) {
+		out = append(out, m.path)
+	}
+	return out
+}
+
+func goModulePath(root string) string {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
+
+func resolveGoImport(modPath, imp string) string {
+	switch {
+	case modPath == "":
+		return ""
+	case imp == modPath:
+		return "."
+	case strings.HasPrefix(imp, modPath+"/"):
+		return strings.TrimPrefix(imp, modPath+"/")
+	}
+	return ""
+}
+
+// resolveImport maps a non-Go import to a known package directory, or "".
+func resolveImport(dirs importGraph, fromDir, imp string) string {
+	imp = strings.Trim(imp, `'";`)
+	if i := strings.IndexAny(imp, "{*"); i >= 0 { // Rust use groups, Java wildcards
+		imp = imp[:i]
+	}
+
+	// Relative paths ("./x", "../x") and Python relative modules (".x").
+	if strings.HasPrefix(imp, ".") {
+		if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+			return existingDir(dirs, slashJoin(fromDir, imp))
+		}
+		dots := len(imp) - len(strings.TrimLeft(imp, "."))
+		base := fromDir
+		for i := 1; i < dots; i++ {
+			base = slashJoin(base, "..")
+		}
+		rest := strings.ReplaceAll(strings.TrimLeft(imp, "."), ".", "/")
+		return existingDir(dirs, slashJoin(base, rest))
+	}
+
+	norm := strings.NewReplacer("::", "/", `\`, "/").Replace(imp)
+	if !strings.Contains(norm, "/") {
+		norm = strings.ReplaceAll(norm, ".", "/")
+	}
+	norm = strings.TrimPrefix(strings.TrimPrefix(norm, "crate/"), "self/")
+	norm = strings.Trim(norm, "/")
+	if norm == "" {
+		return ""
+	}
+
+	// Ruby's require_relative and PHP includes are relative to the file.
+	if dir := existingDir(dirs, slashJoin(fromDir, norm)); dir != "" && dir != fromDir {
+		return dir
+	}
+
+	parts := strings.Split(norm, "/")
+	for n := len(parts); n >= 1; n-- {
+		for _, cand := range []string{strings.Join(parts[:n], "/"), snakeCase(strings.Join(parts[:n], "/"))} {
+			if dir := matchDirSuffix(dirs, cand, n > 1); dir != "" {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+// existingDir returns p if it is a package directory, else its parent if
+// that is one (p named a file or module within the directory).
+func existingDir(dirs importGraph, p string) string {
+	if _, ok := dirs[p]; ok {
+		return p
+	}
+	if _, ok := dirs[pathDir(p)]; ok {
+		return pathDir(p)
+	}
+	return ""
+}
+
+// matchDirSuffix finds the shortest directory equal to cand or, when
+// allowSuffix is set, ending in "/"+cand. Single-segment imports must match
+// exactly so that e.g. "java.util" never resolves to "src/main/java".
+func matchDirSuffix(dirs importGraph, cand string, allowSuffix bool) string {
+	if _, ok := dirs[cand]; ok {
+		return cand
+	}
+	if !allowSuffix {
+		return ""
+	}
+	best := ""
+	for dir := range dirs {
+		if strings.HasSuffix(dir, "/"+cand) && (best == "" || len(dir) < len(best) || (len(dir) == len(best) && dir < best)) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// snakeCase converts module names such as "MyApp/UserAccounts" (Elixir,
+// C#, PHP namespaces) to directory names like "my_app/user_accounts".
+func snakeCase(s string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			if prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			prevLower = false
+			continue
+		}
+		b.WriteRune(r)
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	return b.String()
+}
+
+func slashJoin(elem ...string) string {
+	return filepath.ToSlash(filepath.Clean(filepath.Join(elem...)))
+}
+
+func pathDir(p string) string {
+	return filepath.ToSlash(filepath.Dir(p))
+}
+
+// couplingMetrics computes afferent and efferent coupling and instability
+// (Ce / (Ca + Ce)) for every package in the graph.
+func couplingMetrics(graph importGraph, lang Language) []PackageCoupling {
+	afferent := make(map[string]int)
+	for _, deps := range graph {
+		for to := range deps {
+			afferent[to]++
+		}
+	}
+
+	out := make([]PackageCoupling, 0, len(graph))
+	for pkg, deps := range graph {
+		pc := PackageCoupling{
+			Package:  pkg,
+			Afferent: afferent[pkg],
+			Efferent: len(deps),
+			Language: lang,
+		}
+		if total := pc.Afferent + pc.Efferent; total > 0 {
+			pc.Instability = float64(pc.Efferent) / float64(total)
+		}
+		out = append(out, pc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Package < out[j].Package })
+	return out
+}
+
+// UnstableDependencies returns packages that others depend on yet are
+// themselves unstable (instability above 0.5), ranked by how many packages
+// that instability puts at risk. These violate the stable-dependencies
+// principle and are the first candidates for an interface or a split.
+func UnstableDependencies(coupling []PackageCoupling) []PackageCoupling {
+	var out []PackageCoupling
+	for _, pc := range coupling {
+		if pc.Afferent > 0 && pc.Instability > 0.5 {
+			out = append(out, pc)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return float64(out[i].Afferent)*out[i].Instability > float64(out[j].Afferent)*out[j].Instability
+	})
+	return out
+}
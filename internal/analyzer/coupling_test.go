@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestBuildImportGraph_Go(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"main.go":     "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/api\"\n\t\"example.com/app/store\"\n)\n",
+		"api/api.go":  "package api\n\nimport \"example.com/app/store\"\n",
+		"store/db.go": "package store\n\nimport \"database/sql\"\n",
+	})
+	files := []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "api", "api.go"),
+		filepath.Join(root, "store", "db.go"),
+	}
+
+	coupling := couplingMetrics(buildImportGraph(LangGo, root, files), LangGo)
+	want := map[string]PackageCoupling{
+		".":     {Package: ".", Afferent: 0, Efferent: 2, Instability: 1, Language: LangGo},
+		"api":   {Package: "api", Afferent: 1, Efferent: 1, Instability: 0.5, Language: LangGo},
+		"store": {Package: "store", Afferent: 2, Efferent: 0, Instability: 0, Language: LangGo},
+	}
+	if len(coupling) != len(want) {
+		t.Fatalf("got %+v", coupling)
+	}
+	for _, pc := range coupling {
+		if pc != want[pc.Package] {
+			t.Errorf("%s = %+v, want %+v", pc.Package, pc, want[pc.Package])
+		}
+	}
+}
+
+func TestResolveImport(t *testing.T) {
+	dirs := importGraph{
+		".": nil, "src/components": nil, "src/utils": nil,
+		"pkg/models": nil, "src/main/java/com/acme/billing": nil,
+		"lib/my_app/accounts": nil,
+	}
+	tests := []struct {
+		from, imp, want string
+	}{
+		{"src/components", "../utils/format", "src/utils"}, // TS relative file
+		{"src/components", "./Button", "src/components"},   // same dir
+		{"pkg/models", "pkg.models.user", "pkg/models"},    // Python absolute
+		{"pkg/models", "..models", "pkg/models"},           // Python relative
+		{".", "com.acme.billing.Invoice", "src/main/java/com/acme/billing"},
+		{".", "java.util.List", ""},                    // external
+		{".", "MyApp.Accounts", "lib/my_app/accounts"}, // Elixir alias
+		{".", "react", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveImport(dirs, tt.from, tt.imp); got != tt.want {
+			t.Errorf("resolveImport(%q, %q) = %q, want %q", tt.from, tt.imp, got, tt.want)
+		}
+	}
+}
+
+func TestUnstableDependencies(t *testing.T) {
+	coupling := []PackageCoupling{
+		{Package: "leaf", Afferent: 5, Efferent: 0, Instability: 0},
+		{Package: "mid", Afferent: 1, Efferent: 3, Instability: 0.75},
+		{Package: "hub", Afferent: 4, Efferent: 6, Instability: 0.6},
+		{Package: "cmd", Afferent: 0, Efferent: 4, Instability: 1},
+	}
+	got := UnstableDependencies(coupling)
+	if len(got) != 2 || got[0].Package != "hub" || got[1].Package != "mid" {
+		t.Errorf("UnstableDependencies = %+v, want hub then mid", got)
+	}
+}
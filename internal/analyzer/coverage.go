@@ -2,23 +2,62 @@ package analyzer
 
 import (
 	"bufio"
+	"context"
+	"encoding/xml"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
 )
 
 // Coverage holds overall line-coverage parsed from a standard report.
 // Measured is false when no report was found, so scoring can exclude it
-// instead of assuming a value.
+// instead of assuming a value. Covered and Total are the underlying line
+// (or, for Go, statement) counts, kept so projects can be merged.
 type Coverage struct {
 	Percent  float64
 	Measured bool
+	Covered  int
+	Total    int
+}
+
+func newCoverage(covered, total int) Coverage {
+	if total == 0 {
+		return Coverage{}
+	}
+	return Coverage{
+		Percent:  float64(covered) / float64(total) * 100,
+		Measured: true,
+		Covered:  covered,
+		Total:    total,
+	}
+}
+
+// loadCoverage resolves coverage for root according to cfg: an explicit
+// report path wins, then a fresh `go test -coverprofile` run when enabled,
+// then whatever standard report already sits under root.
+func loadCoverage(root string, cfg config.CoverageConfig) Coverage {
+	if cfg.Path != "" {
+		path := cfg.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		return parseCoverageFile(path)
+	}
+	if cfg.Run {
+		if cov := runGoCoverage(root, time.Duration(cfg.Timeout)*time.Second); cov.Measured {
+			return cov
+		}
+	}
+	return readCoverage(root)
 }
 
 // readCoverage computes overall coverage from a report under root, preferring
-// lcov.info (cross-language) and falling back to Go's coverage.out.
-// ponytail: lcov + go coverage.out; add cobertura/clover if a user needs them.
+// lcov.info (cross-language), then Go's coverage.out, then Cobertura XML.
 func readCoverage(root string) Coverage {
 	if p := findFile(root, "lcov.info", "coverage/lcov.info"); p != "" {
 		return parseLcov(p)
@@ -26,9 +65,67 @@ func readCoverage(root string) Coverage {
 	if p := findFile(root, "coverage.out"); p != "" {
 		return parseGoCover(p)
 	}
+	if p := findFile(root, "coverage.xml", "coverage/cobertura-coverage.xml"); p != "" {
+		return parseCobertura(p)
+	}
 	return Coverage{}
 }
 
+// parseCoverageFile picks a parser from the report's name, defaulting to the
+// Go coverprofile format.
+func parseCoverageFile(path string) Coverage {
+	switch {
+	case strings.HasSuffix(path, ".xml"):
+		return parseCobertura(path)
+	case strings.HasSuffix(path, ".info") || strings.Contains(filepath.Base(path), "lcov"):
+		return parseLcov(path)
+	default:
+		return parseGoCover(path)
+	}
+}
+
+// runGoCoverage runs the module's tests with a coverage profile written to a
+// temporary file. Failing tests still leave a profile for the packages that
+// ran, so the exit status is ignored; only a missing profile is unmeasured.
+func runGoCoverage(root string, timeout time.Duration) Coverage {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		return Coverage{}
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	profile, err := os.CreateTemp("", "drift-cover-*.out")
+	if err != nil {
+		return Coverage{}
+	}
+	profile.Close()
+	defer os.Remove(profile.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "test", "-coverprofile="+profile.Name(), "./...")
+	cmd.Dir = root
+	if err := cmd.Run(); ctx.Err() != nil {
+		return Coverage{}
+	} else if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return Coverage{} // go toolchain missing
+	}
+	return parseGoCover(profile.Name())
+}
+
+// mergeCoverage combines per-project coverage by summing the raw counts.
+func mergeCoverage(covs ...Coverage) Coverage {
+	var covered, total int
+	for _, c := range covs {
+		if c.Measured {
+			covered += c.Covered
+			total += c.Total
+		}
+	}
+	return newCoverage(covered, total)
+}
+
 func findFile(root string, names ...string) string {
 	for _, name := range names {
 		p := filepath.Join(root, name)
@@ -63,10 +160,10 @@ func parseLcov(path string) Coverage {
 		}
 	}
 	// A failed read is reported as unmeasured rather than as partial coverage.
-	if err := sc.Err(); err != nil || found == 0 {
+	if err := sc.Err(); err != nil {
 		return Coverage{}
 	}
-	return Coverage{Percent: float64(hit) / float64(found) * 100, Measured: true}
+	return newCoverage(hit, found)
 }
 
 // parseGoCover sums statements from a `go test -coverprofile` report. Each
@@ -95,8 +192,27 @@ func parseGoCover(path string) Coverage {
 			covered += stmts
 		}
 	}
-	if err := sc.Err(); err != nil || total == 0 {
+	if err := sc.Err(); err != nil {
+		return Coverage{}
+	}
+	return newCoverage(covered, total)
+}
+
+// parseCobertura reads the totals on the root <coverage> element of a
+// Cobertura report, as written by coverage.py, Istanbul, and JaCoCo converters.
+func parseCobertura(path string) Coverage {
+	f, err := os.Open(path)
+	if err != nil {
+		return Coverage{}
+	}
+	defer f.Close()
+
+	var report struct {
+		LinesValid   int `xml:"lines-valid,attr"`
+		LinesCovered int `xml:"lines-covered,attr"`
+	}
+	if err := xml.NewDecoder(f).Decode(&report); err != nil {
 		return Coverage{}
 	}
-	return Coverage{Percent: float64(covered) / float64(total) * 100, Measured: true}
+	return newCoverage(report.LinesCovered, report.LinesValid)
 }
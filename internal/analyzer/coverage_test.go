@@ -2,8 +2,12 @@ package analyzer
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/testutil"
 )
 
 func TestReadCoverage(t *testing.T) {
@@ -51,3 +55,64 @@ func TestReadCoverage_GoCoverProfile(t *testing.T) {
 		t.Errorf("got %+v, want {Percent:40 Measured:true}", cov)
 	}
 }
+
+func TestReadCoverage_Cobertura(t *testing.T) {
+	dir := t.TempDir()
+	xml := `<?xml version="1.0" ?>
+<coverage line-rate="0.75" lines-covered="30" lines-valid="40" version="7.4">
+  <packages/>
+</coverage>`
+	if err := os.WriteFile(filepath.Join(dir, "coverage.xml"), []byte(xml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if cov := readCoverage(dir); !cov.Measured || cov.Percent != 75 {
+		t.Errorf("got %+v, want {Percent:75 Measured:true}", cov)
+	}
+}
+
+func TestLoadCoverage_ConfiguredPath(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteTree(t, dir, map[string]string{
+		"lcov.info":         "LF:10\nLH:10\n",
+		"reports/cover.out": "mode: set\nx/a.go:1.1,3.2 1 1\nx/b.go:5.1,6.2 3 0\n",
+	})
+
+	// The configured report wins over the auto-discovered lcov.info.
+	cov := loadCoverage(dir, config.CoverageConfig{Path: "reports/cover.out"})
+	if !cov.Measured || cov.Percent != 25 {
+		t.Errorf("got %+v, want {Percent:25 Measured:true}", cov)
+	}
+	if cov := loadCoverage(dir, config.CoverageConfig{Path: "missing.out"}); cov.Measured {
+		t.Errorf("missing configured report: got %+v, want unmeasured", cov)
+	}
+}
+
+func TestMergeCoverage(t *testing.T) {
+	got := mergeCoverage(newCoverage(1, 4), Coverage{}, newCoverage(5, 6))
+	if !got.Measured || got.Percent != 60 || got.Covered != 6 || got.Total != 10 {
+		t.Errorf("got %+v, want 6/10 = 60%%", got)
+	}
+	if mergeCoverage(Coverage{}).Measured {
+		t.Error("merging only unmeasured coverage should stay unmeasured")
+	}
+}
+
+func TestRunGoCoverage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	dir := t.TempDir()
+	testutil.WriteTree(t, dir, map[string]string{
+		"go.mod":      "module example.com/cov\n\ngo 1.21\n",
+		"cov.go":      "package cov\n\nfunc Half(x int) int {\n\tif x > 0 {\n\t\treturn 1\n\t}\n\treturn 0\n}\n",
+		"cov_test.go": "package cov\n\nimport \"testing\"\n\nfunc TestHalf(t *testing.T) {\n\tif Half(1) != 1 {\n\t\tt.Fatal()\n\t}\n}\n",
+	})
+
+	cov := loadCoverage(dir, config.CoverageConfig{Run: true, Timeout: 120})
+	if !cov.Measured || cov.Covered == 0 || cov.Covered >= cov.Total {
+		t.Errorf("got %+v, want partial measured coverage", cov)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -18,9 +19,7 @@ func (c *CSharpAnalyzer) Language() Language { return LangCSharp }
 func (c *CSharpAnalyzer) Extensions() []string { return []string{".cs"} }
 
 func (c *CSharpAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "bin", "obj", ".vs", "packages", "TestResults")
-	skip := []string{"Tests.cs", "Test.cs", ".Tests/", ".Test/"}
-	return walkFiles(root, exclude, c.Extensions(), skip)
+	return findLanguageFiles(c, root, exclude)
 }
 
 var csFuncPattern = regexp.MustCompile(
@@ -67,20 +66,20 @@ type csprojPackageRef struct {
 	Version string `xml:"Version,attr"`
 }
 
-func (c *CSharpAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (c *CSharpAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	// Find .csproj file
 	csprojFiles, err := filepath.Glob(filepath.Join(root, "*.csproj"))
 	if err != nil || len(csprojFiles) == 0 {
 		// Try one level deep (common in .NET solutions)
 		csprojFiles, err = filepath.Glob(filepath.Join(root, "*", "*.csproj"))
 		if err != nil || len(csprojFiles) == 0 {
-			return nil, fmt.Errorf("no .csproj file found")
+			return nil, errNoManifest
 		}
 	}
 
 	var results []DepStatus
 	for _, csprojPath := range csprojFiles {
-		deps, err := parseCsprojDeps(csprojPath)
+		deps, err := parseCsprojDeps(reg, csprojPath)
 		if err != nil {
 			continue
 		}
@@ -89,7 +88,7 @@ func (c *CSharpAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 	return results, nil
 }
 
-func parseCsprojDeps(path string) ([]DepStatus, error) {
+func parseCsprojDeps(reg *registryClient, path string) ([]DepStatus, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading .csproj: %w", err)
@@ -101,34 +100,34 @@ func parseCsprojDeps(path string) ([]DepStatus, error) {
 	}
 
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	for _, ig := range proj.ItemGroups {
 		for _, ref := range ig.PackageReferences {
 			if ref.Include == "" {
 				continue
 			}
 
-			dep := DepStatus{
+			results = append(results, DepStatus{
 				Module:         ref.Include,
 				CurrentVersion: ref.Version,
-			}
-
-			latest, err := fetchNuGetLatest(ref.Include)
-			if err != nil {
-				dep.Status = "unknown"
-				dep.LatestVersion = "?"
-			} else {
-				dep.LatestVersion = latest
-				if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
-					dep.Status = "current"
+			})
+			lookups = append(lookups, func(dep *DepStatus) {
+				latest, published, err := fetchNuGetLatest(reg, ref.Include)
+				if err != nil {
+					dep.Status = "unknown"
+					dep.LatestVersion = "?"
 				} else {
-					dep.StaleDays = 30
-					dep.Status = "stale"
+					dep.LatestVersion = latest
+					if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
+						dep.Status = "current"
+					} else {
+						markBehind(dep, published)
+					}
 				}
-			}
-
-			results = append(results, dep)
+			})
 		}
 	}
+	resolveLatest(reg, LangCSharp, results, lookups)
 	return results, nil
 }
 
@@ -136,17 +135,30 @@ type nugetIndexResponse struct {
 	Versions []string `json:"versions"`
 }
 
-func fetchNuGetLatest(name string) (string, error) {
+// nugetRegistrationLeaf is the registration entry for one package version.
+type nugetRegistrationLeaf struct {
+	Published time.Time `json:"published"`
+}
+
+func fetchNuGetLatest(reg *registryClient, name string) (string, time.Time, error) {
 	var resp nugetIndexResponse
-	url := fmt.Sprintf("https://api.nuget.org/v3-flatcontainer/%s/index.json", strings.ToLower(name))
-	if err := fetchJSON(url, &resp, ""); err != nil {
-		return "", err
+	id := strings.ToLower(name)
+	url := fmt.Sprintf("https://api.nuget.org/v3-flatcontainer/%s/index.json", id)
+	if err := reg.fetchJSON(url, &resp, ""); err != nil {
+		return "", time.Time{}, err
 	}
 	if len(resp.Versions) == 0 {
-		return "", fmt.Errorf("no versions found on NuGet")
+		return "", time.Time{}, fmt.Errorf("no versions found on NuGet")
 	}
 	// Return last version (newest)
-	return resp.Versions[len(resp.Versions)-1], nil
+	latest := resp.Versions[len(resp.Versions)-1]
+
+	// The flat container has no dates; the registration leaf does. A failed
+	// lookup leaves the date unknown rather than failing the dependency.
+	var leaf nugetRegistrationLeaf
+	leafURL := fmt.Sprintf("https://api.nuget.org/v3/registration5-semver1/%s/%s.json", id, strings.ToLower(latest))
+	reg.fetchJSON(leafURL, &leaf, "")
+	return latest, leaf.Published, nil
 }
 
 var csImportPatterns = []*regexp.Regexp{
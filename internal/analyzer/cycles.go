@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+)
+
+// ImportCycle is a loop in the internal import graph: each package imports
+// the next, and the last imports the first again.
+type ImportCycle struct {
+	Packages []string // directories relative to the analysis root
+	Language Language
+}
+
+// String renders the cycle as a closed path, e.g. "a → b → a".
+func (c ImportCycle) String() string {
+	if len(c.Packages) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, c.Packages...), c.Packages[0]), " → ")
+}
+
+// findImportCycles reports one cycle per strongly connected component of the
+// graph with more than one package. Components are found with Tarjan's
+// algorithm; the reported path is the shortest loop through the
+// component's alphabetically first package, so output is stable.
+func findImportCycles(graph importGraph, lang Language) []ImportCycle {
+	var cycles []ImportCycle
+	for _, comp := range stronglyConnected(graph) {
+		if len(comp) < 2 {
+			continue
+		}
+		sort.Strings(comp)
+		if path := shortestLoop(graph, comp[0], comp); len(path) > 1 {
+			cycles = append(cycles, ImportCycle{Packages: path, Language: lang})
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Packages[0] < cycles[j].Packages[0] })
+	return cycles
+}
+
+func stronglyConnected(graph importGraph) [][]string {
+	nodes := make([]string, 0, len(graph))
+	for n := range graph {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var comps [][]string
+	next := 0
+
+	var visit func(n string)
+	visit = func(n string) {
+		index[n], low[n] = next, next
+		next++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, m := range sortedKeys(graph[n]) {
+			if _, seen := index[m]; !seen {
+				visit(m)
+				low[n] = min(low[n], low[m])
+			} else if onStack[m] {
+				low[n] = min(low[n], index[m])
+			}
+		}
+
+		if low[n] == index[n] {
+			var comp []string
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				comp = append(comp, m)
+				if m == n {
+					break
+				}
+			}
+			comps = append(comps, comp)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := index[n]; !seen {
+			visit(n)
+		}
+	}
+	return comps
+}
+
+// shortestLoop finds the shortest path from start back to itself that stays
+// within comp, using a breadth-first search.
+func shortestLoop(graph importGraph, start string, comp []string) []string {
+	inComp := make(map[string]bool, len(comp))
+	for _, n := range comp {
+		inComp[n] = true
+	}
+
+	prev := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, m := range sortedKeys(graph[n]) {
+			if !inComp[m] {
+				continue
+			}
+			if m == start {
+				path := []string{n}
+				for path[0] != start {
+					path = append([]string{prev[path[0]]}, path...)
+				}
+				return path
+			}
+			if _, seen := prev[m]; !seen {
+				prev[m] = n
+				queue = append(queue, m)
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import "testing"
+
+func TestFindImportCycles(t *testing.T) {
+	graph := importGraph{
+		"a":    {"b": true},
+		"b":    {"c": true, "leaf": true},
+		"c":    {"a": true, "b": true},
+		"x":    {"y": true},
+		"y":    {"x": true},
+		"leaf": {},
+	}
+	cycles := findImportCycles(graph, LangPython)
+	if len(cycles) != 2 {
+		t.Fatalf("got %d cycles, want 2: %+v", len(cycles), cycles)
+	}
+	if got := cycles[0].String(); got != "a → b → c → a" {
+		t.Errorf("cycle[0] = %q", got)
+	}
+	if got := cycles[1].String(); got != "x → y → x" {
+		t.Errorf("cycle[1] = %q", got)
+	}
+}
+
+func TestFindImportCycles_Acyclic(t *testing.T) {
+	graph := importGraph{"a": {"b": true}, "b": {"c": true}, "c": {}}
+	if cycles := findImportCycles(graph, LangTypeScript); len(cycles) != 0 {
+		t.Errorf("got %+v, want no cycles", cycles)
+	}
+}
@@ -4,39 +4,90 @@ import (
 	"go/ast"
 	"go/token"
 	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 )
 
+// DeadFunction is an exported declaration nothing refers to. Despite the
+// name it covers more than functions for Go, see Kind.
 type DeadFunction struct {
 	File string
+	Path string // File relative to the analysis root
 	Name string
 	Line int
+	Kind string // "function", "method", "type", "const", or "var"
+}
+
+// DeadCodeKinds lists the kinds of DeadFunction in display order.
+var DeadCodeKinds = []string{"function", "method", "type", "const", "var"}
+
+// DeadCodeByKind groups dead declarations by Kind, each group ordered by
+// file and line.
+func DeadCodeByKind(dead []DeadFunction) map[string][]DeadFunction {
+	groups := make(map[string][]DeadFunction)
+	for _, d := range dead {
+		groups[d.Kind] = append(groups[d.Kind], d)
+	}
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool {
+			if g[i].File != g[j].File {
+				return g[i].File < g[j].File
+			}
+			return g[i].Line < g[j].Line
+		})
+	}
+	return groups
 }
 
 func analyzeDeadCode(fset *token.FileSet, files []*ast.File) []DeadFunction {
 	type funcInfo struct {
 		file string
+		path string
 		name string
 		line int
+		kind string
 	}
 
 	exported := make(map[string]funcInfo)
 	called := make(map[string]bool)
+	// Package-level types, consts, and vars count as used when their name
+	// appears anywhere besides the declaration.
+	declared := make(map[string]DeadFunction)
+	mentions := make(map[string]int)
 
 	for _, f := range files {
 		pos := fset.Position(f.Pos())
 		relPath := filepath.Base(pos.Filename)
 
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				for _, id := range specNames(spec) {
+					if isExported(id.Name) {
+						declared[id.Name] = DeadFunction{
+							File: relPath,
+							Path: pos.Filename,
+							Name: id.Name,
+							Line: fset.Position(id.Pos()).Line,
+							Kind: gd.Tok.String(),
+						}
+					}
+				}
+			}
+		}
+
 		ast.Inspect(f, func(n ast.Node) bool {
 			switch node := n.(type) {
+			case *ast.Ident:
+				mentions[node.Name]++
 			case *ast.FuncDecl:
 				name := node.Name.Name
 
-				if name == "main" || name == "init" ||
-					strings.HasPrefix(name, "Test") ||
-					strings.HasPrefix(name, "Benchmark") ||
-					strings.HasPrefix(name, "Example") {
+				if isEntryPoint(name) {
 					return true
 				}
 
@@ -49,8 +100,14 @@ func analyzeDeadCode(fset *token.FileSet, files []*ast.File) []DeadFunction {
 					key = receiverName(node.Recv.List[0].Type) + "." + name
 				}
 
+				kind := "function"
+				if node.Recv != nil {
+					kind = "method"
+				}
 				exported[key] = funcInfo{
+					kind: kind,
 					file: relPath,
+					path: pos.Filename,
 					name: key,
 					line: fset.Position(node.Pos()).Line,
 				}
@@ -78,16 +135,70 @@ func analyzeDeadCode(fset *token.FileSet, files []*ast.File) []DeadFunction {
 			if !called[simpleName] {
 				dead = append(dead, DeadFunction{
 					File: info.file,
+					Path: info.path,
 					Name: info.name,
 					Line: info.line,
+					Kind: info.kind,
 				})
 			}
 		}
 	}
+	for name, d := range declared {
+		if mentions[name] <= 1 {
+			dead = append(dead, d)
+		}
+	}
 
 	return dead
 }
 
+// countExported counts the functions in funcs that are part of their
+// package's API. Visibility is read from the name where the language puts it
+// there (Go's capital letter, Python's leading underscore); in the others
+// every function counts.
+func countExported(lang Language, funcs []FunctionComplexity) int {
+	n := 0
+	for _, fc := range funcs {
+		name := fc.Name
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		switch lang {
+		case LangGo:
+			if isExported(name) {
+				n++
+			}
+		case LangPython:
+			if !strings.HasPrefix(name, "_") {
+				n++
+			}
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// specNames returns the identifiers a type, const, or var spec declares.
+func specNames(spec ast.Spec) []*ast.Ident {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return []*ast.Ident{s.Name}
+	case *ast.ValueSpec:
+		return s.Names
+	}
+	return nil
+}
+
+// isEntryPoint reports whether name is invoked by the toolchain rather than by
+// project code.
+func isEntryPoint(name string) bool {
+	return name == "main" || name == "init" ||
+		strings.HasPrefix(name, "Test") ||
+		strings.HasPrefix(name, "Benchmark") ||
+		strings.HasPrefix(name, "Example")
+}
+
 func isExported(name string) bool {
 	if len(name) == 0 {
 		return false
@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DebtMarker is a TODO, FIXME, HACK, or XXX comment left in the source.
+type DebtMarker struct {
+	Path     string // relative to the analysis root
+	Line     int
+	Kind     string // "TODO", "FIXME", "HACK", or "XXX"
+	Text     string // the rest of the comment after the marker
+	Language Language
+}
+
+// Weight reflects how urgent the marker usually is: FIXME, HACK, and XXX flag
+// known problems, while TODO often just records future work.
+func (d DebtMarker) Weight() int {
+	if d.Kind == "TODO" {
+		return 1
+	}
+	return 2
+}
+
+// debtMarkerPattern only matches a marker that opens the comment, as in
+// "// TODO: ..." or "# FIXME(alice) ...", so prose that merely mentions
+// the words is ignored.
+var debtMarkerPattern = regexp.MustCompile(`^(?://+|#+|/\*+|\*+|--|<!--)?\s*(TODO|FIXME|HACK|XXX)(?:\([^)]*\))?(?::|\s|$)\s*(.*)`)
+
+// trailingComment matches the start of a comment after code on the same
+// line. "//" preceded by ":" is skipped so URLs in strings don't count.
+var trailingComment = regexp.MustCompile(`(?:^|[^:])(//|\s#\s)`)
+
+// scanDebt records comment and code line counts on each file's metrics and
+// returns the debt markers found. files and metrics must be index-aligned, as
+// returned by analyzeFiles.
+func scanDebt(lang Language, files []string, metrics []FileMetrics) []DebtMarker {
+	var markers []DebtMarker
+	for i, path := range files {
+		comments, code, found := scanComments(path)
+		metrics[i].CommentLines = comments
+		metrics[i].CodeLines = code
+		for _, m := range found {
+			m.Path = metrics[i].Path
+			m.Language = lang
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+// scanComments classifies each non-blank line as comment or code. Whole-line
+// comments use the prefixes shared by the supported languages, plus /* */
+// blocks; markers are also picked up from trailing comments.
+func scanComments(path string) (comments, code int, markers []DebtMarker) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, nil
+	}
+	defer f.Close()
+
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		comment := ""
+		switch {
+		case inBlock:
+			comment = line
+			if strings.Contains(line, "*/") {
+				inBlock = false
+			}
+		case isCommentLine(line):
+			comment = line
+			if strings.HasPrefix(line, "/*") && !strings.Contains(line, "*/") {
+				inBlock = true
+			}
+		default:
+			code++
+			if loc := trailingComment.FindStringSubmatchIndex(line); loc != nil {
+				markers = appendMarker(markers, line[loc[2]:], lineNum)
+			}
+			continue
+		}
+		comments++
+		markers = appendMarker(markers, comment, lineNum)
+	}
+	return comments, code, markers
+}
+
+func appendMarker(markers []DebtMarker, comment string, line int) []DebtMarker {
+	m := debtMarkerPattern.FindStringSubmatch(strings.TrimSpace(comment))
+	if m == nil {
+		return markers
+	}
+	text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[2]), "*/"))
+	return append(markers, DebtMarker{Line: line, Kind: m[1], Text: text})
+}
+
+// CommentRatio returns comment lines per line of code across all files.
+func (r *Results) CommentRatio() float64 {
+	comments, code := 0, 0
+	for _, f := range r.Files {
+		comments += f.CommentLines
+		code += f.CodeLines
+	}
+	if code == 0 {
+		return 0
+	}
+	return float64(comments) / float64(code)
+}
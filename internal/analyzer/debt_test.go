@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestScanDebt(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"a.go": `package a
+
+// TODO: split this up
+func A() {
+	url := "https://example.com" // FIXME(bob) hardcoded
+	_ = url
+	/*
+	   HACK around a stdlib bug */
+}
+
+// Mentions TODO/FIXME in prose, which is not a marker.
+func B() {}
+`,
+		"b.py": "x = 1  # XXX magic number\n# plain comment\n",
+	})
+
+	files := []string{filepath.Join(root, "a.go"), filepath.Join(root, "b.py")}
+	metrics := []FileMetrics{{Path: "a.go"}, {Path: "b.py"}}
+	markers := scanDebt(LangGo, files, metrics)
+
+	want := []DebtMarker{
+		{Path: "a.go", Line: 3, Kind: "TODO", Text: "split this up"},
+		{Path: "a.go", Line: 5, Kind: "FIXME", Text: "hardcoded"},
+		{Path: "a.go", Line: 8, Kind: "HACK", Text: "around a stdlib bug"},
+		{Path: "b.py", Line: 1, Kind: "XXX", Text: "magic number"},
+	}
+	if len(markers) != len(want) {
+		t.Fatalf("got %d markers, want %d: %+v", len(markers), len(want), markers)
+	}
+	for i, w := range want {
+		w.Language = LangGo
+		if markers[i] != w {
+			t.Errorf("marker %d = %+v, want %+v", i, markers[i], w)
+		}
+	}
+
+	if metrics[0].CommentLines != 4 || metrics[0].CodeLines != 6 {
+		t.Errorf("a.go comments/code = %d/%d, want 4/6", metrics[0].CommentLines, metrics[0].CodeLines)
+	}
+	if metrics[1].CommentLines != 1 || metrics[1].CodeLines != 1 {
+		t.Errorf("b.py comments/code = %d/%d, want 1/1", metrics[1].CommentLines, metrics[1].CodeLines)
+	}
+
+	r := &Results{Files: metrics}
+	if got := r.CommentRatio(); got != 5.0/7.0 {
+		t.Errorf("CommentRatio = %v, want %v", got, 5.0/7.0)
+	}
+}
@@ -1,15 +1,16 @@
 package analyzer
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
 )
 
 type DepStatus struct {
@@ -18,9 +19,101 @@ type DepStatus struct {
 	LatestVersion  string
 	StaleDays      int
 	Status         string // "current", "stale", "outdated"
+	Language       Language
+	Scope          string // "" for runtime dependencies, "dev" for development and test-only ones
+
+	// Path is the full package identifier (a Go module path, a Swift
+	// package URL) when Module is shortened for display.
+	Path string
+	// License is the dependency's SPDX license expression. It is only
+	// looked up when a license deny list is configured.
+	License string
+
+	// Behind classifies how far CurrentVersion trails LatestVersion:
+	// "major", "minor", or "patch". It is empty when the dependency is
+	// current or either version isn't semver-like.
+	Behind       string
+	MajorsBehind int
+
+	// Transitive counts the packages this direct dependency pulls in, and
+	// Depth how many levels deep they go. Both are zero when the language
+	// has no readable dependency graph (only Go and npm do).
+	Transitive int
+	Depth      int
 }
 
-func analyzeDeps(root string) ([]DepStatus, error) {
+// PackageName is the identifier registries know the dependency by: the full
+// path when Module is a shortened display name.
+func (d DepStatus) PackageName() string {
+	if d.Path != "" {
+		return d.Path
+	}
+	return d.Module
+}
+
+// BehindLabel is a short badge such as "2 majors behind", or "" when the
+// gap is unknown.
+func (d DepStatus) BehindLabel() string {
+	switch {
+	case d.Behind == "":
+		return ""
+	case d.MajorsBehind == 1:
+		return "1 major behind"
+	case d.MajorsBehind > 1:
+		return fmt.Sprintf("%d majors behind", d.MajorsBehind)
+	}
+	return d.Behind + " behind"
+}
+
+// versionGap compares two versions with semver ordering and reports the most
+// significant component in which current trails latest, plus how many major
+// versions apart they are. Loose versions are normalized first ("1.2" is
+// 1.2.0, "7.1.3.2" is 7.1.3); anything else yields an empty gap.
+func versionGap(current, latest string) (behind string, majors int) {
+	cur, lat := normalizeSemver(current), normalizeSemver(latest)
+	if cur == "" || lat == "" || semver.Compare(cur, lat) >= 0 {
+		return "", 0
+	}
+	curMajor, _ := strconv.Atoi(strings.TrimPrefix(semver.Major(cur), "v"))
+	latMajor, _ := strconv.Atoi(strings.TrimPrefix(semver.Major(lat), "v"))
+	switch {
+	case latMajor > curMajor:
+		return "major", latMajor - curMajor
+	case semver.MajorMinor(cur) != semver.MajorMinor(lat):
+		return "minor", 0
+	}
+	return "patch", 0
+}
+
+// normalizeSemver turns registry and manifest versions into the "vX.Y.Z"
+// form golang.org/x/mod/semver expects, or "" if that's not possible.
+func normalizeSemver(v string) string {
+	v = strings.TrimLeft(strings.TrimSpace(v), "^~>=<! v")
+	pre := ""
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v, pre = v[:i], v[i:]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	for _, p := range parts {
+		if _, err := strconv.Atoi(p); err != nil {
+			return ""
+		}
+	}
+	out := "v" + strings.Join(parts, ".") + pre
+	if !semver.IsValid(out) {
+		return ""
+	}
+	return out
+}
+
+// errNoManifest is returned by AnalyzeDeps when a project has none of the
+// files its language declares dependencies in.
+var errNoManifest = errors.New("no dependency manifest found")
+
+func analyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	gomodPath := filepath.Join(root, "go.mod")
 	data, err := os.ReadFile(gomodPath)
 	if err != nil {
@@ -33,43 +126,54 @@ func analyzeDeps(root string) ([]DepStatus, error) {
 	}
 
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 
 	for _, req := range f.Require {
 		if req.Indirect {
 			continue
 		}
 
-		dep := DepStatus{
+		results = append(results, DepStatus{
 			Module:         shortModuleName(req.Mod.Path),
+			Path:           req.Mod.Path,
 			CurrentVersion: req.Mod.Version,
-		}
-
-		latest, latestTime, err := fetchLatestVersion(req.Mod.Path)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			if dep.CurrentVersion == latest {
-				dep.Status = "current"
-				dep.StaleDays = 0
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, latestTime, err := fetchLatestVersion(reg, req.Mod.Path)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
 			} else {
-				staleDays := int(time.Since(latestTime).Hours() / 24)
-				dep.StaleDays = staleDays
-				if staleDays > 90 {
-					dep.Status = "outdated"
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest {
+					dep.Status = "current"
+					dep.StaleDays = 0
 				} else {
-					dep.Status = "stale"
+					markBehind(dep, latestTime)
 				}
 			}
-		}
-
-		results = append(results, dep)
+		})
 	}
 
+	resolveLatest(reg, LangGo, results, lookups)
 	return results, nil
 }
 
+// markBehind records that dep is not on the latest version. Stale days count
+// from the latest release, as that is how long an upgrade has been
+// available; when the registry gives no date, 30 days is assumed.
+func markBehind(dep *DepStatus, published time.Time) {
+	dep.StaleDays = 30
+	if !published.IsZero() {
+		dep.StaleDays = int(time.Since(published).Hours() / 24)
+	}
+	if dep.StaleDays > 90 {
+		dep.Status = "outdated"
+	} else {
+		dep.Status = "stale"
+	}
+}
+
 func shortModuleName(mod string) string {
 	parts := strings.Split(mod, "/")
 	if len(parts) <= 1 {
@@ -83,22 +187,11 @@ type proxyInfo struct {
 	Time    time.Time `json:"Time"`
 }
 
-func fetchLatestVersion(module string) (string, time.Time, error) {
+func fetchLatestVersion(reg *registryClient, module string) (string, time.Time, error) {
 	url := fmt.Sprintf("https://proxy.golang.org/%s/@latest", module)
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", time.Time{}, fmt.Errorf("proxy returned %d", resp.StatusCode)
-	}
-
 	var info proxyInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+	if err := reg.fetchJSON(url, &info, ""); err != nil {
 		return "", time.Time{}, err
 	}
 
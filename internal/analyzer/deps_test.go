@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+// stubRegistry returns a client that routes every registry request to
+// handler.
+func stubRegistry(t *testing.T, handler http.HandlerFunc) *registryClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cache := newRegistryCache("")
+	cache.http.Transport = rewriteHost{target: srv.Listener.Addr().String()}
+	return &registryClient{cache: cache}
+}
+
+type rewriteHost struct{ target string }
+
+func (r rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = r.target
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFetchLatest_ReleaseDates(t *testing.T) {
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/requests/json":
+			w.Write([]byte(`{"info":{"version":"2.32.3"},"urls":[{"upload_time_iso_8601":"2024-05-29T15:37:47.613Z"}]}`))
+		case "/api/v1/gems/rails.json":
+			w.Write([]byte(`{"version":"7.1.3","version_created_at":"2024-01-16T22:52:26.234Z"}`))
+		case "/api/v1/crates/serde":
+			w.Write([]byte(`{"crate":{"max_stable_version":"1.0.200"},"versions":[{"num":"1.0.201-rc","created_at":"2024-05-02T00:00:00Z"},{"num":"1.0.200","created_at":"2024-05-01T00:00:00Z"}]}`))
+		case "/solrsearch/select":
+			w.Write([]byte(`{"response":{"docs":[{"latestVersion":"33.2.0-jre","timestamp":1714521600000}]}}`))
+		case "/p2/monolog/monolog.json":
+			w.Write([]byte(`{"packages":{"monolog/monolog":[{"version":"3.6.0","time":"2024-04-12T21:02:21+00:00"}]}}`))
+		case "/v3-flatcontainer/newtonsoft.json/index.json":
+			w.Write([]byte(`{"versions":["13.0.2","13.0.3"]}`))
+		case "/v3/registration5-semver1/newtonsoft.json/13.0.3.json":
+			w.Write([]byte(`{"published":"2023-03-08T07:42:54.647+00:00"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	tests := []struct {
+		name    string
+		fetch   func() (string, time.Time, error)
+		version string
+		date    time.Time
+	}{
+		{"pypi", func() (string, time.Time, error) { return fetchPyPILatest(reg, "requests") }, "2.32.3", day("2024-05-29")},
+		{"rubygems", func() (string, time.Time, error) { return fetchRubyGemsLatest(reg, "rails") }, "7.1.3", day("2024-01-16")},
+		{"crates.io", func() (string, time.Time, error) { return fetchCratesIOLatest(reg, "serde") }, "1.0.200", day("2024-05-01")},
+		{"maven", func() (string, time.Time, error) { return fetchMavenLatest(reg, "com.google.guava", "guava") }, "33.2.0-jre", day("2024-05-01")},
+		{"packagist", func() (string, time.Time, error) { return fetchPackagistLatest(reg, "monolog/monolog") }, "3.6.0", day("2024-04-12")},
+		{"nuget", func() (string, time.Time, error) { return fetchNuGetLatest(reg, "Newtonsoft.Json") }, "13.0.3", day("2023-03-08")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, published, err := tt.fetch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != tt.version {
+				t.Errorf("version = %q, want %q", version, tt.version)
+			}
+			if got := published.UTC().Truncate(24 * time.Hour); !got.Equal(tt.date) {
+				t.Errorf("published = %v, want %v", published, tt.date)
+			}
+		})
+	}
+}
+
+func TestMarkBehind(t *testing.T) {
+	tests := []struct {
+		published time.Time
+		days      int
+		status    string
+	}{
+		{time.Now().Add(-10 * 24 * time.Hour), 10, "stale"},
+		{time.Now().Add(-200 * 24 * time.Hour), 200, "outdated"},
+		{time.Time{}, 30, "stale"}, // no date from the registry
+	}
+	for _, tt := range tests {
+		var dep DepStatus
+		markBehind(&dep, tt.published)
+		if dep.StaleDays != tt.days || dep.Status != tt.status {
+			t.Errorf("markBehind(%v) = %d days %s, want %d days %s", tt.published, dep.StaleDays, dep.Status, tt.days, tt.status)
+		}
+	}
+}
+
+func TestVersionGap(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		behind          string
+		majors          int
+		label           string
+	}{
+		{"v1.2.3", "v1.2.4", "patch", 0, "patch behind"},
+		{"1.2.3", "1.4.0", "minor", 0, "minor behind"},
+		{"^1.2.3", "2.0.0", "major", 1, "1 major behind"},
+		{"~2.1", "5.0.1", "major", 3, "3 majors behind"},
+		{"7.1.3.2", "7.1.4", "patch", 0, "patch behind"},
+		{"1.0.0-rc1", "1.0.0", "patch", 0, "patch behind"},
+		{"1.2.3", "1.2.3", "", 0, ""},
+		{"2.0.0", "1.9.9", "", 0, ""},      // ahead of the registry
+		{"latest", "1.0.0", "", 0, ""},     // not a version
+		{"1.2.3", "2024-01-01", "", 0, ""}, // date-based tag
+	}
+	for _, tt := range tests {
+		behind, majors := versionGap(tt.current, tt.latest)
+		if behind != tt.behind || majors != tt.majors {
+			t.Errorf("versionGap(%q, %q) = %q, %d; want %q, %d", tt.current, tt.latest, behind, majors, tt.behind, tt.majors)
+		}
+		dep := DepStatus{Behind: behind, MajorsBehind: majors}
+		if got := dep.BehindLabel(); got != tt.label {
+			t.Errorf("BehindLabel(%q, %q) = %q, want %q", tt.current, tt.latest, got, tt.label)
+		}
+	}
+}
+
+func TestAnalyzeDeps_Scope(t *testing.T) {
+	reg := stubRegistry(t, http.NotFound)
+
+	tests := []struct {
+		lang  LanguageAnalyzer
+		files map[string]string
+		want  map[string]string // module -> scope
+	}{
+		{&TypeScriptAnalyzer{}, map[string]string{
+			"package.json": `{"dependencies":{"react":"^18.0.0"},"devDependencies":{"jest":"^29.0.0"}}`,
+		}, map[string]string{"react": "", "jest": "dev"}},
+		{&RustAnalyzer{}, map[string]string{
+			"Cargo.toml": "[dependencies]\nserde = \"1.0\"\n\n[dev-dependencies]\ncriterion = \"0.5\"\n",
+		}, map[string]string{"serde": "", "criterion": "dev"}},
+		{&PHPAnalyzer{}, map[string]string{
+			"composer.json": `{"require":{"php":">=8.1","monolog/monolog":"^3.0"},"require-dev":{"phpunit/phpunit":"^10.0"}}`,
+		}, map[string]string{"monolog/monolog": "", "phpunit/phpunit": "dev"}},
+		{&RubyAnalyzer{}, map[string]string{
+			"Gemfile": "source 'https://rubygems.org'\ngem 'rails', '~> 7.1'\ngem 'rubocop', require: false, group: :development\n" +
+				"group :development, :test do\n  gem 'rspec', '3.12.0'\nend\ngroup :default, :test do\n  gem 'json'\nend\n",
+		}, map[string]string{"rails": "", "rubocop": "dev", "rspec": "dev", "json": ""}},
+		{&JavaAnalyzer{}, map[string]string{
+			"pom.xml": `<project><dependencies>
+				<dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.9</version></dependency>
+				<dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13.2</version><scope>test</scope></dependency>
+			</dependencies></project>`,
+		}, map[string]string{"org.slf4j:slf4j-api": "", "junit:junit": "dev"}},
+		{&JavaAnalyzer{}, map[string]string{
+			"build.gradle": "dependencies {\n  implementation 'com.google.guava:guava:32.1.3-jre'\n  testImplementation(\"org.junit.jupiter:junit-jupiter:5.10.0\")\n}\n",
+		}, map[string]string{"com.google.guava:guava": "", "org.junit.jupiter:junit-jupiter": "dev"}},
+		{&ElixirAnalyzer{}, map[string]string{
+			"mix.exs": "defp deps do\n  [\n    {:phoenix, \"~> 1.7\"},\n    {:credo, \"~> 1.7\", only: [:dev, :test], runtime: false},\n" +
+				"    {:telemetry, \"~> 1.2\", only: [:dev, :prod]}\n  ]\nend\n",
+		}, map[string]string{"phoenix": "", "credo": "dev", "telemetry": ""}},
+		{&PythonAnalyzer{}, map[string]string{
+			"pyproject.toml": "[tool.poetry.dependencies]\npython = \"^3.11\"\nrequests = \"^2.31\"\n\n" +
+				"[tool.poetry.group.dev.dependencies]\npytest = { version = \"^7.4\" }\n",
+		}, map[string]string{"requests": "", "pytest": "dev"}},
+		{&PythonAnalyzer{}, map[string]string{
+			"requirements.txt":     "flask==3.0.0\n",
+			"requirements-dev.txt": "black==23.11.0\n",
+		}, map[string]string{"flask": "", "black": "dev"}},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		testutil.WriteTree(t, root, tt.files)
+		deps, err := tt.lang.AnalyzeDeps(reg, root)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.lang.Language(), err)
+		}
+		got := make(map[string]string)
+		for _, d := range deps {
+			got[d.Module] = d.Scope
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: deps %v, want %v", tt.lang.Language(), got, tt.want)
+			continue
+		}
+		for module, scope := range tt.want {
+			if s, ok := got[module]; !ok || s != scope {
+				t.Errorf("%s: %s scope = %q (found %v), want %q", tt.lang.Language(), module, s, ok, scope)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// deps.dev (https://deps.dev) indexes npm, PyPI, crates.io, Maven Central,
+// RubyGems, NuGet, and Go modules behind one API. Licenses always come from
+// it; with `deps.source: deps.dev`, latest versions, release dates, and
+// advisories for those ecosystems do too, in place of each registry's own
+// API and OSV.dev.
+
+const (
+	depsDevPackageURL  = "https://api.deps.dev/v3/systems/%s/packages/%s"
+	depsDevVersionURL  = "https://api.deps.dev/v3/systems/%s/packages/%s/versions/%s"
+	depsDevAdvisoryURL = "https://api.deps.dev/v3/advisories/%s"
+)
+
+// depsDevSystems maps languages to their deps.dev package system. Packagist
+// and Hex publish licenses in the metadata drift already fetches; Swift
+// packages have no registry to ask.
+var depsDevSystems = map[Language]string{
+	LangGo:         "go",
+	LangTypeScript: "npm",
+	LangPython:     "pypi",
+	LangRust:       "cargo",
+	LangJava:       "maven",
+	LangRuby:       "rubygems",
+	LangCSharp:     "nuget",
+}
+
+type depsDevPackage struct {
+	Versions []struct {
+		VersionKey struct {
+			Version string `json:"version"`
+		} `json:"versionKey"`
+		PublishedAt time.Time `json:"publishedAt"`
+		IsDefault   bool      `json:"isDefault"`
+	} `json:"versions"`
+}
+
+type depsDevVersion struct {
+	Licenses     []string `json:"licenses"`
+	AdvisoryKeys []struct {
+		ID string `json:"id"`
+	} `json:"advisoryKeys"`
+}
+
+type depsDevAdvisory struct {
+	Title       string   `json:"title"`
+	Aliases     []string `json:"aliases"`
+	CVSS3Vector string   `json:"cvss3Vector"`
+}
+
+// usesDepsDev reports whether lang's freshness and advisory data come from
+// deps.dev in this run.
+func usesDepsDev(reg *registryClient, lang Language) bool {
+	_, ok := depsDevSystems[lang]
+	return ok && reg.depsDev
+}
+
+// resolveLatest fills in the latest version and status of lang's
+// dependencies with their registry lookups, or from deps.dev when that is
+// the configured source.
+func resolveLatest(reg *registryClient, lang Language, deps []DepStatus, lookups []func(*DepStatus)) {
+	if usesDepsDev(reg, lang) {
+		for i := range lookups {
+			lookups[i] = func(dep *DepStatus) { fetchDepsDevLatest(reg, lang, dep) }
+		}
+	}
+	resolveDeps(deps, lookups)
+}
+
+// fetchDepsDevLatest compares dep against the version deps.dev marks as the
+// package's default, which is the registry's latest release.
+func fetchDepsDevLatest(reg *registryClient, lang Language, dep *DepStatus) {
+	var pkg depsDevPackage
+	u := fmt.Sprintf(depsDevPackageURL, depsDevSystems[lang], url.QueryEscape(depsDevName(lang, *dep)))
+	if err := reg.fetchJSON(u, &pkg, ""); err != nil {
+		dep.Status = "unknown"
+		dep.LatestVersion = "?"
+		return
+	}
+	for _, v := range pkg.Versions {
+		if !v.IsDefault {
+			continue
+		}
+		dep.LatestVersion = v.VersionKey.Version
+		if dep.CurrentVersion == dep.LatestVersion {
+			dep.Status = "current"
+			dep.StaleDays = 0
+		} else {
+			markBehind(dep, v.PublishedAt)
+		}
+		return
+	}
+	dep.Status = "unknown"
+	dep.LatestVersion = "?"
+}
+
+// fetchDepsDevVersion looks up the version of dep in use, reporting false
+// when deps.dev doesn't cover its language, its version isn't exact, or the
+// lookup fails.
+func fetchDepsDevVersion(reg *registryClient, dep DepStatus) (depsDevVersion, bool) {
+	system, ok := depsDevSystems[dep.Language]
+	version := exactVersion(dep.CurrentVersion)
+	if !ok || version == "" {
+		return depsDevVersion{}, false
+	}
+	if dep.Language == LangGo {
+		version = "v" + version
+	}
+	var resp depsDevVersion
+	u := fmt.Sprintf(depsDevVersionURL, system, url.QueryEscape(depsDevName(dep.Language, dep)), url.QueryEscape(version))
+	if err := reg.fetchJSON(u, &resp, ""); err != nil {
+		return depsDevVersion{}, false
+	}
+	return resp, true
+}
+
+// depsDevName is dep's package name as deps.dev spells it; PyPI names are
+// normalized.
+func depsDevName(lang Language, dep DepStatus) string {
+	if lang == LangPython {
+		return normalizePyName(dep.PackageName())
+	}
+	return dep.PackageName()
+}
+
+// depsDevAdvisoryIDs returns the advisories deps.dev lists for the version
+// of each dependency, in order.
+func depsDevAdvisoryIDs(reg *registryClient, deps []DepStatus) [][]string {
+	ids := make([][]string, len(deps))
+	lookups := make([]func(*DepStatus), len(deps))
+	for i := range deps {
+		lookups[i] = func(dep *DepStatus) {
+			v, ok := fetchDepsDevVersion(reg, *dep)
+			if !ok {
+				return
+			}
+			for _, key := range v.AdvisoryKeys {
+				ids[i] = append(ids[i], key.ID)
+			}
+		}
+	}
+	resolveDeps(deps, lookups)
+	return ids
+}
+
+// fetchDepsDevAdvisory loads an advisory from deps.dev in the shape OSV
+// returns it, so both sources share summary and severity handling.
+func fetchDepsDevAdvisory(reg *registryClient, id string) (osvVuln, error) {
+	var adv depsDevAdvisory
+	if err := reg.fetchJSON(fmt.Sprintf(depsDevAdvisoryURL, url.PathEscape(id)), &adv, ""); err != nil {
+		return osvVuln{}, err
+	}
+	v := osvVuln{ID: id, Summary: adv.Title, Aliases: adv.Aliases}
+	if adv.CVSS3Vector != "" {
+		v.Severity = []osvSeverity{{Type: "CVSS_V3", Score: adv.CVSS3Vector}}
+	}
+	return v, nil
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveLatest_DepsDev(t *testing.T) {
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/cargo/packages/serde":
+			w.Write([]byte(`{"versions":[
+				{"versionKey":{"version":"1.0.190"},"publishedAt":"2023-10-20T00:00:00Z"},
+				{"versionKey":{"version":"1.0.210"},"publishedAt":"2024-09-06T00:00:00Z","isDefault":true}]}`))
+		case "/v3/systems/cargo/packages/anyhow":
+			w.Write([]byte(`{"versions":[{"versionKey":{"version":"1.0.86"},"isDefault":true}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	reg.depsDev = true
+
+	dir := t.TempDir()
+	manifest := "[dependencies]\nserde = \"1.0.190\"\nanyhow = \"1.0.86\"\nmissing = \"0.1.0\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deps, err := (&RustAnalyzer{}).AnalyzeDeps(reg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]DepStatus)
+	for _, d := range deps {
+		got[d.Module] = d
+	}
+	if d := got["serde"]; d.LatestVersion != "1.0.210" || d.Status != "outdated" || d.StaleDays < 365 {
+		t.Errorf("serde = %+v, want outdated against 1.0.210", d)
+	}
+	if d := got["anyhow"]; d.Status != "current" {
+		t.Errorf("anyhow = %+v, want current", d)
+	}
+	if d := got["missing"]; d.Status != "unknown" || d.LatestVersion != "?" {
+		t.Errorf("missing = %+v, want unknown", d)
+	}
+}
+
+func TestScanVulnerabilities_DepsDev(t *testing.T) {
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/pypi/packages/pyyaml/versions/5.3":
+			w.Write([]byte(`{"licenses":["MIT"],"advisoryKeys":[{"id":"GHSA-8q59-q68h-6hv4"}]}`))
+		case "/v3/advisories/GHSA-8q59-q68h-6hv4":
+			w.Write([]byte(`{"title":"Improper Input Validation in PyYAML","aliases":["CVE-2020-14343"],"cvss3Vector":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}`))
+		case "/v1/querybatch":
+			w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-php"}]}]}`))
+		case "/v1/vulns/GHSA-php":
+			w.Write([]byte(`{"id":"GHSA-php","summary":"XSS","database_specific":{"severity":"LOW"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	reg.depsDev = true
+
+	deps := []DepStatus{
+		{Module: "PyYAML", CurrentVersion: "5.3", Language: LangPython},
+		{Module: "vendor/pkg", CurrentVersion: "1.0.0", Language: LangPHP}, // deps.dev doesn't cover Packagist
+	}
+	vulns := scanVulnerabilities(reg, deps)
+	if len(vulns) != 2 {
+		t.Fatalf("got %d vulnerabilities, want 2: %+v", len(vulns), vulns)
+	}
+	if v := vulns[0]; v.Label() != "CVE-2020-14343 (GHSA-8q59-q68h-6hv4)" || v.Severity != "critical" ||
+		v.Summary != "Improper Input Validation in PyYAML" || v.Module != "PyYAML" || v.Version != "5.3" {
+		t.Errorf("vulns[0] = %+v", v)
+	}
+	if v := vulns[1]; v.ID != "GHSA-php" || v.Severity != "low" {
+		t.Errorf("vulns[1] = %+v", v)
+	}
+}
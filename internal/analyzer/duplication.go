@@ -0,0 +1,211 @@
+package analyzer
+
+import (
+	"bufio"
+	"hash/fnv"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DuplicateBlock is a run of at least MinDuplicateLines normalized source
+// lines that appears in more than one place.
+type DuplicateBlock struct {
+	Lines     int // normalized lines in the block
+	Locations []DuplicateLocation
+}
+
+type DuplicateLocation struct {
+	Path string // relative to the analysis root
+	Line int
+}
+
+// DuplicatedLines counts the redundant copies: every occurrence beyond the
+// first contributes Lines.
+func (d DuplicateBlock) DuplicatedLines() int {
+	return d.Lines * (len(d.Locations) - 1)
+}
+
+type dupFile struct {
+	path  string
+	lines []int    // original line number of each normalized line
+	keys  []uint64 // hash of the window starting at each normalized line
+}
+
+type dupRef struct {
+	file int
+	idx  int
+}
+
+// Lines that carry no logic of their own: imports, package clauses, and
+// bare string literals such as entries of a Go import block.
+var dupNoisePattern = regexp.MustCompile(`^(?:import\b|package\b|from\s+\S+\s+import\b|use\s|using\s|require\b|#include\b|"[^"]*",?$)`)
+
+// findDuplicates hashes every window of minLines consecutive normalized lines
+// (whitespace trimmed; blank, comment, brace-only, and import lines dropped)
+// and reports windows shared between locations, extending runs of matching
+// windows into maximal blocks. It is language-agnostic by design.
+func findDuplicates(root string, paths []string, minLines int) []DuplicateBlock {
+	if minLines <= 0 {
+		minLines = 6
+	}
+
+	var files []dupFile
+	index := make(map[uint64][]dupRef)
+	for _, path := range paths {
+		df := hashWindows(path, minLines)
+		df.path = relPath(root, path)
+		for i, k := range df.keys {
+			index[k] = append(index[k], dupRef{file: len(files), idx: i})
+		}
+		files = append(files, df)
+	}
+
+	var blocks []DuplicateBlock
+	for fi, df := range files {
+		for i := 0; i < len(df.keys); {
+			self := dupRef{file: fi, idx: i}
+			refs := index[df.keys[i]]
+			// Emit each block once, from its first location: an earlier
+			// occurrence that doesn't overlap self is always kept.
+			if first := refs[0]; first != self && !(first.file == fi && i-first.idx < minLines) {
+				i++
+				continue
+			}
+			partners := dupPartners(refs, self, minLines)
+			if len(partners) == 0 || !refBefore(self, partners[0]) {
+				i++
+				continue
+			}
+
+			// Copies within one file must not grow into each other.
+			maxWindows := sameFileGap(self, partners) - minLines + 1
+			n := 1
+			for i+n < len(df.keys) && n < maxWindows && allContinue(files, partners, n, df.keys[i+n]) {
+				n++
+			}
+
+			block := DuplicateBlock{
+				Lines:     n + minLines - 1,
+				Locations: []DuplicateLocation{{Path: df.path, Line: df.lines[i]}},
+			}
+			for _, p := range partners {
+				block.Locations = append(block.Locations, DuplicateLocation{
+					Path: files[p.file].path,
+					Line: files[p.file].lines[p.idx],
+				})
+			}
+			blocks = append(blocks, block)
+			i += n + minLines - 1
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].DuplicatedLines() > blocks[j].DuplicatedLines()
+	})
+	return blocks
+}
+
+func hashWindows(path string, minLines int) dupFile {
+	var df dupFile
+	f, err := os.Open(path)
+	if err != nil {
+		return df
+	}
+	defer f.Close()
+
+	var norm []string
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) <= 3 || isCommentLine(line) || dupNoisePattern.MatchString(line) {
+			continue
+		}
+		norm = append(norm, line)
+		df.lines = append(df.lines, lineNum)
+	}
+
+	for i := 0; i+minLines <= len(norm); i++ {
+		h := fnv.New64a()
+		for _, l := range norm[i : i+minLines] {
+			h.Write([]byte(l))
+			h.Write([]byte{'\n'})
+		}
+		df.keys = append(df.keys, h.Sum64())
+	}
+	return df
+}
+
+func isCommentLine(line string) bool {
+	for _, prefix := range []string{"//", "#", "/*", "*", "--", "'''", `"""`} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dupPartners returns the other occurrences of a window, given refs in
+// file and line order as findDuplicates indexes them. Occurrences that
+// overlap self or an earlier occurrence in the same file are dropped, so
+// repetitive code sliding against itself yields distinct copies only.
+func dupPartners(refs []dupRef, self dupRef, minLines int) []dupRef {
+	var out []dupRef
+	last := make(map[int]int) // file -> index of its last kept occurrence
+	for _, r := range refs {
+		if r == self || (r.file == self.file && abs(r.idx-self.idx) < minLines) {
+			continue
+		}
+		if l, ok := last[r.file]; ok && r.idx-l < minLines {
+			continue
+		}
+		last[r.file] = r.idx
+		out = append(out, r)
+	}
+	return out
+}
+
+// sameFileGap returns the smallest distance between occurrences that share a
+// file, or a value larger than any file when every copy is in its own file.
+// self comes before partners, which are in file and line order.
+func sameFileGap(self dupRef, partners []dupRef) int {
+	gap := math.MaxInt32
+	last := map[int]int{self.file: self.idx}
+	for _, p := range partners {
+		if l, ok := last[p.file]; ok {
+			gap = min(gap, p.idx-l)
+		}
+		last[p.file] = p.idx
+	}
+	return gap
+}
+
+// allContinue reports whether every partner also has the window n positions
+// further on, i.e. the duplicated run extends by one more line.
+func allContinue(files []dupFile, partners []dupRef, n int, key uint64) bool {
+	for _, p := range partners {
+		keys := files[p.file].keys
+		if p.idx+n >= len(keys) || keys[p.idx+n] != key {
+			return false
+		}
+	}
+	return true
+}
+
+func refBefore(a, b dupRef) bool {
+	if a.file != b.file {
+		return a.file < b.file
+	}
+	return a.idx < b.idx
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
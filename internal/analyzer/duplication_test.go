@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+const dupBody = `	total := 0
+	for _, item := range items {
+		if item.Enabled {
+			total += item.Weight * factor
+		}
+	}
+	result := float64(total) / float64(len(items))
+	return result
+`
+
+func TestFindDuplicates(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"a.go": "package x\n\nimport \"fmt\"\n\nfunc A(items []Item, factor int) float64 {\n" + dupBody + "}\n",
+		"b/b.go": "package b\n\n// B is a copy of A.\nfunc B(items []Item, factor int) float64 {\n" +
+			"\tfmt.Println(\"weighted\")\n" + dupBody + "}\n",
+		"c.go": "package x\n\nfunc C() {\n\tfmt.Println(\"unrelated\")\n}\n",
+	})
+	paths := []string{
+		filepath.Join(root, "a.go"),
+		filepath.Join(root, "b", "b.go"),
+		filepath.Join(root, "c.go"),
+	}
+
+	dups := findDuplicates(root, paths, 6)
+	if len(dups) != 1 {
+		t.Fatalf("got %d duplicate blocks, want 1: %+v", len(dups), dups)
+	}
+
+	d := dups[0]
+	// The closing braces are dropped by normalization.
+	if d.Lines != 6 {
+		t.Errorf("Lines = %d, want 6", d.Lines)
+	}
+	want := []DuplicateLocation{{Path: "a.go", Line: 6}, {Path: "b/b.go", Line: 6}}
+	if len(d.Locations) != 2 || d.Locations[0] != want[0] || d.Locations[1] != want[1] {
+		t.Errorf("Locations = %+v, want %+v", d.Locations, want)
+	}
+	if d.DuplicatedLines() != 6 {
+		t.Errorf("DuplicatedLines = %d, want 6", d.DuplicatedLines())
+	}
+
+	if got := findDuplicates(root, paths, 20); len(got) != 0 {
+		t.Errorf("minLines 20: got %d blocks, want none", len(got))
+	}
+}
+
+func TestFindDuplicates_SameFileRepetition(t *testing.T) {
+	root := t.TempDir()
+	// A long run of identical lines must not be reported as copying itself.
+	src := "package x\n\nfunc F() {\n"
+	for i := 0; i < 20; i++ {
+		src += "\tcounter.Increment()\n"
+	}
+	src += "}\n"
+	testutil.WriteTree(t, root, map[string]string{"f.go": src})
+
+	got := findDuplicates(root, []string{filepath.Join(root, "f.go")}, 6)
+	if len(got) != 1 {
+		t.Fatalf("got %d blocks, want 1: %+v", len(got), got)
+	}
+	d := got[0]
+	if d.Lines != 6 || len(d.Locations) != 3 {
+		t.Errorf("block = %d lines x%d, want 6 lines x3 (non-overlapping copies)", d.Lines, len(d.Locations))
+	}
+}
+
+func TestFindDuplicates_LargeBucket(t *testing.T) {
+	root := t.TempDir()
+	// Generated tables and boilerplate share one window thousands of times;
+	// each occurrence must not rescan all the others.
+	files := make(map[string]string)
+	var paths []string
+	for i := range 200 {
+		name := filepath.Join("gen", string(rune('a'+i%26)), fmt.Sprintf("t%d.go", i))
+		files[name] = "package gen\n\nfunc init() {\n" + strings.Repeat("\tregistry.Add(entry)\n", 60) + "}\n"
+		paths = append(paths, filepath.Join(root, name))
+	}
+	testutil.WriteTree(t, root, files)
+
+	got := findDuplicates(root, paths, 6)
+	if len(got) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(got))
+	}
+	// The files are copies of each other from the function header on.
+	if d := got[0]; d.Lines != 61 || len(d.Locations) != 200 {
+		t.Errorf("block = %d lines x%d, want 61 lines x200", d.Lines, len(d.Locations))
+	}
+}
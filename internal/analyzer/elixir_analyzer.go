@@ -0,0 +1,266 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+type ElixirAnalyzer struct {
+	// root is the directory the last FindFiles searched, which tells lib
+	// files and test helpers apart.
+	root string
+}
+
+func (e *ElixirAnalyzer) Language() Language { return LangElixir }
+
+func (e *ElixirAnalyzer) Extensions() []string { return []string{".ex", ".exs"} }
+
+func (e *ElixirAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
+	e.root = root
+	return findLanguageFiles(e, root, exclude)
+}
+
+var exFuncPattern = regexp.MustCompile(`^(\s*)(defp?)\s+(\w+[?!]?)`)
+
+var exComplexityPatterns = []complexityPattern{
+	{regexp.MustCompile(`\bif\b`), 1},
+	{regexp.MustCompile(`\bunless\b`), 1},
+	{regexp.MustCompile(`\bcond\b`), 1},
+	{regexp.MustCompile(`\bwith\b`), 1},
+	{regexp.MustCompile(`^\s*[^#]*\S\s*->`), 1}, // case/cond/receive clause
+	{regexp.MustCompile(`\brescue\b`), 1},
+	{regexp.MustCompile(`\bcatch\b`), 1},
+	{regexp.MustCompile(`&&`), 1},
+	{regexp.MustCompile(`\|\|`), 1},
+	{regexp.MustCompile(`\band\b`), 1},
+	{regexp.MustCompile(`\bor\b`), 1},
+}
+
+var (
+	exBlockOpen  = regexp.MustCompile(`\b(?:do|fn)\b(?:[^:]|$)`)
+	exBlockClose = regexp.MustCompile(`\bend\b`)
+	exInlineDo   = regexp.MustCompile(`,\s*do:`)
+)
+
+func (e *ElixirAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity, int) {
+	var results []FunctionComplexity
+	for _, path := range files {
+		funcs := analyzeElixirComplexity(path)
+		results = append(results, funcs...)
+	}
+	return results, len(results)
+}
+
+func analyzeElixirComplexity(path string) []FunctionComplexity {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var results []FunctionComplexity
+
+	for i, line := range lines {
+		matches := exFuncPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		name := matches[3]
+
+		complexity := 1
+		last := i
+		// One-line definitions ("def f(x), do: x") have no do/end block.
+		if !exInlineDo.MatchString(line) {
+			depth := 0
+			for j := i; j < len(lines); j++ {
+				last = j
+				code := stripElixirComment(lines[j])
+				depth += len(exBlockOpen.FindAllString(code, -1))
+				depth -= len(exBlockClose.FindAllString(code, -1))
+
+				if j > i {
+					for _, p := range exComplexityPatterns {
+						if p.pattern.MatchString(code) {
+							complexity += p.weight
+						}
+					}
+				}
+				if depth <= 0 {
+					break
+				}
+			}
+		}
+
+		results = append(results, FunctionComplexity{
+			File:       filepath.Base(path),
+			Name:       name,
+			Line:       i + 1,
+			Complexity: complexity,
+			Lines:      last - i + 1,
+			Params:     countParams(signatureText(lines, i)),
+			Nesting:    indentNesting(lines[i : last+1]),
+		})
+	}
+
+	return results
+}
+
+func stripElixirComment(line string) string {
+	if idx := strings.Index(line, "#"); idx >= 0 && !strings.Contains(line[:idx], `"`) {
+		return line[:idx]
+	}
+	return line
+}
+
+// Mix dependency parsing
+
+var mixDepPattern = regexp.MustCompile(`\{\s*:(\w+)\s*,\s*"([^"]+)"([^}]*)`)
+
+// mixDevOnly matches dependency options limiting a dep to non-production
+// environments, e.g. `only: :test` or `only: [:dev, :test]`.
+var mixDevOnly = regexp.MustCompile(`only:\s*(?:\[[^\]]*\]|:\w+)`)
+
+func (e *ElixirAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
+	mixPath := filepath.Join(root, "mix.exs")
+	data, err := os.ReadFile(mixPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading mix.exs: %w", err)
+	}
+
+	var results []DepStatus
+	var lookups []func(*DepStatus)
+	for _, m := range mixDepPattern.FindAllStringSubmatch(string(data), -1) {
+		name, version := m[1], strings.TrimSpace(strings.TrimLeft(m[2], "~>=< "))
+		scope := ""
+		if only := mixDevOnly.FindString(m[3]); only != "" && !strings.Contains(only, ":prod") {
+			scope = "dev"
+		}
+
+		results = append(results, DepStatus{
+			Module:         name,
+			CurrentVersion: version,
+			Scope:          scope,
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchHexLatest(reg, name)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
+			} else {
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest || strings.HasPrefix(latest, dep.CurrentVersion+".") {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
+			}
+		})
+	}
+	resolveLatest(reg, LangElixir, results, lookups)
+	return results, nil
+}
+
+type hexPackageResponse struct {
+	LatestStableVersion string `json:"latest_stable_version"`
+	Releases            []struct {
+		Version    string    `json:"version"`
+		InsertedAt time.Time `json:"inserted_at"`
+	} `json:"releases"`
+	Meta struct {
+		Licenses []string `json:"licenses"`
+	} `json:"meta"`
+}
+
+func fetchHexLatest(reg *registryClient, name string) (string, time.Time, error) {
+	var resp hexPackageResponse
+	url := fmt.Sprintf("https://hex.pm/api/packages/%s", name)
+	if err := reg.fetchJSON(url, &resp, "drift/1.0 (https://github.com/greatnessinabox/drift)"); err != nil {
+		return "", time.Time{}, err
+	}
+	if resp.LatestStableVersion == "" {
+		return "", time.Time{}, fmt.Errorf("no stable release on hex.pm")
+	}
+	for _, r := range resp.Releases {
+		if r.Version == resp.LatestStableVersion {
+			return r.Version, r.InsertedAt, nil
+		}
+	}
+	return resp.LatestStableVersion, time.Now(), nil
+}
+
+var exImportPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*alias\s+([\w.]+)`),
+	regexp.MustCompile(`^\s*import\s+([\w.]+)`),
+	regexp.MustCompile(`^\s*use\s+([\w.]+)`),
+	regexp.MustCompile(`^\s*require\s+([\w.]+)`),
+}
+
+func (e *ElixirAnalyzer) AnalyzeImports(files []string, rules []config.BoundaryRule, root string) []BoundaryViolation {
+	var violations []BoundaryViolation
+	for _, path := range files {
+		imports := extractImports(path, exImportPatterns, 1)
+		violations = append(violations, checkBoundaryViolations(path, imports, rules, root)...)
+	}
+	return violations
+}
+
+var exExportPattern = regexp.MustCompile(`^\s*def\s+(\w+[?!]?)`)
+
+// exCallbacks are invoked by OTP, Phoenix, or Mix rather than by project
+// code, so they never count as unused.
+var exCallbacks = map[string]bool{
+	"start": true, "start_link": true, "init": true, "child_spec": true,
+	"handle_call": true, "handle_cast": true, "handle_info": true,
+	"handle_continue": true, "handle_event": true, "handle_params": true,
+	"terminate": true, "code_change": true, "mount": true, "render": true,
+	"call": true, "run": true, "project": true, "application": true,
+}
+
+// AnalyzeDeadCode reports public functions defined under lib/ that are never
+// referenced. Scripts, config, and test helpers still count as callers.
+func (e *ElixirAnalyzer) AnalyzeDeadCode(files []string) []DeadFunction {
+	callPatterns := []*regexp.Regexp{
+		regexp.MustCompile(`\b\w+[?!]?\(`),
+		regexp.MustCompile(`&\w+/\d`),
+	}
+
+	// Helpers under test/support are compiled into the test environment,
+	// so they are read for calls even though FindFiles leaves tests out.
+	// They come first so a lib function sharing a helper's name is the
+	// one reported.
+	var callers []string
+	if e.root != "" {
+		all, _ := walkFiles(e.root, languageFiles[LangElixir].exclude, e.Extensions(), nil)
+		for _, path := range all {
+			if rel := relPath(e.root, path); strings.HasPrefix(rel, "test/support/") || strings.Contains(rel, "/test/support/") {
+				callers = append(callers, path)
+			}
+		}
+	}
+	callers = append(callers, files...)
+
+	var dead []DeadFunction
+	for _, d := range detectExportsAndCalls(callers, exExportPattern, 1, callPatterns) {
+		rel := filepath.ToSlash(d.Path)
+		if e.root != "" {
+			rel = relPath(e.root, d.Path)
+		}
+		if (strings.HasPrefix(rel, "lib/") || strings.Contains(rel, "/lib/")) && !exCallbacks[d.Name] {
+			dead = append(dead, d)
+		}
+	}
+	return dead
+}
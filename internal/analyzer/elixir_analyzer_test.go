@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestElixirComplexity_Boundaries(t *testing.T) {
+	src := `defmodule M do
+  def one(x), do: x
+
+  def two(x) do
+    case x do
+      :a -> 1
+      :b -> 2
+    end
+  end
+
+  defp three(x) do
+    x
+  end
+end
+`
+	path := filepath.Join(t.TempDir(), "m.ex")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"one": 1, "two": 3, "three": 1}
+	funcs := analyzeElixirComplexity(path)
+	if len(funcs) != len(want) {
+		t.Fatalf("got %d functions, want %d: %+v", len(funcs), len(want), funcs)
+	}
+	for _, fc := range funcs {
+		if fc.Complexity != want[fc.Name] {
+			t.Errorf("%s complexity = %d, want %d", fc.Name, fc.Complexity, want[fc.Name])
+		}
+	}
+}
+
+func TestElixirDeadCode_LibOnly(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/app.ex":       "defmodule App do\n  def used, do: 1\n  def unused, do: 2\n  def start_link(_), do: :ok\nend\n",
+		"lib/caller.ex":    "defmodule Caller do\n  def go, do: App.used()\nend\n",
+		"scripts/seed.exs": "defmodule Seed do\n  def orphan, do: 3\nend\n",
+	}
+	var paths []string
+	for rel, src := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	dead := (&ElixirAnalyzer{}).AnalyzeDeadCode(paths)
+	got := make(map[string]bool)
+	for _, d := range dead {
+		got[d.Name] = true
+	}
+	if !got["unused"] || !got["go"] {
+		t.Errorf("expected unused and go to be reported, got %+v", dead)
+	}
+	if got["used"] || got["start_link"] || got["orphan"] {
+		t.Errorf("reported a called, callback, or non-lib function: %+v", dead)
+	}
+}
+
+func TestElixirDeadCode_Paths(t *testing.T) {
+	// A checkout under a test/ directory must not read as all tests.
+	root := filepath.Join(t.TempDir(), "test", "app")
+	testutil.WriteTree(t, root, map[string]string{
+		"lib/app/router.ex":          "defmodule App.Router do\n  def lib_unused, do: 1\n  def fixture, do: 2\nend\n",
+		"test/support/router.ex":     "defmodule Test.Router do\n  def fixture, do: App.Router.fixture()\n  def helper_unused, do: 3\nend\n",
+		"apps/web/lib/web/router.ex": "defmodule Web.Router do\n  def umbrella_unused, do: 3\nend\n",
+	})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "elixir"
+	results, err := New(cfg).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.FileCount != 2 {
+		t.Errorf("analyzed %d files, want the two lib files", results.FileCount)
+	}
+	got := make(map[string]DeadFunction)
+	for _, d := range results.DeadCode {
+		got[d.Name] = d
+	}
+	if d := got["lib_unused"]; d.File != "router.ex" || d.Path != "lib/app/router.ex" {
+		t.Errorf("lib_unused reported as %+v", d)
+	}
+	if d := got["umbrella_unused"]; d.Path != "apps/web/lib/web/router.ex" {
+		t.Errorf("umbrella_unused reported as %+v", d)
+	}
+	if _, ok := got["fixture"]; ok {
+		t.Error("a function test helpers call was reported")
+	}
+	if _, ok := got["helper_unused"]; ok {
+		t.Error("a test helper was reported")
+	}
+}
@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileMetrics summarizes one source file. Files far above the configured
+// line limit ("god files") usually mix several responsibilities.
+type FileMetrics struct {
+	Path          string // relative to the analysis root
+	File          string
+	Lines         int
+	CodeLines     int // non-blank, non-comment lines
+	CommentLines  int
+	Functions     int
+	AvgComplexity float64
+	Language      Language
+
+	// Maintainability is the 0-100 maintainability index; only meaningful
+	// when MaintainabilityMeasured is set (Go files at present).
+	Maintainability         float64
+	MaintainabilityMeasured bool
+}
+
+// syntaxChecker is implemented by languages parsed with a real parser,
+// which can tell why a file yields nothing. Heuristic analyzers read any
+// text.
+type syntaxChecker interface {
+	checkSyntax(path string) error
+}
+
+// Notice is a problem that kept part of the codebase out of the analysis,
+// such as a file that doesn't parse.
+type Notice struct {
+	Path    string // relative to the root
+	Message string
+}
+
+func (n Notice) String() string {
+	return n.Path + ": " + n.Message
+}
+
+// fileNotice explains why path yielded no functions, if something went
+// wrong rather than the file simply having none.
+func fileNotice(lang LanguageAnalyzer, root, path string) (Notice, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return Notice{Path: relPath(root, path), Message: "not analyzed: " + err.Error()}, true
+	}
+	if sc, ok := lang.(syntaxChecker); ok {
+		if err := sc.checkSyntax(path); err != nil {
+			return Notice{Path: relPath(root, path), Message: "not analyzed: " + err.Error()}, true
+		}
+	}
+	return Notice{}, false
+}
+
+// analyzeFiles measures complexity one file at a time so every function can
+// be attributed to the file that declares it, and returns the per-function
+// results alongside the per-file summary and notices of files that couldn't
+// be analyzed. progress, if not nil, is called
+// with the number of files done after each one.
+func analyzeFiles(lang LanguageAnalyzer, root string, files []string, progress func(done int)) ([]FunctionComplexity, int, []FileMetrics, []Notice) {
+	var all []FunctionComplexity
+	var metrics []FileMetrics
+	var notices []Notice
+	total := 0
+
+	for _, path := range files {
+		funcs, n := lang.AnalyzeComplexity([]string{path})
+		if n == 0 {
+			if notice, ok := fileNotice(lang, root, path); ok {
+				notices = append(notices, notice)
+			}
+		}
+		for i := range funcs {
+			funcs[i].Path = relPath(root, path)
+		}
+		all = append(all, funcs...)
+		total += n
+
+		fm := FileMetrics{
+			Path:      relPath(root, path),
+			File:      filepath.Base(path),
+			Lines:     countLines(path),
+			Functions: n,
+			Language:  lang.Language(),
+		}
+		sum := 0
+		for _, fc := range funcs {
+			sum += fc.Complexity
+		}
+		if len(funcs) > 0 {
+			fm.AvgComplexity = float64(sum) / float64(len(funcs))
+		}
+		if ha, ok := lang.(halsteadAnalyzer); ok {
+			if volume, ok := ha.halsteadVolume(path); ok {
+				fm.Maintainability = maintainabilityIndex(volume, sum, fm.Lines)
+				fm.MaintainabilityMeasured = true
+			}
+		}
+		metrics = append(metrics, fm)
+		if progress != nil {
+			progress(len(metrics))
+		}
+	}
+	return all, total, metrics, notices
+}
+
+func countLines(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	n := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// FindFile resolves a file name, as boundary violations and dead code
+// record it, to its path relative to the root, when exactly one analyzed
+// file has that name. It returns "" otherwise.
+func (r *Results) FindFile(name string) string {
+	found := ""
+	for _, f := range r.Files {
+		if filepath.Base(f.Path) == name {
+			if found != "" {
+				return ""
+			}
+			found = f.Path
+		}
+	}
+	return found
+}
+
+// GodFiles returns the files longer than maxLines, longest first. A
+// non-positive maxLines disables the check.
+func GodFiles(files []FileMetrics, maxLines int) []FileMetrics {
+	if maxLines <= 0 {
+		return nil
+	}
+	var out []FileMetrics
+	for _, f := range files {
+		if f.Lines > maxLines {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Lines > out[j].Lines })
+	return out
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestAnalyzeFiles(t *testing.T) {
+	root := t.TempDir()
+	small := filepath.Join(root, "small.go")
+	big := filepath.Join(root, "pkg", "big.go")
+	testutil.WriteTree(t, root, map[string]string{
+		"small.go":   goFixture,
+		"pkg/big.go": "package pkg\n\nfunc A() {}\n\nfunc B(x int) {\n\tif x > 0 {\n\t}\n}\n" + strings.Repeat("// filler\n", 100),
+	})
+
+	funcs, n, metrics, _ := analyzeFiles(&GoAnalyzer{}, root, []string{small, big}, nil)
+	if n != 3 || len(funcs) != 3 {
+		t.Fatalf("functions = %d (%d entries), want 3", n, len(funcs))
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("got %d file metrics, want 2", len(metrics))
+	}
+
+	bm := metrics[1]
+	if bm.Path != "pkg/big.go" || bm.Functions != 2 || bm.AvgComplexity != 1.5 {
+		t.Errorf("big.go metrics = %+v, want pkg/big.go with 2 funcs avg 1.5", bm)
+	}
+	if bm.Lines != 108 {
+		t.Errorf("big.go Lines = %d, want 108", bm.Lines)
+	}
+
+	god := GodFiles(metrics, 100)
+	if len(god) != 1 || god[0].File != "big.go" {
+		t.Errorf("GodFiles(100) = %+v, want only big.go", god)
+	}
+	if GodFiles(metrics, 0) != nil {
+		t.Error("GodFiles(0) should disable the check")
+	}
+}
+
+func TestAnalyzeFiles_Notices(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"ok.go":     goFixture,
+		"empty.go":  "package x\n",
+		"broken.go": "package x\n\nfunc A() {\n",
+	})
+	files := []string{filepath.Join(root, "ok.go"), filepath.Join(root, "empty.go"), filepath.Join(root, "broken.go"), filepath.Join(root, "gone.go")}
+
+	_, _, _, notices := analyzeFiles(&GoAnalyzer{}, root, files, nil)
+	if len(notices) != 2 {
+		t.Fatalf("notices = %+v, want broken.go and gone.go", notices)
+	}
+	if notices[0].Path != "broken.go" || !strings.Contains(notices[0].Message, "syntax error at line 3") {
+		t.Errorf("notices[0] = %+v, want a syntax error in broken.go", notices[0])
+	}
+	if notices[1].Path != "gone.go" {
+		t.Errorf("notices[1] = %+v, want gone.go", notices[1])
+	}
+}
+
+func TestResults_FindFile(t *testing.T) {
+	r := &Results{Files: []FileMetrics{{Path: "cmd/main.go"}, {Path: "a/util.go"}, {Path: "b/util.go"}}}
+	for name, want := range map[string]string{"main.go": "cmd/main.go", "util.go": "", "missing.go": ""} {
+		if got := r.FindFile(name); got != want {
+			t.Errorf("FindFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
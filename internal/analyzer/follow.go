@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/watcher"
+)
+
+// Follow keeps results up to date with the changes w reports, calling update
+// with each new analysis and the changes it took in. It re-analyzes the
+// files of each batch one by one, or everything for a large batch, and
+// everything every refresh if that is positive, when changes is nil. It
+// returns when w closes; w may be nil.
+func (a *Analyzer) Follow(results *Results, w *watcher.Watcher, refresh time.Duration, update func(results *Results, changes watcher.Batch)) {
+	var batches <-chan watcher.Batch
+	if w != nil {
+		batches = w.Events
+	}
+	var tick <-chan time.Time
+	if refresh > 0 {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case batch, ok := <-batches:
+			if !ok {
+				return
+			}
+			if batch.Large() {
+				if r, err := a.Run(); err == nil {
+					results = r
+					update(results, batch)
+				}
+				continue
+			}
+			var applied watcher.Batch
+			for _, e := range batch {
+				if e.Removed {
+					results = results.RemovePath(relPath(a.cfg.Root, e.Path))
+				} else if single, err := a.RunSingle(e.Path); err == nil {
+					results = results.ReplaceFile(single)
+				} else {
+					continue
+				}
+				applied = append(applied, e)
+			}
+			if len(applied) > 0 {
+				update(results, applied)
+			}
+		case <-tick:
+			if r, err := a.Run(); err == nil {
+				results = r
+				update(results, nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/testutil"
+	"github.com/greatnessinabox/drift/internal/watcher"
+)
+
+func TestAnalyzer_Follow(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"a.py": pyFixture, "b.py": pyFixture})
+
+	cfg := config.Defaults()
+	cfg.Root = root
+	cfg.Language = "python"
+	ana := New(cfg)
+	results, err := ana.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &watcher.Watcher{Events: make(chan watcher.Batch)}
+	type update struct {
+		results *Results
+		changes watcher.Batch
+	}
+	updates := make(chan update)
+	go func() {
+		ana.Follow(results, w, 0, func(r *Results, changes watcher.Batch) {
+			updates <- update{r, changes}
+		})
+		close(updates)
+	}()
+
+	testutil.WriteTree(t, root, map[string]string{"a.py": pyFixture + "\ndef extra():\n    return 2\n"})
+	w.Events <- watcher.Batch{
+		{Path: filepath.Join(root, "a.py"), Timestamp: time.Now()},
+		{Path: filepath.Join(root, "b.py"), Timestamp: time.Now(), Removed: true},
+	}
+	u := <-updates
+	if len(u.changes) != 2 || u.results.FileCount != 1 || u.results.FuncCount != 2 {
+		t.Errorf("after the batch: %d changes, %d files, %d functions, want 2, 1, 2", len(u.changes), u.results.FileCount, u.results.FuncCount)
+	}
+
+	close(w.Events)
+	if _, ok := <-updates; ok {
+		t.Error("update after the watcher closed")
+	}
+}
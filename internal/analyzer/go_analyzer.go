@@ -1,45 +1,157 @@
 package analyzer
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
 
-type GoAnalyzer struct{}
+// GoAnalyzer loads the module with go/packages so file selection honors build
+// constraints and skips generated code, and so boundary and dead-code checks
+// see real package identities and type information. Packages that fail to
+// type-check are parsed directly instead, as is the whole tree when the
+// module cannot be loaded at all (no go.mod, no go toolchain, or a history
+// snapshot).
+type GoAnalyzer struct {
+	mu      sync.Mutex
+	pkgs    []*packages.Package
+	untyped []string // files FindFiles found outside pkgs
+}
+
+const goLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
 
 func (g *GoAnalyzer) Language() Language { return LangGo }
 
 func (g *GoAnalyzer) Extensions() []string { return []string{".go"} }
 
 func (g *GoAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
+	pkgs, untyped, ok := loadGoPackages(root, exclude)
+	if !ok {
+		files, err := findLanguageFiles(g, root, exclude)
+		g.setLoaded(nil, files)
+		return files, err
+	}
+	g.setLoaded(pkgs, untyped)
+
 	var files []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			if path := syntaxPath(pkg, f); !ast.IsGenerated(f) && !config.Excluded(exclude, relPath(root, path)) {
+				files = append(files, path)
+			}
 		}
-		if info.IsDir() {
-			for _, ex := range exclude {
-				if info.Name() == ex {
-					return filepath.SkipDir
-				}
+	}
+	return append(files, untyped...), nil
+}
+
+// loadGoPackages type-checks every non-test package under root, dropping
+// packages in excluded directories. Packages that fail to load or
+// type-check, or that import one that does, are not returned; their files
+// are listed in untyped instead so callers can parse them. ok is false when
+// the module cannot be loaded at all.
+func loadGoPackages(root string, exclude []string) (pkgs []*packages.Package, untyped []string, ok bool) {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		return nil, nil, false
+	}
+
+	cfg := &packages.Config{Mode: goLoadMode, Dir: root}
+	loaded, err := packages.Load(cfg, "./...")
+	if err != nil || len(loaded) == 0 {
+		return nil, nil, false
+	}
+
+	for _, pkg := range loaded {
+		if len(pkg.GoFiles) > 0 && inExcludedDir(root, pkg.GoFiles[0], exclude) {
+			continue
+		}
+		if !pkg.IllTyped && pkg.TypesInfo != nil {
+			pkgs = append(pkgs, pkg)
+			continue
+		}
+		for _, path := range pkg.GoFiles {
+			if !generatedGoFile(path) && !config.Excluded(exclude, relPath(root, path)) {
+				untyped = append(untyped, path)
 			}
-			return nil
 		}
-		if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
-			files = append(files, path)
+	}
+	return pkgs, untyped, true
+}
+
+// syntaxPath returns the source file f was parsed from. It reads the file's
+// position rather than indexing CompiledGoFiles, which for cgo packages lists
+// generated files instead of the ones under the root.
+func syntaxPath(pkg *packages.Package, f *ast.File) string {
+	return pkg.Fset.Position(f.Package).Filename
+}
+
+func inExcludedDir(root, path string, exclude []string) bool {
+	rel := relPath(root, filepath.Dir(path))
+	return rel != "." && config.Excluded(exclude, rel)
+}
+
+// generatedGoFile reports whether the Go file at path carries a generated
+// code header, which FindFiles leaves out.
+func generatedGoFile(path string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	return err == nil && ast.IsGenerated(f)
+}
+
+func (g *GoAnalyzer) setLoaded(pkgs []*packages.Package, untyped []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pkgs, g.untyped = pkgs, untyped
+}
+
+// loaded returns the well-typed packages from the last FindFiles, or nil when
+// the module could not be loaded and analysis should parse every file itself.
+func (g *GoAnalyzer) loaded() []*packages.Package {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.pkgs
+}
+
+// partition splits files into those the loaded packages cover, which can be
+// analyzed with type information, and the rest, which must be parsed. It also
+// returns every untyped file FindFiles found, since references from those
+// can't be resolved.
+func (g *GoAnalyzer) partition(files []string) (pkgs []*packages.Package, typed, parsed, untyped []string) {
+	g.mu.Lock()
+	pkgs, untyped = g.pkgs, g.untyped
+	g.mu.Unlock()
+
+	covered := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			covered[syntaxPath(pkg, f)] = true
+		}
+	}
+	for _, path := range files {
+		if covered[path] {
+			typed = append(typed, path)
+		} else {
+			parsed = append(parsed, path)
 		}
-		return nil
-	})
-	return files, err
+	}
+	return pkgs, typed, parsed, untyped
 }
 
 func (g *GoAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity, int) {
+	// Always reparse: complexity needs no type information, and single-file
+	// updates must see the file's current contents.
 	var results []FunctionComplexity
 	fset := token.NewFileSet()
 	for _, path := range files {
@@ -53,35 +165,236 @@ func (g *GoAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity, in
 	return results, len(results)
 }
 
-func (g *GoAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
-	return analyzeDeps(root)
+func (g *GoAnalyzer) checkSyntax(path string) error {
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		return fmt.Errorf("syntax error at line %d: %s", list[0].Pos.Line, list[0].Msg)
+	}
+	return err
+}
+
+func (g *GoAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
+	return analyzeDeps(reg, root)
 }
 
 func (g *GoAnalyzer) AnalyzeImports(files []string, rules []config.BoundaryRule, root string) []BoundaryViolation {
 	if len(rules) == 0 {
 		return nil
 	}
-	fset := token.NewFileSet()
-	var allFiles []*ast.File
-	for _, path := range files {
-		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if err != nil {
-			continue
-		}
-		allFiles = append(allFiles, f)
+	pkgs, typed, parsed, _ := g.partition(files)
+
+	var violations []BoundaryViolation
+	if len(typed) > 0 {
+		fset, syntax := packageSyntax(pkgs, typed)
+		violations = analyzeImports(fset, syntax, rules, root)
 	}
-	return analyzeImports(fset, allFiles, rules, root)
+	fset := token.NewFileSet()
+	return append(violations, analyzeImports(fset, parseGoFiles(fset, parsed), rules, root)...)
 }
 
 func (g *GoAnalyzer) AnalyzeDeadCode(files []string) []DeadFunction {
+	pkgs, typed, parsed, untyped := g.partition(files)
+
+	var dead []DeadFunction
+	if len(typed) > 0 {
+		var ok bool
+		if dead, ok = analyzeCallGraphDeadCode(pkgs, typed); !ok {
+			dead = analyzeTypedDeadCode(pkgs, typed)
+		}
+	}
+	if len(parsed) == 0 && len(untyped) == 0 {
+		return dead
+	}
+
+	// Untyped files' references can't be resolved, so any name they mention
+	// counts as used, and declarations in parsed files are matched by name
+	// against every file in the module.
 	fset := token.NewFileSet()
-	var allFiles []*ast.File
-	for _, path := range files {
+	broken := parseGoFiles(fset, slices.Concat(untyped, parsed))
+	mentioned := mentionedNames(broken)
+	dead = slices.DeleteFunc(dead, func(d DeadFunction) bool {
+		return mentioned[d.Name[strings.LastIndex(d.Name, ".")+1:]]
+	})
+
+	inParsed := make(map[string]bool, len(parsed))
+	for _, path := range parsed {
+		inParsed[path] = true
+	}
+	all := append(parseGoFiles(fset, packageFiles(pkgs)), broken...)
+	for _, d := range analyzeDeadCode(fset, all) {
+		if inParsed[d.Path] {
+			dead = append(dead, d)
+		}
+	}
+	return dead
+}
+
+// parseGoFiles parses each of paths into fset, skipping any that fail to
+// parse.
+func parseGoFiles(fset *token.FileSet, paths []string) []*ast.File {
+	var files []*ast.File
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
 		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 		if err != nil {
 			continue
 		}
-		allFiles = append(allFiles, f)
+		files = append(files, f)
+	}
+	return files
+}
+
+// mentionedNames returns every identifier appearing in files.
+func mentionedNames(files []*ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				names[id.Name] = true
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// packageFiles lists the source files of pkgs.
+func packageFiles(pkgs []*packages.Package) []string {
+	var paths []string
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			paths = append(paths, syntaxPath(pkg, f))
+		}
+	}
+	return paths
+}
+
+// packageSyntax returns the already-parsed ASTs for files, all of which share
+// the loader's FileSet.
+func packageSyntax(pkgs []*packages.Package, files []string) (*token.FileSet, []*ast.File) {
+	want := make(map[string]bool, len(files))
+	for _, f := range files {
+		want[f] = true
+	}
+
+	var fset *token.FileSet
+	var syntax []*ast.File
+	for _, pkg := range pkgs {
+		fset = pkg.Fset
+		for _, f := range pkg.Syntax {
+			if want[syntaxPath(pkg, f)] {
+				syntax = append(syntax, f)
+			}
+		}
+	}
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	return fset, syntax
+}
+
+// analyzeTypedDeadCode reports exported functions and methods declared in
+// files that no package in the module references. Uses are resolved through
+// type information, so identically named functions in different packages are
+// no longer conflated. Methods also count as used when any method of the same
+// name is referenced, since calls through interfaces resolve to the interface
+// method rather than the concrete one.
+func analyzeTypedDeadCode(pkgs []*packages.Package, files []string) []DeadFunction {
+	used := make(map[types.Object]bool)
+	usedMethodNames := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, obj := range pkg.TypesInfo.Uses {
+			fn, ok := obj.(*types.Func)
+			if !ok {
+				continue
+			}
+			used[fn.Origin()] = true
+			if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+				usedMethodNames[fn.Name()] = true
+			}
+		}
+	}
+
+	return unusedDeclarations(pkgs, files, func(fn *types.Func) bool {
+		if used[fn] {
+			return true
+		}
+		sig, ok := fn.Type().(*types.Signature)
+		return ok && sig.Recv() != nil && usedMethodNames[fn.Name()]
+	})
+}
+
+// unusedDeclarations lists the exported functions and methods declared in
+// files, among pkgs, for which used reports false, along with the exported
+// package-level types, consts, and vars no package refers to.
+func unusedDeclarations(pkgs []*packages.Package, files []string, used func(*types.Func) bool) []DeadFunction {
+	inScope := make(map[string]bool, len(files))
+	for _, f := range files {
+		inScope[f] = true
+	}
+	referenced := make(map[types.Object]bool)
+	for _, pkg := range pkgs {
+		for _, obj := range pkg.TypesInfo.Uses {
+			referenced[obj] = true
+		}
+	}
+
+	var dead []DeadFunction
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			if !inScope[syntaxPath(pkg, f)] {
+				continue
+			}
+			for _, decl := range f.Decls {
+				if gd, ok := decl.(*ast.GenDecl); ok {
+					for _, spec := range gd.Specs {
+						for _, id := range specNames(spec) {
+							obj := pkg.TypesInfo.Defs[id]
+							if !isExported(id.Name) || obj == nil || referenced[obj] {
+								continue
+							}
+							pos := pkg.Fset.Position(id.Pos())
+							dead = append(dead, DeadFunction{
+								File: filepath.Base(pos.Filename),
+								Path: pos.Filename,
+								Name: id.Name,
+								Line: pos.Line,
+								Kind: gd.Tok.String(),
+							})
+						}
+					}
+					continue
+				}
+
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || !isExported(fd.Name.Name) || isEntryPoint(fd.Name.Name) {
+					continue
+				}
+				obj, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+				if !ok || used(obj) {
+					continue
+				}
+
+				name, kind := fd.Name.Name, "function"
+				if fd.Recv != nil && len(fd.Recv.List) > 0 {
+					name, kind = receiverName(fd.Recv.List[0].Type)+"."+name, "method"
+				}
+
+				pos := pkg.Fset.Position(fd.Pos())
+				dead = append(dead, DeadFunction{
+					File: filepath.Base(pos.Filename),
+					Path: pos.Filename,
+					Name: name,
+					Line: pos.Line,
+					Kind: kind,
+				})
+			}
+		}
 	}
-	return analyzeDeadCode(fset, allFiles)
+	return dead
 }
@@ -0,0 +1,223 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestGoAnalyzer_Packages(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod":    "module example.com/m\n\ngo 1.21\n",
+		"main.go":   "package main\n\nimport \"example.com/m/a\"\n\nfunc main() { a.Used() }\n",
+		"a/a.go":    "package a\n\nfunc Used() {}\n\nfunc Unused() {}\n",
+		"b/b.go":    "package b\n\n// Used shares a name with a.Used but is never called.\nfunc Used() {}\n",
+		"a/gen.go":  "// Code generated by stringer. DO NOT EDIT.\n\npackage a\n\nfunc Generated() {}\n",
+		"a/skip.go": "//go:build ignore\n\npackage a\n\nfunc Tagged() {}\n",
+	})
+
+	g := &GoAnalyzer{}
+	files, err := g.FindFiles(root, []string{"vendor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.loaded() == nil {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+	for _, f := range files {
+		if base := filepath.Base(f); base == "gen.go" || base == "skip.go" {
+			t.Errorf("FindFiles included %s", base)
+		}
+	}
+
+	dead := make(map[string]string)
+	for _, d := range g.AnalyzeDeadCode(files) {
+		dead[d.Name] = d.File
+	}
+	if dead["Unused"] != "a.go" {
+		t.Errorf("Unused not reported in a.go: %v", dead)
+	}
+	if dead["Used"] != "b.go" {
+		t.Errorf("b.Used should be dead despite a.Used being called: %v", dead)
+	}
+	if len(dead) != 2 {
+		t.Errorf("dead = %v, want exactly Unused and b.Used", dead)
+	}
+}
+
+func TestGoAnalyzer_CallGraphDeadCode(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"main.go": `package main
+
+import "example.com/m/shapes"
+
+func main() {
+	var s shapes.Shape = shapes.Circle{}
+	println(s.Area())
+	apply(shapes.Double)
+}
+
+func apply(f func(int) int) { f(1) }
+`,
+		"shapes/shapes.go": `package shapes
+
+type Shape interface{ Area() float64 }
+
+type Circle struct{}
+
+func (Circle) Area() float64 { return 1 }
+
+// Square is never created, so its Area can't be called even though
+// Circle's is.
+type Square struct{}
+
+func (Square) Area() float64 { return 2 }
+
+// Double is only ever passed as a value.
+func Double(n int) int { return n * 2 }
+
+func Triple(n int) int { return n * 3 }
+`,
+	})
+
+	g := &GoAnalyzer{}
+	files, err := g.FindFiles(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.loaded() == nil {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+
+	var dead []string
+	for _, d := range g.AnalyzeDeadCode(files) {
+		dead = append(dead, d.Name)
+	}
+	sort.Strings(dead)
+	if want := []string{"Square.Area", "Triple"}; !reflect.DeepEqual(dead, want) {
+		t.Errorf("dead = %v, want %v", dead, want)
+	}
+}
+
+func TestGoAnalyzer_BrokenPackage(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod":    "module example.com/m\n\ngo 1.21\n",
+		"a/a.go":    "package a\n\nfunc Used() {}\n\nfunc Unused() {}\n",
+		"b/b.go":    "package b\n\nimport \"example.com/m/a\"\n\nfunc Run() { a.Used(); var n int = \"typo\" }\n\nfunc Stale() {}\n",
+		"b/gen.go":  "// Code generated by stringer. DO NOT EDIT.\n\npackage b\n\nfunc Generated() {}\n",
+		"c/main.go": "package main\n\nimport \"example.com/m/b\"\n\nfunc main() { b.Run() }\n",
+	})
+
+	g := &GoAnalyzer{}
+	files, err := g.FindFiles(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.loaded() == nil {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, relPath(root, f))
+	}
+	sort.Strings(names)
+	if want := []string{"a/a.go", "b/b.go", "c/main.go"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	if pkgs := g.loaded(); len(pkgs) != 1 || pkgs[0].PkgPath != "example.com/m/a" {
+		t.Errorf("typed packages = %v, want only example.com/m/a", pkgs)
+	}
+
+	// a is still analyzed with types; b and main, which imports it, are
+	// parsed, and the call from b keeps a.Used alive.
+	var dead []string
+	for _, d := range g.AnalyzeDeadCode(files) {
+		dead = append(dead, d.Name)
+	}
+	sort.Strings(dead)
+	if want := []string{"Stale", "Unused"}; !reflect.DeepEqual(dead, want) {
+		t.Errorf("dead = %v, want %v", dead, want)
+	}
+}
+
+func TestAnalyzeCallGraphDeadCode_Library(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod": "module example.com/lib\n\ngo 1.21\n",
+		"lib.go": "package lib\n\nfunc API() {}\n",
+	})
+	pkgs, _, ok := loadGoPackages(root, nil)
+	if !ok {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+	if _, ok := analyzeCallGraphDeadCode(pkgs, []string{filepath.Join(root, "lib.go")}); ok {
+		t.Error("a module without main packages should fall back to the typed analysis")
+	}
+}
+
+func TestGoAnalyzer_DeadDeclarationKinds(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod": "module example.com/lib\n\ngo 1.21\n",
+		"lib.go": `package lib
+
+type Config struct{ Name string }
+
+type Orphan struct{}
+
+const (
+	Version = "1.0"
+	Stale   = 2
+)
+
+var Default = Config{Name: Version}
+
+var Forgotten, unexported = 1, 2
+
+func Load() Config { return Default }
+`,
+		"cmd/main.go": `package main
+
+import "example.com/lib"
+
+func main() { println(lib.Load().Name) }
+`,
+	})
+
+	g := &GoAnalyzer{}
+	files, err := g.FindFiles(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.loaded() == nil {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+
+	got := make(map[string][]string)
+	for kind, dead := range DeadCodeByKind(g.AnalyzeDeadCode(files)) {
+		for _, d := range dead {
+			got[kind] = append(got[kind], d.Name)
+		}
+	}
+	want := map[string][]string{"type": {"Orphan"}, "const": {"Stale"}, "var": {"Forgotten"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dead by kind = %v, want %v", got, want)
+	}
+}
+
+func TestCountExported(t *testing.T) {
+	funcs := []FunctionComplexity{{Name: "Open"}, {Name: "parse"}, {Name: "Store.Put"}, {Name: "Store.flush"}, {Name: "_helper"}}
+	for lang, want := range map[Language]int{LangGo: 2, LangPython: 4, LangRuby: 5} {
+		if got := countExported(lang, funcs); got != want {
+			t.Errorf("countExported(%s) = %d, want %d", lang, got, want)
+		}
+	}
+}
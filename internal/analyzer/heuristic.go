@@ -2,18 +2,67 @@ package analyzer
 
 import (
 	"bufio"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
 
+// fileRules are the files a language leaves out of analysis on top of the
+// configured excludes: build output and dependency directories in exclude,
+// and tests in skip, whose entries match anywhere in a file's path relative
+// to the root.
+type fileRules struct {
+	exclude []string
+	skip    []string
+}
+
+var languageFiles = map[Language]fileRules{
+	LangGo:         {skip: []string{"_test.go"}},
+	LangTypeScript: {exclude: []string{"node_modules", "dist", "build", ".next", "coverage"}, skip: []string{".test.", ".spec.", "__tests__", "__mocks__", ".d.ts"}},
+	LangPython:     {exclude: []string{"__pycache__", ".venv", "venv", "env", ".tox", ".eggs", ".mypy_cache"}, skip: []string{"test_", "_test.py", "conftest.py"}},
+	LangRust:       {exclude: []string{"target"}},
+	LangJava:       {exclude: []string{"target", "build", ".gradle", ".idea", "bin", "out"}, skip: []string{"Test.java", "Tests.java", "IT.java"}},
+	LangRuby:       {exclude: []string{".bundle", "vendor", "tmp", ".ruby-lsp"}, skip: []string{"_test.rb", "_spec.rb", "spec/", "test/"}},
+	LangPHP:        {exclude: []string{"vendor", "cache", ".phpunit.cache", "storage"}, skip: []string{"Test.php", "Tests.php", "test/", "tests/"}},
+	LangCSharp:     {exclude: []string{"bin", "obj", ".vs", "packages", "TestResults"}, skip: []string{"Tests.cs", "Test.cs", ".Tests/", ".Test/"}},
+	LangSwift:      {exclude: []string{".build", ".swiftpm", "Pods", "Carthage", "DerivedData"}, skip: []string{"Tests.swift", "Test.swift", "Tests/", "Package.swift"}},
+	LangElixir:     {exclude: []string{"_build", "deps", ".elixir_ls", "cover"}, skip: []string{"_test.exs", "test/", "mix.exs"}},
+}
+
+// findLanguageFiles walks root for lang's files, leaving out exclude and the
+// language's fileRules.
+func findLanguageFiles(lang LanguageAnalyzer, root string, exclude []string) ([]string, error) {
+	rules := languageFiles[lang.Language()]
+	return walkFiles(root, slices.Concat(exclude, rules.exclude), lang.Extensions(), rules.skip)
+}
+
+// acceptsFile reports whether findLanguageFiles would return path, a file
+// with one of lang's extensions under root.
+func acceptsFile(lang LanguageAnalyzer, root string, exclude []string, path string) bool {
+	rules := languageFiles[lang.Language()]
+	exclude = slices.Concat(exclude, rules.exclude)
+	rel := relPath(root, path)
+	for dir := filepath.Dir(filepath.FromSlash(rel)); dir != "."; dir = filepath.Dir(dir) {
+		if config.Excluded(exclude, filepath.ToSlash(dir)) {
+			return false
+		}
+	}
+	return !config.Excluded(exclude, rel) && !skipped(rules.skip, rel)
+}
+
+func skipped(skipPatterns []string, rel string) bool {
+	for _, skip := range skipPatterns {
+		if strings.Contains(rel, skip) {
+			return true
+		}
+	}
+	return false
+}
+
 func walkFiles(root string, exclude, extensions, skipPatterns []string) ([]string, error) {
 	var files []string
 	extSet := make(map[string]bool)
@@ -25,27 +74,19 @@ func walkFiles(root string, exclude, extensions, skipPatterns []string) ([]strin
 		if err != nil {
 			return nil
 		}
+		rel := relPath(root, path)
 		if info.IsDir() {
-			name := info.Name()
-			for _, ex := range exclude {
-				if name == ex {
-					return filepath.SkipDir
-				}
+			if rel != "." && config.Excluded(exclude, rel) {
+				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		ext := filepath.Ext(path)
-		if !extSet[ext] {
+		if !extSet[ext] || config.Excluded(exclude, rel) || skipped(skipPatterns, rel) {
 			return nil
 		}
 
-		for _, skip := range skipPatterns {
-			if strings.Contains(path, skip) {
-				return nil
-			}
-		}
-
 		files = append(files, path)
 		return nil
 	})
@@ -101,6 +142,9 @@ func scanHeuristicComplexity(
 			Name:       fn.name,
 			Line:       fn.line,
 			Complexity: complexity,
+			Lines:      fn.end - fn.start,
+			Params:     countParams(signatureText(allLines, fn.start)),
+			Nesting:    indentNesting(allLines[fn.start:min(fn.end, len(allLines))]),
 		})
 	}
 	return results
@@ -151,9 +195,85 @@ func detectFunctions(lines []string, funcPattern *regexp.Regexp, nameGroup int,
 	return funcs
 }
 
+// signatureText joins lines from start until the first parameter list
+// closes, so signatures wrapped over several lines are counted in full.
+func signatureText(lines []string, start int) string {
+	var b strings.Builder
+	depth := 0
+	opened := false
+	for i := start; i < len(lines) && i < start+10; i++ {
+		b.WriteString(lines[i])
+		b.WriteByte(' ')
+		for _, ch := range lines[i] {
+			switch ch {
+			case '(':
+				depth++
+				opened = true
+			case ')':
+				depth--
+			}
+		}
+		if !opened || depth <= 0 {
+			break
+		}
+	}
+	return b.String()
+}
+
+// countParams counts the entries in the first parenthesized list of a
+// signature. Receiver-style parameters (self, &self, cls) are not counted.
+func countParams(signature string) int {
+	open := strings.Index(signature, "(")
+	if open < 0 {
+		return 0
+	}
+
+	count := 0
+	depth := 0
+	var current strings.Builder
+	flush := func() {
+		p := strings.TrimSpace(current.String())
+		current.Reset()
+		switch p {
+		case "", "self", "&self", "&mut self", "mut self", "cls", "this":
+			return
+		}
+		count++
+	}
+
+	var prev rune
+	for _, ch := range signature[open:] {
+		arrow := ch == '>' && (prev == '-' || prev == '=')
+		prev = ch
+		switch {
+		case arrow:
+		case ch == '(' || ch == '[' || ch == '{' || ch == '<':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ch == ')' || ch == ']' || ch == '}' || ch == '>':
+			depth--
+			if depth == 0 {
+				flush()
+				return count
+			}
+		case ch == ',':
+			if depth == 1 {
+				flush()
+				continue
+			}
+		}
+		current.WriteRune(ch)
+	}
+	flush()
+	return count
+}
+
 type heuristicImportMatch struct {
-	path string
-	line int
+	path    string
+	line    int
+	ignored bool // the line carries a drift:ignore boundary comment
 }
 
 func extractImports(filePath string, patterns []*regexp.Regexp, groupIndex int) []heuristicImportMatch {
@@ -172,8 +292,9 @@ func extractImports(filePath string, patterns []*regexp.Regexp, groupIndex int)
 		for _, p := range patterns {
 			if matches := p.FindStringSubmatch(line); matches != nil && groupIndex < len(matches) {
 				imports = append(imports, heuristicImportMatch{
-					path: matches[groupIndex],
-					line: lineNum,
+					path:    matches[groupIndex],
+					line:    lineNum,
+					ignored: ignoresBoundary(line),
 				})
 			}
 		}
@@ -195,19 +316,20 @@ func checkBoundaryViolations(filePath string, imports []heuristicImportMatch, ru
 	var violations []BoundaryViolation
 	for _, imp := range imports {
 		for _, rule := range rules {
-			from, to := parseBoundaryRule(rule.Deny)
-			if from == "" || to == "" {
+			from, to, ok := violatesBoundary(rule, fileDir, imp.path, matchesLoosely)
+			if !ok {
 				continue
 			}
-			if matchesPath(fileDir, from) && matchesImport(imp.path, to) {
-				violations = append(violations, BoundaryViolation{
-					File:   filepath.Base(filePath),
-					Line:   imp.line,
-					From:   from,
-					To:     to,
-					Import: imp.path,
-				})
-			}
+			violations = append(violations, BoundaryViolation{
+				File:        filepath.Base(filePath),
+				Line:        imp.line,
+				From:        from,
+				To:          to,
+				Import:      imp.path,
+				Rule:        rule.Name,
+				Description: rule.Description,
+				Suppressed:  imp.ignored,
+			})
 		}
 	}
 	return violations
@@ -244,7 +366,7 @@ func detectExportsAndCalls(
 				name := matches[exportNameGroup]
 				if name != "" {
 					exported[name] = exportInfo{
-						file: filepath.Base(path),
+						file: path,
 						name: name,
 						line: lineNum,
 					}
@@ -278,31 +400,13 @@ func detectExportsAndCalls(
 	for name, info := range exported {
 		if !called[name] {
 			dead = append(dead, DeadFunction{
-				File: info.file,
+				File: filepath.Base(info.file),
+				Path: info.file,
 				Name: info.name,
 				Line: info.line,
+				Kind: "function",
 			})
 		}
 	}
 	return dead
 }
-
-func fetchJSON(url string, target interface{}, userAgent string) error {
-	client := &http.Client{Timeout: 5 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	if userAgent != "" {
-		req.Header.Set("User-Agent", userAgent)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-	return json.NewDecoder(resp.Body).Decode(target)
-}
@@ -3,9 +3,12 @@ package analyzer
 import (
 	"go/ast"
 	"go/token"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/greatnessinabox/drift/internal/config"
 )
 
@@ -15,6 +18,23 @@ type BoundaryViolation struct {
 	From   string
 	To     string
 	Import string
+	// Rule and Description come from the broken rule's name and
+	// description, when it has them.
+	Rule        string
+	Description string
+	// Suppressed is set when the import line carries a
+	// "drift:ignore boundary" comment. Suppressed violations are reported
+	// separately and don't count against the score.
+	Suppressed bool
+}
+
+// boundaryIgnoreDirective matches an inline suppression in any of the
+// supported languages' comment syntaxes: "// drift:ignore boundary",
+// "# drift:ignore boundary", or "/* drift:ignore boundary */".
+var boundaryIgnoreDirective = regexp.MustCompile(`(?://|#|/\*)\s*drift:ignore\s+boundary\b`)
+
+func ignoresBoundary(line string) bool {
+	return boundaryIgnoreDirective.MatchString(line)
 }
 
 func analyzeImports(fset *token.FileSet, files []*ast.File, rules []config.BoundaryRule, root string) []BoundaryViolation {
@@ -23,6 +43,7 @@ func analyzeImports(fset *token.FileSet, files []*ast.File, rules []config.Bound
 	}
 
 	var violations []BoundaryViolation
+	match := goPackageMatcher(goModulePath(root))
 
 	for _, f := range files {
 		pos := fset.Position(f.Pos())
@@ -35,25 +56,34 @@ func analyzeImports(fset *token.FileSet, files []*ast.File, rules []config.Bound
 
 		fileDir := filepath.Dir(relPath)
 
+		ignored := make(map[int]bool)
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if ignoresBoundary(c.Text) {
+					ignored[fset.Position(c.Slash).Line] = true
+				}
+			}
+		}
+
 		for _, imp := range f.Imports {
 			importPath := strings.Trim(imp.Path.Value, `"`)
 
 			for _, rule := range rules {
-				from, to := parseBoundaryRule(rule.Deny)
-				if from == "" || to == "" {
+				from, to, ok := violatesBoundary(rule, fileDir, importPath, match)
+				if !ok {
 					continue
 				}
-
-				if matchesPath(fileDir, from) && matchesImport(importPath, to) {
-					impPos := fset.Position(imp.Pos())
-					violations = append(violations, BoundaryViolation{
-						File:   filepath.Base(filePath),
-						Line:   impPos.Line,
-						From:   from,
-						To:     to,
-						Import: importPath,
-					})
-				}
+				impPos := fset.Position(imp.Pos())
+				violations = append(violations, BoundaryViolation{
+					File:        filepath.Base(filePath),
+					Line:        impPos.Line,
+					From:        from,
+					To:          to,
+					Import:      importPath,
+					Rule:        rule.Name,
+					Description: rule.Description,
+					Suppressed:  ignored[impPos.Line],
+				})
 			}
 		}
 	}
@@ -61,6 +91,35 @@ func analyzeImports(fset *token.FileSet, files []*ast.File, rules []config.Bound
 	return violations
 }
 
+// importMatcher reports whether a file in dir importing importPath falls
+// under the from and to sides of a boundary rule.
+type importMatcher func(dir, importPath, from, to string) bool
+
+// violatesBoundary reports whether a file in fileDir importing importPath
+// breaks rule: match accepts the import for its deny pattern and for none of
+// its allow exceptions. Relative imports ("./x", "../x") are resolved against
+// fileDir first. It returns the two sides to report, the rule's From and To
+// labels when it has them and otherwise the deny pattern's.
+func violatesBoundary(rule config.BoundaryRule, fileDir, importPath string, match importMatcher) (string, string, bool) {
+	if strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../") {
+		importPath = path.Join(filepath.ToSlash(fileDir), importPath)
+	}
+	from, to := parseBoundaryRule(rule.Deny)
+	if from == "" || to == "" || !match(fileDir, importPath, from, to) {
+		return "", "", false
+	}
+	for _, allow := range rule.Allow {
+		af, at := parseBoundaryRule(allow)
+		if af != "" && at != "" && match(fileDir, importPath, af, at) {
+			return "", "", false
+		}
+	}
+	if rule.From != "" && rule.To != "" {
+		return rule.From, rule.To, true
+	}
+	return from, to, true
+}
+
 func parseBoundaryRule(deny string) (string, string) {
 	parts := strings.Split(deny, "->")
 	if len(parts) != 2 {
@@ -69,12 +128,73 @@ func parseBoundaryRule(deny string) (string, string) {
 	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
 }
 
+// matchesPath reports whether dir, relative to the root, falls under
+// pattern: a directory prefix such as "pkg/api", or a doublestar glob such
+// as "internal/**".
 func matchesPath(dir, pattern string) bool {
 	dir = filepath.ToSlash(dir)
 	pattern = filepath.ToSlash(pattern)
+	if isGlob(pattern) {
+		ok, _ := doublestar.Match(pattern, dir)
+		return ok
+	}
 	return strings.HasPrefix(dir, pattern) || dir == pattern
 }
 
+// matchesImport reports whether importPath contains pattern. A glob such as
+// "cmd/**" matches when it covers the import path from any segment on, so
+// "github.com/acme/app/cmd/tool" and "../cmd/tool" both match it.
 func matchesImport(importPath, pattern string) bool {
-	return strings.Contains(importPath, pattern)
+	if !isGlob(pattern) {
+		return strings.Contains(importPath, pattern)
+	}
+	for rest := importPath; ; {
+		if ok, _ := doublestar.Match(pattern, rest); ok {
+			return true
+		}
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+}
+
+// matchesLoosely is the importMatcher for languages whose imports drift
+// can't resolve to packages: directory prefixes and import substrings.
+func matchesLoosely(dir, importPath, from, to string) bool {
+	return matchesPath(dir, from) && matchesImport(importPath, to)
+}
+
+// goPackageMatcher matches Go packages on path segment boundaries, so
+// "internal/db" covers "internal/db/query" but not "internal/dbmodels".
+// Imports inside the module at modPath are resolved to their directory
+// under the root; the full import path is tried too, so a pattern may also
+// name an external module or spell out the module path.
+func goPackageMatcher(modPath string) importMatcher {
+	return func(dir, importPath, from, to string) bool {
+		if !matchesPackage(filepath.ToSlash(dir), from) {
+			return false
+		}
+		if matchesPackage(importPath, to) {
+			return true
+		}
+		rel, ok := strings.CutPrefix(importPath, modPath+"/")
+		return ok && modPath != "" && matchesPackage(rel, to)
+	}
+}
+
+// matchesPackage reports whether pkg is the package pattern names or one
+// below it. Globs match the whole path.
+func matchesPackage(pkg, pattern string) bool {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if isGlob(pattern) {
+		ok, _ := doublestar.Match(pattern, pkg)
+		return ok
+	}
+	return pkg == pattern || strings.HasPrefix(pkg, pattern+"/")
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[{")
 }
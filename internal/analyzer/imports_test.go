@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestViolatesBoundary(t *testing.T) {
+	prefix := config.BoundaryRule{Deny: "pkg/api -> internal/db"}
+	glob := config.BoundaryRule{
+		Deny:  "internal/** -> cmd/**",
+		Allow: []string{"internal/testutil -> cmd/**", "internal/** -> cmd/shared"},
+	}
+
+	tests := []struct {
+		name       string
+		rule       config.BoundaryRule
+		dir, imp   string
+		wantBroken bool
+	}{
+		{"prefix match", prefix, "pkg/api/v1", "github.com/acme/app/internal/db", true},
+		{"prefix outside from", prefix, "pkg/web", "github.com/acme/app/internal/db", false},
+		{"prefix other import", prefix, "pkg/api", "github.com/acme/app/internal/cache", false},
+		{"glob full module path", glob, "internal/analyzer", "github.com/acme/app/cmd/tool", true},
+		{"glob relative import", glob, "internal/tui/views", "../../cmd/tool", true},
+		{"glob from root of pattern", glob, "internal", "github.com/acme/app/cmd/tool", true},
+		{"glob outside from", glob, "pkg/api", "github.com/acme/app/cmd/tool", false},
+		{"glob needs a segment boundary", glob, "internal/tui", "github.com/acme/app/subcmd/tool", false},
+		{"allowed by dir", glob, "internal/testutil", "github.com/acme/app/cmd/tool", false},
+		{"allowed by import", glob, "internal/tui", "github.com/acme/app/cmd/shared", false},
+		{"malformed rule", config.BoundaryRule{Deny: "pkg/api"}, "pkg/api", "internal/db", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, got := violatesBoundary(tt.rule, tt.dir, tt.imp, matchesLoosely)
+			if got != tt.wantBroken {
+				t.Errorf("violatesBoundary(%q, %q, %q) = %v, want %v", tt.rule.Deny, tt.dir, tt.imp, got, tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestCheckBoundaryViolations_RuleName(t *testing.T) {
+	root := t.TempDir()
+	rules := []config.BoundaryRule{{
+		Name:        "domain-is-pure",
+		Description: "Domain code must not reach into infrastructure.",
+		Deny:        "src/domain/** -> infra/**",
+	}}
+	imports := []heuristicImportMatch{{path: "../../infra/db", line: 3}, {path: "./money", line: 4}}
+
+	got := checkBoundaryViolations(filepath.Join(root, "src/domain/orders/order.ts"), imports, rules, root)
+	if len(got) != 1 {
+		t.Fatalf("got %d violations, want 1: %+v", len(got), got)
+	}
+	v := got[0]
+	if v.Rule != "domain-is-pure" || v.Description != rules[0].Description || v.Line != 3 ||
+		v.From != "src/domain/**" || v.To != "infra/**" || v.Import != "../../infra/db" {
+		t.Errorf("violation = %+v", v)
+	}
+}
+
+func TestCheckBoundaryViolations_Layers(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	cfg.Layers = []config.Layer{
+		{Name: "domain", Paths: []string{"src/domain/**"}},
+		{Name: "application", Paths: []string{"src/app/**"}},
+		{Name: "infrastructure", Paths: []string{"src/infra/**", "src/db/**"}},
+	}
+	rules := cfg.BoundaryRules()
+	if len(rules) != 5 { // domain→app, domain→infra ×2, app→infra ×2
+		t.Fatalf("derived %d rules, want 5: %+v", len(rules), rules)
+	}
+
+	imports := []heuristicImportMatch{
+		{path: "../app/usecases", line: 1},
+		{path: "../db/orders", line: 2},
+	}
+	got := checkBoundaryViolations(filepath.Join(cfg.Root, "src/domain/order.ts"), imports, rules, cfg.Root)
+	if len(got) != 2 {
+		t.Fatalf("domain: got %d violations, want 2: %+v", len(got), got)
+	}
+	if v := got[0]; v.From != "domain" || v.To != "application" || v.Rule != "layers" {
+		t.Errorf("got[0] = %+v, want domain → application", v)
+	}
+	if v := got[1]; v.From != "domain" || v.To != "infrastructure" {
+		t.Errorf("got[1] = %+v, want domain → infrastructure", v)
+	}
+
+	// Higher layers may import lower ones.
+	imports = []heuristicImportMatch{{path: "../domain/order", line: 1}, {path: "../app/usecases", line: 2}}
+	if got := checkBoundaryViolations(filepath.Join(cfg.Root, "src/infra/repo.ts"), imports, rules, cfg.Root); len(got) != 0 {
+		t.Errorf("infrastructure: got %+v, want none", got)
+	}
+}
+
+func TestAnalyzeImports_Suppressed(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pkg/api/handler.go")
+	src := `package api
+
+import (
+	"example.com/app/internal/db" // drift:ignore boundary
+	"example.com/app/internal/db/query"
+)
+`
+	testutil.WriteTree(t, root, map[string]string{"go.mod": "module example.com/app\n"})
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []config.BoundaryRule{{Deny: "pkg/api -> internal/db"}}
+
+	got := analyzeImports(fset, []*ast.File{f}, rules, root)
+	if len(got) != 2 {
+		t.Fatalf("got %d violations, want 2: %+v", len(got), got)
+	}
+	if !got[0].Suppressed || got[0].Line != 4 {
+		t.Errorf("got[0] = %+v, want suppressed at line 4", got[0])
+	}
+	if got[1].Suppressed {
+		t.Errorf("got[1] = %+v, want not suppressed", got[1])
+	}
+}
+
+func TestExtractImports_Suppressed(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "app/views.py")
+	src := "import app.db  # drift:ignore boundary\nfrom app.db import models\nimport app.db.cache  # drift:ignore\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns := []*regexp.Regexp{regexp.MustCompile(`^\s*(?:from|import)\s+([\w.]+)`)}
+	imports := extractImports(path, patterns, 1)
+	rules := []config.BoundaryRule{{Deny: "app -> app.db"}}
+	got := checkBoundaryViolations(path, imports, rules, root)
+	if len(got) != 3 {
+		t.Fatalf("got %d violations, want 3: %+v", len(got), got)
+	}
+	// Only the full directive suppresses; a bare "drift:ignore" doesn't.
+	for i, want := range []bool{true, false, false} {
+		if got[i].Suppressed != want {
+			t.Errorf("line %d: Suppressed = %v, want %v", got[i].Line, got[i].Suppressed, want)
+		}
+	}
+}
+
+func TestIgnoresBoundary(t *testing.T) {
+	for line, want := range map[string]bool{
+		`import "x" // drift:ignore boundary`:              true,
+		`use crate::db; //drift:ignore boundary`:           true,
+		`require "db" # drift:ignore  boundary`:            true,
+		`import db from "db"; /* drift:ignore boundary */`: true,
+		`import "x" // drift:ignore boundaries`:            false,
+		`import "drift:ignore boundary"`:                   false,
+	} {
+		if got := ignoresBoundary(line); got != want {
+			t.Errorf("ignoresBoundary(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestAnalyzeImports_GoPackages(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"go.mod": "module example.com/app\n\ngo 1.22\n"})
+	rules := []config.BoundaryRule{
+		{Deny: "internal/api -> internal/db"},
+		{Deny: "internal/api -> github.com/lib/pq"},
+	}
+	src := `package api
+
+import (
+	"example.com/app/internal/db"
+	"example.com/app/internal/db/query"
+	"example.com/app/internal/dbmodels"
+	"github.com/lib/pq"
+	"github.com/lib/pqx"
+)
+`
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, dir := range []string{"internal/api", "internal/apiv2"} {
+		f, err := parser.ParseFile(fset, filepath.Join(root, dir, "api.go"), src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	var got []string
+	for _, v := range analyzeImports(fset, files, rules, root) {
+		got = append(got, v.Import)
+	}
+	// internal/apiv2 isn't under internal/api, and neither dbmodels nor pqx
+	// is under the denied packages.
+	want := []string{"example.com/app/internal/db", "example.com/app/internal/db/query", "github.com/lib/pq"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -19,9 +20,7 @@ func (j *JavaAnalyzer) Language() Language { return LangJava }
 func (j *JavaAnalyzer) Extensions() []string { return []string{".java"} }
 
 func (j *JavaAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "target", "build", ".gradle", ".idea", "bin", "out")
-	skip := []string{"Test.java", "Tests.java", "IT.java"}
-	return walkFiles(root, exclude, j.Extensions(), skip)
+	return findLanguageFiles(j, root, exclude)
 }
 
 var javaFuncPattern = regexp.MustCompile(
@@ -62,25 +61,26 @@ type pomDep struct {
 	GroupID    string `xml:"groupId"`
 	ArtifactID string `xml:"artifactId"`
 	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
 }
 
-func (j *JavaAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (j *JavaAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	// Try pom.xml first
 	pomPath := filepath.Join(root, "pom.xml")
 	if _, err := os.Stat(pomPath); err == nil {
-		return parsePomDeps(pomPath)
+		return parsePomDeps(reg, pomPath)
 	}
 
 	// Try build.gradle
 	gradlePath := filepath.Join(root, "build.gradle")
 	if _, err := os.Stat(gradlePath); err == nil {
-		return parseGradleDeps(gradlePath)
+		return parseGradleDeps(reg, gradlePath)
 	}
 
-	return nil, fmt.Errorf("no Java build file found")
+	return nil, errNoManifest
 }
 
-func parsePomDeps(path string) ([]DepStatus, error) {
+func parsePomDeps(reg *registryClient, path string) ([]DepStatus, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading pom.xml: %w", err)
@@ -92,40 +92,45 @@ func parsePomDeps(path string) ([]DepStatus, error) {
 	}
 
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	for _, dep := range pom.Dependencies.Dependency {
 		if dep.Version == "" || strings.HasPrefix(dep.Version, "${") {
 			continue
 		}
 
-		ds := DepStatus{
+		scope := ""
+		if dep.Scope == "test" {
+			scope = "dev"
+		}
+		results = append(results, DepStatus{
 			Module:         dep.GroupID + ":" + dep.ArtifactID,
 			CurrentVersion: dep.Version,
-		}
-
-		latest, err := fetchMavenLatest(dep.GroupID, dep.ArtifactID)
-		if err != nil {
-			ds.Status = "unknown"
-			ds.LatestVersion = "?"
-		} else {
-			ds.LatestVersion = latest
-			if ds.CurrentVersion == latest {
-				ds.Status = "current"
+			Scope:          scope,
+		})
+		lookups = append(lookups, func(ds *DepStatus) {
+			latest, published, err := fetchMavenLatest(reg, dep.GroupID, dep.ArtifactID)
+			if err != nil {
+				ds.Status = "unknown"
+				ds.LatestVersion = "?"
 			} else {
-				ds.StaleDays = 30
-				ds.Status = "stale"
+				ds.LatestVersion = latest
+				if ds.CurrentVersion == latest {
+					ds.Status = "current"
+				} else {
+					markBehind(ds, published)
+				}
 			}
-		}
-
-		results = append(results, ds)
+		})
 	}
+	resolveLatest(reg, LangJava, results, lookups)
 	return results, nil
 }
 
 var gradleDepPattern = regexp.MustCompile(
-	`(?:implementation|api|compile|testImplementation)\s+['"]([^:]+):([^:]+):([^'"]+)['"]`,
+	`\b(implementation|api|compile|testImplementation|testCompileOnly|testRuntimeOnly)\s*\(?\s*['"]([^:]+):([^:]+):([^'"]+)['"]`,
 )
 
-func parseGradleDeps(path string) ([]DepStatus, error) {
+func parseGradleDeps(reg *registryClient, path string) ([]DepStatus, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading build.gradle: %w", err)
@@ -133,35 +138,40 @@ func parseGradleDeps(path string) ([]DepStatus, error) {
 	defer f.Close()
 
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	scanner := bufio.NewScanner(f)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if m := gradleDepPattern.FindStringSubmatch(line); m != nil {
-			groupID, artifactID, version := m[1], m[2], m[3]
+			groupID, artifactID, version := m[2], m[3], m[4]
+			scope := ""
+			if strings.HasPrefix(m[1], "test") {
+				scope = "dev"
+			}
 
-			ds := DepStatus{
+			results = append(results, DepStatus{
 				Module:         groupID + ":" + artifactID,
 				CurrentVersion: version,
-			}
-
-			latest, err := fetchMavenLatest(groupID, artifactID)
-			if err != nil {
-				ds.Status = "unknown"
-				ds.LatestVersion = "?"
-			} else {
-				ds.LatestVersion = latest
-				if ds.CurrentVersion == latest {
-					ds.Status = "current"
+				Scope:          scope,
+			})
+			lookups = append(lookups, func(ds *DepStatus) {
+				latest, published, err := fetchMavenLatest(reg, groupID, artifactID)
+				if err != nil {
+					ds.Status = "unknown"
+					ds.LatestVersion = "?"
 				} else {
-					ds.StaleDays = 30
-					ds.Status = "stale"
+					ds.LatestVersion = latest
+					if ds.CurrentVersion == latest {
+						ds.Status = "current"
+					} else {
+						markBehind(ds, published)
+					}
 				}
-			}
-
-			results = append(results, ds)
+			})
 		}
 	}
+	resolveLatest(reg, LangJava, results, lookups)
 	return results, nil
 }
 
@@ -169,23 +179,29 @@ type mavenSearchResponse struct {
 	Response struct {
 		Docs []struct {
 			LatestVersion string `json:"latestVersion"`
+			Timestamp     int64  `json:"timestamp"` // milliseconds since the epoch
 		} `json:"docs"`
 	} `json:"response"`
 }
 
-func fetchMavenLatest(groupID, artifactID string) (string, error) {
+func fetchMavenLatest(reg *registryClient, groupID, artifactID string) (string, time.Time, error) {
 	var resp mavenSearchResponse
 	url := fmt.Sprintf(
 		"https://search.maven.org/solrsearch/select?q=g:%%22%s%%22+AND+a:%%22%s%%22&rows=1&wt=json",
 		groupID, artifactID,
 	)
-	if err := fetchJSON(url, &resp, ""); err != nil {
-		return "", err
+	if err := reg.fetchJSON(url, &resp, ""); err != nil {
+		return "", time.Time{}, err
 	}
 	if len(resp.Response.Docs) == 0 {
-		return "", fmt.Errorf("not found on Maven Central")
+		return "", time.Time{}, fmt.Errorf("not found on Maven Central")
+	}
+	doc := resp.Response.Docs[0]
+	var published time.Time
+	if doc.Timestamp > 0 {
+		published = time.UnixMilli(doc.Timestamp)
 	}
-	return resp.Response.Docs[0].LatestVersion, nil
+	return doc.LatestVersion, published, nil
 }
 
 var javaImportPatterns = []*regexp.Regexp{
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -18,6 +19,8 @@ const (
 	LangRuby       Language = "ruby"
 	LangPHP        Language = "php"
 	LangCSharp     Language = "csharp"
+	LangSwift      Language = "swift"
+	LangElixir     Language = "elixir"
 	LangUnknown    Language = "unknown"
 )
 
@@ -26,7 +29,7 @@ type LanguageAnalyzer interface {
 	Extensions() []string
 	FindFiles(root string, exclude []string) ([]string, error)
 	AnalyzeComplexity(files []string) ([]FunctionComplexity, int)
-	AnalyzeDeps(root string) ([]DepStatus, error)
+	AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error)
 	AnalyzeImports(files []string, rules []config.BoundaryRule, root string) []BoundaryViolation
 	AnalyzeDeadCode(files []string) []DeadFunction
 }
@@ -45,6 +48,8 @@ func DetectLanguage(root string) Language {
 		{"build.gradle", LangJava},
 		{"Gemfile", LangRuby},
 		{"composer.json", LangPHP},
+		{"Package.swift", LangSwift},
+		{"mix.exs", LangElixir},
 	}
 
 	for _, c := range checks {
@@ -85,7 +90,58 @@ func NewLanguageAnalyzer(lang Language) LanguageAnalyzer {
 		return &PHPAnalyzer{}
 	case LangCSharp:
 		return &CSharpAnalyzer{}
+	case LangSwift:
+		return &SwiftAnalyzer{}
+	case LangElixir:
+		return &ElixirAnalyzer{}
 	default:
 		return &GoAnalyzer{}
 	}
 }
+
+// sourceLanguages are the languages DetectSources counts files of, in the
+// order ties are broken.
+var sourceLanguages = []Language{LangGo, LangTypeScript, LangPython, LangRust, LangJava, LangRuby, LangPHP, LangCSharp, LangSwift, LangElixir}
+
+// maxDetectFiles bounds how many files DetectSources looks at.
+const maxDetectFiles = 20000
+
+// DetectSources picks the language with the most source files under root,
+// for projects without a manifest DetectLanguage recognizes, such as a
+// folder of scripts. It returns LangUnknown when there are none.
+func DetectSources(root string, exclude []string) Language {
+	byExt := make(map[string]Language)
+	for _, lang := range sourceLanguages {
+		for _, ext := range NewLanguageAnalyzer(lang).Extensions() {
+			byExt[ext] = lang
+		}
+	}
+	counts := make(map[Language]int)
+	seen := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && config.Excluded(exclude, relPath(root, path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if seen++; seen > maxDetectFiles {
+			return filepath.SkipAll
+		}
+		if lang, ok := byExt[filepath.Ext(path)]; ok {
+			counts[lang]++
+		}
+		return nil
+	})
+
+	best := LangUnknown
+	for _, lang := range sourceLanguages {
+		if counts[lang] > counts[best] {
+			best = lang
+		}
+	}
+	return best
+}
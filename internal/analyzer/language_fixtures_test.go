@@ -102,6 +102,32 @@ const csFixture = `class H {
 }
 `
 
+const swiftFixture = `func handle(_ x: Int) -> Int {
+    guard x >= 0 else {
+        return -1
+    }
+    if x > 0 {
+        return 1
+    }
+    for i in 0..<x {
+        print(i)
+    }
+    return 0
+}
+`
+
+const exFixture = `defmodule H do
+  def handle(x) do
+    if x > 0 do
+      1
+    else
+      Enum.each(0..x, fn i -> IO.puts(i) end)
+      0
+    end
+  end
+end
+`
+
 func TestLanguageAnalyzers_DetectComplexity(t *testing.T) {
 	tests := []struct {
 		lang Language
@@ -117,6 +143,8 @@ func TestLanguageAnalyzers_DetectComplexity(t *testing.T) {
 		{LangRuby, "h.rb", rbFixture, "handle"},
 		{LangPHP, "h.php", phpFixture, "handle"},
 		{LangCSharp, "H.cs", csFixture, "Handle"},
+		{LangSwift, "h.swift", swiftFixture, "handle"},
+		{LangElixir, "h.ex", exFixture, "handle"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LicenseViolation is a dependency whose declared license is on the
+// configured deny list.
+type LicenseViolation struct {
+	Module   string
+	Version  string
+	License  string // SPDX expression as published, e.g. "GPL-3.0-only"
+	Denied   string // the deny-list entry it matched
+	Language Language
+}
+
+// checkLicenses looks up the license of every dependency, records it on the
+// dependency, and returns those the deny list rules out. Nothing is fetched
+// when deny is empty.
+func checkLicenses(reg *registryClient, deps []DepStatus, deny []string) []LicenseViolation {
+	if len(deny) == 0 || len(deps) == 0 {
+		return nil
+	}
+
+	lookups := make([]func(*DepStatus), len(deps))
+	for i := range deps {
+		lookups[i] = func(dep *DepStatus) {
+			dep.License = fetchLicense(reg, *dep)
+		}
+	}
+	resolveDeps(deps, lookups)
+
+	var out []LicenseViolation
+	for _, dep := range deps {
+		if denied := deniedLicense(dep.License, deny); denied != "" {
+			out = append(out, LicenseViolation{
+				Module:   dep.Module,
+				Version:  dep.CurrentVersion,
+				License:  dep.License,
+				Denied:   denied,
+				Language: dep.Language,
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Module < out[j].Module })
+	return out
+}
+
+// fetchLicense returns dep's license as an SPDX expression, or "" when the
+// registry doesn't say.
+func fetchLicense(reg *registryClient, dep DepStatus) string {
+	name := dep.PackageName()
+
+	switch dep.Language {
+	case LangPHP:
+		var resp packagistResponse
+		if err := reg.fetchJSON(fmt.Sprintf("https://repo.packagist.org/p2/%s.json", name), &resp, ""); err != nil {
+			return ""
+		}
+		for _, v := range resp.Packages[name] {
+			if strings.TrimPrefix(v.Version, "v") == dep.CurrentVersion || dep.CurrentVersion == "" {
+				return strings.Join(v.License, " OR ")
+			}
+		}
+		if versions := resp.Packages[name]; len(versions) > 0 {
+			return strings.Join(versions[0].License, " OR ")
+		}
+		return ""
+	case LangElixir:
+		var resp hexPackageResponse
+		if err := reg.fetchJSON(fmt.Sprintf("https://hex.pm/api/packages/%s", name), &resp, "drift/1.0 (https://github.com/greatnessinabox/drift)"); err != nil {
+			return ""
+		}
+		return strings.Join(resp.Meta.Licenses, " OR ")
+	}
+
+	v, ok := fetchDepsDevVersion(reg, dep)
+	if !ok {
+		return ""
+	}
+	return strings.Join(v.Licenses, " AND ")
+}
+
+// deniedLicense reports which deny-list entry rules out license, or "".
+// An entry matches an SPDX identifier exactly or as its base, so "GPL-3.0"
+// also denies "GPL-3.0-only", "GPL-3.0-or-later", and "GPL-3.0+". In an
+// expression, "A OR B" is only denied when every alternative is, while
+// "A AND B" is denied when either part is.
+func deniedLicense(license string, deny []string) string {
+	license = strings.NewReplacer("(", " ", ")", " ").Replace(license)
+	if strings.TrimSpace(license) == "" {
+		return ""
+	}
+
+	denied := ""
+	for _, alt := range splitSPDX(license, "OR") {
+		match := ""
+		for _, id := range splitSPDX(alt, "AND") {
+			if m := deniedID(id, deny); m != "" {
+				match = m
+				break
+			}
+		}
+		if match == "" {
+			return "" // this alternative is allowed
+		}
+		if denied == "" {
+			denied = match
+		}
+	}
+	return denied
+}
+
+func deniedID(id string, deny []string) string {
+	id, _, _ = strings.Cut(strings.TrimSpace(id), " WITH ") // exceptions don't change the license
+	base := strings.TrimSuffix(id, "+")
+	for _, suffix := range []string{"-only", "-or-later"} {
+		base = strings.TrimSuffix(base, suffix)
+	}
+	for _, d := range deny {
+		if strings.EqualFold(id, d) || strings.EqualFold(base, d) {
+			return d
+		}
+	}
+	return ""
+}
+
+// splitSPDX splits an expression on a case-insensitive operator keyword.
+func splitSPDX(expr, op string) []string {
+	var parts []string
+	var cur []string
+	for _, f := range strings.Fields(expr) {
+		if strings.EqualFold(f, op) {
+			parts = append(parts, strings.Join(cur, " "))
+			cur = nil
+			continue
+		}
+		cur = append(cur, f)
+	}
+	return append(parts, strings.Join(cur, " "))
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDeniedLicense(t *testing.T) {
+	deny := []string{"GPL-3.0", "AGPL-3.0"}
+	tests := []struct {
+		license string
+		want    string
+	}{
+		{"MIT", ""},
+		{"", ""},
+		{"GPL-3.0", "GPL-3.0"},
+		{"gpl-3.0-only", "GPL-3.0"},
+		{"GPL-3.0-or-later", "GPL-3.0"},
+		{"GPL-3.0+", "GPL-3.0"},
+		{"GPL-2.0-only", ""},
+		{"LGPL-3.0", ""},
+		{"MIT OR GPL-3.0", ""},             // can be used under MIT
+		{"GPL-3.0 OR AGPL-3.0", "GPL-3.0"}, // every choice denied
+		{"(MIT AND AGPL-3.0-only)", "AGPL-3.0"},
+		{"GPL-3.0 WITH GCC-exception-3.1", "GPL-3.0"},
+	}
+	for _, tt := range tests {
+		if got := deniedLicense(tt.license, deny); got != tt.want {
+			t.Errorf("deniedLicense(%q) = %q, want %q", tt.license, got, tt.want)
+		}
+	}
+}
+
+func TestCheckLicenses(t *testing.T) {
+	requests := 0
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/npm/packages/%40scope%2Fcopyleft/versions/2.1.0":
+			w.Write([]byte(`{"licenses":["GPL-3.0-or-later"]}`))
+		case "/v3/systems/npm/packages/permissive/versions/1.0.0":
+			w.Write([]byte(`{"licenses":["MIT"]}`))
+		case "/v3/systems/go/packages/github.com%2Facme%2Flib/versions/v1.2.0":
+			w.Write([]byte(`{"licenses":["AGPL-3.0-only"]}`))
+		case "/p2/vendor/pkg.json":
+			w.Write([]byte(`{"packages":{"vendor/pkg":[{"version":"2.0.0","license":["MIT"]},{"version":"1.0.0","license":["GPL-3.0-only"]}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	deps := []DepStatus{
+		{Module: "@scope/copyleft", CurrentVersion: "^2.1.0", Language: LangTypeScript},
+		{Module: "permissive", CurrentVersion: "1.0.0", Language: LangTypeScript},
+		{Module: "lib", Path: "github.com/acme/lib", CurrentVersion: "v1.2.0", Language: LangGo},
+		{Module: "vendor/pkg", CurrentVersion: "1.0.0", Language: LangPHP},
+		{Module: "unknown", CurrentVersion: "3.0.0", Language: LangTypeScript},
+	}
+	got := checkLicenses(reg, deps, []string{"GPL-3.0", "AGPL-3.0"})
+
+	want := []LicenseViolation{
+		{Module: "@scope/copyleft", Version: "^2.1.0", License: "GPL-3.0-or-later", Denied: "GPL-3.0", Language: LangTypeScript},
+		{Module: "lib", Version: "v1.2.0", License: "AGPL-3.0-only", Denied: "AGPL-3.0", Language: LangGo},
+		{Module: "vendor/pkg", Version: "1.0.0", License: "GPL-3.0-only", Denied: "GPL-3.0", Language: LangPHP},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("violation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if deps[1].License != "MIT" {
+		t.Errorf("permissive License = %q, want MIT", deps[1].License)
+	}
+
+	requests = 0
+	if v := checkLicenses(reg, deps, nil); v != nil || requests != 0 {
+		t.Errorf("no deny list: got %+v after %d requests, want nothing fetched", v, requests)
+	}
+}
@@ -0,0 +1,231 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lockfile readers return the installed version of each package, keyed by
+// name. Manifests often hold ranges ("^1.2.0", "~> 7.1") that say little
+// about what is actually installed, so AnalyzeDeps prefers a locked version
+// when there is one. A missing or unreadable lockfile yields an empty map.
+
+// readNpmLock reads package-lock.json, yarn.lock, or pnpm-lock.yaml, in that
+// order, whichever exists first.
+func readNpmLock(root string) map[string]string {
+	if data, err := os.ReadFile(filepath.Join(root, "package-lock.json")); err == nil {
+		return parsePackageLock(data)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "yarn.lock")); err == nil {
+		return parseYarnLock(data)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-lock.yaml")); err == nil {
+		return parsePnpmLock(data)
+	}
+	return map[string]string{}
+}
+
+// parsePackageLock handles lockfileVersion 2 and 3 ("packages" keyed by
+// install path) as well as version 1 ("dependencies" keyed by name).
+func parsePackageLock(data []byte) map[string]string {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	locked := make(map[string]string)
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return locked
+	}
+	for name, dep := range lock.Dependencies {
+		locked[name] = dep.Version
+	}
+	for path, pkg := range lock.Packages {
+		// Only top-level installs; nested node_modules are transitive copies.
+		name, ok := strings.CutPrefix(path, "node_modules/")
+		if !ok || strings.Contains(name, "/node_modules/") || pkg.Version == "" {
+			continue
+		}
+		locked[name] = pkg.Version
+	}
+	return locked
+}
+
+var yarnVersionLine = regexp.MustCompile(`^\s+version:?\s+"?([^"\s]+)"?`)
+
+// parseYarnLock handles both classic (v1) and Berry lockfiles. An entry's
+// header lists every requested range, e.g. `"@babel/core@^7.0.0", "@babel/core@^7.1.0":`.
+func parseYarnLock(data []byte) map[string]string {
+	locked := make(map[string]string)
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			names = names[:0]
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				spec = strings.Trim(strings.TrimSpace(spec), `"`)
+				if at := strings.LastIndex(spec, "@"); at > 0 {
+					names = append(names, spec[:at])
+				}
+			}
+			continue
+		}
+		if m := yarnVersionLine.FindStringSubmatch(line); m != nil {
+			for _, name := range names {
+				if _, ok := locked[name]; !ok {
+					locked[name] = m[1]
+				}
+			}
+			names = names[:0]
+		}
+	}
+	return locked
+}
+
+// parsePnpmLock reads the root importer's resolved versions (lockfile v6 and
+// later) or the top-level dependencies map of older lockfiles. Versions
+// carry peer suffixes such as "18.2.0(react@18.2.0)", which are dropped.
+func parsePnpmLock(data []byte) map[string]string {
+	var lock struct {
+		Importers map[string]struct {
+			Dependencies map[string]interface{} `yaml:"dependencies"`
+		} `yaml:"importers"`
+		Dependencies map[string]interface{} `yaml:"dependencies"`
+	}
+	locked := make(map[string]string)
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return locked
+	}
+
+	deps := lock.Dependencies
+	if root, ok := lock.Importers["."]; ok {
+		deps = root.Dependencies
+	}
+	for name, v := range deps {
+		var version string
+		switch v := v.(type) {
+		case string:
+			version = v
+		case map[string]interface{}:
+			version, _ = v["version"].(string)
+		}
+		if i := strings.IndexAny(version, "(_"); i >= 0 {
+			version = version[:i]
+		}
+		if version != "" {
+			locked[name] = version
+		}
+	}
+	return locked
+}
+
+// readTOMLPackages collects name and version from the [[package]] tables of
+// poetry.lock or Cargo.lock. When a package is locked at several versions
+// (Cargo allows this), the first is kept.
+func readTOMLPackages(path string) map[string]string {
+	locked := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return locked
+	}
+	defer f.Close()
+
+	var name, version string
+	flush := func() {
+		if _, ok := locked[name]; name != "" && version != "" && !ok {
+			locked[name] = version
+		}
+		name, version = "", ""
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "[[package]]":
+			flush()
+		case strings.HasPrefix(line, "name = "):
+			name = strings.Trim(strings.TrimPrefix(line, "name = "), `"`)
+		case strings.HasPrefix(line, "version = "):
+			version = strings.Trim(strings.TrimPrefix(line, "version = "), `"`)
+		}
+	}
+	flush()
+	return locked
+}
+
+// readPoetryLock keys packages by their normalized PyPI name, see
+// normalizePyName.
+func readPoetryLock(root string) map[string]string {
+	locked := make(map[string]string)
+	for name, version := range readTOMLPackages(filepath.Join(root, "poetry.lock")) {
+		locked[normalizePyName(name)] = version
+	}
+	return locked
+}
+
+// normalizePyName applies PEP 503 normalization: names compare
+// case-insensitively and treat "-", "_", and "." alike.
+func normalizePyName(name string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+var gemfileLockSpec = regexp.MustCompile(`^    ([\w.-]+) \(([^)]+)\)$`)
+
+// readGemfileLock reads the gems resolved under each "specs:" section.
+// Platform-specific builds ("1.15.4-x86_64-linux") report the base version.
+func readGemfileLock(root string) map[string]string {
+	locked := make(map[string]string)
+	f, err := os.Open(filepath.Join(root, "Gemfile.lock"))
+	if err != nil {
+		return locked
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := gemfileLockSpec.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		version, _, _ := strings.Cut(m[2], "-")
+		if _, ok := locked[m[1]]; !ok {
+			locked[m[1]] = version
+		}
+	}
+	return locked
+}
+
+// readComposerLock reads composer.lock's packages list.
+func readComposerLock(root string) map[string]string {
+	locked := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(root, "composer.lock"))
+	if err != nil {
+		return locked
+	}
+	var lock struct {
+		Packages []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return locked
+	}
+	for _, p := range lock.Packages {
+		locked[p.Name] = strings.TrimPrefix(p.Version, "v")
+	}
+	return locked
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestReadNpmLock(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]string
+	}{
+		{
+			"package-lock v3",
+			map[string]string{"package-lock.json": `{"lockfileVersion":3,"packages":{
+				"":{"name":"app"},
+				"node_modules/react":{"version":"18.2.0"},
+				"node_modules/@types/node":{"version":"20.8.1"},
+				"node_modules/react/node_modules/loose-envify":{"version":"1.0.0"}}}`},
+			map[string]string{"react": "18.2.0", "@types/node": "20.8.1"},
+		},
+		{
+			"package-lock v1",
+			map[string]string{"package-lock.json": `{"lockfileVersion":1,"dependencies":{"lodash":{"version":"4.17.21"}}}`},
+			map[string]string{"lodash": "4.17.21"},
+		},
+		{
+			"yarn classic",
+			map[string]string{"yarn.lock": "# yarn lockfile v1\n\n" +
+				"\"@babel/core@^7.0.0\", \"@babel/core@^7.1.0\":\n  version \"7.23.2\"\n  resolved \"https://x\"\n\n" +
+				"lodash@^4.17.0:\n  version \"4.17.21\"\n"},
+			map[string]string{"@babel/core": "7.23.2", "lodash": "4.17.21"},
+		},
+		{
+			"yarn berry",
+			map[string]string{"yarn.lock": "__metadata:\n  version: 6\n\n" +
+				"\"react@npm:^18.0.0\":\n  version: 18.2.0\n  resolution: \"react@npm:18.2.0\"\n"},
+			map[string]string{"react": "18.2.0"},
+		},
+		{
+			"pnpm v9",
+			map[string]string{"pnpm-lock.yaml": "lockfileVersion: '9.0'\nimporters:\n  .:\n    dependencies:\n" +
+				"      react-dom:\n        specifier: ^18.0.0\n        version: 18.2.0(react@18.2.0)\n" +
+				"      react:\n        specifier: ^18.0.0\n        version: 18.2.0\n"},
+			map[string]string{"react": "18.2.0", "react-dom": "18.2.0"},
+		},
+		{
+			"pnpm v5",
+			map[string]string{"pnpm-lock.yaml": "lockfileVersion: 5.4\ndependencies:\n  react: 17.0.2\n  react-dom: 17.0.2_react@17.0.2\n"},
+			map[string]string{"react": "17.0.2", "react-dom": "17.0.2"},
+		},
+		{"none", nil, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			testutil.WriteTree(t, root, tt.files)
+			if got := readNpmLock(root); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readNpmLock = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLockfiles(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"poetry.lock": "[[package]]\nname = \"Django\"\nversion = \"4.2.7\"\n\n[[package]]\nname = \"typing_extensions\"\nversion = \"4.8.0\"\n",
+		"Cargo.lock":  "version = 3\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.190\"\n\n[[package]]\nname = \"syn\"\nversion = \"1.0.109\"\n\n[[package]]\nname = \"syn\"\nversion = \"2.0.38\"\n",
+		"Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n  specs:\n    nokogiri (1.15.4-x86_64-linux)\n      racc (~> 1.4)\n    rails (7.1.2)\n\n" +
+			"PLATFORMS\n  x86_64-linux\n\nDEPENDENCIES\n  rails (~> 7.1)\n",
+		"composer.lock": `{"packages":[{"name":"monolog/monolog","version":"v3.5.0"},{"name":"symfony/console","version":"6.3.8"}]}`,
+	})
+
+	checks := []struct {
+		name string
+		got  map[string]string
+		want map[string]string
+	}{
+		{"poetry.lock", readPoetryLock(root), map[string]string{"django": "4.2.7", "typing-extensions": "4.8.0"}},
+		{"Cargo.lock", readTOMLPackages(filepath.Join(root, "Cargo.lock")), map[string]string{"serde": "1.0.190", "syn": "1.0.109"}},
+		{"Gemfile.lock", readGemfileLock(root), map[string]string{"nokogiri": "1.15.4", "rails": "7.1.2"}},
+		{"composer.lock", readComposerLock(root), map[string]string{"monolog/monolog": "3.5.0", "symfony/console": "6.3.8"}},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestAnalyzeDeps_PrefersLockedVersion(t *testing.T) {
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"info":{"version":"4.2.7"},"urls":[]}`))
+	})
+
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"requirements.txt": "Django>=4.0\n",
+		"poetry.lock":      "[[package]]\nname = \"django\"\nversion = \"4.2.7\"\n",
+	})
+	deps, err := (&PythonAnalyzer{}).AnalyzeDeps(reg, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].CurrentVersion != "4.2.7" || deps[0].Status != "current" {
+		t.Errorf("deps = %+v, want Django at the locked, current 4.2.7", deps)
+	}
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"go/scanner"
+	"go/token"
+	"math"
+	"os"
+	"sort"
+)
+
+// halsteadAnalyzer is implemented by languages that can tokenize source
+// precisely enough for Halstead metrics. Other languages report no
+// maintainability index.
+type halsteadAnalyzer interface {
+	halsteadVolume(path string) (float64, bool)
+}
+
+// halsteadVolume tokenizes a Go file and returns N * log2(n), where N counts
+// every operator and operand occurrence and n the distinct ones. Identifiers
+// and literals are operands; keywords, operators, and delimiters are
+// operators. Closing brackets are skipped so each pair counts once.
+func (g *GoAnalyzer) halsteadVolume(path string) (float64, bool) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(path, -1, len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+
+	distinct := make(map[string]bool)
+	total := 0
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch {
+		case tok == token.SEMICOLON && lit == "\n", // inserted automatically
+			tok == token.RPAREN, tok == token.RBRACK, tok == token.RBRACE,
+			tok == token.ILLEGAL:
+			continue
+		case tok == token.IDENT || tok.IsLiteral():
+			distinct["v:"+lit] = true
+		default:
+			distinct["o:"+tok.String()] = true
+		}
+		total++
+	}
+	if total == 0 || len(distinct) < 2 {
+		return 0, false
+	}
+	return float64(total) * math.Log2(float64(len(distinct))), true
+}
+
+// maintainabilityIndex combines Halstead volume, total cyclomatic complexity,
+// and line count into the classic index, rescaled to 0-100 as Visual Studio
+// does. Above 20 is generally maintainable; below 10 is hard to maintain.
+func maintainabilityIndex(volume float64, complexity, lines int) float64 {
+	if volume <= 0 || lines <= 0 {
+		return 100
+	}
+	mi := 171 - 5.2*math.Log(volume) - 0.23*float64(complexity) - 16.2*math.Log(float64(lines))
+	return math.Max(0, math.Min(100, mi*100/171))
+}
+
+// Maintainability returns the line-weighted average index over files that
+// support it, and false when none do.
+func (r *Results) Maintainability() (float64, bool) {
+	var sum float64
+	lines := 0
+	for _, f := range r.Files {
+		if f.MaintainabilityMeasured {
+			sum += f.Maintainability * float64(f.Lines)
+			lines += f.Lines
+		}
+	}
+	if lines == 0 {
+		return 0, false
+	}
+	return sum / float64(lines), true
+}
+
+// LeastMaintainable returns up to limit measured files, lowest index first.
+func LeastMaintainable(files []FileMetrics, limit int) []FileMetrics {
+	var out []FileMetrics
+	for _, f := range files {
+		if f.MaintainabilityMeasured {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Maintainability < out[j].Maintainability })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
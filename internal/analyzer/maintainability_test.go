@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestHalsteadVolume(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"add.go": "package x\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+	})
+
+	v, ok := (&GoAnalyzer{}).halsteadVolume(filepath.Join(root, "add.go"))
+	if !ok {
+		t.Fatal("halsteadVolume: not measured")
+	}
+	// Tokens: package x ; func add ( a , b int ) int { return a + b ; } ;
+	// Counted (closing brackets and newline semicolons skipped):
+	// package x func add ( a , b int int { return a + b = 15 tokens,
+	// distinct: package x func add ( a , b int { return + = 12.
+	want := 15 * math.Log2(12)
+	if math.Abs(v-want) > 1e-9 {
+		t.Errorf("volume = %v, want %v", v, want)
+	}
+
+	if _, ok := (&GoAnalyzer{}).halsteadVolume(filepath.Join(root, "missing.go")); ok {
+		t.Error("missing file should not be measured")
+	}
+}
+
+func TestMaintainabilityIndex(t *testing.T) {
+	small := maintainabilityIndex(100, 2, 20)
+	large := maintainabilityIndex(20000, 60, 800)
+	if small <= large {
+		t.Errorf("small file index %v should exceed large file index %v", small, large)
+	}
+	if small > 100 || large < 0 {
+		t.Errorf("index out of range: %v, %v", small, large)
+	}
+	if got := maintainabilityIndex(0, 0, 0); got != 100 {
+		t.Errorf("empty file index = %v, want 100", got)
+	}
+}
+
+func TestResultsMaintainability(t *testing.T) {
+	r := &Results{Files: []FileMetrics{
+		{Path: "a.go", Lines: 100, Maintainability: 60, MaintainabilityMeasured: true},
+		{Path: "b.go", Lines: 300, Maintainability: 20, MaintainabilityMeasured: true},
+		{Path: "c.py", Lines: 1000},
+	}}
+	mi, ok := r.Maintainability()
+	if !ok || mi != 30 {
+		t.Errorf("Maintainability() = %v, %v; want 30 (line-weighted), true", mi, ok)
+	}
+
+	least := LeastMaintainable(r.Files, 1)
+	if len(least) != 1 || least[0].Path != "b.go" {
+		t.Errorf("LeastMaintainable = %+v, want b.go", least)
+	}
+
+	if _, ok := (&Results{Files: []FileMetrics{{Lines: 10}}}).Maintainability(); ok {
+		t.Error("unmeasured files should not report an index")
+	}
+}
+
+func TestAnalyzeFiles_Maintainability(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"x.go": goFixture,
+		"x.py": "def f():\n    return 1\n" + strings.Repeat("\n", 3),
+	})
+
+	_, _, goMetrics, _ := analyzeFiles(&GoAnalyzer{}, root, []string{filepath.Join(root, "x.go")}, nil)
+	if !goMetrics[0].MaintainabilityMeasured || goMetrics[0].Maintainability <= 0 {
+		t.Errorf("Go file metrics = %+v, want a measured index", goMetrics[0])
+	}
+
+	_, _, pyMetrics, _ := analyzeFiles(&PythonAnalyzer{}, root, []string{filepath.Join(root, "x.py")}, nil)
+	if pyMetrics[0].MaintainabilityMeasured {
+		t.Error("Python files should not report a maintainability index")
+	}
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// NamingIssue is an exported function whose name breaks the language's
+// naming convention. These are low-severity and do not affect the score.
+type NamingIssue struct {
+	File     string
+	Name     string
+	Line     int
+	Rule     string // the regex the name matched
+	Language Language
+}
+
+// defaultNamingRules flag underscores in Go exported names and
+// SCREAMING_CASE function names elsewhere, plus mixedCase in the
+// snake_case languages.
+var defaultNamingRules = map[Language]string{
+	LangGo:         `_`,
+	LangTypeScript: `^[A-Z][A-Z0-9]*_[A-Z0-9_]*$`,
+	LangPython:     `^[A-Z0-9_]{2,}$|[a-z][A-Z]`,
+	LangRust:       `^[A-Z0-9_]{2,}$|[a-z][A-Z]`,
+	LangJava:       `^[A-Z][A-Z0-9]*_[A-Z0-9_]*$`,
+	LangRuby:       `^[A-Z0-9_]{2,}[?!]?$|[a-z][A-Z]`,
+	LangPHP:        `^[A-Z][A-Z0-9]*_[A-Z0-9_]*$`,
+	LangCSharp:     `^[A-Z][A-Z0-9]*_[A-Z0-9_]*$`,
+	LangSwift:      `^[A-Z][A-Z0-9]*_[A-Z0-9_]*$`,
+	LangElixir:     `^[A-Z0-9_]{2,}[?!]?$|[a-z][A-Z]`,
+}
+
+// namingRule returns the compiled rule for lang, or nil when the check is
+// off for it.
+func namingRule(cfg config.NamingConfig, lang Language) (*regexp.Regexp, error) {
+	expr, ok := cfg.Rules[string(lang)]
+	if !ok {
+		expr = defaultNamingRules[lang]
+	}
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("naming rule for %s: %w", lang, err)
+	}
+	return re, nil
+}
+
+// checkNaming applies rule to the exported functions in funcs. Method names
+// are checked without their receiver or class prefix.
+func checkNaming(funcs []FunctionComplexity, lang Language, rule *regexp.Regexp) []NamingIssue {
+	var issues []NamingIssue
+	for _, fn := range funcs {
+		name := fn.Name
+		if i := strings.LastIndexAny(name, ".#:"); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == "" || name == "anonymous" || !isPublicName(lang, name) {
+			continue
+		}
+		if rule.MatchString(name) {
+			issues = append(issues, NamingIssue{
+				File:     fn.File,
+				Name:     fn.Name,
+				Line:     fn.Line,
+				Rule:     rule.String(),
+				Language: lang,
+			})
+		}
+	}
+	return issues
+}
+
+// isPublicName reports whether name is part of the public API. Go uses
+// capitalization and the scripting languages a leading underscore; for the
+// rest every function is checked.
+func isPublicName(lang Language, name string) bool {
+	switch lang {
+	case LangGo:
+		return isExported(name)
+	case LangPython, LangRuby, LangElixir:
+		return !strings.HasPrefix(name, "_")
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+func TestCheckNaming_Defaults(t *testing.T) {
+	tests := []struct {
+		lang  Language
+		names []string
+		want  []string
+	}{
+		{LangGo, []string{"Do_Thing", "doThing_x", "Server.Handle_Req", "Fine"}, []string{"Do_Thing", "Server.Handle_Req"}},
+		{LangPython, []string{"parse_file", "parseFile", "_privateThing", "LOAD_ALL"}, []string{"parseFile", "LOAD_ALL"}},
+		{LangTypeScript, []string{"renderPage", "Button", "GET", "FETCH_ALL"}, []string{"FETCH_ALL"}},
+		{LangElixir, []string{"valid?", "SAVE!", "do_work"}, []string{"SAVE!"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.lang), func(t *testing.T) {
+			rule, err := namingRule(config.NamingConfig{Enabled: true}, tt.lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var funcs []FunctionComplexity
+			for _, n := range tt.names {
+				funcs = append(funcs, FunctionComplexity{File: "f", Name: n})
+			}
+			issues := checkNaming(funcs, tt.lang, rule)
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got %+v, want %v", issues, tt.want)
+			}
+			for i, issue := range issues {
+				if issue.Name != tt.want[i] {
+					t.Errorf("issue %d = %q, want %q", i, issue.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNamingRule_Override(t *testing.T) {
+	cfg := config.NamingConfig{Rules: map[string]string{"go": "^Get", "python": ""}}
+
+	rule, err := namingRule(cfg, LangGo)
+	if err != nil || rule == nil || !rule.MatchString("GetName") {
+		t.Errorf("go override not applied: %v, %v", rule, err)
+	}
+	if rule, _ := namingRule(cfg, LangPython); rule != nil {
+		t.Errorf("empty rule should disable the check, got %v", rule)
+	}
+	if _, err := namingRule(config.NamingConfig{Rules: map[string]string{"go": "("}}, LangGo); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
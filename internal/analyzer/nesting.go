@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// calcNesting returns the deepest level of nested control flow in a Go
+// function body: a statement inside one if is at depth 1, inside an if in a
+// for at depth 2, and so on. An else-if chain stays at the depth of its first
+// if, and closures count as a level of their own.
+func calcNesting(body *ast.BlockStmt) int {
+	if body == nil {
+		return 0
+	}
+	v := &nestingVisitor{}
+	for _, stmt := range body.List {
+		ast.Walk(v, stmt)
+	}
+	return v.max
+}
+
+type nestingVisitor struct {
+	depth int
+	max   int
+}
+
+func (v *nestingVisitor) nested(body *ast.BlockStmt) {
+	inner := &nestingVisitor{depth: v.depth + 1, max: v.depth + 1}
+	ast.Walk(inner, body)
+	if inner.max > v.max {
+		v.max = inner.max
+	}
+}
+
+func (v *nestingVisitor) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.IfStmt:
+		v.nested(n.Body)
+		switch els := n.Else.(type) {
+		case *ast.IfStmt:
+			ast.Walk(v, els)
+		case *ast.BlockStmt:
+			v.nested(els)
+		}
+		return nil
+	case *ast.ForStmt:
+		v.nested(n.Body)
+		return nil
+	case *ast.RangeStmt:
+		v.nested(n.Body)
+		return nil
+	case *ast.SwitchStmt:
+		v.nested(n.Body)
+		return nil
+	case *ast.TypeSwitchStmt:
+		v.nested(n.Body)
+		return nil
+	case *ast.SelectStmt:
+		v.nested(n.Body)
+		return nil
+	case *ast.FuncLit:
+		v.nested(n.Body)
+		return nil
+	}
+	return v
+}
+
+// indentNesting estimates nesting for languages without an AST: the deepest
+// indentation inside the function, in units of the first body level, minus
+// one so top-level statements are depth 0. Blank and comment lines are
+// ignored; tabs count as four spaces.
+func indentNesting(fnLines []string) int {
+	if len(fnLines) < 2 {
+		return 0
+	}
+	base := indentWidth(fnLines[0])
+
+	var indents []int
+	unit := 0
+	for _, line := range fnLines[1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || isCommentLine(trimmed) {
+			continue
+		}
+		rel := indentWidth(line) - base
+		if rel <= 0 {
+			continue
+		}
+		indents = append(indents, rel)
+		if unit == 0 || rel < unit {
+			unit = rel
+		}
+	}
+
+	depth := 0
+	for _, rel := range indents {
+		if d := rel/unit - 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func indentWidth(line string) int {
+	w := 0
+	for _, ch := range line {
+		switch ch {
+		case ' ':
+			w++
+		case '\t':
+			w += 4
+		default:
+			return w
+		}
+	}
+	return w
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestCalcNesting(t *testing.T) {
+	src := `package x
+
+func flat() { x := 1; _ = x }
+
+func one(x int) {
+	if x > 0 {
+		return
+	} else if x < 0 {
+		return
+	} else {
+		x++
+	}
+}
+
+func three(xs []int) {
+	for _, x := range xs {
+		switch {
+		case x > 0:
+			if x > 10 {
+				println(x)
+			}
+		}
+	}
+}
+
+func closure() {
+	go func() {
+		for {
+		}
+	}()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "x.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"flat": 0, "one": 1, "three": 3, "closure": 2}
+	for _, fc := range analyzeComplexity(fset, f, "x.go") {
+		if fc.Nesting != want[fc.Name] {
+			t.Errorf("%s: Nesting = %d, want %d", fc.Name, fc.Nesting, want[fc.Name])
+		}
+	}
+}
+
+func TestIndentNesting(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int
+	}{
+		{"flat", "def f():\n    return 1\n", 0},
+		{"python two levels", "def f(xs):\n    for x in xs:\n        if x:\n            # note\n            print(x)\n", 2},
+		{"tabs", "function f() {\n\tif (a) {\n\t\tif (b) {\n\t\t\tgo()\n\t\t}\n\t}\n}\n", 2},
+		{"indented method", "  def f\n    if a\n      b\n    end\n  end\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indentNesting(strings.Split(tt.src, "\n")); got != tt.want {
+				t.Errorf("indentNesting = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// OverExported is an exported package-level identifier that only its own
+// package refers to, a candidate for unexporting. Identifiers nothing refers
+// to are dead code instead.
+type OverExported struct {
+	File    string
+	Package string // import path
+	Name    string
+	Line    int
+	Kind    string // "function", "type", "const", or "var"
+	Uses    int    // references from within the package
+}
+
+// overExportAnalyzer is implemented by languages whose type information
+// tells which package each reference comes from.
+type overExportAnalyzer interface {
+	analyzeOverExported(files []string) []OverExported
+}
+
+func (g *GoAnalyzer) analyzeOverExported(files []string) []OverExported {
+	pkgs, typed, _, untyped := g.partition(files)
+	if len(typed) == 0 {
+		return nil
+	}
+	over := findOverExported(pkgs, typed)
+	if len(untyped) == 0 {
+		return over
+	}
+	// Packages that fail to type-check may still refer to these names.
+	mentioned := mentionedNames(parseGoFiles(token.NewFileSet(), untyped))
+	return slices.DeleteFunc(over, func(o OverExported) bool { return mentioned[o.Name] })
+}
+
+// findOverExported reports the exported functions, types, consts, and vars
+// declared in files that are referenced, but only from their own package.
+// A type also counts as used elsewhere when it appears in the type of
+// something another package refers to, since unexporting it would leave that
+// API returning or accepting an unexported type. Package main is skipped, as
+// nothing can import it. Only non-test packages are loaded, so identifiers
+// exported for another package's tests are reported too.
+func findOverExported(pkgs []*packages.Package, files []string) []OverExported {
+	inScope := make(map[string]bool, len(files))
+	for _, f := range files {
+		inScope[f] = true
+	}
+
+	internalUses := make(map[types.Object]int)
+	external := make(map[types.Object]bool)
+	for _, pkg := range pkgs {
+		for _, obj := range pkg.TypesInfo.Uses {
+			obj = objectOrigin(obj)
+			if obj.Pkg() == nil {
+				continue
+			}
+			if obj.Pkg() == pkg.Types {
+				internalUses[obj]++
+				continue
+			}
+			external[obj] = true
+			markExposedTypes(obj.Type(), external, make(map[types.Type]bool))
+		}
+	}
+
+	var over []OverExported
+	for _, pkg := range pkgs {
+		if pkg.Name == "main" {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			if !inScope[syntaxPath(pkg, f)] {
+				continue
+			}
+			for _, decl := range f.Decls {
+				var ids []*ast.Ident
+				kind := "function"
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv != nil {
+						continue // methods may be needed to satisfy interfaces
+					}
+					ids = []*ast.Ident{d.Name}
+				case *ast.GenDecl:
+					kind = d.Tok.String()
+					for _, spec := range d.Specs {
+						ids = append(ids, specNames(spec)...)
+					}
+				}
+				for _, id := range ids {
+					obj := pkg.TypesInfo.Defs[id]
+					if !isExported(id.Name) || obj == nil || external[obj] || internalUses[obj] == 0 {
+						continue
+					}
+					pos := pkg.Fset.Position(id.Pos())
+					over = append(over, OverExported{
+						File:    filepath.Base(pos.Filename),
+						Package: pkg.PkgPath,
+						Name:    id.Name,
+						Line:    pos.Line,
+						Kind:    kind,
+						Uses:    internalUses[obj],
+					})
+				}
+			}
+		}
+	}
+	return over
+}
+
+// objectOrigin maps references to instantiated generic functions and their
+// fields back to the declared object.
+func objectOrigin(obj types.Object) types.Object {
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Origin()
+	case *types.Var:
+		return o.Origin()
+	}
+	return obj
+}
+
+// markExposedTypes marks the named types that make up t as used outside
+// their package. It doesn't descend into a named type's own definition:
+// whatever of it other packages touch shows up among their references.
+func markExposedTypes(t types.Type, external map[types.Object]bool, seen map[types.Type]bool) {
+	if t == nil || seen[t] {
+		return
+	}
+	seen[t] = true
+	switch t := t.(type) {
+	case *types.Named:
+		external[t.Origin().Obj()] = true
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			markExposedTypes(t.TypeArgs().At(i), external, seen)
+		}
+	case *types.Alias:
+		external[t.Obj()] = true
+		markExposedTypes(types.Unalias(t), external, seen)
+	case *types.Pointer:
+		markExposedTypes(t.Elem(), external, seen)
+	case *types.Slice:
+		markExposedTypes(t.Elem(), external, seen)
+	case *types.Array:
+		markExposedTypes(t.Elem(), external, seen)
+	case *types.Chan:
+		markExposedTypes(t.Elem(), external, seen)
+	case *types.Map:
+		markExposedTypes(t.Key(), external, seen)
+		markExposedTypes(t.Elem(), external, seen)
+	case *types.Signature:
+		if t.Recv() != nil {
+			markExposedTypes(t.Recv().Type(), external, seen)
+		}
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				markExposedTypes(tuple.At(i).Type(), external, seen)
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			markExposedTypes(t.Field(i).Type(), external, seen)
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			markExposedTypes(t.Method(i).Type(), external, seen)
+		}
+	}
+}
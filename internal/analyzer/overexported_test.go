@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestGoAnalyzer_OverExported(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"store/store.go": `package store
+
+// Options is never named outside, but Open accepts it.
+type Options struct{ Path string }
+
+type entry struct{ key Key }
+
+type Key string
+
+const MaxKeys = 64
+
+var ErrFull = errNew("full")
+
+func Open(o Options) *Store { return &Store{path: o.Path, cap: MaxKeys} }
+
+type Store struct {
+	path string
+	cap  int
+}
+
+func (s *Store) Put(k Key) error { _ = entry{key: k}; return ErrFull }
+
+func errNew(s string) error { return nil }
+
+func Unused() {}
+`,
+		"main.go": `package main
+
+import "example.com/m/store"
+
+func main() { _ = store.Open(store.Options{}).Put("k") }
+`,
+	})
+
+	g := &GoAnalyzer{}
+	files, err := g.FindFiles(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.loaded() == nil {
+		t.Skip("go/packages unavailable (no go toolchain?)")
+	}
+
+	got := make(map[string]string)
+	for _, o := range g.analyzeOverExported(files) {
+		got[o.Name] = o.Kind
+	}
+	// Store and Key reach main only through the signatures of Open and Put;
+	// Unused is dead code.
+	want := map[string]string{"MaxKeys": "const", "ErrFull": "var"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("over-exported = %v, want %v", got, want)
+	}
+}
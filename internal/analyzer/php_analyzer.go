@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -18,9 +19,7 @@ func (p *PHPAnalyzer) Language() Language { return LangPHP }
 func (p *PHPAnalyzer) Extensions() []string { return []string{".php"} }
 
 func (p *PHPAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "vendor", "cache", ".phpunit.cache", "storage")
-	skip := []string{"Test.php", "Tests.php", "test/", "tests/"}
-	return walkFiles(root, exclude, p.Extensions(), skip)
+	return findLanguageFiles(p, root, exclude)
 }
 
 var phpFuncPattern = regexp.MustCompile(
@@ -51,7 +50,7 @@ func (p *PHPAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity, i
 	return results, len(results)
 }
 
-func (p *PHPAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (p *PHPAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	composerPath := filepath.Join(root, "composer.json")
 	data, err := os.ReadFile(composerPath)
 	if err != nil {
@@ -59,56 +58,67 @@ func (p *PHPAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 	}
 
 	var composer struct {
-		Require map[string]string `json:"require"`
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
 	}
 	if err := json.Unmarshal(data, &composer); err != nil {
 		return nil, fmt.Errorf("parsing composer.json: %w", err)
 	}
 
-	var results []DepStatus
-	for name, version := range composer.Require {
-		// Skip PHP version and extensions
-		if name == "php" || strings.HasPrefix(name, "ext-") {
-			continue
-		}
+	locked := readComposerLock(root)
 
-		cleanVersion := strings.TrimLeft(version, "^~>=<! ")
-
-		dep := DepStatus{
-			Module:         name,
-			CurrentVersion: cleanVersion,
-		}
+	var results []DepStatus
+	var lookups []func(*DepStatus)
+	for scope, require := range map[string]map[string]string{"": composer.Require, "dev": composer.RequireDev} {
+		for name, version := range require {
+			// Skip PHP version and extensions
+			if name == "php" || strings.HasPrefix(name, "ext-") {
+				continue
+			}
 
-		latest, err := fetchPackagistLatest(name)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
-				dep.Status = "current"
-			} else {
-				dep.StaleDays = 30
-				dep.Status = "stale"
+			cleanVersion := strings.TrimLeft(version, "^~>=<! ")
+			if v, ok := locked[name]; ok {
+				cleanVersion = v
 			}
-		}
 
-		results = append(results, dep)
+			results = append(results, DepStatus{
+				Module:         name,
+				CurrentVersion: cleanVersion,
+				Scope:          scope,
+			})
+			lookups = append(lookups, func(dep *DepStatus) {
+				latest, published, err := fetchPackagistLatest(reg, name)
+				if err != nil {
+					dep.Status = "unknown"
+					dep.LatestVersion = "?"
+				} else {
+					dep.LatestVersion = latest
+					if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
+						dep.Status = "current"
+					} else {
+						markBehind(dep, published)
+					}
+				}
+			})
+		}
 	}
+	resolveLatest(reg, LangPHP, results, lookups)
 	return results, nil
 }
 
 type packagistResponse struct {
 	Packages map[string][]struct {
-		Version string `json:"version"`
+		Version string    `json:"version"`
+		Time    time.Time `json:"time"`
+		License []string  `json:"license"`
 	} `json:"packages"`
 }
 
-func fetchPackagistLatest(name string) (string, error) {
+func fetchPackagistLatest(reg *registryClient, name string) (string, time.Time, error) {
 	var resp packagistResponse
 	url := fmt.Sprintf("https://repo.packagist.org/p2/%s.json", name)
-	if err := fetchJSON(url, &resp, ""); err != nil {
-		return "", err
+	if err := reg.fetchJSON(url, &resp, ""); err != nil {
+		return "", time.Time{}, err
 	}
 
 	versions := resp.Packages[name]
@@ -118,13 +128,13 @@ func fetchPackagistLatest(name string) (string, error) {
 			continue
 		}
 		// Return first stable version (they're sorted newest first)
-		return strings.TrimPrefix(v.Version, "v"), nil
+		return strings.TrimPrefix(v.Version, "v"), v.Time, nil
 	}
 
 	if len(versions) > 0 {
-		return strings.TrimPrefix(versions[0].Version, "v"), nil
+		return strings.TrimPrefix(versions[0].Version, "v"), versions[0].Time, nil
 	}
-	return "", fmt.Errorf("no versions found")
+	return "", time.Time{}, fmt.Errorf("no versions found")
 }
 
 var phpImportPatterns = []*regexp.Regexp{
@@ -0,0 +1,255 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// Project is one independently analyzed unit of a monorepo: a directory with
+// its own manifest (go.mod, package.json, Cargo.toml, ...).
+type Project struct {
+	Name     string
+	Path     string // absolute
+	Language Language
+}
+
+// ProjectResults holds the analysis of a single project in monorepo mode.
+type ProjectResults struct {
+	Project Project
+	Results *Results
+}
+
+// DiscoverProjects walks root and returns every directory that contains a
+// recognized manifest, root included. Excluded directory names are skipped.
+func DiscoverProjects(root string, exclude []string) []Project {
+	var projects []Project
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if path != root && config.Excluded(exclude, relPath(root, path)) {
+			return filepath.SkipDir
+		}
+		if lang := DetectLanguage(path); lang != LangUnknown {
+			projects = append(projects, Project{
+				Name:     projectName(root, path),
+				Path:     path,
+				Language: lang,
+			})
+		}
+		return nil
+	})
+	return projects
+}
+
+// configuredProjects resolves the `projects` list, falling back to discovery
+// when `discover_projects` is set. It returns nil outside monorepo mode.
+func configuredProjects(cfg *config.Config) []Project {
+	if len(cfg.Projects) == 0 {
+		if cfg.DiscoverProjects {
+			return DiscoverProjects(cfg.Root, cfg.Exclude)
+		}
+		return nil
+	}
+
+	var projects []Project
+	for _, pc := range cfg.Projects {
+		path := pc.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.Root, path)
+		}
+		lang := Language(pc.Language)
+		if lang == "" {
+			lang = DetectLanguage(path)
+		}
+		name := pc.Name
+		if name == "" {
+			name = projectName(cfg.Root, path)
+		}
+		projects = append(projects, Project{Name: name, Path: path, Language: lang})
+	}
+	return projects
+}
+
+func projectName(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// runProjects analyzes each project separately and merges the findings so the
+// overall score still covers the whole repository. Files belonging to a
+// nested project are only counted once, by the innermost project.
+func (a *Analyzer) runProjects() (*Results, error) {
+	results := a.newResults()
+	results.Languages = nil
+
+	seen := make(map[Language]bool)
+	for _, p := range a.projects {
+		subCfg := *a.cfg
+		subCfg.Root = p.Path
+		subCfg.Language = string(p.Language)
+		subCfg.Languages = nil
+		subCfg.Projects = nil
+		subCfg.DiscoverProjects = false
+		subCfg.Coverage.Path = "" // an explicit report covers the whole repo
+
+		sub := New(&subCfg)
+		sub.skipDirs = a.nestedProjectDirs(p)
+		sub.includeRoot = a.includeRoot
+		if a.progress != nil {
+			sub.OnProgress(func(pr Progress) {
+				pr.Phase = p.Name + ": " + pr.Phase
+				a.progress(pr)
+			})
+		}
+		r, err := sub.Run()
+		if err != nil {
+			return nil, err
+		}
+		results.Projects = append(results.Projects, ProjectResults{Project: p, Results: r})
+
+		for _, fc := range r.Complexity {
+			fc.Path = relPath(a.cfg.Root, filepath.Join(p.Path, fc.Path))
+			results.Complexity = append(results.Complexity, fc)
+		}
+		results.Dependencies = append(results.Dependencies, r.Dependencies...)
+		results.Vulnerabilities = append(results.Vulnerabilities, r.Vulnerabilities...)
+		results.LicenseViolations = append(results.LicenseViolations, r.LicenseViolations...)
+		results.Violations = append(results.Violations, r.Violations...)
+		results.SuppressedViolations = append(results.SuppressedViolations, r.SuppressedViolations...)
+		for _, d := range r.DeadCode {
+			d.Path = relPath(a.cfg.Root, filepath.Join(p.Path, d.Path))
+			results.DeadCode = append(results.DeadCode, d)
+		}
+		results.OverExported = append(results.OverExported, r.OverExported...)
+		results.Naming = append(results.Naming, r.Naming...)
+		for _, f := range r.Files {
+			f.Path = relPath(a.cfg.Root, filepath.Join(p.Path, f.Path))
+			results.Files = append(results.Files, f)
+		}
+		for _, pc := range r.Coupling {
+			pc.Package = relPath(a.cfg.Root, filepath.Join(p.Path, pc.Package))
+			results.Coupling = append(results.Coupling, pc)
+		}
+		for _, c := range r.Cycles {
+			pkgs := make([]string, len(c.Packages))
+			for i, pkg := range c.Packages {
+				pkgs[i] = relPath(a.cfg.Root, filepath.Join(p.Path, pkg))
+			}
+			c.Packages = pkgs
+			results.Cycles = append(results.Cycles, c)
+		}
+		for _, ts := range r.Types {
+			ts.Path = relPath(a.cfg.Root, filepath.Join(p.Path, ts.Path))
+			results.Types = append(results.Types, ts)
+		}
+		for _, pt := range r.Tests {
+			pt.Package = relPath(a.cfg.Root, filepath.Join(p.Path, pt.Package))
+			results.Tests = append(results.Tests, pt)
+		}
+		for _, d := range r.Debt {
+			d.Path = relPath(a.cfg.Root, filepath.Join(p.Path, d.Path))
+			results.Debt = append(results.Debt, d)
+		}
+		for _, n := range r.Notices {
+			n.Path = relPath(a.cfg.Root, filepath.Join(p.Path, n.Path))
+			results.Notices = append(results.Notices, n)
+		}
+		for _, d := range r.Duplicates {
+			for i := range d.Locations {
+				d.Locations[i].Path = relPath(a.cfg.Root, filepath.Join(p.Path, d.Locations[i].Path))
+			}
+			results.Duplicates = append(results.Duplicates, d)
+		}
+		results.TransitiveDeps += r.TransitiveDeps
+		results.FileCount += r.FileCount
+		results.FuncCount += r.FuncCount
+		results.ExportedFuncs += r.ExportedFuncs
+		if !seen[p.Language] {
+			seen[p.Language] = true
+			results.Languages = append(results.Languages, p.Language)
+		}
+	}
+	if len(results.Languages) > 0 {
+		results.Language = results.Languages[0]
+	}
+	sortVulnerabilities(results.Vulnerabilities)
+	results.Coverage = a.projectCoverage(results.Projects)
+
+	sortComplexityDesc(results.Complexity)
+
+	return results, nil
+}
+
+// projectCoverage prefers an explicitly configured report, then the merged
+// coverage of every project that has one, then a report at the repo root.
+func (a *Analyzer) projectCoverage(projects []ProjectResults) Coverage {
+	if a.cfg.Coverage.Path != "" {
+		return loadCoverage(a.cfg.Root, a.cfg.Coverage)
+	}
+	var covs []Coverage
+	for _, p := range projects {
+		covs = append(covs, p.Results.Coverage)
+	}
+	if cov := mergeCoverage(covs...); cov.Measured {
+		return cov
+	}
+	return readCoverage(a.cfg.Root)
+}
+
+// nestedProjectDirs lists the other projects that live inside p.
+func (a *Analyzer) nestedProjectDirs(p Project) []string {
+	var dirs []string
+	for _, other := range a.projects {
+		if other.Path != p.Path && isWithin(other.Path, p.Path) {
+			dirs = append(dirs, other.Path)
+		}
+	}
+	return dirs
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// withoutSkipped drops files that belong to a nested project.
+func (a *Analyzer) withoutSkipped(files []string) []string {
+	if len(a.skipDirs) == 0 {
+		return files
+	}
+	kept := files[:0]
+	for _, f := range files {
+		skip := false
+		for _, dir := range a.skipDirs {
+			if isWithin(f, dir) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// included drops files that match none of the configured include globs.
+func (a *Analyzer) included(files []string) []string {
+	if len(a.cfg.Include) == 0 {
+		return files
+	}
+	kept := files[:0]
+	for _, f := range files {
+		if config.Included(a.cfg.Include, relPath(a.includeRoot, f)) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -18,9 +19,7 @@ func (p *PythonAnalyzer) Language() Language { return LangPython }
 func (p *PythonAnalyzer) Extensions() []string { return []string{".py"} }
 
 func (p *PythonAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "__pycache__", ".venv", "venv", "env", ".tox", ".eggs", ".mypy_cache")
-	skip := []string{"test_", "_test.py", "conftest.py"}
-	return walkFiles(root, exclude, p.Extensions(), skip)
+	return findLanguageFiles(p, root, exclude)
 }
 
 var pyFuncPattern = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)\s*\(`)
@@ -75,6 +74,7 @@ func analyzePythonComplexity(path string) []FunctionComplexity {
 		}
 
 		complexity := 1
+		last := i
 		for j := i + 1; j < len(lines); j++ {
 			bodyLine := lines[j]
 			if strings.TrimSpace(bodyLine) == "" {
@@ -85,6 +85,7 @@ func analyzePythonComplexity(path string) []FunctionComplexity {
 			if bodyIndent <= indent && strings.TrimSpace(bodyLine) != "" {
 				break
 			}
+			last = j
 
 			for _, p := range pyComplexityPatterns {
 				if p.pattern.MatchString(bodyLine) {
@@ -98,29 +99,40 @@ func analyzePythonComplexity(path string) []FunctionComplexity {
 			Name:       name,
 			Line:       i + 1,
 			Complexity: complexity,
+			Lines:      last - i + 1,
+			Params:     countParams(signatureText(lines, i)),
+			Nesting:    indentNesting(lines[i : last+1]),
 		})
 	}
 
 	return results
 }
 
-func (p *PythonAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
-	// Try requirements.txt first
+func (p *PythonAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
+	// Try requirements.txt first, with requirements-dev.txt as dev deps
 	reqPath := filepath.Join(root, "requirements.txt")
 	if _, err := os.Stat(reqPath); err == nil {
-		return parsePythonRequirements(reqPath)
+		locked := readPoetryLock(root)
+		deps, err := parsePythonRequirements(reg, reqPath, locked, "")
+		if err != nil {
+			return nil, err
+		}
+		if dev, err := parsePythonRequirements(reg, filepath.Join(root, "requirements-dev.txt"), locked, "dev"); err == nil {
+			deps = append(deps, dev...)
+		}
+		return deps, nil
 	}
 
 	// Try pyproject.toml
 	pyprojectPath := filepath.Join(root, "pyproject.toml")
 	if _, err := os.Stat(pyprojectPath); err == nil {
-		return parsePyproject(pyprojectPath)
+		return parsePyproject(reg, pyprojectPath, readPoetryLock(root))
 	}
 
-	return nil, fmt.Errorf("no Python dependency file found")
+	return nil, errNoManifest
 }
 
-func parsePythonRequirements(path string) ([]DepStatus, error) {
+func parsePythonRequirements(reg *registryClient, path string, locked map[string]string, scope string) ([]DepStatus, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -128,6 +140,7 @@ func parsePythonRequirements(path string) ([]DepStatus, error) {
 	defer f.Close()
 
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -146,32 +159,38 @@ func parsePythonRequirements(path string) ([]DepStatus, error) {
 		if name == "" {
 			name = line
 		}
+		if v, ok := locked[normalizePyName(name)]; ok {
+			version = v
+		}
 
-		dep := DepStatus{
+		results = append(results, DepStatus{
 			Module:         name,
 			CurrentVersion: version,
-		}
-
-		latest, err := fetchPyPILatest(name)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
-				dep.Status = "current"
+			Scope:          scope,
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchPyPILatest(reg, name)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
 			} else {
-				dep.StaleDays = 30
-				dep.Status = "stale"
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
 			}
-		}
-
-		results = append(results, dep)
+		})
 	}
+	resolveLatest(reg, LangPython, results, lookups)
 	return results, nil
 }
 
-func parsePyproject(path string) ([]DepStatus, error) {
+// poetryDevTable matches Poetry's legacy dev table and its dependency groups.
+var poetryDevTable = regexp.MustCompile(`^\[tool\.poetry\.(?:dev-dependencies|group\.[\w-]+\.dependencies)\]$`)
+
+func parsePyproject(reg *registryClient, path string, locked map[string]string) ([]DepStatus, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -179,14 +198,20 @@ func parsePyproject(path string) ([]DepStatus, error) {
 	defer f.Close()
 
 	var results []DepStatus
-	inDeps := false
+	var lookups []func(*DepStatus)
+	inDeps, inTable := false, false
+	scope := ""
 	scanner := bufio.NewScanner(f)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
 		if line == "dependencies = [" || line == "[tool.poetry.dependencies]" {
-			inDeps = true
+			inDeps, inTable, scope = true, strings.HasPrefix(line, "["), ""
+			continue
+		}
+		if poetryDevTable.MatchString(line) {
+			inDeps, inTable, scope = true, true, "dev"
 			continue
 		}
 		if inDeps && (strings.HasPrefix(line, "[") || line == "]") {
@@ -204,9 +229,13 @@ func parsePyproject(path string) ([]DepStatus, error) {
 		}
 
 		name := line
+		if inTable { // poetry tables: name = "^1.2" or name = { version = ... }
+			name, _, _ = strings.Cut(line, "=")
+			name = strings.TrimSpace(name)
+		}
 		for _, sep := range []string{">=", "==", "~=", "^"} {
-			if idx := strings.Index(line, sep); idx > 0 {
-				name = strings.TrimSpace(line[:idx])
+			if idx := strings.Index(name, sep); idx > 0 {
+				name = strings.TrimSpace(name[:idx])
 				break
 			}
 		}
@@ -215,17 +244,25 @@ func parsePyproject(path string) ([]DepStatus, error) {
 			continue
 		}
 
-		dep := DepStatus{Module: name, CurrentVersion: ""}
-		latest, err := fetchPyPILatest(name)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			dep.Status = "current"
-		}
-		results = append(results, dep)
+		// Without poetry.lock only the name is known, so the dependency
+		// counts as current.
+		results = append(results, DepStatus{Module: name, CurrentVersion: locked[normalizePyName(name)], Scope: scope})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchPyPILatest(reg, name)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
+			} else {
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
+			}
+		})
 	}
+	resolveLatest(reg, LangPython, results, lookups)
 	return results, nil
 }
 
@@ -233,15 +270,23 @@ type pypiInfo struct {
 	Info struct {
 		Version string `json:"version"`
 	} `json:"info"`
+	// URLs lists the files of the latest release.
+	URLs []struct {
+		UploadTime time.Time `json:"upload_time_iso_8601"`
+	} `json:"urls"`
 }
 
-func fetchPyPILatest(pkg string) (string, error) {
+func fetchPyPILatest(reg *registryClient, pkg string) (string, time.Time, error) {
 	var info pypiInfo
 	url := fmt.Sprintf("https://pypi.org/pypi/%s/json", pkg)
-	if err := fetchJSON(url, &info, ""); err != nil {
-		return "", err
+	if err := reg.fetchJSON(url, &info, ""); err != nil {
+		return "", time.Time{}, err
+	}
+	var published time.Time
+	if len(info.URLs) > 0 {
+		published = info.URLs[0].UploadTime
 	}
-	return info.Info.Version, nil
+	return info.Info.Version, published, nil
 }
 
 var pyImportPatterns = []*regexp.Regexp{
@@ -0,0 +1,238 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// maxRegistryRequests bounds how many registry lookups run at once.
+const maxRegistryRequests = 8
+
+// registryCache keeps successful registry responses on disk, so repeated
+// runs, the watch loop, and history snapshots reuse them instead of querying
+// npm, PyPI, crates.io, and the rest again. One cache is shared by every
+// Analyzer in the process.
+type registryCache struct {
+	http *http.Client
+	path string // cache file; empty keeps the cache in memory only
+
+	mu      sync.Mutex
+	entries map[string]registryEntry
+	loaded  bool
+	dirty   bool
+}
+
+type registryEntry struct {
+	Body    json.RawMessage `json:"body"`
+	Fetched time.Time       `json:"fetched"`
+}
+
+var sharedRegistryCache = newRegistryCache(defaultRegistryCachePath())
+
+func newRegistryCache(path string) *registryCache {
+	return &registryCache{
+		http: &http.Client{Timeout: 5 * time.Second},
+		path: path,
+	}
+}
+
+func defaultRegistryCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "drift", "registry.json")
+}
+
+// registryClient fetches package metadata from the language registries
+// through a registryCache, with the settings of one Analyzer. Responses stay
+// fresh for ttl; zero or less turns caching off.
+type registryClient struct {
+	cache   *registryCache
+	ttl     time.Duration
+	depsDev bool // take freshness and advisories from deps.dev where it covers the ecosystem
+	offline bool // answer from the cache only, however old
+}
+
+// newRegistry returns the client for cfg's dependency settings.
+func newRegistry(cfg *config.Config) *registryClient {
+	return &registryClient{
+		cache:   sharedRegistryCache,
+		ttl:     time.Duration(cfg.Deps.CacheTTL) * time.Hour,
+		depsDev: cfg.Deps.Source == "deps.dev",
+		offline: cfg.Offline,
+	}
+}
+
+// errOffline fails lookups that aren't cached while offline.
+var errOffline = errors.New("offline, and not in the registry cache")
+
+// fetchJSON decodes the JSON document at url into target, from the cache
+// when a fresh copy is there.
+func (c *registryClient) fetchJSON(url string, target interface{}, userAgent string) error {
+	if body, ok := c.cached(url); ok {
+		return json.Unmarshal(body, target)
+	}
+	if c.offline {
+		return errOffline
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return c.do(req, url, target)
+}
+
+// postJSON sends payload as a JSON request body and decodes the response
+// into target. Responses are cached per URL and payload.
+func (c *registryClient) postJSON(url string, payload, target interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	key := url + "\x00" + string(body)
+	if cached, ok := c.cached(key); ok {
+		return json.Unmarshal(cached, target)
+	}
+	if c.offline {
+		return errOffline
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, key, target)
+}
+
+// do sends req and decodes a successful response into target, caching the
+// body under key.
+func (c *registryClient) do(req *http.Request, key string, target interface{}) error {
+	resp, err := c.cache.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return err
+	}
+	if c.ttl > 0 {
+		c.cache.store(key, body)
+	}
+	return nil
+}
+
+func (c *registryClient) cached(url string) ([]byte, bool) {
+	if c.ttl <= 0 && !c.offline {
+		return nil, false
+	}
+	e, ok := c.cache.get(url)
+	if !ok || (time.Since(e.Fetched) > c.ttl && !c.offline) {
+		return nil, false
+	}
+	return e.Body, true
+}
+
+// save writes new responses back to disk, dropping those older than c's
+// ttl.
+func (c *registryClient) save() error {
+	return c.cache.save(c.ttl)
+}
+
+func (c *registryCache) get(url string) (registryEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *registryCache) store(url string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	c.entries[url] = registryEntry{Body: body, Fetched: time.Now()}
+	c.dirty = true
+}
+
+// load reads the cache file once. A missing or corrupt file starts an empty
+// cache. Callers hold c.mu.
+func (c *registryCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]registryEntry)
+	if c.path == "" {
+		return
+	}
+	if data, err := os.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+}
+
+func (c *registryCache) save(ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+	for url, e := range c.entries {
+		if time.Since(e.Fetched) > ttl {
+			delete(c.entries, url)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// resolveDeps runs lookups[i] against deps[i], with up to
+// maxRegistryRequests lookups in flight. Each lookup fills in the latest
+// version and status of its dependency.
+func resolveDeps(deps []DepStatus, lookups []func(dep *DepStatus)) {
+	sem := make(chan struct{}, maxRegistryRequests)
+	var wg sync.WaitGroup
+	for i := range deps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			lookups[i](&deps[i])
+		}()
+	}
+	wg.Wait()
+}
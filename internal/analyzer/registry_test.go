@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistryClient_Cache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"version":"1.2.3"}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "registry.json")
+	c := &registryClient{cache: newRegistryCache(path), ttl: time.Hour}
+
+	var info struct{ Version string }
+	for range 2 {
+		if err := c.fetchJSON(srv.URL+"/pkg", &info, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if info.Version != "1.2.3" || hits.Load() != 1 {
+		t.Errorf("version %q after %d requests, want 1.2.3 after 1", info.Version, hits.Load())
+	}
+
+	// Failures are not cached.
+	for range 2 {
+		if err := c.fetchJSON(srv.URL+"/missing", &info, ""); err == nil {
+			t.Error("expected an error for a 404")
+		}
+	}
+	if hits.Load() != 3 {
+		t.Errorf("hits = %d, want 3", hits.Load())
+	}
+
+	// A new process reads the saved cache.
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	fresh := &registryClient{cache: newRegistryCache(path), ttl: time.Hour}
+	if err := fresh.fetchJSON(srv.URL+"/pkg", &info, ""); err != nil {
+		t.Fatal(err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("saved cache not reused: hits = %d", hits.Load())
+	}
+
+	// A zero TTL always refetches.
+	fresh.ttl = 0
+	if err := fresh.fetchJSON(srv.URL+"/pkg", &info, ""); err != nil {
+		t.Fatal(err)
+	}
+	if hits.Load() != 4 {
+		t.Errorf("zero TTL served from cache: hits = %d", hits.Load())
+	}
+
+	// Offline, the cache answers whatever its age, and nothing else does.
+	fresh.offline = true
+	if err := fresh.fetchJSON(srv.URL+"/pkg", &info, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := fresh.fetchJSON(srv.URL+"/other", &info, ""); !errors.Is(err, errOffline) {
+		t.Errorf("uncached lookup offline: err = %v, want errOffline", err)
+	}
+	if hits.Load() != 4 {
+		t.Errorf("offline lookups reached the registry: hits = %d", hits.Load())
+	}
+}
+
+func TestResolveDeps(t *testing.T) {
+	deps := make([]DepStatus, 50)
+	lookups := make([]func(*DepStatus), len(deps))
+	var inFlight, peak atomic.Int32
+	for i := range deps {
+		deps[i].Module = string(rune('a' + i%26))
+		lookups[i] = func(dep *DepStatus) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			dep.LatestVersion = dep.Module
+			dep.Status = "current"
+			inFlight.Add(-1)
+		}
+	}
+
+	resolveDeps(deps, lookups)
+
+	for i, dep := range deps {
+		if dep.Status != "current" || dep.LatestVersion != dep.Module {
+			t.Fatalf("dep %d not resolved: %+v", i, dep)
+		}
+	}
+	if peak.Load() > maxRegistryRequests {
+		t.Errorf("%d lookups in flight, limit is %d", peak.Load(), maxRegistryRequests)
+	}
+}
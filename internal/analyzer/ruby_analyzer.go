@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -18,9 +19,7 @@ func (r *RubyAnalyzer) Language() Language { return LangRuby }
 func (r *RubyAnalyzer) Extensions() []string { return []string{".rb"} }
 
 func (r *RubyAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, ".bundle", "vendor", "tmp", ".ruby-lsp")
-	skip := []string{"_test.rb", "_spec.rb", "spec/", "test/"}
-	return walkFiles(root, exclude, r.Extensions(), skip)
+	return findLanguageFiles(r, root, exclude)
 }
 
 var rbFuncPattern = regexp.MustCompile(`^(\s*)def\s+(self\.)?(\w+[?!=]?)`)
@@ -83,6 +82,7 @@ func analyzeRubyComplexity(path string) []FunctionComplexity {
 		// Track def/end depth to find function boundary
 		complexity := 1
 		depth := 1
+		last := len(lines) - 1
 		for j := i + 1; j < len(lines); j++ {
 			bodyLine := lines[j]
 			trimmed := strings.TrimSpace(bodyLine)
@@ -114,6 +114,7 @@ func analyzeRubyComplexity(path string) []FunctionComplexity {
 				if endIndent <= indent {
 					depth--
 					if depth <= 0 {
+						last = j
 						break
 					}
 				} else {
@@ -133,13 +134,16 @@ func analyzeRubyComplexity(path string) []FunctionComplexity {
 			Name:       name,
 			Line:       i + 1,
 			Complexity: complexity,
+			Lines:      last - i + 1,
+			Params:     countParams(signatureText(lines, i)),
+			Nesting:    indentNesting(lines[i : last+1]),
 		})
 	}
 
 	return results
 }
 
-func (r *RubyAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (r *RubyAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	gemfilePath := filepath.Join(root, "Gemfile")
 	f, err := os.Open(gemfilePath)
 	if err != nil {
@@ -147,9 +151,13 @@ func (r *RubyAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 	}
 	defer f.Close()
 
+	locked := readGemfileLock(root)
+
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	scanner := bufio.NewScanner(f)
 	gemPattern := regexp.MustCompile(`gem\s+['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+	groupScope := ""
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -157,52 +165,83 @@ func (r *RubyAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 			continue
 		}
 
+		if m := gemGroupBlock.FindStringSubmatch(line); m != nil {
+			groupScope = gemGroupScope(m[1])
+			continue
+		}
+		if line == "end" {
+			groupScope = ""
+			continue
+		}
+
 		m := gemPattern.FindStringSubmatch(line)
 		if m == nil {
 			continue
 		}
+		scope := groupScope
+		if g := gemGroupOption.FindStringSubmatch(line); g != nil {
+			scope = gemGroupScope(g[1])
+		}
 
 		name := m[1]
 		version := ""
 		if len(m) > 2 {
 			version = strings.TrimLeft(m[2], "~>= ")
 		}
+		if v, ok := locked[name]; ok {
+			version = v
+		}
 
-		dep := DepStatus{
+		results = append(results, DepStatus{
 			Module:         name,
 			CurrentVersion: version,
-		}
-
-		latest, err := fetchRubyGemsLatest(name)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
-				dep.Status = "current"
+			Scope:          scope,
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchRubyGemsLatest(reg, name)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
 			} else {
-				dep.StaleDays = 30
-				dep.Status = "stale"
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest || dep.CurrentVersion == "" {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
 			}
-		}
-
-		results = append(results, dep)
+		})
 	}
+	resolveLatest(reg, LangRuby, results, lookups)
 	return results, nil
 }
 
+var (
+	gemGroupBlock  = regexp.MustCompile(`^group\s+(.+?)\s+do\b`)
+	gemGroupOption = regexp.MustCompile(`\bgroups?:\s*(\[[^\]]*\]|:\w+)`)
+)
+
+// gemGroupScope classifies a Gemfile group list such as ":development, :test".
+// Gems in any group that ships (default or production) are runtime deps.
+func gemGroupScope(groups string) string {
+	if strings.Contains(groups, ":default") || strings.Contains(groups, ":production") {
+		return ""
+	}
+	return "dev"
+}
+
 type rubyGemsResponse struct {
-	Version string `json:"version"`
+	Version          string    `json:"version"`
+	VersionCreatedAt time.Time `json:"version_created_at"`
 }
 
-func fetchRubyGemsLatest(name string) (string, error) {
+func fetchRubyGemsLatest(reg *registryClient, name string) (string, time.Time, error) {
 	var resp rubyGemsResponse
 	url := fmt.Sprintf("https://rubygems.org/api/v1/gems/%s.json", name)
-	if err := fetchJSON(url, &resp, ""); err != nil {
-		return "", err
+	if err := reg.fetchJSON(url, &resp, ""); err != nil {
+		return "", time.Time{}, err
 	}
-	return resp.Version, nil
+	return resp.Version, resp.VersionCreatedAt, nil
 }
 
 var rbImportPatterns = []*regexp.Regexp{
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/config"
 )
@@ -18,8 +19,7 @@ func (r *RustAnalyzer) Language() Language { return LangRust }
 func (r *RustAnalyzer) Extensions() []string { return []string{".rs"} }
 
 func (r *RustAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "target")
-	return walkFiles(root, exclude, r.Extensions(), nil)
+	return findLanguageFiles(r, root, exclude)
 }
 
 var rsFuncPattern = regexp.MustCompile(`(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+(\w+)`)
@@ -46,7 +46,7 @@ func (r *RustAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity,
 	return results, len(results)
 }
 
-func (r *RustAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (r *RustAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	cargoPath := filepath.Join(root, "Cargo.toml")
 	f, err := os.Open(cargoPath)
 	if err != nil {
@@ -54,8 +54,12 @@ func (r *RustAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 	}
 	defer f.Close()
 
+	locked := readTOMLPackages(filepath.Join(root, "Cargo.lock"))
+
 	var results []DepStatus
+	var lookups []func(*DepStatus)
 	inDeps := false
+	scope := ""
 	scanner := bufio.NewScanner(f)
 
 	depLineSimple := regexp.MustCompile(`^(\w[\w-]*)\s*=\s*"([^"]+)"`)
@@ -65,7 +69,11 @@ func (r *RustAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 		line := strings.TrimSpace(scanner.Text())
 
 		if line == "[dependencies]" || line == "[dev-dependencies]" {
-			inDeps = line == "[dependencies]"
+			inDeps = true
+			scope = ""
+			if line == "[dev-dependencies]" {
+				scope = "dev"
+			}
 			continue
 		}
 		if strings.HasPrefix(line, "[") {
@@ -85,28 +93,31 @@ func (r *RustAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 		} else {
 			continue
 		}
+		if v, ok := locked[name]; ok {
+			version = v
+		}
 
-		dep := DepStatus{
+		results = append(results, DepStatus{
 			Module:         name,
 			CurrentVersion: version,
-		}
-
-		latest, err := fetchCratesIOLatest(name)
-		if err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = latest
-			if dep.CurrentVersion == latest {
-				dep.Status = "current"
+			Scope:          scope,
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchCratesIOLatest(reg, name)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
 			} else {
-				dep.StaleDays = 30
-				dep.Status = "stale"
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
 			}
-		}
-
-		results = append(results, dep)
+		})
 	}
+	resolveLatest(reg, LangRust, results, lookups)
 	return results, nil
 }
 
@@ -114,15 +125,25 @@ type cratesIOResponse struct {
 	Crate struct {
 		MaxStableVersion string `json:"max_stable_version"`
 	} `json:"crate"`
+	Versions []struct {
+		Num       string    `json:"num"`
+		CreatedAt time.Time `json:"created_at"`
+	} `json:"versions"`
 }
 
-func fetchCratesIOLatest(name string) (string, error) {
+func fetchCratesIOLatest(reg *registryClient, name string) (string, time.Time, error) {
 	var resp cratesIOResponse
 	url := fmt.Sprintf("https://crates.io/api/v1/crates/%s", name)
-	if err := fetchJSON(url, &resp, "drift/1.0 (https://github.com/greatnessinabox/drift)"); err != nil {
-		return "", err
+	if err := reg.fetchJSON(url, &resp, "drift/1.0 (https://github.com/greatnessinabox/drift)"); err != nil {
+		return "", time.Time{}, err
+	}
+	latest := resp.Crate.MaxStableVersion
+	for _, v := range resp.Versions {
+		if v.Num == latest {
+			return latest, v.CreatedAt, nil
+		}
 	}
-	return resp.Crate.MaxStableVersion, nil
+	return latest, time.Time{}, nil
 }
 
 var rsImportPatterns = []*regexp.Regexp{
@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Vulnerability is a known advisory affecting the version of a dependency
+// the project uses, as reported by OSV.dev or deps.dev.
+type Vulnerability struct {
+	ID       string // OSV identifier, e.g. "GHSA-xxxx-xxxx-xxxx" or "GO-2024-2687"
+	CVE      string // first CVE alias, if the advisory has one
+	Summary  string
+	Severity string // "critical", "high", "medium", "low", or "unknown"
+	Module   string
+	Version  string
+	Language Language
+}
+
+const (
+	osvBatchURL = "https://api.osv.dev/v1/querybatch"
+	osvVulnURL  = "https://api.osv.dev/v1/vulns/%s"
+
+	// osvBatchSize is the most queries OSV accepts in one batch request.
+	osvBatchSize = 1000
+)
+
+// osvEcosystems maps each language to its OSV ecosystem name.
+var osvEcosystems = map[Language]string{
+	LangGo:         "Go",
+	LangTypeScript: "npm",
+	LangPython:     "PyPI",
+	LangRust:       "crates.io",
+	LangJava:       "Maven",
+	LangRuby:       "RubyGems",
+	LangPHP:        "Packagist",
+	LangCSharp:     "NuGet",
+	LangSwift:      "SwiftURL",
+	LangElixir:     "Hex",
+}
+
+var severityRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3, "unknown": 4}
+
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+type osvVuln struct {
+	ID               string        `json:"id"`
+	Summary          string        `json:"summary"`
+	Details          string        `json:"details"`
+	Aliases          []string      `json:"aliases"`
+	Severity         []osvSeverity `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// advisoryMatch is a dependency version and the advisories found for it.
+type advisoryMatch struct {
+	dep     DepStatus
+	version string
+	ids     []string
+	depsDev bool // the advisories are deps.dev's rather than OSV.dev's
+}
+
+// scanVulnerabilities asks OSV.dev, or deps.dev when it is the configured
+// source for a language, which advisories affect the pinned versions of
+// deps. Dependencies without an exact version (ranges, "*", git references)
+// can't be matched and are skipped, as are lookup failures: a scan that
+// can't reach either service reports nothing rather than failing the run.
+func scanVulnerabilities(reg *registryClient, deps []DepStatus) []Vulnerability {
+	var viaOSV, viaDepsDev []DepStatus
+	for _, dep := range deps {
+		if usesDepsDev(reg, dep.Language) {
+			viaDepsDev = append(viaDepsDev, dep)
+		} else {
+			viaOSV = append(viaOSV, dep)
+		}
+	}
+	matches := append(queryOSV(reg, viaOSV), queryDepsDev(reg, viaDepsDev)...)
+	details := fetchAdvisories(reg, matches)
+
+	var out []Vulnerability
+	for _, m := range matches {
+		for _, id := range m.ids {
+			adv, ok := details[id]
+			if !ok {
+				adv = osvVuln{ID: id}
+			}
+			out = append(out, Vulnerability{
+				ID:       id,
+				CVE:      firstCVE(adv.Aliases),
+				Summary:  advisorySummary(adv),
+				Severity: advisorySeverity(adv),
+				Module:   m.dep.Module,
+				Version:  m.version,
+				Language: m.dep.Language,
+			})
+		}
+	}
+	sortVulnerabilities(out)
+	return out
+}
+
+// queryOSV batches a query per dependency to OSV.dev.
+func queryOSV(reg *registryClient, deps []DepStatus) []advisoryMatch {
+	var queries []osvQuery
+	var matches []advisoryMatch
+	for _, dep := range deps {
+		q, ok := osvQueryFor(dep)
+		if !ok {
+			continue
+		}
+		queries = append(queries, q)
+		matches = append(matches, advisoryMatch{dep: dep, version: q.Version})
+	}
+
+	for start := 0; start < len(queries); start += osvBatchSize {
+		end := min(start+osvBatchSize, len(queries))
+		var resp osvBatchResponse
+		err := reg.postJSON(osvBatchURL, map[string]interface{}{"queries": queries[start:end]}, &resp)
+		if err != nil {
+			return nil
+		}
+		for i, r := range resp.Results {
+			if start+i >= end {
+				break
+			}
+			for _, v := range r.Vulns {
+				matches[start+i].ids = append(matches[start+i].ids, v.ID)
+			}
+		}
+	}
+	return matches
+}
+
+// queryDepsDev reads the advisory keys deps.dev lists for each dependency
+// version.
+func queryDepsDev(reg *registryClient, deps []DepStatus) []advisoryMatch {
+	ids := depsDevAdvisoryIDs(reg, deps)
+	var matches []advisoryMatch
+	for i, dep := range deps {
+		if len(ids[i]) > 0 {
+			matches = append(matches, advisoryMatch{dep: dep, version: exactVersion(dep.CurrentVersion), ids: ids[i], depsDev: true})
+		}
+	}
+	return matches
+}
+
+// fetchAdvisories loads the full record of every distinct advisory ID from
+// the service that reported it, with up to maxRegistryRequests requests in
+// flight. Missing records are left out.
+func fetchAdvisories(reg *registryClient, matches []advisoryMatch) map[string]osvVuln {
+	fromDepsDev := make(map[string]bool)
+	var unique []string
+	for _, m := range matches {
+		for _, id := range m.ids {
+			if _, seen := fromDepsDev[id]; !seen {
+				fromDepsDev[id] = m.depsDev
+				unique = append(unique, id)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	out := make(map[string]osvVuln, len(unique))
+	sem := make(chan struct{}, maxRegistryRequests)
+	var wg sync.WaitGroup
+	for _, id := range unique {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var v osvVuln
+			var err error
+			if fromDepsDev[id] {
+				v, err = fetchDepsDevAdvisory(reg, id)
+			} else {
+				err = reg.fetchJSON(fmt.Sprintf(osvVulnURL, id), &v, "")
+			}
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			out[id] = v
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// osvQueryFor builds the OSV query for dep, or reports false when its
+// ecosystem is unknown or its version isn't an exact release.
+func osvQueryFor(dep DepStatus) (osvQuery, bool) {
+	ecosystem, ok := osvEcosystems[dep.Language]
+	if !ok {
+		return osvQuery{}, false
+	}
+	version := exactVersion(dep.CurrentVersion)
+	if version == "" {
+		return osvQuery{}, false
+	}
+
+	name := dep.PackageName()
+	if dep.Language == LangSwift { // SwiftURL names drop the scheme and .git
+		name = strings.TrimSuffix(name, ".git")
+		if i := strings.Index(name, "://"); i >= 0 {
+			name = name[i+3:]
+		}
+	}
+	return osvQuery{Package: osvPackage{Name: name, Ecosystem: ecosystem}, Version: version}, true
+}
+
+// exactVersion strips a single comparison or caret/tilde prefix from v and
+// returns it if what remains is one concrete version such as "1.4.2".
+func exactVersion(v string) string {
+	v = strings.TrimLeft(strings.TrimSpace(v), "^~>=<!v ")
+	if v == "" || strings.ContainsAny(v, " ,|*") || v[0] < '0' || v[0] > '9' {
+		return ""
+	}
+	for _, part := range strings.Split(v, ".") {
+		if part == "x" || part == "X" { // "1.x" wildcards
+			return ""
+		}
+	}
+	return v
+}
+
+func firstCVE(aliases []string) string {
+	for _, a := range aliases {
+		if strings.HasPrefix(a, "CVE-") {
+			return a
+		}
+	}
+	return ""
+}
+
+func advisorySummary(v osvVuln) string {
+	if v.Summary != "" {
+		return v.Summary
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(v.Details), "\n")
+	return line
+}
+
+// advisorySeverity prefers the rating assigned by the advisory database
+// (GitHub's "MODERATE" counts as medium) and otherwise derives one from a
+// CVSS v3 vector.
+func advisorySeverity(v osvVuln) string {
+	switch s := strings.ToLower(v.DatabaseSpecific.Severity); s {
+	case "critical", "high", "medium", "low":
+		return s
+	case "moderate":
+		return "medium"
+	}
+	for _, sev := range v.Severity {
+		if sev.Type == "CVSS_V3" {
+			if score, ok := cvss3BaseScore(sev.Score); ok {
+				return cvssRating(score)
+			}
+		}
+	}
+	return "unknown"
+}
+
+// cvss3BaseScore computes the CVSS v3.x base score of a vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+func cvss3BaseScore(vector string) (float64, bool) {
+	m := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			m[k] = v
+		}
+	}
+	if !strings.HasPrefix(m["CVSS"], "3") {
+		return 0, false
+	}
+	changed := m["S"] == "C"
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	if changed {
+		weights["PR"]["L"], weights["PR"]["H"] = 0.68, 0.5
+	}
+	w := make(map[string]float64)
+	for metric, values := range weights {
+		v, ok := values[m[metric]]
+		if !ok {
+			return 0, false
+		}
+		w[metric] = v
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	score := impact + exploitability
+	if changed {
+		score *= 1.08
+	}
+	return roundUp(math.Min(score, 10)), true
+}
+
+// roundUp rounds to one decimal place, upwards, as the CVSS spec requires.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return (math.Floor(float64(i)/10000) + 1) / 10
+}
+
+func cvssRating(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	}
+	return "unknown"
+}
+
+func sortVulnerabilities(vulns []Vulnerability) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		a, b := vulns[i], vulns[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Label names the advisory by its CVE when it has one, e.g.
+// "CVE-2024-1234 (GHSA-xxxx-xxxx-xxxx)".
+func (v Vulnerability) Label() string {
+	if v.CVE == "" || v.CVE == v.ID {
+		return v.ID
+	}
+	return v.CVE + " (" + v.ID + ")"
+}
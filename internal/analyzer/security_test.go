@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestScanVulnerabilities(t *testing.T) {
+	var queries []osvQuery
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/querybatch":
+			var body struct{ Queries []osvQuery }
+			json.NewDecoder(r.Body).Decode(&body)
+			queries = body.Queries
+			w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-1"},{"id":"GO-2"}]},{}]}`))
+		case r.URL.Path == "/v1/vulns/GHSA-1":
+			w.Write([]byte(`{"id":"GHSA-1","summary":"Request smuggling","aliases":["CVE-2024-1"],"database_specific":{"severity":"MODERATE"}}`))
+		case r.URL.Path == "/v1/vulns/GO-2":
+			w.Write([]byte(`{"id":"GO-2","details":"Panic on malformed input.\nMore text.","severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	deps := []DepStatus{
+		{Module: "net", Path: "golang.org/x/net", CurrentVersion: "v0.7.0", Language: LangGo},
+		{Module: "left-pad", CurrentVersion: "^1.3.0", Language: LangTypeScript},
+		{Module: "lodash", CurrentVersion: "*", Language: LangTypeScript}, // no exact version
+	}
+	vulns := scanVulnerabilities(reg, deps)
+
+	if len(queries) != 2 {
+		t.Fatalf("sent %d queries, want 2: %+v", len(queries), queries)
+	}
+	if q := queries[0]; q.Package.Name != "golang.org/x/net" || q.Package.Ecosystem != "Go" || q.Version != "0.7.0" {
+		t.Errorf("Go query = %+v", q)
+	}
+	if q := queries[1]; q.Package.Name != "left-pad" || q.Package.Ecosystem != "npm" || q.Version != "1.3.0" {
+		t.Errorf("npm query = %+v", q)
+	}
+
+	if len(vulns) != 2 {
+		t.Fatalf("got %d vulnerabilities, want 2: %+v", len(vulns), vulns)
+	}
+	// Most severe first: the CVSS 9.8 advisory outranks the moderate one.
+	if v := vulns[0]; v.ID != "GO-2" || v.Severity != "critical" || v.Summary != "Panic on malformed input." || v.Module != "net" {
+		t.Errorf("vulns[0] = %+v", v)
+	}
+	if v := vulns[1]; v.Label() != "CVE-2024-1 (GHSA-1)" || v.Severity != "medium" || v.Version != "0.7.0" {
+		t.Errorf("vulns[1] = %+v", v)
+	}
+}
+
+func TestScanVulnerabilities_Unreachable(t *testing.T) {
+	reg := stubRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+	deps := []DepStatus{{Module: "requests", CurrentVersion: "2.0.0", Language: LangPython}}
+	if vulns := scanVulnerabilities(reg, deps); len(vulns) != 0 {
+		t.Errorf("got %+v, want none when OSV is unreachable", vulns)
+	}
+}
+
+func TestOSVQueryFor(t *testing.T) {
+	tests := []struct {
+		dep     DepStatus
+		name    string
+		version string
+		ok      bool
+	}{
+		{DepStatus{Module: "serde", CurrentVersion: "1.0.190", Language: LangRust}, "serde", "1.0.190", true},
+		{DepStatus{Module: "org.slf4j:slf4j-api", CurrentVersion: "2.0.9", Language: LangJava}, "org.slf4j:slf4j-api", "2.0.9", true},
+		{DepStatus{Module: "swift-nio", Path: "https://github.com/apple/swift-nio.git", CurrentVersion: "2.40.0", Language: LangSwift}, "github.com/apple/swift-nio", "2.40.0", true},
+		{DepStatus{Module: "react", CurrentVersion: ">=16 <18", Language: LangTypeScript}, "", "", false},
+		{DepStatus{Module: "rails", CurrentVersion: "7.x", Language: LangRuby}, "", "", false},
+		{DepStatus{Module: "django", CurrentVersion: "", Language: LangPython}, "", "", false},
+	}
+	for _, tt := range tests {
+		q, ok := osvQueryFor(tt.dep)
+		if ok != tt.ok || q.Package.Name != tt.name || q.Version != tt.version {
+			t.Errorf("osvQueryFor(%s %q) = %+v, %v; want %s %s, %v", tt.dep.Module, tt.dep.CurrentVersion, q, ok, tt.name, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		score  float64
+		rating string
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, "critical"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, "critical"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, "medium"},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5, "medium"},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:H/A:H", 8.1, "high"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, "unknown"},
+	}
+	for _, tt := range tests {
+		got, ok := cvss3BaseScore(tt.vector)
+		if !ok || got != tt.score {
+			t.Errorf("cvss3BaseScore(%s) = %v, %v; want %v", tt.vector, got, ok, tt.score)
+		}
+		if r := cvssRating(got); r != tt.rating {
+			t.Errorf("cvssRating(%v) = %s, want %s", got, r, tt.rating)
+		}
+	}
+	for _, bad := range []string{"CVSS:2.0/AV:N", "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", strings.Repeat("x", 5)} {
+		if _, ok := cvss3BaseScore(bad); ok {
+			t.Errorf("cvss3BaseScore(%q) ok, want failure", bad)
+		}
+	}
+}
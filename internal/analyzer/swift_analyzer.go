@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+type SwiftAnalyzer struct{}
+
+func (s *SwiftAnalyzer) Language() Language { return LangSwift }
+
+func (s *SwiftAnalyzer) Extensions() []string { return []string{".swift"} }
+
+func (s *SwiftAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
+	return findLanguageFiles(s, root, exclude)
+}
+
+var swiftFuncPattern = regexp.MustCompile(
+	`(?:^|\s)(?:func\s+(\w+)|(init)\??\s*\()`,
+)
+
+var swiftComplexityPatterns = []complexityPattern{
+	{regexp.MustCompile(`\bif\b`), 1},
+	{regexp.MustCompile(`\bguard\b`), 1},
+	{regexp.MustCompile(`\bfor\b.+\bin\b`), 1},
+	{regexp.MustCompile(`\bwhile\b`), 1},
+	{regexp.MustCompile(`\brepeat\s*\{`), 1},
+	{regexp.MustCompile(`\bcase\b[^:]*:`), 1},
+	{regexp.MustCompile(`\bcatch\b`), 1},
+	{regexp.MustCompile(`&&`), 1},
+	{regexp.MustCompile(`\|\|`), 1},
+	{regexp.MustCompile(`\?\?`), 1},
+}
+
+func (s *SwiftAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComplexity, int) {
+	var results []FunctionComplexity
+	for _, path := range files {
+		funcs := scanHeuristicComplexity(path, swiftFuncPattern, 1, swiftComplexityPatterns)
+		for i := range funcs {
+			if funcs[i].Name == "anonymous" {
+				funcs[i].Name = "init"
+			}
+		}
+		results = append(results, funcs...)
+	}
+	return results, len(results)
+}
+
+// SwiftPM manifest parsing. Package.swift is Swift source, so declared
+// dependencies are matched with a regex; Package.resolved (v1 or v2/v3) pins
+// the versions actually in use and takes precedence when present.
+
+var swiftPackagePattern = regexp.MustCompile(
+	`\.package\(\s*(?:name:\s*"[^"]*"\s*,\s*)?url:\s*"([^"]+)"\s*,\s*(?:from:\s*|exact:\s*|\.upToNextMajor\(from:\s*|\.upToNextMinor\(from:\s*|\.exact\(\s*)?"([^"]+)"`,
+)
+
+type swiftResolved struct {
+	// v1 nests pins under "object"; v2 and later keep them top level.
+	Object struct {
+		Pins []swiftPin `json:"pins"`
+	} `json:"object"`
+	Pins []swiftPin `json:"pins"`
+}
+
+type swiftPin struct {
+	Identity      string `json:"identity"`
+	Package       string `json:"package"`
+	Location      string `json:"location"`
+	RepositoryURL string `json:"repositoryURL"`
+	State         struct {
+		Version string `json:"version"`
+	} `json:"state"`
+}
+
+func (s *SwiftAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
+	manifestPath := filepath.Join(root, "Package.swift")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading Package.swift: %w", err)
+	}
+
+	resolved := readSwiftResolved(filepath.Join(root, "Package.resolved"))
+
+	var results []DepStatus
+	var lookups []func(*DepStatus)
+	for _, m := range swiftPackagePattern.FindAllStringSubmatch(string(data), -1) {
+		url, version := m[1], m[2]
+		name := swiftPackageName(url)
+		if v, ok := resolved[strings.ToLower(name)]; ok {
+			version = v
+		}
+
+		results = append(results, DepStatus{
+			Module:         name,
+			Path:           url,
+			CurrentVersion: version,
+		})
+		lookups = append(lookups, func(dep *DepStatus) {
+			latest, published, err := fetchSwiftLatest(reg, url)
+			if err != nil {
+				dep.Status = "unknown"
+				dep.LatestVersion = "?"
+			} else {
+				dep.LatestVersion = latest
+				if dep.CurrentVersion == latest {
+					dep.Status = "current"
+				} else {
+					markBehind(dep, published)
+				}
+			}
+		})
+	}
+	resolveLatest(reg, LangSwift, results, lookups)
+	return results, nil
+}
+
+// readSwiftResolved maps lowercased package identity to its pinned version.
+// A missing or unreadable Package.resolved yields an empty map.
+func readSwiftResolved(path string) map[string]string {
+	pinned := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return pinned
+	}
+
+	var res swiftResolved
+	if err := json.Unmarshal(data, &res); err != nil {
+		return pinned
+	}
+
+	for _, pin := range append(res.Pins, res.Object.Pins...) {
+		if pin.State.Version == "" {
+			continue
+		}
+		id := pin.Identity
+		if id == "" {
+			loc := pin.Location
+			if loc == "" {
+				loc = pin.RepositoryURL
+			}
+			id = swiftPackageName(loc)
+		}
+		pinned[strings.ToLower(id)] = pin.State.Version
+	}
+	return pinned
+}
+
+// swiftPackageName derives the SwiftPM identity from a repository URL, e.g.
+// "https://github.com/apple/swift-nio.git" -> "swift-nio".
+func swiftPackageName(url string) string {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if idx := strings.LastIndex(url, "/"); idx >= 0 {
+		return url[idx+1:]
+	}
+	return url
+}
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// fetchSwiftLatest looks up the latest release of a GitHub-hosted package.
+// SwiftPM has no central registry, so other hosts are reported as unknown.
+func fetchSwiftLatest(reg *registryClient, url string) (string, time.Time, error) {
+	const prefix = "github.com/"
+	idx := strings.Index(url, prefix)
+	if idx < 0 {
+		return "", time.Time{}, fmt.Errorf("unsupported package host: %s", url)
+	}
+	repo := strings.TrimSuffix(strings.TrimSuffix(url[idx+len(prefix):], "/"), ".git")
+
+	var rel githubRelease
+	api := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+	if err := reg.fetchJSON(api, &rel, "drift/1.0 (https://github.com/greatnessinabox/drift)"); err != nil {
+		return "", time.Time{}, err
+	}
+	return strings.TrimPrefix(rel.TagName, "v"), rel.PublishedAt, nil
+}
+
+var swiftImportPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?:@\w+\s+)*import\s+(?:(?:class|struct|enum|protocol|func|var|let|typealias)\s+)?([\w.]+)`),
+}
+
+func (s *SwiftAnalyzer) AnalyzeImports(files []string, rules []config.BoundaryRule, root string) []BoundaryViolation {
+	var violations []BoundaryViolation
+	for _, path := range files {
+		imports := extractImports(path, swiftImportPatterns, 1)
+		violations = append(violations, checkBoundaryViolations(path, imports, rules, root)...)
+	}
+	return violations
+}
+
+var swiftExportPattern = regexp.MustCompile(`(?:public|open)\s+(?:static\s+|class\s+|final\s+|override\s+)*func\s+(\w+)`)
+
+func (s *SwiftAnalyzer) AnalyzeDeadCode(files []string) []DeadFunction {
+	callPatterns := []*regexp.Regexp{
+		regexp.MustCompile(`\b\w+\(`),
+	}
+	return detectExportsAndCalls(files, swiftExportPattern, 1, callPatterns)
+}
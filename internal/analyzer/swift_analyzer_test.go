@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSwiftResolved(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"v1", `{"object":{"pins":[{"package":"swift-nio","repositoryURL":"https://github.com/apple/swift-nio.git","state":{"version":"2.40.0"}}]},"version":1}`},
+		{"v2", `{"pins":[{"identity":"swift-nio","location":"https://github.com/apple/swift-nio.git","state":{"version":"2.40.0"}}],"version":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Package.resolved")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readSwiftResolved(path)["swift-nio"]; got != "2.40.0" {
+				t.Errorf("pinned swift-nio = %q, want 2.40.0", got)
+			}
+		})
+	}
+}
+
+func TestSwiftPackagePattern(t *testing.T) {
+	manifest := `dependencies: [
+        .package(url: "https://github.com/apple/swift-nio.git", from: "2.0.0"),
+        .package(url: "https://github.com/vapor/vapor", .upToNextMajor(from: "4.1.0")),
+        .package(url: "https://github.com/pointfreeco/swift-case-paths.git", exact: "1.0.0"),
+    ]`
+
+	want := map[string]string{"swift-nio": "2.0.0", "vapor": "4.1.0", "swift-case-paths": "1.0.0"}
+	matches := swiftPackagePattern.FindAllStringSubmatch(manifest, -1)
+	if len(matches) != len(want) {
+		t.Fatalf("matched %d packages, want %d: %v", len(matches), len(want), matches)
+	}
+	for _, m := range matches {
+		name := swiftPackageName(m[1])
+		if want[name] != m[2] {
+			t.Errorf("%s version = %q, want %q", name, m[2], want[name])
+		}
+	}
+}
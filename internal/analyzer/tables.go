@@ -0,0 +1,62 @@
+package analyzer
+
+import "strconv"
+
+// Table is one kind of raw metric flattened into rows, for `drift export`
+// to write as CSV.
+type Table struct {
+	Name   string // file name without extension, e.g. "functions"
+	Header []string
+	Rows   [][]string
+}
+
+// Tables flattens the results into per-function, per-file, and
+// per-dependency tables.
+func (r *Results) Tables() []Table {
+	functions := Table{
+		Name:   "functions",
+		Header: []string{"path", "line", "name", "language", "complexity", "lines", "params", "nesting"},
+	}
+	for _, fc := range r.Complexity {
+		functions.Rows = append(functions.Rows, []string{
+			fc.Path, strconv.Itoa(fc.Line), fc.Name, string(fc.Language),
+			strconv.Itoa(fc.Complexity), strconv.Itoa(fc.Lines), strconv.Itoa(fc.Params), strconv.Itoa(fc.Nesting),
+		})
+	}
+
+	files := Table{
+		Name:   "files",
+		Header: []string{"path", "language", "lines", "code_lines", "comment_lines", "functions", "avg_complexity", "maintainability"},
+	}
+	for _, f := range r.Files {
+		maintainability := ""
+		if f.MaintainabilityMeasured {
+			maintainability = strconv.FormatFloat(f.Maintainability, 'f', 1, 64)
+		}
+		files.Rows = append(files.Rows, []string{
+			f.Path, string(f.Language), strconv.Itoa(f.Lines), strconv.Itoa(f.CodeLines), strconv.Itoa(f.CommentLines),
+			strconv.Itoa(f.Functions), strconv.FormatFloat(f.AvgComplexity, 'f', 2, 64), maintainability,
+		})
+	}
+
+	deps := Table{
+		Name:   "dependencies",
+		Header: []string{"module", "language", "scope", "current", "latest", "status", "stale_days", "behind", "transitive", "license"},
+	}
+	for _, d := range r.Dependencies {
+		module := d.Module
+		if d.Path != "" {
+			module = d.Path
+		}
+		scope := d.Scope
+		if scope == "" {
+			scope = "runtime"
+		}
+		deps.Rows = append(deps.Rows, []string{
+			module, string(d.Language), scope, d.CurrentVersion, d.LatestVersion, d.Status,
+			strconv.Itoa(d.StaleDays), d.Behind, strconv.Itoa(d.Transitive), d.License,
+		})
+	}
+
+	return []Table{functions, files, deps}
+}
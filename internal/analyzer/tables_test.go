@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResults_Tables(t *testing.T) {
+	r := &Results{
+		Complexity: []FunctionComplexity{{Path: "a.go", Line: 3, Name: "Run", Language: LangGo, Complexity: 7, Lines: 20, Params: 2, Nesting: 1}},
+		Files:      []FileMetrics{{Path: "a.go", Language: LangGo, Lines: 40, Functions: 1, AvgComplexity: 7}},
+		Dependencies: []DepStatus{
+			{Module: "x", Language: LangGo, CurrentVersion: "v1.0.0", LatestVersion: "v1.2.0", Status: "stale", StaleDays: 90, Behind: "minor"},
+			{Module: "y", Scope: "dev", Status: "current"},
+		},
+	}
+	tables := r.Tables()
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+		for _, row := range table.Rows {
+			if len(row) != len(table.Header) {
+				t.Errorf("%s row %v has %d columns, header has %d", table.Name, row, len(row), len(table.Header))
+			}
+		}
+	}
+	if !slices.Equal(names, []string{"functions", "files", "dependencies"}) {
+		t.Fatalf("tables = %v", names)
+	}
+	if got := tables[0].Rows[0]; !slices.Equal(got, []string{"a.go", "3", "Run", "go", "7", "20", "2", "1"}) {
+		t.Errorf("function row = %v", got)
+	}
+	if got := tables[1].Rows[0]; got[6] != "7.00" || got[7] != "" {
+		t.Errorf("file row = %v, want average 7.00 and no maintainability", got)
+	}
+	if got := tables[2].Rows; got[0][2] != "runtime" || got[1][2] != "dev" {
+		t.Errorf("dependency scopes = %q, %q", got[0][2], got[1][2])
+	}
+}
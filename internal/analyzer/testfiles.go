@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PackageTests counts source files, test files, and test functions in one
+// directory. Languages that keep tests in a separate tree (tests/, spec/)
+// report those directories with no source files.
+type PackageTests struct {
+	Package     string // directory relative to the analysis root, "." for the root
+	SourceFiles int
+	TestFiles   int
+	TestFuncs   int
+	Language    Language
+}
+
+// testFilePatterns mirror the skip lists in each analyzer's FindFiles and are
+// matched against "/" + the slash-separated path relative to the root.
+var testFilePatterns = map[Language][]string{
+	LangGo:         {"_test.go"},
+	LangTypeScript: {".test.", ".spec.", "/__tests__/"},
+	LangPython:     {"/test_", "_test.py", "/conftest.py"},
+	LangRust:       {"/tests/"},
+	LangJava:       {"Test.java", "Tests.java", "IT.java", "/src/test/"},
+	LangRuby:       {"_test.rb", "_spec.rb", "/spec/", "/test/"},
+	LangPHP:        {"Test.php", "/tests/", "/test/"},
+	LangCSharp:     {"Tests.cs", "Test.cs", ".Tests/", ".Test/"},
+	LangSwift:      {"Tests.swift", "Test.swift", "Tests/"},
+	LangElixir:     {"_test.exs"},
+}
+
+// testFuncPatterns match one test case per line.
+var testFuncPatterns = map[Language]*regexp.Regexp{
+	LangGo:         regexp.MustCompile(`^func (?:Test|Benchmark|Fuzz|Example)\w*\(`),
+	LangTypeScript: regexp.MustCompile(`^\s*(?:it|test)(?:\.\w+)?\s*\(`),
+	LangPython:     regexp.MustCompile(`^\s*(?:async\s+)?def\s+test\w*\s*\(`),
+	LangRust:       regexp.MustCompile(`^\s*#\[(?:tokio::)?test\]`),
+	LangJava:       regexp.MustCompile(`^\s*@(?:Test|ParameterizedTest)\b`),
+	LangRuby:       regexp.MustCompile(`^\s*(?:it\s+['"]|def\s+test_)`),
+	LangPHP:        regexp.MustCompile(`function\s+test\w*\s*\(|@test\b`),
+	LangCSharp:     regexp.MustCompile(`^\s*\[(?:Test|Fact|Theory|TestMethod|TestCase)\b`),
+	LangSwift:      regexp.MustCompile(`^\s*func\s+test\w*\s*\(`),
+	LangElixir:     regexp.MustCompile(`^\s*test\s+"`),
+}
+
+// Build output and dependency directories that FindFiles implementations add
+// to the configured excludes; they never hold the project's own tests.
+var testWalkExcludes = []string{"_build", "deps", ".build", "bin", "obj", "vendor", "node_modules"}
+
+func isTestFile(lang Language, root, path string) bool {
+	rel := "/" + relPath(root, path)
+	for _, p := range testFilePatterns[lang] {
+		if strings.Contains(rel, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeTests walks root for test files of lang and tallies them per
+// directory alongside the already-discovered source files. Rust keeps unit
+// tests inline, so its source files are scanned for test functions too.
+func analyzeTests(lang LanguageAnalyzer, root string, exclude []string, sources []string) []PackageTests {
+	l := lang.Language()
+	byDir := make(map[string]*PackageTests)
+	entry := func(path string) *PackageTests {
+		dir := relPath(root, filepath.Dir(path))
+		pt, ok := byDir[dir]
+		if !ok {
+			pt = &PackageTests{Package: dir, Language: l}
+			byDir[dir] = pt
+		}
+		return pt
+	}
+
+	for _, path := range sources {
+		pt := entry(path)
+		pt.SourceFiles++
+		if l == LangRust {
+			pt.TestFuncs += countTestFuncs(l, path)
+		}
+	}
+
+	all, _ := walkFiles(root, append(append([]string{}, exclude...), testWalkExcludes...), lang.Extensions(), nil)
+	for _, path := range all {
+		if !isTestFile(l, root, path) {
+			continue
+		}
+		pt := entry(path)
+		pt.TestFiles++
+		pt.TestFuncs += countTestFuncs(l, path)
+	}
+
+	out := make([]PackageTests, 0, len(byDir))
+	for _, pt := range byDir {
+		out = append(out, *pt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Package < out[j].Package })
+	return out
+}
+
+func countTestFuncs(lang Language, path string) int {
+	pattern := testFuncPatterns[lang]
+	if pattern == nil {
+		return 0
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if pattern.MatchString(scanner.Text()) {
+			n++
+		}
+	}
+	return n
+}
+
+// TestRatio returns test files per source file across every package.
+func (r *Results) TestRatio() float64 {
+	var src, tests int
+	for _, pt := range r.Tests {
+		src += pt.SourceFiles
+		tests += pt.TestFiles
+	}
+	if src == 0 {
+		return 0
+	}
+	return float64(tests) / float64(src)
+}
+
+// TestFuncCount returns the number of test functions found.
+func (r *Results) TestFuncCount() int {
+	n := 0
+	for _, pt := range r.Tests {
+		n += pt.TestFuncs
+	}
+	return n
+}
+
+// UntestedPackages returns packages with source files but no tests,
+// largest first.
+func UntestedPackages(tests []PackageTests) []PackageTests {
+	var out []PackageTests
+	for _, pt := range tests {
+		if pt.SourceFiles > 0 && pt.TestFiles == 0 && pt.TestFuncs == 0 {
+			out = append(out, pt)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].SourceFiles > out[j].SourceFiles })
+	return out
+}
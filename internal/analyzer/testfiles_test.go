@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestAnalyzeTests(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"a.go":               "package x\n",
+		"b.go":               "package x\n",
+		"a_test.go":          "package x\n\nfunc TestA(t *testing.T) {}\nfunc TestB(t *testing.T) {}\nfunc helper() {}\n",
+		"sub/c.go":           "package sub\n",
+		"vendor/v/v_test.go": "package v\n\nfunc TestV(t *testing.T) {}\n",
+	})
+	sources := []string{
+		filepath.Join(root, "a.go"),
+		filepath.Join(root, "b.go"),
+		filepath.Join(root, "sub", "c.go"),
+	}
+
+	got := analyzeTests(&GoAnalyzer{}, root, []string{"vendor"}, sources)
+	want := []PackageTests{
+		{Package: ".", SourceFiles: 2, TestFiles: 1, TestFuncs: 2, Language: LangGo},
+		{Package: "sub", SourceFiles: 1, Language: LangGo},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("package %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	r := &Results{Tests: got}
+	if ratio := r.TestRatio(); ratio != 1.0/3.0 {
+		t.Errorf("TestRatio = %v, want 1/3", ratio)
+	}
+	if n := r.TestFuncCount(); n != 2 {
+		t.Errorf("TestFuncCount = %d, want 2", n)
+	}
+	if untested := UntestedPackages(got); len(untested) != 1 || untested[0].Package != "sub" {
+		t.Errorf("UntestedPackages = %+v, want only sub", untested)
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		lang Language
+		path string
+		want bool
+	}{
+		{LangPython, "pkg/test_models.py", true},
+		{LangPython, "pkg/latest_models.py", false},
+		{LangTypeScript, "src/app.spec.ts", true},
+		{LangTypeScript, "src/app.ts", false},
+		{LangRust, "tests/integration.rs", true},
+		{LangRust, "src/lib.rs", false},
+		{LangJava, "src/test/java/AppHelper.java", true},
+		{LangElixir, "test/app_test.exs", true},
+	}
+	for _, tt := range tests {
+		if got := isTestFile(tt.lang, "/repo", "/repo/"+tt.path); got != tt.want {
+			t.Errorf("isTestFile(%s, %q) = %v, want %v", tt.lang, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// depGraph maps each installed package to the packages it requires.
+// Direct dependencies are keyed by their package name (see
+// DepStatus.PackageName); other keys are whatever identifies a package in
+// the ecosystem's lockfile.
+type depGraph map[string][]string
+
+// depGraphReaders load the installed dependency graph for languages that
+// record one: Go through `go mod graph`, npm through package-lock.json.
+var depGraphReaders = map[Language]func(root string) depGraph{
+	LangGo:         readGoModGraph,
+	LangTypeScript: readNpmGraph,
+}
+
+// goModGraphTimeout bounds `go mod graph`. It runs against the local module
+// cache only, so it is fast or fails.
+const goModGraphTimeout = 30 * time.Second
+
+// addTransitiveWeights records, for each direct dependency, how many
+// packages it pulls in and how deep its subtree goes, and returns how many
+// distinct packages all of deps pull in beyond themselves.
+func addTransitiveWeights(deps []DepStatus, graph depGraph) int {
+	direct := make(map[string]bool, len(deps))
+	for _, d := range deps {
+		direct[d.PackageName()] = true
+	}
+
+	all := make(map[string]bool)
+	for i := range deps {
+		reached, depth := reachable(graph, deps[i].PackageName())
+		deps[i].Transitive = len(reached)
+		deps[i].Depth = depth
+		for pkg := range reached {
+			if !direct[pkg] {
+				all[pkg] = true
+			}
+		}
+	}
+	return len(all)
+}
+
+// reachable walks the graph breadth-first from start and returns every
+// package below it along with the longest shortest-path distance found.
+func reachable(graph depGraph, start string) (map[string]bool, int) {
+	seen := map[string]bool{start: true}
+	frontier := []string{start}
+	depth := 0
+	for len(frontier) > 0 {
+		var next []string
+		for _, pkg := range frontier {
+			for _, dep := range graph[pkg] {
+				if !seen[dep] {
+					seen[dep] = true
+					next = append(next, dep)
+				}
+			}
+		}
+		if len(next) > 0 {
+			depth++
+		}
+		frontier = next
+	}
+	delete(seen, start)
+	return seen, depth
+}
+
+// HeaviestDependencies returns the direct dependencies that pull in the most
+// transitive packages, heaviest first, leaving out those that pull in none.
+func HeaviestDependencies(deps []DepStatus, n int) []DepStatus {
+	var out []DepStatus
+	for _, d := range deps {
+		if d.Transitive > 0 {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Transitive > out[j].Transitive })
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func readGoModGraph(root string) depGraph {
+	ctx, cancel := context.WithTimeout(context.Background(), goModGraphTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "mod", "graph")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOPROXY=off")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseGoModGraph(string(out))
+}
+
+// parseGoModGraph reads `go mod graph` output ("a@v1 b@v2" per edge). Minimal
+// version selection builds one version of each module, so versions are
+// dropped and edges merged by module path.
+func parseGoModGraph(out string) depGraph {
+	graph := make(depGraph)
+	seen := make(map[[2]string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		from, to, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		from, _, _ = strings.Cut(from, "@")
+		to, _, _ = strings.Cut(to, "@")
+		edge := [2]string{from, to}
+		if from == to || seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		graph[from] = append(graph[from], to)
+	}
+	return graph
+}
+
+type npmLockPackage struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// readNpmGraph builds the graph from package-lock.json (lockfile v2 and
+// later). Each install path's dependencies are resolved the way Node does:
+// the closest node_modules directory walking up from the dependent wins.
+// Top-level packages are keyed by name, nested ones by their install path
+// below node_modules (e.g. "react/node_modules/loose-envify").
+func readNpmGraph(root string) depGraph {
+	data, err := os.ReadFile(filepath.Join(root, "package-lock.json"))
+	if err != nil {
+		return nil
+	}
+	var lock struct {
+		Packages map[string]npmLockPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil || len(lock.Packages) == 0 {
+		return nil
+	}
+
+	graph := make(depGraph)
+	for path, pkg := range lock.Packages {
+		if !strings.HasPrefix(path, "node_modules/") {
+			continue // the root project or a workspace
+		}
+		from := strings.TrimPrefix(path, "node_modules/")
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies} {
+			for name := range deps {
+				if to, ok := resolveNodeModule(lock.Packages, path, name); ok {
+					graph[from] = append(graph[from], strings.TrimPrefix(to, "node_modules/"))
+				}
+			}
+		}
+	}
+	for _, deps := range graph {
+		sort.Strings(deps)
+	}
+	return graph
+}
+
+// resolveNodeModule finds the install path that satisfies name for the
+// package installed at path.
+func resolveNodeModule(installed map[string]npmLockPackage, path, name string) (string, bool) {
+	dir := path
+	for {
+		cand := dir + "/node_modules/" + name
+		if dir == "" {
+			cand = "node_modules/" + name
+		}
+		if _, ok := installed[cand]; ok {
+			return cand, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		// Step out of the innermost node_modules/<pkg>.
+		i := strings.LastIndex(dir, "node_modules/")
+		if i <= 0 {
+			dir = ""
+		} else {
+			dir = strings.TrimSuffix(dir[:i], "/")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestParseGoModGraph(t *testing.T) {
+	out := `example.com/app github.com/spf13/cobra@v1.8.0
+example.com/app golang.org/x/mod@v0.14.0
+github.com/spf13/cobra@v1.8.0 github.com/spf13/pflag@v1.0.5
+github.com/spf13/cobra@v1.8.0 github.com/inconshreveable/mousetrap@v1.1.0
+github.com/spf13/cobra@v1.7.0 github.com/spf13/pflag@v1.0.5
+github.com/spf13/pflag@v1.0.5 go@1.12
+`
+	want := depGraph{
+		"example.com/app":        {"github.com/spf13/cobra", "golang.org/x/mod"},
+		"github.com/spf13/cobra": {"github.com/spf13/pflag", "github.com/inconshreveable/mousetrap"},
+		"github.com/spf13/pflag": {"go"},
+	}
+	if got := parseGoModGraph(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoModGraph = %v, want %v", got, want)
+	}
+}
+
+func TestReadNpmGraph(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"package-lock.json": `{"lockfileVersion":3,"packages":{
+		"": {"dependencies":{"express":"^4.0.0","react":"^18.0.0"}},
+		"node_modules/express": {"dependencies":{"body-parser":"1.x","debug":"2.6.9"}},
+		"node_modules/express/node_modules/debug": {"dependencies":{"ms":"2.0.0"}},
+		"node_modules/body-parser": {"dependencies":{"debug":"2.6.9","bytes":"3.1.2"}},
+		"node_modules/debug": {"dependencies":{"ms":"2.1.2"}},
+		"node_modules/ms": {},
+		"node_modules/bytes": {},
+		"node_modules/react": {"dependencies":{"loose-envify":"^1.1.0"},"optionalDependencies":{"missing":"1.0.0"}},
+		"node_modules/loose-envify": {}}}`})
+
+	graph := readNpmGraph(root)
+	want := depGraph{
+		"express":                    {"body-parser", "express/node_modules/debug"},
+		"express/node_modules/debug": {"ms"},
+		"body-parser":                {"bytes", "debug"},
+		"debug":                      {"ms"},
+		"react":                      {"loose-envify"},
+	}
+	if !reflect.DeepEqual(graph, want) {
+		t.Fatalf("readNpmGraph = %v, want %v", graph, want)
+	}
+
+	deps := []DepStatus{{Module: "express"}, {Module: "react"}, {Module: "left-pad"}}
+	total := addTransitiveWeights(deps, graph)
+	// express: body-parser, express's own debug, ms, bytes, top-level debug.
+	if deps[0].Transitive != 5 || deps[0].Depth != 2 {
+		t.Errorf("express = +%d, depth %d; want +5, depth 2", deps[0].Transitive, deps[0].Depth)
+	}
+	if deps[1].Transitive != 1 || deps[1].Depth != 1 {
+		t.Errorf("react = +%d, depth %d; want +1, depth 1", deps[1].Transitive, deps[1].Depth)
+	}
+	if deps[2].Transitive != 0 {
+		t.Errorf("left-pad = +%d, want 0", deps[2].Transitive)
+	}
+	if total != 6 {
+		t.Errorf("total transitive = %d, want 6", total)
+	}
+
+	heavy := HeaviestDependencies(deps, 5)
+	if len(heavy) != 2 || heavy[0].Module != "express" || heavy[1].Module != "react" {
+		t.Errorf("HeaviestDependencies = %+v", heavy)
+	}
+}
+
+func TestAddTransitiveWeights_GoPath(t *testing.T) {
+	graph := depGraph{
+		"github.com/spf13/cobra": {"github.com/spf13/pflag"},
+		"github.com/spf13/pflag": {},
+	}
+	deps := []DepStatus{
+		{Module: "cobra", Path: "github.com/spf13/cobra"},
+		{Module: "pflag", Path: "github.com/spf13/pflag"},
+	}
+	// pflag is itself a direct dependency, so it adds nothing to the total.
+	if total := addTransitiveWeights(deps, graph); total != 0 || deps[0].Transitive != 1 {
+		t.Errorf("total = %d, cobra = +%d; want 0, +1", total, deps[0].Transitive)
+	}
+}
@@ -21,9 +21,7 @@ func (t *TypeScriptAnalyzer) Extensions() []string {
 }
 
 func (t *TypeScriptAnalyzer) FindFiles(root string, exclude []string) ([]string, error) {
-	exclude = append(exclude, "node_modules", "dist", "build", ".next", "coverage")
-	skip := []string{".test.", ".spec.", "__tests__", "__mocks__", ".d.ts"}
-	return walkFiles(root, exclude, t.Extensions(), skip)
+	return findLanguageFiles(t, root, exclude)
 }
 
 var tsFuncPattern = regexp.MustCompile(
@@ -75,7 +73,8 @@ func (t *TypeScriptAnalyzer) AnalyzeComplexity(files []string) ([]FunctionComple
 }
 
 type packageJSON struct {
-	Dependencies map[string]string `json:"dependencies"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
 }
 
 type npmPackageInfo struct {
@@ -83,7 +82,7 @@ type npmPackageInfo struct {
 	Time    string `json:"time"`
 }
 
-func (t *TypeScriptAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
+func (t *TypeScriptAnalyzer) AnalyzeDeps(reg *registryClient, root string) ([]DepStatus, error) {
 	pkgPath := filepath.Join(root, "package.json")
 	data, err := os.ReadFile(pkgPath)
 	if err != nil {
@@ -95,33 +94,44 @@ func (t *TypeScriptAnalyzer) AnalyzeDeps(root string) ([]DepStatus, error) {
 		return nil, fmt.Errorf("parsing package.json: %w", err)
 	}
 
-	var results []DepStatus
-	for name, version := range pkg.Dependencies {
-		dep := DepStatus{
-			Module:         name,
-			CurrentVersion: cleanVersion(version),
-		}
+	locked := readNpmLock(root)
 
-		var info npmPackageInfo
-		url := fmt.Sprintf("https://registry.npmjs.org/%s/latest", name)
-		if err := fetchJSON(url, &info, ""); err != nil {
-			dep.Status = "unknown"
-			dep.LatestVersion = "?"
-		} else {
-			dep.LatestVersion = info.Version
-			if dep.CurrentVersion == info.Version {
-				dep.Status = "current"
-			} else {
-				dep.StaleDays = estimateStaleDays(name, info.Version)
-				if dep.StaleDays > 90 {
-					dep.Status = "outdated"
+	var results []DepStatus
+	var lookups []func(*DepStatus)
+	for scope, deps := range map[string]map[string]string{"": pkg.Dependencies, "dev": pkg.DevDependencies} {
+		for name, version := range deps {
+			version = cleanVersion(version)
+			if v, ok := locked[name]; ok {
+				version = v
+			}
+			results = append(results, DepStatus{
+				Module:         name,
+				CurrentVersion: version,
+				Scope:          scope,
+			})
+			lookups = append(lookups, func(dep *DepStatus) {
+				var info npmPackageInfo
+				url := fmt.Sprintf("https://registry.npmjs.org/%s/latest", name)
+				if err := reg.fetchJSON(url, &info, ""); err != nil {
+					dep.Status = "unknown"
+					dep.LatestVersion = "?"
 				} else {
-					dep.Status = "stale"
+					dep.LatestVersion = info.Version
+					if dep.CurrentVersion == info.Version {
+						dep.Status = "current"
+					} else {
+						dep.StaleDays = estimateStaleDays(reg, name, info.Version)
+						if dep.StaleDays > 90 {
+							dep.Status = "outdated"
+						} else {
+							dep.Status = "stale"
+						}
+					}
 				}
-			}
+			})
 		}
-		results = append(results, dep)
 	}
+	resolveLatest(reg, LangTypeScript, results, lookups)
 	return results, nil
 }
 
@@ -160,13 +170,13 @@ func cleanVersion(v string) string {
 	return strings.TrimSpace(v)
 }
 
-func estimateStaleDays(pkg, latestVersion string) int {
+func estimateStaleDays(reg *registryClient, pkg, latestVersion string) int {
 	type npmFullInfo struct {
 		Time map[string]string `json:"time"`
 	}
 	var info npmFullInfo
 	url := fmt.Sprintf("https://registry.npmjs.org/%s", pkg)
-	if err := fetchJSON(url, &info, ""); err != nil {
+	if err := reg.fetchJSON(url, &info, ""); err != nil {
 		return 30
 	}
 	if timeStr, ok := info.Time[latestVersion]; ok {
@@ -0,0 +1,269 @@
+package analyzer
+
+import (
+	"bufio"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TypeSize counts the fields and methods of one type. Types far above the
+// configured limits ("god objects") usually carry too many responsibilities.
+type TypeSize struct {
+	Path     string // file declaring the type, relative to the analysis root
+	Name     string
+	Line     int
+	Fields   int // struct fields; Go only
+	Methods  int
+	Language Language
+}
+
+// classPatterns find class-like declarations; the last non-empty group is
+// the type name. Rust impl blocks and Swift extensions add their methods to
+// the type they extend.
+var classPatterns = map[Language]*regexp.Regexp{
+	LangTypeScript: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`),
+	LangPython:     regexp.MustCompile(`^\s*class\s+(\w+)`),
+	LangRust:       regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:[\w:<>, ]+\s+for\s+)?(\w+)`),
+	LangJava:       regexp.MustCompile(`^\s*(?:(?:public|private|protected|abstract|final|static|sealed)\s+)*(?:class|enum|record)\s+(\w+)`),
+	LangRuby:       regexp.MustCompile(`^\s*class\s+([\w:]+)`),
+	LangPHP:        regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*(?:class|trait)\s+(\w+)`),
+	LangCSharp:     regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|abstract|sealed|static|partial)\s+)*(?:class|struct|record)\s+(\w+)`),
+	LangSwift:      regexp.MustCompile(`^\s*(?:(?:public|open|private|fileprivate|internal|final)\s+)*(?:class|struct|enum|actor|extension)\s+(\w+)`),
+	LangElixir:     regexp.MustCompile(`^\s*defmodule\s+([\w.]+)`),
+}
+
+// methodPatterns reuse each analyzer's function pattern. Brace languages
+// only count matches directly in the class body, so calls and control flow
+// inside method bodies are never mistaken for declarations.
+var methodPatterns = map[Language]*regexp.Regexp{
+	LangTypeScript: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*\*?(\w+)\s*(?:<[^>]*>)?\s*\(`),
+	LangPython:     pyFuncPattern,
+	LangRust:       rsFuncPattern,
+	LangJava:       javaFuncPattern,
+	LangRuby:       rbFuncPattern,
+	LangPHP:        phpFuncPattern,
+	LangCSharp:     csFuncPattern,
+	LangSwift:      swiftFuncPattern,
+	LangElixir:     exFuncPattern,
+}
+
+// Languages whose blocks are delimited by indentation (Python) or by an
+// `end` at the declaration's indentation (Ruby, Elixir).
+var indentBlockLanguages = map[Language]bool{
+	LangPython: true,
+	LangRuby:   true,
+	LangElixir: true,
+}
+
+var notMethodNames = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "super": true, "function": true,
+}
+
+// analyzeTypeSizes measures every type declared in files. Go types are read
+// from the AST and their methods collected across the package; other
+// languages are scanned per file.
+func analyzeTypeSizes(lang Language, root string, files []string) []TypeSize {
+	var out []TypeSize
+	if lang == LangGo {
+		out = goTypeSizes(root, files)
+	} else if classPatterns[lang] != nil {
+		for _, path := range files {
+			out = append(out, scanTypeSizes(lang, root, path)...)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
+
+func goTypeSizes(root string, files []string) []TypeSize {
+	types := make(map[string]*TypeSize) // keyed by package dir + type name
+	methods := make(map[string]int)
+
+	for _, path := range files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		dir := filepath.Dir(path)
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					size := &TypeSize{
+						Path:     relPath(root, path),
+						Name:     ts.Name.Name,
+						Line:     fset.Position(ts.Pos()).Line,
+						Language: LangGo,
+					}
+					if st, ok := ts.Type.(*ast.StructType); ok && st.Fields != nil {
+						for _, field := range st.Fields.List {
+							size.Fields += max(len(field.Names), 1) // embedded fields have no names
+						}
+					}
+					types[dir+"\x00"+ts.Name.Name] = size
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					methods[dir+"\x00"+receiverName(d.Recv.List[0].Type)]++
+				}
+			}
+		}
+	}
+
+	out := make([]TypeSize, 0, len(types))
+	for key, ts := range types {
+		ts.Methods = methods[key]
+		out = append(out, *ts)
+	}
+	return out
+}
+
+// scanTypeSizes counts methods per class in one file. A type declared more
+// than once in the file (partial classes, extensions, several impl blocks)
+// is reported once, at its first declaration, with the methods summed.
+func scanTypeSizes(lang Language, root, path string) []TypeSize {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var out []TypeSize
+	index := make(map[string]int)
+	for i, line := range lines {
+		m := classPatterns[lang].FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := m[len(m)-1]
+
+		var n int
+		if indentBlockLanguages[lang] {
+			n = countIndentedMethods(lang, lines, i)
+		} else {
+			n = countBracedMethods(lang, lines, i)
+		}
+
+		if j, ok := index[name]; ok {
+			out[j].Methods += n
+			continue
+		}
+		index[name] = len(out)
+		out = append(out, TypeSize{
+			Path:     relPath(root, path),
+			Name:     name,
+			Line:     i + 1,
+			Methods:  n,
+			Language: lang,
+		})
+	}
+	return out
+}
+
+// countBracedMethods counts method declarations at the top level of the
+// brace-delimited body that opens on or after lines[start].
+func countBracedMethods(lang Language, lines []string, start int) int {
+	depth, n := 0, 0
+	started := false
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if started && depth == 1 && isMethodLine(lang, line) {
+			n++
+		}
+		for _, ch := range line {
+			switch ch {
+			case '{':
+				depth++
+				started = true
+			case '}':
+				depth--
+			}
+		}
+		if started && depth <= 0 {
+			break
+		}
+	}
+	return n
+}
+
+// countIndentedMethods counts method declarations at the first indentation
+// level of the block opened by lines[start]. The block ends at the next
+// non-blank line indented no deeper than the declaration.
+func countIndentedMethods(lang Language, lines []string, start int) int {
+	base := indentWidth(lines[start])
+	bodyIndent := -1
+	n := 0
+	for _, line := range lines[start+1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || isCommentLine(trimmed) {
+			continue
+		}
+		indent := indentWidth(line)
+		if indent <= base {
+			break
+		}
+		if bodyIndent < 0 {
+			bodyIndent = indent
+		}
+		if indent == bodyIndent && isMethodLine(lang, line) {
+			n++
+		}
+	}
+	return n
+}
+
+func isMethodLine(lang Language, line string) bool {
+	m := methodPatterns[lang].FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	// "Foo foo = new Foo(...)" is a field initializer, not a declaration.
+	if eq := strings.Index(line, "="); eq >= 0 && eq < strings.Index(line, "(") {
+		return false
+	}
+	for _, name := range m[1:] {
+		if name != "" && notMethodNames[strings.TrimSpace(name)] {
+			return false
+		}
+	}
+	return true
+}
+
+// GodObjects returns the types with more than maxFields fields or maxMethods
+// methods, largest first. A non-positive limit disables that check.
+func GodObjects(types []TypeSize, maxFields, maxMethods int) []TypeSize {
+	var out []TypeSize
+	for _, ts := range types {
+		if (maxFields > 0 && ts.Fields > maxFields) || (maxMethods > 0 && ts.Methods > maxMethods) {
+			out = append(out, ts)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Fields+out[i].Methods > out[j].Fields+out[j].Methods
+	})
+	return out
+}
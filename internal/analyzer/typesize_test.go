@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestAnalyzeTypeSizes_Go(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		"store/store.go": `package store
+
+type Store struct {
+	a, b int
+	name string
+	Embedded
+}
+
+type Embedded struct{}
+
+func (s *Store) Get() {}
+`,
+		"store/more.go": `package store
+
+func (s Store) Put() {}
+func (e Embedded) Close() {}
+`,
+	})
+	files := []string{filepath.Join(root, "store", "store.go"), filepath.Join(root, "store", "more.go")}
+
+	got := analyzeTypeSizes(LangGo, root, files)
+	want := []TypeSize{
+		{Path: "store/store.go", Name: "Store", Line: 3, Fields: 4, Methods: 2, Language: LangGo},
+		{Path: "store/store.go", Name: "Embedded", Line: 9, Fields: 0, Methods: 1, Language: LangGo},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("type %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnalyzeTypeSizes_Heuristic(t *testing.T) {
+	tests := []struct {
+		lang    Language
+		file    string
+		src     string
+		methods map[string]int
+	}{
+		{LangJava, "Svc.java", `public class Svc {
+    private Map<String, Integer> cache = new HashMap<>();
+
+    public Svc() {}
+
+    public int get(String k) {
+        if (k == null) {
+            return helper(k);
+        }
+        return 0;
+    }
+
+    private static int helper(String k) { return 1; }
+}
+`, map[string]int{"Svc": 3}},
+		{LangPython, "svc.py", `class Svc:
+    def __init__(self):
+        def inner():
+            pass
+
+    async def fetch(self):
+        pass
+
+def top():
+    pass
+`, map[string]int{"Svc": 2}},
+		{LangRuby, "svc.rb", `class Svc
+  def call
+    run
+  end
+
+  def self.build
+  end
+end
+
+def outside
+end
+`, map[string]int{"Svc": 2}},
+		{LangRust, "svc.rs", `struct Svc;
+
+impl Svc {
+    pub fn new() -> Self { Svc }
+    fn run(&self) {}
+}
+
+impl Display for Svc {
+    fn fmt(&self, f: &mut Formatter) -> Result { Ok(()) }
+}
+`, map[string]int{"Svc": 3}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.lang), func(t *testing.T) {
+			root := t.TempDir()
+			testutil.WriteTree(t, root, map[string]string{tt.file: tt.src})
+			got := analyzeTypeSizes(tt.lang, root, []string{filepath.Join(root, tt.file)})
+			if len(got) != len(tt.methods) {
+				t.Fatalf("got %+v, want %v", got, tt.methods)
+			}
+			for _, ts := range got {
+				if ts.Methods != tt.methods[ts.Name] {
+					t.Errorf("%s has %d methods, want %d", ts.Name, ts.Methods, tt.methods[ts.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestGodObjects(t *testing.T) {
+	types := []TypeSize{
+		{Name: "small", Fields: 3, Methods: 4},
+		{Name: "wide", Fields: 25, Methods: 1},
+		{Name: "busy", Fields: 2, Methods: 40},
+	}
+	got := GodObjects(types, 20, 20)
+	if len(got) != 2 || got[0].Name != "busy" || got[1].Name != "wide" {
+		t.Errorf("GodObjects = %+v, want busy then wide", got)
+	}
+	if got := GodObjects(types, 0, 0); len(got) != 0 {
+		t.Errorf("disabled limits flagged %+v", got)
+	}
+}
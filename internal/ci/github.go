@@ -0,0 +1,296 @@
+// Package ci reports findings to CI platforms and chat: GitHub check runs,
+// GitLab code quality reports and merge request notes, and Slack messages.
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// maxAnnotations is how many annotations GitHub accepts per check run
+// request; the rest go in updates.
+const maxAnnotations = 50
+
+// GitHub creates check runs through the REST API.
+type GitHub struct {
+	API        string // e.g. "https://api.github.com"
+	Repository string // "owner/name"
+	Token      string
+	SHA        string // the commit to attach check runs to
+
+	http *http.Client
+}
+
+// GitHubFromEnv configures GitHub from the GitHub Actions environment:
+// GITHUB_TOKEN, GITHUB_REPOSITORY, GITHUB_API_URL, and the commit. On pull
+// requests that is the head of the branch, read from GITHUB_EVENT_PATH, as
+// GITHUB_SHA is a merge commit annotations wouldn't show on.
+func GitHubFromEnv() (*GitHub, error) {
+	g, err := GitHubAPIFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	g.SHA = os.Getenv("GITHUB_SHA")
+	if data, err := os.ReadFile(os.Getenv("GITHUB_EVENT_PATH")); err == nil {
+		var event struct {
+			PullRequest struct {
+				Head struct {
+					SHA string `json:"sha"`
+				} `json:"head"`
+			} `json:"pull_request"`
+		}
+		if json.Unmarshal(data, &event) == nil && event.PullRequest.Head.SHA != "" {
+			g.SHA = event.PullRequest.Head.SHA
+		}
+	}
+	if g.SHA == "" {
+		return nil, fmt.Errorf("GITHUB_SHA environment variable not set")
+	}
+	return g, nil
+}
+
+// GitHubAPIFromEnv configures GitHub for calls that don't need a commit,
+// such as pull request reviews, from GITHUB_TOKEN, GITHUB_REPOSITORY, and
+// GITHUB_API_URL, so it also works outside GitHub Actions.
+func GitHubAPIFromEnv() (*GitHub, error) {
+	g := &GitHub{
+		API:        os.Getenv("GITHUB_API_URL"),
+		Repository: os.Getenv("GITHUB_REPOSITORY"),
+		Token:      os.Getenv("GITHUB_TOKEN"),
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}
+	if g.API == "" {
+		g.API = "https://api.github.com"
+	}
+	switch {
+	case g.Token == "":
+		return nil, fmt.Errorf("GITHUB_TOKEN environment variable not set")
+	case g.Repository == "":
+		return nil, fmt.Errorf("GITHUB_REPOSITORY environment variable not set")
+	}
+	return g, nil
+}
+
+// CheckRun is a completed check run to create.
+type CheckRun struct {
+	Name     string // shown in the pull request's checks, e.g. "drift"
+	Title    string
+	Summary  string // markdown
+	Passed   bool
+	Findings []health.Finding
+}
+
+type annotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"annotation_level"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+}
+
+type checkOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []annotation `json:"annotations"`
+}
+
+// annotationLevels map finding levels to GitHub's.
+var annotationLevels = map[string]string{
+	"error":   "failure",
+	"warning": "warning",
+	"note":    "notice",
+}
+
+// CreateCheckRun creates run on the commit, annotating each finding at its
+// file and line, and returns the check run's URL.
+func (g *GitHub) CreateCheckRun(run CheckRun) (string, error) {
+	annotations := make([]annotation, len(run.Findings))
+	for i, f := range run.Findings {
+		line := max(1, f.Line) // annotations need a line; whole-file findings get the first
+		annotations[i] = annotation{f.Path, line, line, annotationLevels[f.Level], "drift: " + f.Rule, f.Text()}
+	}
+	conclusion := "success"
+	if !run.Passed {
+		conclusion = "failure"
+	}
+	output := func(batch []annotation) checkOutput {
+		return checkOutput{Title: run.Title, Summary: run.Summary, Annotations: batch}
+	}
+
+	first := annotations[:min(len(annotations), maxAnnotations)]
+	var created struct {
+		ID      int64  `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	err := g.send("POST", "/repos/"+g.Repository+"/check-runs", map[string]interface{}{
+		"name":       run.Name,
+		"head_sha":   g.SHA,
+		"status":     "completed",
+		"conclusion": conclusion,
+		"output":     output(first),
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("creating check run: %w", err)
+	}
+
+	for rest := annotations[len(first):]; len(rest) > 0; {
+		batch := rest[:min(len(rest), maxAnnotations)]
+		rest = rest[len(batch):]
+		path := fmt.Sprintf("/repos/%s/check-runs/%d", g.Repository, created.ID)
+		if err := g.send("PATCH", path, map[string]interface{}{"output": output(batch)}, nil); err != nil {
+			return created.HTMLURL, fmt.Errorf("adding annotations: %w", err)
+		}
+	}
+	return created.HTMLURL, nil
+}
+
+// PullRequestFile is a file a pull request changes.
+type PullRequestFile struct {
+	Path   string `json:"filename"`
+	Status string `json:"status"` // "added", "modified", "removed", "renamed", ...
+	// Patch is the file's diff hunks, without headers; GitHub leaves it out
+	// for binary files and very large diffs.
+	Patch string `json:"patch"`
+}
+
+// maxFilePages caps the pages of files listed; GitHub lists at most 3000.
+const maxFilePages = 30
+
+// PullRequestFiles lists the files pull request n changes, with their diffs.
+func (g *GitHub) PullRequestFiles(n int) ([]PullRequestFile, error) {
+	var files []PullRequestFile
+	for page := 1; page <= maxFilePages; page++ {
+		var batch []PullRequestFile
+		path := fmt.Sprintf("/repos/%s/pulls/%d/files?per_page=100&page=%d", g.Repository, n, page)
+		if err := g.send("GET", path, nil, &batch); err != nil {
+			return nil, fmt.Errorf("listing pull request files: %w", err)
+		}
+		files = append(files, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return files, nil
+}
+
+// Commentable reports whether a review comment can go on line of the new
+// version of the file: GitHub only takes lines its diff shows, added or
+// unchanged.
+func (f PullRequestFile) Commentable(line int) bool {
+	next := 0
+	for _, l := range strings.Split(f.Patch, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			// @@ -a,b +c,d @@
+			var from, count, to int
+			if _, err := fmt.Sscanf(l, "@@ -%d,%d +%d", &from, &count, &to); err != nil {
+				if _, err := fmt.Sscanf(l, "@@ -%d +%d", &from, &to); err != nil {
+					next = 0
+					continue
+				}
+			}
+			next = to
+		case next == 0 || strings.HasPrefix(l, "-") || strings.HasPrefix(l, "\\"):
+			// Outside a hunk, a removed line, or "\ No newline at end of file".
+		default:
+			if next == line {
+				return true
+			}
+			next++
+		}
+	}
+	return false
+}
+
+// ReviewComment is a comment on a line of a pull request's changes.
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"` // markdown
+}
+
+// Review is a pull request review: a summary and comments on its lines.
+type Review struct {
+	Body     string
+	Comments []ReviewComment
+}
+
+// CreateReview posts review on pull request n as a plain comment review,
+// neither approving nor requesting changes, on its latest commit, and
+// returns the review's URL.
+func (g *GitHub) CreateReview(n int, review Review) (string, error) {
+	type comment struct {
+		ReviewComment
+		Side string `json:"side"`
+	}
+	comments := make([]comment, len(review.Comments))
+	for i, c := range review.Comments {
+		comments[i] = comment{c, "RIGHT"}
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	err := g.send("POST", fmt.Sprintf("/repos/%s/pulls/%d/reviews", g.Repository, n), map[string]interface{}{
+		"body":     review.Body,
+		"event":    "COMMENT",
+		"comments": comments,
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("creating review: %w", err)
+	}
+	return created.HTMLURL, nil
+}
+
+// workflowCommands map finding levels to the GitHub Actions workflow
+// commands that annotate them.
+var workflowCommands = map[string]string{
+	"error":   "error",
+	"warning": "warning",
+	"note":    "notice",
+}
+
+// WriteAnnotations prints each finding as a GitHub Actions workflow command,
+// which the runner turns into an annotation at the file and line with no
+// token or API call needed.
+func WriteAnnotations(w io.Writer, findings []health.Finding) error {
+	for _, f := range findings {
+		props := "file=" + escapeProperty(f.Path)
+		if f.Line > 0 {
+			props += ",line=" + strconv.Itoa(f.Line)
+		}
+		props += ",title=" + escapeProperty("drift: "+f.Rule)
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", workflowCommands[f.Level], props, escapeData(f.Text())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes a workflow command's message the way the Actions
+// toolkit does.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value, where colons
+// and commas are also separators.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// send makes a GitHub API request; see sendJSON.
+func (g *GitHub) send(method, path string, payload, target interface{}) error {
+	return sendJSON(g.http, method, g.API+path, map[string]string{
+		"Authorization": "Bearer " + g.Token,
+		"Accept":        "application/vnd.github+json",
+	}, payload, target)
+}
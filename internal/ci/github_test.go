@@ -0,0 +1,114 @@
+package ci
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestGitHub_CreateCheckRun(t *testing.T) {
+	type request struct {
+		method, path string
+		body         map[string]interface{}
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, request{r.Method, r.URL.Path, body})
+		w.Write([]byte(`{"id": 7, "html_url": "https://github.com/o/r/runs/7"}`))
+	}))
+	defer srv.Close()
+
+	findings := make([]health.Finding, 60)
+	for i := range findings {
+		findings[i] = health.Finding{Rule: "complexity", Level: "warning", Path: "a.go", Line: i, Message: "too complex"}
+	}
+	g := &GitHub{API: srv.URL, Repository: "o/r", Token: "secret", SHA: "abc", http: srv.Client()}
+	url, err := g.CreateCheckRun(CheckRun{Name: "drift", Title: "64/100", Passed: false, Findings: findings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/o/r/runs/7" {
+		t.Errorf("url = %q", url)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want a create and an update", len(requests))
+	}
+	create, update := requests[0], requests[1]
+	if create.method != "POST" || create.path != "/repos/o/r/check-runs" || create.body["conclusion"] != "failure" || create.body["head_sha"] != "abc" {
+		t.Errorf("create = %s %s %v", create.method, create.path, create.body)
+	}
+	annotations := create.body["output"].(map[string]interface{})["annotations"].([]interface{})
+	first := annotations[0].(map[string]interface{})
+	if len(annotations) != 50 || first["start_line"] != 1.0 || first["annotation_level"] != "warning" {
+		t.Errorf("created with %d annotations, first %v", len(annotations), first)
+	}
+	if update.method != "PATCH" || update.path != "/repos/o/r/check-runs/7" {
+		t.Errorf("update = %s %s", update.method, update.path)
+	}
+	if n := len(update.body["output"].(map[string]interface{})["annotations"].([]interface{})); n != 10 {
+		t.Errorf("updated with %d annotations, want 10", n)
+	}
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	var b strings.Builder
+	err := WriteAnnotations(&b, []health.Finding{
+		{Rule: "complexity", Level: "warning", Path: "a.go", Line: 12, Message: "Run has cyclomatic complexity 20 (limit 15)."},
+		{Rule: "vulnerability", Level: "error", Path: "go.mod", Message: "x has GO-1: 100% bad\nupgrade"},
+		{Rule: "dead-code", Level: "note", Path: "dir,1/b.go", Line: 3, Message: "Unused is never used."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "::warning file=a.go,line=12,title=drift%3A complexity::Run has cyclomatic complexity 20 (limit 15).\n" +
+		"::error file=go.mod,title=drift%3A vulnerability::x has GO-1: 100%25 bad%0Aupgrade\n" +
+		"::notice file=dir%2C1/b.go,line=3,title=drift%3A dead-code::Unused is never used.\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestGitHub_CreateReview(t *testing.T) {
+	var method, path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"html_url": "https://github.com/o/r/pull/4#pullrequestreview-1"}`))
+	}))
+	defer srv.Close()
+
+	g := &GitHub{API: srv.URL, Repository: "o/r", Token: "secret", http: srv.Client()}
+	url, err := g.CreateReview(4, Review{Body: "summary", Comments: []ReviewComment{{Path: "a.go", Line: 3, Body: "split this"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/o/r/pull/4#pullrequestreview-1" {
+		t.Errorf("url = %q", url)
+	}
+	if method != "POST" || path != "/repos/o/r/pulls/4/reviews" || body["event"] != "COMMENT" || body["body"] != "summary" {
+		t.Errorf("request = %s %s %v", method, path, body)
+	}
+	comment := body["comments"].([]interface{})[0].(map[string]interface{})
+	if comment["path"] != "a.go" || comment["line"] != 3.0 || comment["side"] != "RIGHT" {
+		t.Errorf("comment = %v", comment)
+	}
+}
+
+func TestPullRequestFile_Commentable(t *testing.T) {
+	f := PullRequestFile{Path: "a.go", Patch: "@@ -1,3 +1,4 @@\n package a\n-func Old() {}\n+func New() {}\n+func Other() {}\n \n@@ -20 +21,2 @@\n-x\n+y\n+z\n\\ No newline at end of file"}
+	for line, want := range map[int]bool{1: true, 2: true, 3: true, 4: true, 5: false, 20: false, 21: true, 22: true, 23: false} {
+		if got := f.Commentable(line); got != want {
+			t.Errorf("Commentable(%d) = %v, want %v", line, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// codeQualityIssue is an entry of a GitLab Code Quality report.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// codeQualitySeverities map finding levels to Code Quality severities.
+var codeQualitySeverities = map[string]string{
+	"error":   "major",
+	"warning": "minor",
+	"note":    "info",
+}
+
+// CodeQuality renders findings as a GitLab Code Quality report, for
+// artifacts:reports:codequality to show in merge requests. Fingerprints
+// cover the rule, file, and subject of a finding but not its line or
+// numbers, so GitLab doesn't count an issue as fixed and new again when
+// code above it moves or a function grows.
+func CodeQuality(findings []health.Finding) ([]byte, error) {
+	issues := make([]codeQualityIssue, len(findings))
+	seen := make(map[string]int)
+	for i, f := range findings {
+		key := f.Rule + "\x00" + f.Path + "\x00" + f.Subject
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key += fmt.Sprintf("\x00%d", n) // fingerprints must be unique
+		}
+		sum := sha256.Sum256([]byte(key))
+		issues[i] = codeQualityIssue{
+			Description: f.Text(),
+			CheckName:   "drift/" + f.Rule,
+			Fingerprint: hex.EncodeToString(sum[:16]),
+			Severity:    codeQualitySeverities[f.Level],
+		}
+		issues[i].Location.Path = f.Path
+		issues[i].Location.Lines.Begin = max(1, f.Line)
+	}
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// GitLab posts merge request notes through the REST API.
+type GitLab struct {
+	API          string // e.g. "https://gitlab.com/api/v4"
+	Project      string // ID or URL-encoded path
+	MergeRequest string // the merge request's IID
+	Token        string
+
+	http *http.Client
+}
+
+// GitLabFromEnv configures GitLab from the GitLab CI environment of a
+// merge request pipeline: CI_API_V4_URL, CI_PROJECT_ID, and
+// CI_MERGE_REQUEST_IID. The job token can't write notes, so GITLAB_TOKEN
+// must hold a project or personal access token with the api scope.
+func GitLabFromEnv() (*GitLab, error) {
+	g := &GitLab{
+		API:          os.Getenv("CI_API_V4_URL"),
+		Project:      os.Getenv("CI_PROJECT_ID"),
+		MergeRequest: os.Getenv("CI_MERGE_REQUEST_IID"),
+		Token:        os.Getenv("GITLAB_TOKEN"),
+		http:         &http.Client{Timeout: 30 * time.Second},
+	}
+	if g.API == "" {
+		g.API = "https://gitlab.com/api/v4"
+	}
+	switch {
+	case g.Token == "":
+		return nil, fmt.Errorf("GITLAB_TOKEN environment variable not set")
+	case g.Project == "":
+		return nil, fmt.Errorf("CI_PROJECT_ID environment variable not set")
+	case g.MergeRequest == "":
+		return nil, fmt.Errorf("CI_MERGE_REQUEST_IID environment variable not set; run in a merge request pipeline")
+	}
+	return g, nil
+}
+
+// NoteMarker identifies drift's merge request note, so later runs update
+// it instead of adding another. The GitHub workflow marks its pull request
+// comment the same way.
+const NoteMarker = "<!-- drift-health-comment -->"
+
+// UpsertNote posts body as a note on the merge request, replacing the one
+// an earlier run posted, if any.
+func (g *GitLab) UpsertNote(body string) error {
+	body = NoteMarker + "\n" + body
+	notes := fmt.Sprintf("/projects/%s/merge_requests/%s/notes", url.PathEscape(g.Project), g.MergeRequest)
+
+	var existing []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := g.send("GET", notes+"?per_page=100&sort=asc", nil, &existing); err != nil {
+		return fmt.Errorf("listing notes: %w", err)
+	}
+	for _, n := range existing {
+		if strings.Contains(n.Body, NoteMarker) {
+			if err := g.send("PUT", fmt.Sprintf("%s/%d", notes, n.ID), map[string]string{"body": body}, nil); err != nil {
+				return fmt.Errorf("updating note: %w", err)
+			}
+			return nil
+		}
+	}
+	if err := g.send("POST", notes, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("creating note: %w", err)
+	}
+	return nil
+}
+
+// send makes a GitLab API request; see sendJSON.
+func (g *GitLab) send(method, path string, payload, target interface{}) error {
+	return sendJSON(g.http, method, g.API+path, map[string]string{"PRIVATE-TOKEN": g.Token}, payload, target)
+}
@@ -0,0 +1,75 @@
+package ci
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestCodeQuality(t *testing.T) {
+	findings := []health.Finding{
+		{Rule: "boundary", Level: "error", Path: "api/h.go", Line: 5, Message: "Import of db breaks the api → db boundary.", Subject: "db"},
+		{Rule: "boundary", Level: "error", Path: "api/h.go", Line: 9, Message: "Import of db breaks the api → db boundary.", Subject: "db"},
+		{Rule: "vulnerability", Level: "note", Path: "go.mod", Message: "x has GO-1", Language: analyzer.LangGo},
+	}
+	data, err := CodeQuality(findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Error("identical findings share a fingerprint")
+	}
+	if issues[0].CheckName != "drift/boundary" || issues[0].Severity != "major" || issues[0].Location.Lines.Begin != 5 {
+		t.Errorf("issue = %+v", issues[0])
+	}
+	if issues[2].Severity != "info" || issues[2].Location.Lines.Begin != 1 || issues[2].Description != "[go] x has GO-1" {
+		t.Errorf("whole-file issue = %+v, want info at line 1, tagged go", issues[2])
+	}
+
+	again, _ := CodeQuality(findings[1:])
+	if !strings.Contains(string(again), issues[0].Fingerprint) {
+		t.Error("fingerprint changed with the line number")
+	}
+}
+
+func TestGitLab_UpsertNote(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"id": 1, "body": "looks good"}, {"id": 2, "body": "` + NoteMarker + `\nold"}]`))
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if !strings.HasPrefix(body["body"], NoteMarker) || !strings.Contains(body["body"], "Score 80") {
+			t.Errorf("note body = %q", body["body"])
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	g := &GitLab{API: srv.URL, Project: "group/app", MergeRequest: "3", Token: "secret", http: srv.Client()}
+	if err := g.UpsertNote("Score 80"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GET /projects/group%2Fapp/merge_requests/3/notes", "PUT /projects/group%2Fapp/merge_requests/3/notes/2"}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
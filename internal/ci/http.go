@@ -0,0 +1,61 @@
+package ci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// sendJSON makes a request to url with payload, if any, as its JSON body
+// and the given headers, decoding the response into target unless it is
+// nil.
+func sendJSON(client *http.Client, method, url string, header map[string]string, payload, target interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(client, req, target)
+}
+
+// do sends req and decodes a successful JSON response into target unless it
+// is nil. Failures include the start of the response body, where APIs
+// explain them.
+func do(client *http.Client, req *http.Request, target interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+	if target == nil {
+		return nil
+	}
+	return json.Unmarshal(body, target)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
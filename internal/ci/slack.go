@@ -0,0 +1,203 @@
+package ci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// Caps on what a Slack message lists; the rest is counted.
+const (
+	maxSlackRegressions = 5
+	maxSlackFindings    = 5
+	// Slack accepts at most 10 fields per section block.
+	maxSlackFields = 10
+)
+
+// Slack posts messages as a bot through the Web API.
+type Slack struct {
+	API     string // e.g. "https://slack.com/api"
+	Token   string // a bot token with chat:write
+	Channel string // e.g. "#code-health" or a channel ID
+
+	http *http.Client
+}
+
+// SlackFromEnv configures Slack to post to channel with the bot token in
+// SLACK_BOT_TOKEN.
+func SlackFromEnv(channel string) (*Slack, error) {
+	s := &Slack{
+		API:     "https://slack.com/api",
+		Token:   os.Getenv("SLACK_BOT_TOKEN"),
+		Channel: channel,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	switch {
+	case s.Token == "":
+		return nil, fmt.Errorf("SLACK_BOT_TOKEN environment variable not set")
+	case s.Channel == "":
+		return nil, fmt.Errorf("no Slack channel: set notify.slack.channel or pass --channel")
+	}
+	return s, nil
+}
+
+// SlackReport is the summary of a run to post.
+type SlackReport struct {
+	Project     string
+	Score       health.Score // Delta is shown when not 0
+	Against     string       // what Regressions compare with
+	Regressions []health.Regression
+	Findings    []health.Finding
+	RunURL      string // the CI run that produced the report, if any
+}
+
+// slackText is a Slack text object.
+type slackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// slackBlock covers the parts of the header, section, and context blocks
+// reports use.
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackMessage is a chat.postMessage request. Text is the fallback shown in
+// notifications.
+type slackMessage struct {
+	Channel string       `json:"channel"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+// Post sends r to the channel.
+func (s *Slack) Post(r SlackReport) error {
+	body, err := SlackPayload(s.Channel, r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", s.API+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	// The Web API reports most failures with a 200 and ok set to false.
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := do(s.http, req, &resp); err != nil {
+		return fmt.Errorf("posting to Slack: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("posting to Slack: %s", resp.Error)
+	}
+	return nil
+}
+
+// SlackPayload lays r out as a chat.postMessage request for channel: the
+// score and its change, each category, the largest regressions, and the
+// first findings, errors first.
+func SlackPayload(channel string, r SlackReport) ([]byte, error) {
+	headline := fmt.Sprintf("Health score %.1f/100 (%s)", r.Score.Total, r.Score.Grade())
+	if r.Score.Delta > 0 {
+		headline += fmt.Sprintf("  ▲ +%.1f since last run", r.Score.Delta)
+	} else if r.Score.Delta < 0 {
+		headline += fmt.Sprintf("  ▼ %.1f since last run", r.Score.Delta)
+	}
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{"plain_text", "📊 drift: " + r.Project}},
+		{Type: "section", Text: &slackText{"mrkdwn", "*" + headline + "*"}},
+	}
+
+	scores := r.Score.Categories()
+	categories := make([]string, 0, len(scores))
+	for c := range scores {
+		if c != "total" {
+			categories = append(categories, c)
+		}
+	}
+	sort.Strings(categories)
+	for len(categories) > 0 {
+		batch := categories[:min(len(categories), maxSlackFields)]
+		categories = categories[len(batch):]
+		fields := make([]slackText, len(batch))
+		for i, c := range batch {
+			fields[i] = slackText{"mrkdwn", fmt.Sprintf("*%s*\n%.0f", c, scores[c])}
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+	}
+
+	if len(r.Regressions) > 0 {
+		regs := append([]health.Regression(nil), r.Regressions...)
+		sort.SliceStable(regs, func(i, j int) bool { return regs[i].Before-regs[i].After > regs[j].Before-regs[j].After })
+		var b strings.Builder
+		fmt.Fprintf(&b, "*Regressed since %s*", slackEscape(r.Against))
+		for i, reg := range regs {
+			if i == maxSlackRegressions {
+				fmt.Fprintf(&b, "\n… and %d more", len(regs)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n• %s: %.1f → %.1f", reg.Category, reg.Before, reg.After)
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{"mrkdwn", b.String()}})
+	}
+
+	if len(r.Findings) > 0 {
+		rank := map[string]int{"error": 0, "warning": 1, "note": 2}
+		findings := append([]health.Finding(nil), r.Findings...)
+		sort.SliceStable(findings, func(i, j int) bool { return rank[findings[i].Level] < rank[findings[j].Level] })
+		var b strings.Builder
+		fmt.Fprintf(&b, "*Findings (%d)*", len(findings))
+		for i, f := range findings {
+			if i == maxSlackFindings {
+				fmt.Fprintf(&b, "\n… and %d more", len(findings)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n• `%s:%d` %s", f.Path, max(1, f.Line), slackEscape(f.Text()))
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{"mrkdwn", b.String()}})
+	}
+
+	footer := "<https://github.com/greatnessinabox/drift|drift>"
+	if r.RunURL != "" {
+		footer = "<" + r.RunURL + "|CI run> · " + footer
+	}
+	blocks = append(blocks, slackBlock{Type: "context", Elements: []slackText{{"mrkdwn", footer}}})
+
+	data, err := json.MarshalIndent(slackMessage{Channel: channel, Text: "drift: " + r.Project + " " + headline, Blocks: blocks}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// slackEscape escapes the characters Slack's mrkdwn treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// RunURL links to the CI run in progress on GitHub Actions or GitLab CI,
+// or returns "" elsewhere.
+func RunURL() string {
+	if id := os.Getenv("GITHUB_RUN_ID"); id != "" {
+		server := os.Getenv("GITHUB_SERVER_URL")
+		if server == "" {
+			server = "https://github.com"
+		}
+		return server + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + id
+	}
+	return os.Getenv("CI_PIPELINE_URL")
+}
@@ -0,0 +1,91 @@
+package ci
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestSlackPayload(t *testing.T) {
+	findings := make([]health.Finding, 7)
+	for i := range findings {
+		findings[i] = health.Finding{Rule: "dead-code", Level: "note", Path: "a.go", Line: i + 1, Message: "unused"}
+	}
+	findings[6] = health.Finding{Rule: "boundary", Level: "error", Path: "api/h.go", Line: 3, Message: "api -> db <breaks>"}
+	data, err := SlackPayload("#health", SlackReport{
+		Project: "shop",
+		Score:   health.Score{Total: 72.4, Complexity: 60, Delta: -1.5},
+		Against: "the baseline",
+		Regressions: []health.Regression{
+			{Category: "complexity", Before: 70, After: 60},
+			{Category: "total", Before: 73, After: 72.4},
+		},
+		Findings: findings,
+		RunURL:   "https://ci.example/run/1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg slackMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Channel != "#health" || !strings.Contains(msg.Text, "72.4/100") || !strings.Contains(msg.Text, "▼ -1.5") {
+		t.Errorf("channel %q, text %q", msg.Channel, msg.Text)
+	}
+
+	var sections []string
+	for _, b := range msg.Blocks {
+		if b.Type == "section" && b.Text != nil {
+			sections = append(sections, b.Text.Text)
+		}
+		if len(b.Fields) > maxSlackFields {
+			t.Errorf("section has %d fields, over Slack's limit", len(b.Fields))
+		}
+	}
+	text := strings.Join(sections, "\n")
+	if !strings.Contains(text, "Regressed since the baseline*\n• complexity: 70.0 → 60.0\n• total") {
+		t.Errorf("regressions not listed largest first:\n%s", text)
+	}
+	if !strings.Contains(text, "Findings (7)*\n• `api/h.go:3` api -&gt; db &lt;breaks&gt;") || !strings.Contains(text, "… and 2 more") {
+		t.Errorf("findings not listed errors first and capped:\n%s", text)
+	}
+	if footer := msg.Blocks[len(msg.Blocks)-1]; footer.Type != "context" || !strings.Contains(footer.Elements[0].Text, "<https://ci.example/run/1|CI run>") {
+		t.Errorf("footer = %+v", footer)
+	}
+}
+
+func TestSlack_Post(t *testing.T) {
+	var channel string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb" {
+			t.Errorf("%s %s, Authorization %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var msg slackMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		channel = msg.Channel
+		if channel == "#missing" {
+			w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	s := &Slack{API: srv.URL, Token: "xoxb", Channel: "#health", http: srv.Client()}
+	if err := s.Post(SlackReport{Project: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if channel != "#health" {
+		t.Errorf("posted to %q", channel)
+	}
+
+	s.Channel = "#missing"
+	if err := s.Post(SlackReport{Project: "shop"}); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Post to a missing channel = %v, want channel_not_found", err)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// area is a subdirectory with its own .drift.yaml, and the thresholds that
+// apply under it.
+type area struct {
+	path       string // relative to the root, slash-separated
+	thresholds ThresholdConfig
+}
+
+// areaConfig is what a subdirectory's .drift.yaml may set. Anything else
+// is an error rather than silently ignored.
+type areaConfig struct {
+	Thresholds yaml.Node      `yaml:"thresholds"`
+	Exclude    []string       `yaml:"exclude"`
+	Boundaries []BoundaryRule `yaml:"boundaries"`
+}
+
+// areaFiles are the names a subdirectory's config goes by.
+var areaFiles = []string{".drift.yaml", ".drift.yml"}
+
+// For returns the thresholds that apply to the file at rel, relative to the
+// root: those of the deepest subdirectory with its own .drift.yaml that
+// holds it, or t itself.
+func (t ThresholdConfig) For(rel string) ThresholdConfig {
+	rel = filepath.ToSlash(rel)
+	best := -1
+	for i, a := range t.areas {
+		if strings.HasPrefix(rel, a.path+"/") && (best < 0 || len(a.path) > len(t.areas[best].path)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return t
+	}
+	return t.areas[best].thresholds
+}
+
+// Areas maps the subdirectories with thresholds of their own, relative to
+// the root, to those thresholds.
+func (t ThresholdConfig) Areas() map[string]ThresholdConfig {
+	areas := make(map[string]ThresholdConfig, len(t.areas))
+	for _, a := range t.areas {
+		areas[a.path] = a.thresholds
+	}
+	return areas
+}
+
+// loadAreas merges the .drift.yaml files of subdirectories under the root
+// into c, shallowest first, so that teams in a monorepo can tune their own
+// part of it: thresholds override those of the enclosing directory for the
+// files below, while excludes and boundary rules are added, with their
+// paths taken relative to the subdirectory.
+func (c *Config) loadAreas() error {
+	var dirs []string
+	filepath.WalkDir(c.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel := relSlash(c.Root, p)
+		if p != c.Root && (Excluded(c.Exclude, rel) || d.Name() == ".git") {
+			return filepath.SkipDir
+		}
+		if p != c.Root {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	})
+
+	// WalkDir visits parents before their subdirectories.
+	for _, dir := range dirs {
+		if Excluded(c.Exclude, dir) {
+			continue // by a subdirectory's exclude
+		}
+		for _, name := range areaFiles {
+			data, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(dir), name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("reading %s/%s: %w", dir, name, err)
+			}
+			if err := c.mergeArea(dir, data); err != nil {
+				return fmt.Errorf("%s/%s: %w", dir, name, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// mergeArea merges the config data of the subdirectory dir into c.
+func (c *Config) mergeArea(dir string, data []byte) error {
+	var ac areaConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&ac); err != nil {
+		return fmt.Errorf("only thresholds, exclude, and boundaries can be set for a subdirectory: %w", err)
+	}
+
+	if !ac.Thresholds.IsZero() {
+		t := c.Thresholds.For(dir + "/")
+		t.areas = nil
+		if err := ac.Thresholds.Decode(&t); err != nil {
+			return fmt.Errorf("parsing thresholds: %w", err)
+		}
+		c.Thresholds.areas = append(c.Thresholds.areas, area{path: dir, thresholds: t})
+	}
+
+	for _, ex := range ac.Exclude {
+		if strings.Contains(ex, "/") {
+			c.Exclude = append(c.Exclude, dir+"/"+ex)
+		} else {
+			// A name or glob at any depth below dir.
+			c.Exclude = append(c.Exclude, dir+"/**/"+ex+"/**")
+		}
+	}
+
+	for _, rule := range ac.Boundaries {
+		rule.Deny = inArea(dir, rule.Deny)
+		allow := make([]string, len(rule.Allow))
+		for i, a := range rule.Allow {
+			allow[i] = inArea(dir, a)
+		}
+		rule.Allow = allow
+		c.Boundaries = append(c.Boundaries, rule)
+	}
+	return nil
+}
+
+// inArea makes the importing side of the boundary rule "from -> to",
+// written in the subdirectory dir, relative to the root. The imported side
+// matches imports, which don't depend on where the rule is written.
+func inArea(dir, rule string) string {
+	from, to, ok := strings.Cut(rule, "->")
+	if !ok {
+		return rule
+	}
+	return path.Join(dir, strings.TrimSpace(from)) + " -> " + strings.TrimSpace(to)
+}
+
+func relSlash(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestLoad_Areas(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{
+		".drift.yaml": "thresholds:\n  max_complexity: 10\n  max_nesting: 3\n",
+		"services/api/.drift.yaml": `thresholds:
+  max_complexity: 25
+exclude:
+  - gen-*
+  - fixtures/**
+boundaries:
+  - name: handlers-no-db
+    deny: handlers -> internal/db
+    allow: ["handlers/admin -> internal/db"]
+`,
+		"services/api/legacy/.drift.yml": "thresholds:\n  max_nesting: 6\n",
+		"vendor/lib/.drift.yaml":         "thresholds:\n  max_complexity: 99\n",
+	})
+
+	cfg, err := Load(filepath.Join(root, ".drift.yaml"), Override{"root", root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path                   string
+		complexity, nestingMax int
+	}{
+		{"cmd/main.go", 10, 3},
+		{"services/api/server.go", 25, 3},
+		{"services/api/legacy/old.go", 25, 6},
+		{"services/apiary/x.go", 10, 3},
+		{"vendor/lib/a.go", 10, 3},
+	} {
+		th := cfg.Thresholds.For(tt.path)
+		if th.MaxComplexity != tt.complexity || th.MaxNesting != tt.nestingMax {
+			t.Errorf("For(%q) = complexity %d, nesting %d; want %d, %d",
+				tt.path, th.MaxComplexity, th.MaxNesting, tt.complexity, tt.nestingMax)
+		}
+	}
+
+	for rel, want := range map[string]bool{
+		"services/api/out/gen-1/a.go":  true,
+		"services/api/gen-2":           true,
+		"services/api/fixtures/big.go": true,
+		"services/web/gen-1/a.go":      false,
+		"fixtures/big.go":              false,
+	} {
+		if got := Excluded(cfg.Exclude, rel); got != want {
+			t.Errorf("Excluded(%q) = %v, want %v", rel, got, want)
+		}
+	}
+
+	if len(cfg.Boundaries) != 1 {
+		t.Fatalf("boundaries = %+v", cfg.Boundaries)
+	}
+	rule := cfg.Boundaries[0]
+	if rule.Deny != "services/api/handlers -> internal/db" || rule.Allow[0] != "services/api/handlers/admin -> internal/db" {
+		t.Errorf("rule = %+v", rule)
+	}
+}
+
+func TestLoad_AreaUnknownSetting(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{"web/.drift.yaml": "language: typescript\n"})
+	_, err := Load("", Override{"root", root})
+	if err == nil || !strings.Contains(err.Error(), "web/.drift.yaml") {
+		t.Errorf("err = %v, want one naming web/.drift.yaml", err)
+	}
+}
@@ -3,24 +3,112 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Root     string   `yaml:"root"`
-	Language string   `yaml:"language"` // empty = auto-detect; "go", "typescript", "python", "rust", "java"
-	Exclude  []string `yaml:"exclude"`
+	Language string   `yaml:"language"` // empty = auto-detect; one of SupportedLanguages
+	Exclude  []string `yaml:"exclude"`  // names or globs matching any path component, or doublestar globs such as "**/*_gen.go"
+
+	// Include limits analysis to files matching these doublestar globs
+	// (e.g. "src/**"), relative to Root. Empty means every file.
+	Include []string `yaml:"include"`
+
+	// Languages analyzes several languages in one run (e.g. [go, typescript]
+	// for a monorepo) and overrides Language when set.
+	Languages []string `yaml:"languages"`
+
+	// Projects analyzes each listed directory as its own project. With
+	// DiscoverProjects, nested manifests are found automatically instead.
+	Projects         []ProjectConfig `yaml:"projects"`
+	DiscoverProjects bool            `yaml:"discover_projects"`
 
 	Weights WeightConfig `yaml:"weights"`
 
+	Scoring ScoringConfig `yaml:"scoring"`
+
 	Boundaries []BoundaryRule `yaml:"boundaries"`
 
+	// Layers orders the architecture from the bottom up (e.g. domain,
+	// application, infrastructure). A layer may not import any layer listed
+	// after it; see BoundaryRules.
+	Layers []Layer `yaml:"layers"`
+
 	AI AIConfig `yaml:"ai"`
 
 	Thresholds ThresholdConfig `yaml:"thresholds"`
+
+	Coverage CoverageConfig `yaml:"coverage"`
+
+	Deps DepsConfig `yaml:"deps"`
+
+	Naming NamingConfig `yaml:"naming"`
+
+	Licenses LicensesConfig `yaml:"licenses"`
+
+	DeadCode DeadCodeConfig `yaml:"deadcode"`
+
+	Goals GoalsConfig `yaml:"goals"`
+
+	Theme ThemeConfig `yaml:"theme"`
+
+	Watch WatchConfig `yaml:"watch"`
+
+	History HistoryConfig `yaml:"history"`
+
+	Notify NotifyConfig `yaml:"notify"`
+
+	Owners OwnersConfig `yaml:"owners"`
+
+	Fix FixConfig `yaml:"fix"`
+
+	// Offline keeps drift off the network: dependency freshness and
+	// advisories come only from the registry cache, however old, and AI
+	// requests fail.
+	Offline bool `yaml:"offline"`
+
+	// Profiles are named sets of settings applied over the rest of the
+	// config with --profile or DRIFT_PROFILE, e.g. a stricter "ci".
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"`
+}
+
+// SupportedLanguages are the values language, languages, and a project's
+// language accept.
+var SupportedLanguages = []string{"go", "typescript", "python", "rust", "java", "ruby", "php", "csharp", "swift", "elixir"}
+
+// validLanguages checks the configured languages are supported, rather
+// than letting a misspelled one be analyzed as Go.
+func (c *Config) validLanguages() error {
+	check := func(key, lang string) error {
+		if lang != "" && !slices.Contains(SupportedLanguages, lang) {
+			return fmt.Errorf("%s: unsupported language %q (supported: %s)", key, lang, strings.Join(SupportedLanguages, ", "))
+		}
+		return nil
+	}
+	if err := check("language", c.Language); err != nil {
+		return err
+	}
+	for _, lang := range c.Languages {
+		if err := check("languages", lang); err != nil {
+			return err
+		}
+	}
+	for _, p := range c.Projects {
+		if err := check("projects["+p.Path+"].language", p.Language); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type WeightConfig struct {
@@ -29,22 +117,391 @@ type WeightConfig struct {
 	Boundaries float64 `yaml:"boundaries"`
 	DeadCode   float64 `yaml:"dead_code"`
 	Coverage   float64 `yaml:"coverage"`
+	// Duplication weights the share of source lines that are copies of
+	// code found elsewhere.
+	Duplication float64 `yaml:"duplication"`
+	// Debt weights TODO/FIXME/HACK/XXX markers per thousand lines of code.
+	Debt float64 `yaml:"debt"`
+	// Maintainability weights the maintainability index (Halstead volume,
+	// complexity, and size). Off by default; only Go files are measured.
+	Maintainability float64 `yaml:"maintainability"`
+	// Testing weights the ratio of test files to source files. Off by
+	// default, since coverage usually measures the same thing better.
+	Testing float64 `yaml:"testing"`
+	// Coupling weights how many packages depend on unstable packages. Off
+	// by default.
+	Coupling float64 `yaml:"coupling"`
+	// Security weights known vulnerabilities in dependencies, by severity.
+	Security float64 `yaml:"security"`
+}
+
+// ScoringConfig tunes how findings turn into scores.
+type ScoringConfig struct {
+	// AbsoluteCounts charges boundary violations, import cycles, and dead
+	// code per finding whatever the codebase's size, instead of per
+	// thousand lines of code and per exported function.
+	AbsoluteCounts bool `yaml:"absolute_counts"`
+}
+
+type ProjectConfig struct {
+	Path     string `yaml:"path"`     // relative to root, e.g. "services/api"
+	Name     string `yaml:"name"`     // display name (defaults to path)
+	Language string `yaml:"language"` // empty = auto-detect from the project's manifest
+}
+
+// CoverageConfig controls where the Coverage score comes from. With neither
+// field set, drift looks for lcov.info, coverage.out, or coverage.xml under
+// the root.
+type CoverageConfig struct {
+	Path    string `yaml:"path"`    // report to read: lcov, Go coverprofile, or Cobertura XML
+	Run     bool   `yaml:"run"`     // run `go test -coverprofile` on each full analysis (Go only)
+	Timeout int    `yaml:"timeout"` // seconds allowed for the test run
+}
+
+// NamingConfig enables naming convention checks. Rules maps a language
+// ("go", "python", ...) to a regex; exported function names that match it
+// are flagged. Languages without an entry use drift's built-in rule, and an
+// empty regex turns the check off for that language.
+type NamingConfig struct {
+	Enabled bool              `yaml:"enabled"`
+	Rules   map[string]string `yaml:"rules"`
+}
+
+// DepsConfig controls dependency freshness lookups.
+type DepsConfig struct {
+	CacheTTL int `yaml:"cache_ttl"` // hours registry responses are reused across runs (0 = always refetch)
+
+	// IncludeDev also analyzes development and test dependencies
+	// (devDependencies, dev-dependencies, test scopes). They are listed
+	// separately and weigh less in the dependency score.
+	IncludeDev bool `yaml:"include_dev"`
+
+	// Source picks where latest versions, release dates, and advisories come
+	// from: empty queries each ecosystem's registry and OSV.dev, while
+	// "deps.dev" asks deps.dev for npm, PyPI, crates.io, Maven, RubyGems,
+	// NuGet, and Go modules.
+	Source string `yaml:"source"` // "" or "deps.dev"
+}
+
+// LicensesConfig lists dependency licenses the project can't accept, as SPDX
+// identifiers (e.g. "GPL-3.0", "AGPL-3.0"). A base identifier also covers its
+// "-only" and "-or-later" variants.
+type LicensesConfig struct {
+	Deny []string `yaml:"deny"`
+}
+
+// DeadCodeConfig lists exports that are unused on purpose, such as plugin
+// entry points or methods only reflection calls. Each Ignore glob is matched
+// against the declaration's name ("MarshalJSON", "Handle*"), where a method
+// also matches by its bare name, and against the name of its file
+// ("*_plugin.go").
+type DeadCodeConfig struct {
+	Ignore []string `yaml:"ignore"`
+}
+
+// Ignores reports whether the dead declaration name, found in file, is on
+// the ignore list.
+func (d DeadCodeConfig) Ignores(name, file string) bool {
+	_, method, isMethod := strings.Cut(name, ".")
+	for _, pattern := range d.Ignore {
+		for _, s := range []string{name, filepath.Base(file)} {
+			if ok, _ := path.Match(pattern, s); ok {
+				return true
+			}
+		}
+		if ok, _ := path.Match(pattern, method); ok && isMethod {
+			return true
+		}
+	}
+	return false
+}
+
+// GoalsConfig sets a total score to work toward and, optionally, the date
+// to reach it by. `drift report` and the dashboard show the progress.
+type GoalsConfig struct {
+	Total float64 `yaml:"total"` // 0 = no goal
+	By    string  `yaml:"by"`    // YYYY-MM-DD; empty = no deadline
+}
+
+// Deadline parses By. It returns the zero time when there is no deadline.
+func (g GoalsConfig) Deadline() (time.Time, error) {
+	if g.By == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, g.By)
+}
+
+// WatchConfig controls how the dashboard keeps up with changes.
+type WatchConfig struct {
+	// Disabled stops re-analyzing files as they are saved.
+	Disabled bool `yaml:"disabled"`
+	// Refresh re-analyzes everything at this interval (e.g. "5m") when
+	// watching is disabled. Empty means only on request.
+	Refresh string `yaml:"refresh"`
+	// Mode is how changes are noticed: WatchEvents (default) or WatchPoll,
+	// for filesystems that don't deliver events, such as NFS, Docker bind
+	// mounts, and WSL2 mounts of Windows drives.
+	Mode string `yaml:"mode"`
+	// PollInterval is how often WatchPoll scans for changes, e.g. "2s";
+	// empty means every two seconds.
+	PollInterval string `yaml:"poll_interval"`
+}
+
+// Ways WatchConfig.Mode notices changes.
+const (
+	WatchEvents = "events" // filesystem notifications
+	WatchPoll   = "poll"   // comparing modification times every PollInterval
+)
+
+// defaultPollInterval is how often polling scans without a PollInterval.
+const defaultPollInterval = 2 * time.Second
+
+// PollEvery parses PollInterval.
+func (w WatchConfig) PollEvery() (time.Duration, error) {
+	if w.PollInterval == "" {
+		return defaultPollInterval, nil
+	}
+	d, err := time.ParseDuration(w.PollInterval)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("%s is not positive", w.PollInterval)
+	}
+	return d, err
+}
+
+// Interval parses Refresh. It returns 0 when there is no refresh interval.
+func (w WatchConfig) Interval() (time.Duration, error) {
+	if w.Refresh == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(w.Refresh)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("%s is not positive", w.Refresh)
+	}
+	return d, err
+}
+
+// Ways HistoryConfig.Sample picks the commits the sparklines chart.
+const (
+	SampleCommits = "commits" // every Every-th commit
+	SampleDaily   = "daily"   // the last commit of each day
+	SampleWeekly  = "weekly"  // the last commit of each week
+	SampleTags    = "tags"    // tagged commits
+)
+
+// HistoryConfig controls which past commits the dashboard's sparklines
+// chart when there aren't enough recorded runs.
+type HistoryConfig struct {
+	Depth  int    `yaml:"depth"`  // points to chart
+	Sample string `yaml:"sample"` // SampleCommits (default), SampleDaily, SampleWeekly, or SampleTags
+	Every  int    `yaml:"every"`  // with SampleCommits, chart every Nth commit
+	Branch string `yaml:"branch"` // branch or other revision to walk back from; empty = HEAD
+}
+
+// Sampled reports whether the sparklines should chart commits picked
+// other than the last Depth on HEAD, so recorded runs don't stand in
+// for them.
+func (h HistoryConfig) Sampled() bool {
+	return (h.Sample != "" && h.Sample != SampleCommits) || h.Every > 1 || h.Branch != ""
+}
+
+// Validate reports an unknown sampling strategy or a depth or step below one.
+func (h HistoryConfig) Validate() error {
+	switch h.Sample {
+	case "", SampleCommits, SampleDaily, SampleWeekly, SampleTags:
+	default:
+		return fmt.Errorf("history.sample must be commits, daily, weekly, or tags, not %q", h.Sample)
+	}
+	if h.Depth < 1 || h.Every < 1 {
+		return fmt.Errorf("history.depth and history.every must be at least 1")
+	}
+	return nil
+}
+
+// OwnersConfig sets how `drift owners` attributes issues.
+type OwnersConfig struct {
+	By         string `yaml:"by"`         // "author" (default) or "team"
+	CodeOwners string `yaml:"codeowners"` // CODEOWNERS file for "team"; empty = the usual locations
+}
+
+// FixConfig sets how `drift fix` verifies an applied patch.
+type FixConfig struct {
+	Test    string `yaml:"test"`    // shell command run after each patch, e.g. "go test ./..."; empty = none
+	Timeout int    `yaml:"timeout"` // seconds allowed for the test command; 0 = no limit
+}
+
+// NotifyConfig sets where `drift notify` posts the report summary.
+type NotifyConfig struct {
+	Slack SlackConfig `yaml:"slack"`
+}
+
+// SlackConfig names the channel `drift notify slack` posts to. The bot token
+// is read from SLACK_BOT_TOKEN rather than the config, to keep it out of the
+// repository.
+type SlackConfig struct {
+	Channel string `yaml:"channel"` // e.g. "#code-health" or a channel ID
+}
+
+// ThemeColors names the colors a theme defines and ThemeConfig.Colors can
+// override.
+var ThemeColors = []string{"green", "lime", "yellow", "orange", "red", "cyan", "dim", "text", "accent", "purple", "border"}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ThemeConfig picks the colors of the dashboard and reports. It may also be
+// written as just the theme name (`theme: light`).
+type ThemeConfig struct {
+	Name   string            `yaml:"name"`   // "dark" (default), "light", or "high-contrast"
+	Colors map[string]string `yaml:"colors"` // hex overrides by ThemeColors name, e.g. {accent: "#FF8800"}
+}
+
+// UnmarshalYAML accepts a bare theme name as well as the full mapping.
+func (t *ThemeConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.Name)
+	}
+	type plain ThemeConfig
+	return value.Decode((*plain)(t))
 }
 
+// Validate checks the theme name and that overrides are known colors given
+// as hex.
+func (t ThemeConfig) Validate() error {
+	switch t.Name {
+	case "", "dark", "light", "high-contrast":
+	default:
+		return fmt.Errorf("theme %q: must be dark, light, or high-contrast", t.Name)
+	}
+	for name, value := range t.Colors {
+		if !slices.Contains(ThemeColors, name) {
+			return fmt.Errorf("theme color %q: must be one of %s", name, strings.Join(ThemeColors, ", "))
+		}
+		if !hexColor.MatchString(value) {
+			return fmt.Errorf("theme color %s: %q is not a hex color such as #FF8800", name, value)
+		}
+	}
+	return nil
+}
+
+// BoundaryRule forbids imports from one part of the tree into another. Each
+// side of "from -> to" is a directory prefix (from) or import substring (to),
+// or a doublestar glob such as "internal/** -> cmd/**". Allow lists
+// exceptions in the same form.
 type BoundaryRule struct {
-	Deny string `yaml:"deny"` // e.g. "pkg/api -> internal/db"
+	Name        string   `yaml:"name"`        // shown with violations, e.g. "domain-is-pure"
+	Description string   `yaml:"description"` // why the boundary exists
+	Deny        string   `yaml:"deny"`        // e.g. "pkg/api -> internal/db"
+	Allow       []string `yaml:"allow"`       // e.g. ["internal/testutil -> cmd/**"]
+
+	// From and To replace the deny pattern's sides in violations. Rules
+	// derived from layers set them to the layer names.
+	From string `yaml:"-"`
+	To   string `yaml:"-"`
+}
+
+// Layer is one level of a layered architecture and the directories it
+// covers, written like the sides of a boundary rule ("internal/domain" or
+// "internal/domain/**").
+type Layer struct {
+	Name  string   `yaml:"name"`
+	Paths []string `yaml:"paths"`
+}
+
+// BoundaryRules returns the configured boundary rules followed by those
+// derived from Layers: for every pair of layers, the lower one may not
+// import the higher one.
+func (c *Config) BoundaryRules() []BoundaryRule {
+	rules := append([]BoundaryRule(nil), c.Boundaries...)
+	for i, lower := range c.Layers {
+		for _, higher := range c.Layers[i+1:] {
+			for _, from := range lower.Paths {
+				for _, to := range higher.Paths {
+					rules = append(rules, BoundaryRule{
+						Name:        "layers",
+						Description: fmt.Sprintf("%s sits below %s and may not import it", lower.Name, higher.Name),
+						Deny:        from + " -> " + to,
+						From:        lower.Name,
+						To:          higher.Name,
+					})
+				}
+			}
+		}
+	}
+	return rules
 }
 
 type AIConfig struct {
-	Provider  string `yaml:"provider"`   // "anthropic" or "openai"
+	Provider  string `yaml:"provider"`   // "anthropic", "openai", or "copilot"
 	Model     string `yaml:"model"`      // e.g. "claude-sonnet-4-5-20250929" or "gpt-4o"
 	MaxTokens int    `yaml:"max_tokens"` // response token budget per AI call
+	Timeout   string `yaml:"timeout"`    // per attempt, e.g. "60s"; empty = no limit
+	Retries   int    `yaml:"retries"`    // extra attempts after rate limits and server errors
+
+	// KeyFile holds the provider's API key, for when ANTHROPIC_API_KEY or
+	// OPENAI_API_KEY isn't set, e.g. "~/.config/drift/anthropic.key". A
+	// leading ~ is the home directory.
+	KeyFile string `yaml:"key_file"`
+
+	// MaxPromptTokens caps the estimated size of a codebase prompt: lists
+	// are cut, keeping the worst items, and code is left out first. 0 = no
+	// limit.
+	MaxPromptTokens int `yaml:"max_prompt_tokens"`
+	// InputPrice and OutputPrice override the model's list price, in US
+	// dollars per million tokens, for cost estimates.
+	InputPrice  float64 `yaml:"input_price"`
+	OutputPrice float64 `yaml:"output_price"`
+
+	// Redact limits what of the code prompts send to the provider.
+	Redact RedactConfig `yaml:"redact"`
+}
+
+// RedactConfig sets what is stripped from prompts before they are sent, for
+// teams whose policies keep some of the code from third parties.
+type RedactConfig struct {
+	Strings  bool `yaml:"strings"`  // empty string literals in code
+	Comments bool `yaml:"comments"` // drop comments from code
+	// Paths replaces source file paths with aliases such as file1.go,
+	// restored in answers.
+	Paths bool `yaml:"paths"`
+	// MetricsOnly sends no code at all, only metrics, names, and paths.
+	MetricsOnly bool `yaml:"metrics_only"`
+}
+
+// AltersCode reports whether the code prompts include differs from the
+// files, so patches against it wouldn't apply.
+func (r RedactConfig) AltersCode() bool {
+	return r.Strings || r.Comments || r.MetricsOnly
+}
+
+// RequestTimeout parses Timeout. It returns 0 when attempts aren't limited.
+func (c AIConfig) RequestTimeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("%s is not positive", c.Timeout)
+	}
+	return d, err
 }
 
 type ThresholdConfig struct {
-	MaxComplexity int     `yaml:"max_complexity"` // per-function complexity threshold
-	MaxStaleDays  int     `yaml:"max_stale_days"` // dependency staleness threshold
-	MinScore      float64 `yaml:"min_score"`      // minimum acceptable health score
+	MaxComplexity int `yaml:"max_complexity"` // per-function complexity threshold
+	MaxFuncLines  int `yaml:"max_func_lines"` // per-function length threshold (0 = off)
+	MaxParams     int `yaml:"max_params"`     // per-function parameter count threshold (0 = off)
+	MaxNesting    int `yaml:"max_nesting"`    // per-function nesting depth threshold (0 = off)
+	MaxFileLines  int `yaml:"max_file_lines"` // files above this are flagged as god files (0 = off)
+
+	MaxStructFields int `yaml:"max_struct_fields"` // types with more fields are flagged as god objects (0 = off)
+	MaxMethods      int `yaml:"max_methods"`       // types with more methods are flagged as god objects (0 = off)
+
+	MinDuplicateLines int     `yaml:"min_duplicate_lines"` // shortest repeated block reported as duplication
+	MinTestRatio      float64 `yaml:"min_test_ratio"`      // test files per source file that earns a full testing score
+	MaxStaleDays      int     `yaml:"max_stale_days"`      // dependency staleness threshold
+	MinScore          float64 `yaml:"min_score"`           // minimum acceptable health score
+
+	// areas are the thresholds of subdirectories with their own
+	// .drift.yaml; see For.
+	areas []area
 }
 
 func Defaults() *Config {
@@ -63,43 +520,160 @@ func Defaults() *Config {
 			"build",
 		},
 		Weights: WeightConfig{
-			Complexity: 0.30,
-			Deps:       0.20,
-			Boundaries: 0.20,
-			DeadCode:   0.15,
-			Coverage:   0.15,
+			Complexity:  0.25,
+			Deps:        0.10,
+			Security:    0.10,
+			Boundaries:  0.15,
+			DeadCode:    0.10,
+			Coverage:    0.15,
+			Duplication: 0.10,
+			Debt:        0.05,
 		},
 		AI: AIConfig{
-			Provider:  "anthropic",
-			Model:     "claude-sonnet-4-5-20250929",
-			MaxTokens: 1024,
+			Provider:        "anthropic",
+			MaxTokens:       1024,
+			Timeout:         "60s",
+			Retries:         2,
+			MaxPromptTokens: 8000,
+		},
+		Coverage: CoverageConfig{
+			Timeout: 300,
+		},
+		Fix: FixConfig{
+			Timeout: 300,
+		},
+		Deps: DepsConfig{
+			CacheTTL: 24,
+		},
+		History: HistoryConfig{
+			Depth:  10,
+			Sample: SampleCommits,
+			Every:  1,
 		},
 		Thresholds: ThresholdConfig{
 			MaxComplexity: 15,
-			MaxStaleDays:  90,
-			MinScore:      70,
+			MaxNesting:    4,
+			MaxFileLines:  500,
+
+			MaxStructFields: 20,
+			MaxMethods:      20,
+
+			MinDuplicateLines: 6,
+			MinTestRatio:      0.5,
+			MaxStaleDays:      90,
+			MinScore:          70,
 		},
 	}
 }
 
-func Load(path string) (*Config, error) {
+// Load reads the config file at path, or DRIFT_CONFIG, or the first of
+// .drift.yaml and its variants in the working directory, over the user's
+// config and the defaults. The profile DRIFT_PROFILE names is applied over
+// the files. The DRIFT_* environment variables, then overrides, take
+// precedence over the files, so a CI job can change a setting without one.
+// The .drift.yaml files of subdirectories under the root are merged in
+// last; see loadAreas.
+func Load(path string, overrides ...Override) (*Config, error) {
+	return LoadProfile(path, "", overrides...)
+}
+
+// LoadProfile is Load applying the named profile, or DRIFT_PROFILE's if
+// profile is empty.
+func LoadProfile(path, profile string, overrides ...Override) (*Config, error) {
 	cfg := Defaults()
 
+	if user := UserConfigPath(); user != "" {
+		data, err := os.ReadFile(user)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading user config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parsing user config %s: %w", user, err)
+		}
+	}
+
+	if path == "" {
+		path = os.Getenv(envPrefix + "CONFIG")
+	}
 	if path == "" {
 		path = findConfigFile()
 	}
 
-	if path == "" {
-		return cfg, nil
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file: %w", err)
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv(envPrefix + "PROFILE")
+	}
+	if profile != "" {
+		if err := cfg.applyProfile(profile); err != nil {
+			return nil, err
+		}
 	}
 
-	data, err := os.ReadFile(path)
+	env, err := envOverrides(os.Environ())
 	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
+		return nil, err
+	}
+	for _, o := range append(env, overrides...) {
+		if err := cfg.Set(o.Key, o.Value); err != nil {
+			return nil, fmt.Errorf("overriding %s: %w", o.Key, err)
+		}
+	}
+
+	if err := cfg.validLanguages(); err != nil {
+		return nil, err
+	}
+
+	if _, err := cfg.Goals.Deadline(); err != nil {
+		return nil, fmt.Errorf("goals.by must be a YYYY-MM-DD date: %w", err)
+	}
+
+	if err := cfg.Theme.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := cfg.Watch.Interval(); err != nil {
+		return nil, fmt.Errorf("watch.refresh must be a duration such as 5m: %w", err)
+	}
+	switch cfg.Watch.Mode {
+	case "", WatchEvents, WatchPoll:
+	default:
+		return nil, fmt.Errorf("watch.mode must be events or poll, not %q", cfg.Watch.Mode)
+	}
+	if _, err := cfg.Watch.PollEvery(); err != nil {
+		return nil, fmt.Errorf("watch.poll_interval must be a duration such as 2s: %w", err)
 	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file: %w", err)
+	if _, err := cfg.AI.RequestTimeout(); err != nil {
+		return nil, fmt.Errorf("ai.timeout must be a duration such as 60s: %w", err)
+	}
+	if cfg.AI.Retries < 0 {
+		return nil, fmt.Errorf("ai.retries must not be negative")
+	}
+	if cfg.AI.MaxPromptTokens < 0 {
+		return nil, fmt.Errorf("ai.max_prompt_tokens must not be negative")
+	}
+	if cfg.AI.InputPrice < 0 || cfg.AI.OutputPrice < 0 {
+		return nil, fmt.Errorf("ai.input_price and ai.output_price must not be negative")
+	}
+
+	if err := cfg.History.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Owners.By {
+	case "", "author", "team":
+	default:
+		return nil, fmt.Errorf("owners.by must be author or team, not %q", cfg.Owners.By)
 	}
 
 	if cfg.Root == "" {
@@ -115,9 +689,44 @@ func Load(path string) (*Config, error) {
 		cfg.Root = abs
 	}
 
+	if err := cfg.loadAreas(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// UserConfigPath is where the user's config, holding defaults for every
+// project, lives: drift/config.yaml under $XDG_CONFIG_HOME, or ~/.config.
+// It is "" when there is no home directory.
+func UserConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "drift", "config.yaml")
+}
+
+// applyProfile sets what the profile name sets.
+func (c *Config) applyProfile(name string) error {
+	node, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		if len(names) == 0 {
+			return fmt.Errorf("no profile %q: no profiles are configured", name)
+		}
+		return fmt.Errorf("no profile %q (configured: %s)", name, strings.Join(names, ", "))
+	}
+	if err := node.Decode(c); err != nil {
+		return fmt.Errorf("parsing profile %s: %w", name, err)
+	}
+	return nil
+}
+
 func findConfigFile() string {
 	candidates := []string{
 		".drift.yaml",
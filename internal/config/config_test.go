@@ -0,0 +1,116 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/testutil"
+)
+
+func TestLoad_UserConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	testutil.WriteTree(t, home, map[string]string{
+		"drift/config.yaml": "theme: light\noffline: true\nai:\n  provider: openai\n  key_file: ~/keys/openai\n",
+	})
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{".drift.yaml": "ai:\n  provider: anthropic\n"})
+
+	cfg, err := Load(filepath.Join(root, ".drift.yaml"), Override{"root", root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The project's file wins where both set something.
+	if cfg.Theme.Name != "light" || !cfg.Offline || cfg.AI.Provider != "anthropic" || cfg.AI.KeyFile != "~/keys/openai" {
+		t.Errorf("theme %q, offline %v, provider %q, key file %q", cfg.Theme.Name, cfg.Offline, cfg.AI.Provider, cfg.AI.KeyFile)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	testutil.WriteTree(t, home, map[string]string{
+		"drift/config.yaml": "profiles:\n  local:\n    offline: true\n",
+	})
+	root := t.TempDir()
+	testutil.WriteTree(t, root, map[string]string{".drift.yaml": `thresholds:
+  min_score: 70
+profiles:
+  ci:
+    thresholds:
+      min_score: 85
+    ai:
+      provider: openai
+`})
+	path := filepath.Join(root, ".drift.yaml")
+
+	cfg, err := LoadProfile(path, "ci", Override{"root", root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Thresholds.MinScore != 85 || cfg.AI.Provider != "openai" || cfg.Thresholds.MaxComplexity != 15 {
+		t.Errorf("ci: min_score %v, provider %q, max_complexity %d", cfg.Thresholds.MinScore, cfg.AI.Provider, cfg.Thresholds.MaxComplexity)
+	}
+
+	t.Setenv("DRIFT_PROFILE", "local")
+	if cfg, err := Load(path, Override{"root", root}); err != nil || !cfg.Offline || cfg.Thresholds.MinScore != 70 {
+		t.Errorf("DRIFT_PROFILE=local: %+v, %v", cfg, err)
+	}
+
+	_, err = LoadProfile(path, "nightly", Override{"root", root})
+	if err == nil || !strings.Contains(err.Error(), "configured: ci, local") {
+		t.Errorf("err = %v, want the configured profiles listed", err)
+	}
+}
+
+func TestLoad_Languages(t *testing.T) {
+	root := t.TempDir()
+	for _, o := range []Override{
+		{"language", "golang"},
+		{"languages", "go, typscript"},
+		{"projects", `[{path: web, language: js}]`},
+	} {
+		_, err := Load("", Override{"root", root}, o)
+		if err == nil || !strings.Contains(err.Error(), "unsupported language") {
+			t.Errorf("%s=%s: err = %v, want an unsupported language", o.Key, o.Value, err)
+		}
+	}
+
+	cfg, err := Load("", Override{"root", root}, Override{"languages", "go,typescript"}, Override{"language", "python"}, Override{"languages", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Language != "python" || len(cfg.Languages) != 0 {
+		t.Errorf("language %q, languages %v; want python alone", cfg.Language, cfg.Languages)
+	}
+}
+
+func TestHistoryConfig(t *testing.T) {
+	for _, tt := range []struct {
+		h       HistoryConfig
+		sampled bool
+		valid   bool
+	}{
+		{HistoryConfig{Depth: 20, Every: 1}, false, true},
+		{HistoryConfig{Depth: 20, Every: 1, Sample: SampleCommits}, false, true},
+		{HistoryConfig{Depth: 20, Every: 5}, true, true},
+		{HistoryConfig{Depth: 20, Every: 1, Sample: SampleDaily}, true, true},
+		{HistoryConfig{Depth: 20, Every: 1, Sample: SampleWeekly}, true, true},
+		{HistoryConfig{Depth: 20, Every: 1, Sample: SampleTags}, true, true},
+		{HistoryConfig{Depth: 20, Every: 1, Branch: "main"}, true, true},
+		{HistoryConfig{Depth: 20, Every: 1, Sample: "hourly"}, true, false},
+		{HistoryConfig{Depth: 0, Every: 1}, false, false},
+		{HistoryConfig{Depth: 20, Every: 0}, false, false},
+	} {
+		if got := tt.h.Sampled(); got != tt.sampled {
+			t.Errorf("%+v: Sampled() = %v, want %v", tt.h, got, tt.sampled)
+		}
+		if err := tt.h.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: Validate() = %v, want valid %v", tt.h, err, tt.valid)
+		}
+	}
+	if err := Defaults().History.Validate(); err != nil {
+		t.Errorf("default history config: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the environment variables that override settings, such
+// as DRIFT_AI_PROVIDER for ai.provider. DRIFT_CONFIG names the config file
+// and DRIFT_PROFILE the profile to apply.
+const envPrefix = "DRIFT_"
+
+// Override is a setting given for one run, by its key in the config file
+// ("ai.provider", "thresholds.max_complexity") and a value as it would be
+// written there. Lists also take comma-separated values.
+type Override struct {
+	Key   string
+	Value string
+}
+
+// Set changes the setting key to value, as Override describes.
+func (c *Config) Set(key, value string) error {
+	field, err := lookup(reflect.ValueOf(c).Elem(), key)
+	if err != nil {
+		return err
+	}
+	v := reflect.New(field.Type()).Elem()
+	switch {
+	case field.Kind() == reflect.String:
+		v.SetString(value)
+	case field.Type() == reflect.TypeOf([]string(nil)) && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		if err := yaml.Unmarshal([]byte(value), v.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	field.Set(v)
+	return nil
+}
+
+// lookup finds the field of the struct v that key names by yaml tags.
+func lookup(v reflect.Value, key string) (reflect.Value, error) {
+	name, rest, nested := strings.Cut(key, ".")
+	for i := 0; i < v.NumField(); i++ {
+		if yamlName(v.Type().Field(i)) != name {
+			continue
+		}
+		field := v.Field(i)
+		if !nested {
+			return field, nil
+		}
+		if field.Kind() != reflect.Struct {
+			break
+		}
+		return lookup(field, rest)
+	}
+	return reflect.Value{}, fmt.Errorf("no setting %q", key)
+}
+
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" || !f.IsExported() {
+		return ""
+	}
+	return name
+}
+
+// envOverrides reads the DRIFT_* variables in environ, a list of
+// KEY=value pairs, as overrides, in a stable order. DRIFT_CONFIG and
+// DRIFT_PROFILE are left to Load.
+func envOverrides(environ []string) ([]Override, error) {
+	var overrides []Override
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, envPrefix) || name == envPrefix+"CONFIG" || name == envPrefix+"PROFILE" {
+			continue
+		}
+		key, ok := envKey(reflect.TypeOf(Config{}), strings.TrimPrefix(name, envPrefix))
+		if !ok {
+			return nil, fmt.Errorf("%s matches no setting", name)
+		}
+		overrides = append(overrides, Override{Key: key, Value: value})
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Key < overrides[j].Key })
+	return overrides, nil
+}
+
+// envKey finds the key of the setting of struct t that name, such as
+// THRESHOLDS_MAX_COMPLEXITY, spells in upper case with underscores. Keys
+// contain underscores themselves, so each field that name starts with is
+// tried.
+func envKey(t reflect.Type, name string) (string, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		tag := yamlName(t.Field(i))
+		if tag == "" {
+			continue
+		}
+		upper := strings.ToUpper(tag)
+		if name == upper {
+			return tag, true
+		}
+		if rest, ok := strings.CutPrefix(name, upper+"_"); ok && t.Field(i).Type.Kind() == reflect.Struct {
+			if key, ok := envKey(t.Field(i).Type, rest); ok {
+				return tag + "." + key, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConfig_Set(t *testing.T) {
+	cfg := Defaults()
+	for _, o := range []Override{
+		{"language", "typescript"},
+		{"exclude", "vendor, gen-*"},
+		{"include", `["src/**"]`},
+		{"ai.provider", "openai"},
+		{"ai.redact.strings", "true"},
+		{"thresholds.max_complexity", "20"},
+		{"weights.debt", "0.1"},
+		{"watch.refresh", "5m"},
+	} {
+		if err := cfg.Set(o.Key, o.Value); err != nil {
+			t.Fatalf("Set(%q, %q): %v", o.Key, o.Value, err)
+		}
+	}
+	if cfg.Language != "typescript" || !slices.Equal(cfg.Exclude, []string{"vendor", "gen-*"}) ||
+		!slices.Equal(cfg.Include, []string{"src/**"}) || cfg.AI.Provider != "openai" || !cfg.AI.Redact.Strings ||
+		cfg.Thresholds.MaxComplexity != 20 || cfg.Weights.Debt != 0.1 || cfg.Watch.Refresh != "5m" {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	for _, o := range []Override{
+		{"nope", "1"},
+		{"ai.nope", "1"},
+		{"ai.provider.name", "x"},
+		{"thresholds.max_complexity", "many"},
+	} {
+		if err := cfg.Set(o.Key, o.Value); err == nil {
+			t.Errorf("Set(%q, %q) succeeded", o.Key, o.Value)
+		}
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	got, err := envOverrides([]string{
+		"HOME=/root",
+		"DRIFT_THRESHOLDS_MAX_COMPLEXITY=20",
+		"DRIFT_AI_PROVIDER=openai",
+		"DRIFT_AI_REDACT_METRICS_ONLY=true",
+		"DRIFT_CONFIG=ci.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Override{
+		{"ai.provider", "openai"},
+		{"ai.redact.metrics_only", "true"},
+		{"thresholds.max_complexity", "20"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("envOverrides = %v, want %v", got, want)
+	}
+
+	if _, err := envOverrides([]string{"DRIFT_AI_PROVDER=openai"}); err == nil {
+		t.Error("a misspelled variable was accepted")
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "drift.yaml")
+	yaml := "language: go\nai:\n  provider: anthropic\nthresholds:\n  max_complexity: 10\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DRIFT_CONFIG", path)
+	t.Setenv("DRIFT_AI_PROVIDER", "openai")
+	t.Setenv("DRIFT_THRESHOLDS_MAX_COMPLEXITY", "20")
+
+	cfg, err := Load("", Override{"thresholds.max_complexity", "30"}, Override{"root", dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Language != "go" || cfg.AI.Provider != "openai" || cfg.Thresholds.MaxComplexity != 30 || cfg.Root != dir {
+		t.Errorf("language %q, provider %q, max_complexity %d, root %q; want go, openai, 30, %s",
+			cfg.Language, cfg.AI.Provider, cfg.Thresholds.MaxComplexity, cfg.Root, dir)
+	}
+
+	t.Setenv("DRIFT_WATCH_MODE", "sometimes")
+	if _, err := Load(""); err == nil {
+		t.Error("an invalid DRIFT_WATCH_MODE was accepted")
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Excluded reports whether rel, a slash-separated path relative to the
+// analyzed root, is excluded. Entries without a slash match any path
+// component: plain names such as "vendor", as they always have, and globs
+// such as "gen-*" or "*.pb.go", for generated output whose names vary.
+// Entries containing a slash ("**/*_gen.go", "web/legacy/**") are
+// doublestar patterns matched against the whole path.
+func Excluded(exclude []string, rel string) bool {
+	rel = strings.TrimPrefix(rel, "./")
+	parts := strings.Split(rel, "/")
+	for _, ex := range exclude {
+		if strings.Contains(ex, "/") {
+			if ok, _ := doublestar.Match(ex, rel); ok {
+				return true
+			}
+			continue
+		}
+		for _, part := range parts {
+			if part == ex {
+				return true
+			}
+			if isPattern(ex) {
+				if ok, _ := doublestar.Match(ex, part); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Included reports whether the file rel matches one of the include glob
+// patterns. Everything is included when there are none.
+func Included(include []string, rel string) bool {
+	if len(include) == 0 {
+		return true
+	}
+	rel = strings.TrimPrefix(rel, "./")
+	for _, in := range include {
+		if ok, _ := doublestar.Match(in, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isPattern(s string) bool {
+	return strings.ContainsAny(s, "/*?[{")
+}
@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestExcluded(t *testing.T) {
+	exclude := []string{"vendor", "gen-*", "*.pb.go", "**/*_gen.go", "web/legacy/**"}
+	for _, tt := range []struct {
+		rel  string
+		want bool
+	}{
+		{"vendor", true},
+		{"pkg/vendor/lib/a.go", true},
+		{"vendored/a.go", false},
+		{"gen-1234", true},
+		{"out/gen-abc/a.go", true},
+		{"pkg/generate.go", false},
+		{"api/v1/user.pb.go", true},
+		{"pkg/types_gen.go", true},
+		{"web/legacy", true},
+		{"web/legacy/old.ts", true},
+		{"web/app/legacy/new.ts", false},
+		{"./vendor/a.go", true},
+		{"cmd/main.go", false},
+	} {
+		if got := Excluded(exclude, tt.rel); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
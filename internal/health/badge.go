@@ -0,0 +1,92 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+)
+
+// Grade turns a 0-100 score into a letter: A from 90, B from 80, C from 70,
+// D from 60, and F below.
+func Grade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
+
+// Grade is the letter grade of the total score.
+func (s Score) Grade() string {
+	return Grade(s.Total)
+}
+
+// gradeColors are the shields.io color names for each grade, with the hex
+// value shields.io renders them as for the SVG badge.
+var gradeColors = map[string]struct{ name, hex string }{
+	"A": {"brightgreen", "#4c1"},
+	"B": {"green", "#97ca00"},
+	"C": {"yellow", "#dfb317"},
+	"D": {"orange", "#fe7d37"},
+	"F": {"red", "#e05d44"},
+}
+
+// badgeMessage is the right-hand side of a badge, e.g. "84 B".
+func badgeMessage(s Score) string {
+	return fmt.Sprintf("%.0f %s", s.Total, s.Grade())
+}
+
+// BadgeJSON renders the score in the shields.io endpoint format, for
+// https://img.shields.io/endpoint?url=... to draw.
+func BadgeJSON(label string, s Score) ([]byte, error) {
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"schemaVersion": 1,
+		"label":         label,
+		"message":       badgeMessage(s),
+		"color":         gradeColors[s.Grade()].name,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// BadgeSVG renders the score as a flat badge in the shields.io style, to
+// commit next to the README. Text widths are estimated, since measuring
+// them needs the font.
+func BadgeSVG(label string, s Score) []byte {
+	message := badgeMessage(s)
+	lw, mw := textWidth(label), textWidth(message)
+	w := lw + mw
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+  <title>%s: %s</title>
+  <linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+  <clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%d" height="20" fill="#555"/>
+    <rect x="%d" width="%d" height="20" fill="%s"/>
+    <rect width="%d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%d" y="14">%s</text>
+    <text x="%d" y="14">%s</text>
+  </g>
+</svg>
+`, w, label, message, label, message,
+		w, lw, lw, mw, gradeColors[s.Grade()].hex, w,
+		lw/2, label, lw+mw/2, message)
+	return []byte(svg)
+}
+
+// textWidth estimates the width of s in 11px Verdana plus padding.
+func textWidth(s string) int {
+	return len([]rune(s))*7 + 10
+}
@@ -0,0 +1,260 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+)
+
+// Penalty is what one finding costs its category.
+type Penalty struct {
+	Item   string  // the finding, e.g. "internal/tui/app.go:1032 PrintReport() complexity 38"
+	Points float64 // taken off the category's 0-100 score
+	// Worth is how much the total score would rise if the finding were
+	// fixed and nothing else changed. It is less than Points scaled by the
+	// category's weight when the category score is clamped at 0.
+	Worth float64
+}
+
+// Explanation breaks one category score down into the findings that lowered
+// it. Testing, coverage, and maintainability are ratios rather than sums of
+// findings and come without penalties.
+type Explanation struct {
+	Category  string  // as keyed by Score.Categories, e.g. "dead_code"
+	Score     float64 // 0-100
+	Weight    float64 // share of the total after renormalization, 0-1
+	Penalties []Penalty
+}
+
+// Lost is how many points of the total the category costs.
+func (e Explanation) Lost() float64 {
+	return (100 - e.Score) * e.Weight
+}
+
+// Explain lists, for each category that counts toward the total, which
+// findings cost how many points, most expensive first. Categories are in the
+// order of their share of the points lost. Unlike Calculate, it leaves the
+// scorer's delta tracking alone.
+func (s *Scorer) Explain(r *analyzer.Results) []Explanation {
+	w := s.cfg.Weights
+	type category struct {
+		name      string
+		weight    float64
+		penalties []Penalty
+		ratio     bool    // scored from a ratio rather than findings
+		score     float64 // of a ratio category
+	}
+	cats := []category{
+		{name: "complexity", weight: w.Complexity, penalties: s.complexityPenalties(r)},
+		{name: "deps", weight: w.Deps, penalties: s.depsPenalties(r)},
+		{name: "security", weight: w.Security, penalties: s.securityPenalties(r)},
+		{name: "boundaries", weight: w.Boundaries, penalties: s.boundariesPenalties(r)},
+		{name: "dead_code", weight: w.DeadCode, penalties: s.deadCodePenalties(r)},
+		{name: "duplication", weight: w.Duplication, penalties: s.duplicationPenalties(r)},
+		{name: "debt", weight: w.Debt, penalties: s.debtPenalties(r)},
+		{name: "coupling", weight: w.Coupling, penalties: s.couplingPenalties(r)},
+		{name: "testing", weight: w.Testing, ratio: true, score: s.testingScore(r)},
+	}
+	if r.Coverage.Measured {
+		cats = append(cats, category{name: "coverage", weight: w.Coverage, ratio: true, score: r.Coverage.Percent})
+	}
+	if mi, ok := r.Maintainability(); ok {
+		cats = append(cats, category{name: "maintainability", weight: w.Maintainability, ratio: true, score: mi})
+	}
+
+	totalWeight := 0.0
+	for _, c := range cats {
+		totalWeight += c.weight
+	}
+
+	var out []Explanation
+	for _, c := range cats {
+		if c.weight <= 0 {
+			continue
+		}
+		share := c.weight / totalWeight
+		e := Explanation{Category: c.name, Score: c.score, Weight: share}
+		if !c.ratio {
+			e.Score = scoreAfter(c.penalties)
+			sum := totalPoints(c.penalties)
+			for _, p := range c.penalties {
+				p.Worth = (clampScore(100-(sum-p.Points)) - e.Score) * share
+				e.Penalties = append(e.Penalties, p)
+			}
+			sort.SliceStable(e.Penalties, func(i, j int) bool { return e.Penalties[i].Points > e.Penalties[j].Points })
+		}
+		out = append(out, e)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Lost() > out[j].Lost() })
+	return out
+}
+
+// scoreAfter is a category score with penalties taken off 100.
+func scoreAfter(penalties []Penalty) float64 {
+	return clampScore(100 - totalPoints(penalties))
+}
+
+func totalPoints(penalties []Penalty) float64 {
+	var sum float64
+	for _, p := range penalties {
+		sum += p.Points
+	}
+	return sum
+}
+
+func clampScore(score float64) float64 {
+	return math.Max(0, math.Min(100, score))
+}
+
+func (s *Scorer) complexityPenalties(r *analyzer.Results) []Penalty {
+	var out []Penalty
+	for _, fc := range r.Complexity {
+		t := s.cfg.Thresholds.For(fc.Path)
+		threshold := float64(t.MaxComplexity)
+		if threshold == 0 {
+			threshold = 15
+		}
+		var points float64
+		if float64(fc.Complexity) > threshold {
+			excess := float64(fc.Complexity) - threshold
+			points += math.Min(excess/threshold*20, 20)
+		}
+		points += overLimitPenalty(fc.Lines, t.MaxFuncLines)
+		points += overLimitPenalty(fc.Params, t.MaxParams)
+		points += overLimitPenalty(fc.Nesting, t.MaxNesting)
+		if points > 0 {
+			out = append(out, Penalty{
+				Item: fmt.Sprintf("%s:%d %s() complexity %d, %d lines, %d params, nesting %d",
+					fc.Path, fc.Line, fc.Name, fc.Complexity, fc.Lines, fc.Params, fc.Nesting),
+				Points: points,
+			})
+		}
+	}
+	return out
+}
+
+func (s *Scorer) depsPenalties(r *analyzer.Results) []Penalty {
+	maxStale := float64(s.cfg.Thresholds.MaxStaleDays)
+	if maxStale == 0 {
+		maxStale = 90
+	}
+
+	var out []Penalty
+	for _, dep := range r.Dependencies {
+		var points float64
+		if dep.StaleDays > 0 {
+			ratio := float64(dep.StaleDays) / maxStale
+			points = math.Min(ratio*15, 15)
+		}
+		points += gapPenalty(dep)
+		if dep.Scope == "dev" {
+			points *= devDepWeight
+		}
+		if points > 0 {
+			out = append(out, Penalty{
+				Item:   fmt.Sprintf("%s %s → %s (%d days behind)", dep.Module, dep.CurrentVersion, dep.LatestVersion, dep.StaleDays),
+				Points: points,
+			})
+		}
+	}
+	return out
+}
+
+func (s *Scorer) securityPenalties(r *analyzer.Results) []Penalty {
+	var out []Penalty
+	for _, v := range r.Vulnerabilities {
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%s %s: %s (%s)", v.Module, v.Version, v.Label(), v.Severity),
+			Points: severityPenalties[v.Severity],
+		})
+	}
+	return out
+}
+
+func (s *Scorer) boundariesPenalties(r *analyzer.Results) []Penalty {
+	scale := s.sizeScale(float64(codeLines(r))/1000, referenceKLOC)
+	var out []Penalty
+	for _, v := range r.Violations {
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%s:%d imports %s (%s → %s)", v.File, v.Line, v.Import, v.From, v.To),
+			Points: 10 / scale,
+		})
+	}
+	for _, c := range r.Cycles {
+		out = append(out, Penalty{
+			Item:   "import cycle " + c.String(),
+			Points: 15 / scale,
+		})
+	}
+	return out
+}
+
+func (s *Scorer) deadCodePenalties(r *analyzer.Results) []Penalty {
+	scale := s.sizeScale(float64(r.ExportedFuncs), referenceExports)
+	var out []Penalty
+	for _, d := range r.DeadCode {
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%s:%d %s (%s never referenced)", d.File, d.Line, d.Name, d.Kind),
+			Points: 5 / scale,
+		})
+	}
+	return out
+}
+
+func (s *Scorer) duplicationPenalties(r *analyzer.Results) []Penalty {
+	total := 0
+	for _, f := range r.Files {
+		total += f.Lines
+	}
+	if total == 0 {
+		return nil
+	}
+
+	var out []Penalty
+	for _, d := range r.Duplicates {
+		lines := d.DuplicatedLines()
+		if lines == 0 {
+			continue
+		}
+		locs := make([]string, len(d.Locations))
+		for i, loc := range d.Locations {
+			locs[i] = fmt.Sprintf("%s:%d", loc.Path, loc.Line)
+		}
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%d duplicated lines at %s", lines, strings.Join(locs, ", ")),
+			Points: float64(lines) / float64(total) * 100 * 3,
+		})
+	}
+	return out
+}
+
+func (s *Scorer) debtPenalties(r *analyzer.Results) []Penalty {
+	code := codeLines(r)
+	if code == 0 {
+		return nil
+	}
+
+	var out []Penalty
+	for _, d := range r.Debt {
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%s:%d %s %s", d.Path, d.Line, d.Kind, d.Text),
+			Points: float64(d.Weight()) / float64(code) * 1000 * 5,
+		})
+	}
+	return out
+}
+
+func (s *Scorer) couplingPenalties(r *analyzer.Results) []Penalty {
+	var out []Penalty
+	for _, pc := range analyzer.UnstableDependencies(r.Coupling) {
+		excess := (pc.Instability - 0.5) * 2
+		out = append(out, Penalty{
+			Item:   fmt.Sprintf("%s: %d dependents, instability %.2f", pc.Package, pc.Afferent, pc.Instability),
+			Points: math.Min(float64(pc.Afferent)*excess*20, 20),
+		})
+	}
+	return out
+}
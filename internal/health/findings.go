@@ -0,0 +1,151 @@
+package health
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// Finding is an issue at a place in the code, as code-scanning formats and
+// pull request annotations report it.
+type Finding struct {
+	Rule    string // "complexity", "boundary", "dead-code", or "vulnerability"
+	Level   string // "error", "warning", or "note"
+	Path    string // relative to the root
+	Line    int    // 0 for findings about a whole file, such as a manifest
+	Message string
+	// Subject is what the finding is about, a function, import,
+	// declaration, or advisory, to tell findings apart as lines move.
+	Subject string
+	// Language is the language of the code at fault, set only when r
+	// merges several.
+	Language analyzer.Language
+}
+
+// manifests are the files a language's dependencies are declared in, most
+// likely first, to locate vulnerabilities at.
+var manifests = map[analyzer.Language][]string{
+	analyzer.LangGo:         {"go.mod"},
+	analyzer.LangTypeScript: {"package.json"},
+	analyzer.LangPython:     {"pyproject.toml", "requirements.txt"},
+	analyzer.LangRust:       {"Cargo.toml"},
+	analyzer.LangJava:       {"pom.xml", "build.gradle"},
+	analyzer.LangRuby:       {"Gemfile"},
+	analyzer.LangPHP:        {"composer.json"},
+	analyzer.LangSwift:      {"Package.swift"},
+	analyzer.LangElixir:     {"mix.exs"},
+}
+
+// Findings lists the complex functions, boundary violations, dead code, and
+// vulnerable dependencies in r, in that order.
+func Findings(cfg *config.Config, r *analyzer.Results) []Finding {
+	var findings []Finding
+	lang := func(l analyzer.Language) analyzer.Language {
+		if !r.MultiLanguage() {
+			return ""
+		}
+		return l
+	}
+	for _, fc := range r.Complexity {
+		if over := overLimits(fc, cfg.Thresholds.For(fc.Path)); len(over) > 0 {
+			findings = append(findings, Finding{"complexity", "warning", fc.Path, fc.Line,
+				fmt.Sprintf("%s has %s.", fc.Name, strings.Join(over, ", ")), fc.Name, lang(fc.Language)})
+		}
+	}
+	for _, v := range r.Violations {
+		msg := fmt.Sprintf("Import of %s breaks the %s → %s boundary.", v.Import, v.From, v.To)
+		if v.Description != "" {
+			msg += " " + v.Description
+		}
+		path := filePath(r, v.File)
+		findings = append(findings, Finding{"boundary", "error", path, v.Line, msg, v.Import, lang(fileLanguage(r, path))})
+	}
+	for _, d := range r.DeadCode {
+		findings = append(findings, Finding{"dead-code", "note", d.Path, d.Line,
+			fmt.Sprintf("Exported %s %s is never used.", d.Kind, d.Name), d.Name, lang(fileLanguage(r, d.Path))})
+	}
+	for _, v := range r.Vulnerabilities {
+		findings = append(findings, Finding{"vulnerability", vulnerabilityLevel(v.Severity), manifest(cfg.Root, v.Language), 0,
+			fmt.Sprintf("%s %s has %s (%s severity): %s", v.Module, v.Version, v.Label(), v.Severity, v.Summary), v.Module + " " + v.ID, lang(v.Language)})
+	}
+	return findings
+}
+
+// Text is the finding's message, marked with its language when it has
+// one, as multi-language reports mark their entries.
+func (f Finding) Text() string {
+	if f.Language == "" {
+		return f.Message
+	}
+	return "[" + string(f.Language) + "] " + f.Message
+}
+
+// overLimits describes the function thresholds fc exceeds, e.g.
+// "cyclomatic complexity 22 (limit 15)".
+func overLimits(fc analyzer.FunctionComplexity, t config.ThresholdConfig) []string {
+	limits := []struct {
+		name         string
+		value, limit int
+	}{
+		{"cyclomatic complexity", fc.Complexity, t.MaxComplexity},
+		{"length", fc.Lines, t.MaxFuncLines},
+		{"parameters", fc.Params, t.MaxParams},
+		{"nesting depth", fc.Nesting, t.MaxNesting},
+	}
+	var over []string
+	for _, l := range limits {
+		if l.limit > 0 && l.value > l.limit {
+			over = append(over, fmt.Sprintf("%s %d (limit %d)", l.name, l.value, l.limit))
+		}
+	}
+	return over
+}
+
+// filePath is the path of the file a violation or dead declaration names,
+// or just its name when several files share it.
+func filePath(r *analyzer.Results, name string) string {
+	if p := r.FindFile(name); p != "" {
+		return p
+	}
+	return name
+}
+
+// fileLanguage is the language of the analyzed file at path, or "" if
+// there's none.
+func fileLanguage(r *analyzer.Results, path string) analyzer.Language {
+	for _, f := range r.Files {
+		if f.Path == path {
+			return f.Language
+		}
+	}
+	return ""
+}
+
+// manifest is the dependency manifest of lang under root.
+func manifest(root string, lang analyzer.Language) string {
+	candidates := manifests[lang]
+	if len(candidates) == 0 {
+		return "."
+	}
+	for _, name := range candidates {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return name
+		}
+	}
+	return candidates[0]
+}
+
+// vulnerabilityLevel maps an advisory severity to a level.
+func vulnerabilityLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	}
+	return "note"
+}
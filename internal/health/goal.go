@@ -0,0 +1,64 @@
+package health
+
+import "time"
+
+// trendWindow is how far back the goal trend looks.
+const trendWindow = 28 * 24 * time.Hour
+
+const week = 7 * 24 * time.Hour
+
+// GoalProgress measures the total score against the configured goal.
+type GoalProgress struct {
+	Target  float64
+	By      time.Time // zero without a deadline
+	Current float64
+
+	// Needed is the weekly gain it takes to reach Target by the deadline.
+	// It is 0 without a deadline, once the goal is reached, or once the
+	// deadline has passed.
+	Needed float64
+
+	// Trend is the weekly change over the last four weeks of recorded
+	// history. TrendKnown is false until the history spans at least a day.
+	Trend      float64
+	TrendKnown bool
+}
+
+// Remaining is how many points the total is short of the goal, or 0 once
+// it is reached.
+func (g GoalProgress) Remaining() float64 {
+	return max(0, g.Target-g.Current)
+}
+
+// Reached reports whether the total is at or above the goal.
+func (g GoalProgress) Reached() bool {
+	return g.Remaining() == 0
+}
+
+// Overdue reports whether the deadline passed before the goal was reached.
+func (g GoalProgress) Overdue(now time.Time) bool {
+	return !g.By.IsZero() && !g.Reached() && !now.Before(g.By)
+}
+
+// Progress measures current against target, due by (zero for no deadline),
+// with the trend taken from history as of now.
+func Progress(target float64, by time.Time, current float64, history []Sample, now time.Time) GoalProgress {
+	g := GoalProgress{Target: target, By: by, Current: current}
+
+	if left := by.Sub(now); !by.IsZero() && left > 0 && !g.Reached() {
+		g.Needed = g.Remaining() / max(left.Hours()/week.Hours(), 1.0/7)
+	}
+
+	for _, sample := range history {
+		if now.Sub(sample.Time) > trendWindow {
+			continue
+		}
+		span := now.Sub(sample.Time)
+		if span >= 24*time.Hour {
+			g.Trend = (current - sample.Score) / (span.Hours() / week.Hours())
+			g.TrendKnown = true
+		}
+		break // the oldest sample in the window
+	}
+	return g
+}
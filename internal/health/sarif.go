@@ -0,0 +1,129 @@
+package health
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// SARIF types, covering the parts of SARIF 2.1.0 that GitHub code scanning
+// reads.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	// Properties tag a result with its language in multi-language mode.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRules describe the rules of Findings, which SARIF results refer to
+// by index.
+var sarifRules = []sarifRule{
+	{ID: "drift/complexity", Name: "ComplexFunction", ShortDescription: sarifMessage{"Function exceeds a complexity, length, parameter, or nesting limit"}, DefaultConfig: sarifConfig{"warning"}},
+	{ID: "drift/boundary", Name: "BoundaryViolation", ShortDescription: sarifMessage{"Import breaks an architecture boundary rule"}, DefaultConfig: sarifConfig{"error"}},
+	{ID: "drift/dead-code", Name: "DeadCode", ShortDescription: sarifMessage{"Exported declaration is never used"}, DefaultConfig: sarifConfig{"note"}},
+	{ID: "drift/vulnerability", Name: "Vulnerability", ShortDescription: sarifMessage{"Dependency has a known vulnerability"}, DefaultConfig: sarifConfig{"error"}},
+}
+
+// SARIF reports complex functions, boundary violations, dead code, and
+// vulnerable dependencies as SARIF 2.1.0, for GitHub code scanning and other
+// consumers to annotate. Locations are relative to cfg.Root; version is
+// drift's.
+func SARIF(cfg *config.Config, r *analyzer.Results, version string) ([]byte, error) {
+	results := []sarifResult{}
+	for _, f := range Findings(cfg, r) {
+		index := slices.IndexFunc(sarifRules, func(rule sarifRule) bool { return rule.ID == "drift/"+f.Rule })
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: f.Path, URIBaseID: "%SRCROOT%"}}
+		if f.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: f.Line}
+		}
+		result := sarifResult{
+			RuleID:    sarifRules[index].ID,
+			RuleIndex: index,
+			Level:     f.Level,
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		}
+		if f.Language != "" {
+			result.Properties = map[string]string{"language": string(f.Language)}
+		}
+		results = append(results, result)
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "drift",
+				Version:        version,
+				InformationURI: "https://github.com/greatnessinabox/drift",
+				Rules:          sarifRules,
+			}},
+			Results: results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
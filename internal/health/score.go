@@ -1,12 +1,16 @@
 // Package health computes a weighted codebase health score from analyzer
-// results. Each metric (complexity, deps, boundaries, dead code, coverage) is
-// scored 0-100, then combined as a weighted average using cfg.Weights. Weights
-// are renormalized over the metrics actually present, so a missing metric (e.g.
-// coverage with no lcov.info report) neither inflates nor tanks the total.
+// results. Each metric (complexity, deps, boundaries, dead code, duplication,
+// debt markers, security, coverage, ...) is scored 0-100, then combined as a weighted
+// average using cfg.Weights. Weights are renormalized over the metrics
+// actually present, so a missing metric (e.g. coverage with no lcov.info
+// report) neither inflates nor tanks the total. Maintainability, testing, and
+// coupling are computed but carry zero weight unless configured.
 package health
 
 import (
 	"math"
+	"sort"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/analyzer"
 	"github.com/greatnessinabox/drift/internal/config"
@@ -18,14 +22,82 @@ type Score struct {
 	Deps             float64
 	Boundaries       float64
 	DeadCode         float64
+	Duplication      float64
+	Debt             float64
 	Coverage         float64
 	CoverageMeasured bool
 	Delta            float64
+
+	// Maintainability is the line-weighted maintainability index across
+	// files that support it.
+	Maintainability         float64
+	MaintainabilityMeasured bool
+
+	// Testing scores the test-to-source file ratio against MinTestRatio.
+	Testing float64
+	// Coupling penalizes depended-on packages that are themselves unstable.
+	Coupling float64
+	// Security penalizes known vulnerabilities in dependencies by severity.
+	Security float64
+
+	// Goal tracks the total against the configured goal, if there is one.
+	Goal *GoalProgress
+}
+
+// Categories returns the total and each category score keyed the way
+// `drift snapshot` reports them. Coverage and maintainability are included
+// only when measured.
+func (s Score) Categories() map[string]float64 {
+	scores := map[string]float64{
+		"total":       s.Total,
+		"complexity":  s.Complexity,
+		"deps":        s.Deps,
+		"boundaries":  s.Boundaries,
+		"dead_code":   s.DeadCode,
+		"duplication": s.Duplication,
+		"debt":        s.Debt,
+		"testing":     s.Testing,
+		"coupling":    s.Coupling,
+		"security":    s.Security,
+	}
+	if s.CoverageMeasured {
+		scores["coverage"] = s.Coverage
+	}
+	if s.MaintainabilityMeasured {
+		scores["maintainability"] = s.Maintainability
+	}
+	return scores
+}
+
+// Regression is a score that dropped since an earlier run.
+type Regression struct {
+	Category string
+	Before   float64
+	After    float64
+}
+
+// Regressions compares current against scores recorded earlier (as returned
+// by Categories) and lists those that dropped, sorted by category. Scores
+// are compared to one decimal place, as they are displayed, and categories
+// missing from either side are skipped.
+func Regressions(before map[string]float64, current Score) []Regression {
+	var regs []Regression
+	for category, after := range current.Categories() {
+		prev, ok := before[category]
+		if ok && math.Round(after*10) < math.Round(prev*10) {
+			regs = append(regs, Regression{Category: category, Before: prev, After: after})
+		}
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i].Category < regs[j].Category })
+	return regs
 }
 
 type Scorer struct {
 	cfg      *config.Config
 	previous float64
+	// statePath is where LoadState found the last total, if anywhere.
+	statePath string
+	history   []Sample
 }
 
 func NewScorer(cfg *config.Config) *Scorer {
@@ -38,14 +110,21 @@ func (s *Scorer) Calculate(r *analyzer.Results) Score {
 		Deps:       s.depsScore(r),
 		Boundaries: s.boundariesScore(r),
 		DeadCode:   s.deadCodeScore(r),
+
+		Duplication: s.duplicationScore(r),
+		Debt:        s.debtScore(r),
+		Security:    s.securityScore(r),
 	}
 
 	w := s.cfg.Weights
 	weightedSum := score.Complexity*w.Complexity +
 		score.Deps*w.Deps +
 		score.Boundaries*w.Boundaries +
-		score.DeadCode*w.DeadCode
-	totalWeight := w.Complexity + w.Deps + w.Boundaries + w.DeadCode
+		score.DeadCode*w.DeadCode +
+		score.Duplication*w.Duplication +
+		score.Debt*w.Debt +
+		score.Security*w.Security
+	totalWeight := w.Complexity + w.Deps + w.Boundaries + w.DeadCode + w.Duplication + w.Debt + w.Security
 
 	// Coverage only counts when an lcov report was found. Otherwise the
 	// remaining weights are renormalized so the score isn't inflated by an
@@ -57,6 +136,23 @@ func (s *Scorer) Calculate(r *analyzer.Results) Score {
 		totalWeight += w.Coverage
 	}
 
+	score.Testing = s.testingScore(r)
+	weightedSum += score.Testing * w.Testing
+	totalWeight += w.Testing
+
+	score.Coupling = s.couplingScore(r)
+	weightedSum += score.Coupling * w.Coupling
+	totalWeight += w.Coupling
+
+	// The maintainability index is opt-in and, like coverage, only counts
+	// when at least one file could be measured.
+	if mi, ok := r.Maintainability(); ok {
+		score.Maintainability = mi
+		score.MaintainabilityMeasured = true
+		weightedSum += mi * w.Maintainability
+		totalWeight += w.Maintainability
+	}
+
 	if totalWeight > 0 {
 		score.Total = weightedSum / totalWeight
 	}
@@ -67,71 +163,139 @@ func (s *Scorer) Calculate(r *analyzer.Results) Score {
 	}
 	s.previous = score.Total
 
+	if goal := s.cfg.Goals; goal.Total > 0 {
+		now := time.Now()
+		by, _ := goal.Deadline() // validated by config.Load
+		progress := Progress(goal.Total, by, score.Total, withSample(s.history, score.Total, now), now)
+		score.Goal = &progress
+	}
+
 	return score
 }
 
 func (s *Scorer) complexityScore(r *analyzer.Results) float64 {
-	if len(r.Complexity) == 0 {
-		return 100
-	}
+	return scoreAfter(s.complexityPenalties(r))
+}
 
-	threshold := float64(s.cfg.Thresholds.MaxComplexity)
-	if threshold == 0 {
-		threshold = 15
+// overLimitPenalty charges up to 10 points for a function length, parameter
+// count, or nesting depth above its limit. A zero limit disables the check.
+func overLimitPenalty(value, limit int) float64 {
+	if limit <= 0 || value <= limit {
+		return 0
 	}
+	excess := float64(value - limit)
+	return math.Min(excess/float64(limit)*10, 10)
+}
 
-	var totalPenalty float64
-	for _, fc := range r.Complexity {
-		if float64(fc.Complexity) > threshold {
-			excess := float64(fc.Complexity) - threshold
-			penalty := math.Min(excess/threshold*20, 20)
-			totalPenalty += penalty
-		}
-	}
+func (s *Scorer) depsScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.depsPenalties(r))
+}
 
-	score := 100 - totalPenalty
-	return math.Max(0, math.Min(100, score))
+// severityPenalties is what one vulnerability of each severity costs.
+// Advisories without a rating count as medium-low.
+var severityPenalties = map[string]float64{
+	"critical": 40,
+	"high":     20,
+	"medium":   10,
+	"low":      3,
+	"unknown":  5,
 }
 
-func (s *Scorer) depsScore(r *analyzer.Results) float64 {
-	if len(r.Dependencies) == 0 {
-		return 100
-	}
+func (s *Scorer) securityScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.securityPenalties(r))
+}
 
-	maxStale := float64(s.cfg.Thresholds.MaxStaleDays)
-	if maxStale == 0 {
-		maxStale = 90
-	}
+// devDepWeight scales the penalty of development and test dependencies,
+// which never ship to production.
+const devDepWeight = 0.25
 
-	var totalPenalty float64
-	for _, dep := range r.Dependencies {
-		if dep.StaleDays > 0 {
-			ratio := float64(dep.StaleDays) / maxStale
-			penalty := math.Min(ratio*15, 15)
-			totalPenalty += penalty
-		}
+// gapPenalty adds to the staleness penalty by how far behind a dependency
+// is: a patch release is routine, a missed major usually means breaking
+// changes waiting to be dealt with.
+func gapPenalty(dep analyzer.DepStatus) float64 {
+	switch dep.Behind {
+	case "major":
+		return math.Min(float64(dep.MajorsBehind)*5, 15)
+	case "minor":
+		return 3
+	case "patch":
+		return 1
 	}
-
-	score := 100 - totalPenalty
-	return math.Max(0, math.Min(100, score))
+	return 0
 }
 
-func (s *Scorer) boundariesScore(r *analyzer.Results) float64 {
-	if len(r.Violations) == 0 {
-		return 100
+// Boundary and dead-code penalties are set for a codebase of referenceKLOC
+// thousand lines of code with referenceExports exported functions. Bigger
+// codebases have them scaled down in proportion, so ten violations in 100
+// KLOC cost what one does in 10; smaller ones are charged as if they were
+// that big. Scoring.AbsoluteCounts turns the scaling off.
+const (
+	referenceKLOC    = 10.0
+	referenceExports = 100.0
+)
+
+// sizeScale is how many times reference size is, and at least 1.
+func (s *Scorer) sizeScale(size, reference float64) float64 {
+	if s.cfg.Scoring.AbsoluteCounts || size <= reference {
+		return 1
 	}
+	return size / reference
+}
 
-	penalty := float64(len(r.Violations)) * 10
-	score := 100 - penalty
-	return math.Max(0, math.Min(100, score))
+// boundariesScore charges 10 points per boundary violation and 15 per import
+// cycle, since a cycle ties every package in it together, per 10 KLOC.
+func (s *Scorer) boundariesScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.boundariesPenalties(r))
 }
 
+// deadCodeScore charges 5 points per dead declaration per 100 exported
+// functions.
 func (s *Scorer) deadCodeScore(r *analyzer.Results) float64 {
-	if len(r.DeadCode) == 0 {
+	return scoreAfter(s.deadCodePenalties(r))
+}
+
+// duplicationScore charges 3 points per percent of source lines that are
+// redundant copies of a block found elsewhere.
+func (s *Scorer) duplicationScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.duplicationPenalties(r))
+}
+
+// debtScore charges 5 points per weighted debt marker per thousand lines of
+// code; FIXME, HACK, and XXX count double.
+func (s *Scorer) debtScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.debtPenalties(r))
+}
+
+// codeLines counts the lines of code, without blanks and comments, across
+// all files.
+func codeLines(r *analyzer.Results) int {
+	code := 0
+	for _, f := range r.Files {
+		code += f.CodeLines
+	}
+	return code
+}
+
+// testingScore scales linearly with the test-to-source file ratio, reaching
+// 100 at the configured minimum.
+func (s *Scorer) testingScore(r *analyzer.Results) float64 {
+	target := s.cfg.Thresholds.MinTestRatio
+	if target <= 0 {
+		target = 0.5
+	}
+	sources := 0
+	for _, pt := range r.Tests {
+		sources += pt.SourceFiles
+	}
+	if sources == 0 {
 		return 100
 	}
+	return math.Min(100, r.TestRatio()/target*100)
+}
 
-	penalty := float64(len(r.DeadCode)) * 5
-	score := 100 - penalty
-	return math.Max(0, math.Min(100, score))
+// couplingScore charges each package that others import while its own
+// instability exceeds 0.5: 20 points per dependent at full instability,
+// scaled by how far above 0.5 it is and capped at 20 per package.
+func (s *Scorer) couplingScore(r *analyzer.Results) float64 {
+	return scoreAfter(s.couplingPenalties(r))
 }
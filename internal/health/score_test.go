@@ -1,7 +1,12 @@
 package health
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/greatnessinabox/drift/internal/analyzer"
 	"github.com/greatnessinabox/drift/internal/config"
@@ -97,6 +102,65 @@ func TestDepsScore(t *testing.T) {
 	}
 }
 
+func TestDepsScore_VersionGap(t *testing.T) {
+	tests := []struct {
+		name   string
+		behind string
+		majors int
+		want   float64
+	}{
+		{"unknown", "", 0, 100},
+		{"patch", "patch", 0, 99},
+		{"minor", "minor", 0, 97},
+		{"one major", "major", 1, 95},
+		{"two majors", "major", 2, 90},
+		{"capped", "major", 7, 85},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Dependencies: []analyzer.DepStatus{{Behind: tt.behind, MajorsBehind: tt.majors}}}
+			if got := newScorer().depsScore(r); !approx(got, tt.want) {
+				t.Errorf("depsScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDepsScore_DevWeighsLess(t *testing.T) {
+	r := &analyzer.Results{Dependencies: []analyzer.DepStatus{
+		{StaleDays: 90, Behind: "major", MajorsBehind: 1},               // 15 + 5
+		{StaleDays: 90, Behind: "major", MajorsBehind: 1, Scope: "dev"}, // a quarter of that
+	}}
+	if got := newScorer().depsScore(r); !approx(got, 75) {
+		t.Errorf("depsScore = %v, want 75", got)
+	}
+}
+
+func TestSecurityScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		severities []string
+		want       float64
+	}{
+		{"none", nil, 100},
+		{"one low", []string{"low"}, 97},
+		{"unrated", []string{"unknown"}, 95},
+		{"high and medium", []string{"high", "medium"}, 70},
+		{"clamped", []string{"critical", "critical", "critical"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{}
+			for _, s := range tt.severities {
+				r.Vulnerabilities = append(r.Vulnerabilities, analyzer.Vulnerability{Severity: s})
+			}
+			if got := newScorer().securityScore(r); !approx(got, tt.want) {
+				t.Errorf("securityScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBoundariesScore(t *testing.T) {
 	tests := []struct {
 		n    int
@@ -110,6 +174,17 @@ func TestBoundariesScore(t *testing.T) {
 	}
 }
 
+func TestBoundariesScore_Cycles(t *testing.T) {
+	r := &analyzer.Results{
+		Violations: make([]analyzer.BoundaryViolation, 2),
+		Cycles:     make([]analyzer.ImportCycle, 3),
+	}
+	// 2 * 10 + 3 * 15
+	if got := newScorer().boundariesScore(r); got != 35 {
+		t.Errorf("boundariesScore = %v, want 35", got)
+	}
+}
+
 func TestDeadCodeScore(t *testing.T) {
 	tests := []struct {
 		n    int
@@ -153,6 +228,29 @@ func TestCalculate_Delta(t *testing.T) {
 	}
 }
 
+func TestCalculate_DeltaPersisted(t *testing.T) {
+	root := t.TempDir()
+
+	s := newScorer()
+	s.LoadState(root)
+	first := s.Calculate(&analyzer.Results{})
+	if first.Delta != 0 {
+		t.Errorf("first delta = %v, want 0 (no state file)", first.Delta)
+	}
+	if _, err := os.Stat(filepath.Join(root, StateFile)); !os.IsNotExist(err) {
+		t.Errorf("Calculate wrote the state file: %v", err)
+	}
+	s.SaveState(first.Total)
+
+	// A new scorer, as in the next invocation, picks up where the last left off.
+	s = newScorer()
+	s.LoadState(root)
+	second := s.Calculate(&analyzer.Results{DeadCode: make([]analyzer.DeadFunction, 4)})
+	if second.Delta >= 0 || !approx(second.Delta, second.Total-first.Total) {
+		t.Errorf("delta across runs = %v, want %v", second.Delta, second.Total-first.Total)
+	}
+}
+
 func approx(a, b float64) bool {
 	d := a - b
 	if d < 0 {
@@ -160,3 +258,376 @@ func approx(a, b float64) bool {
 	}
 	return d < 0.0001
 }
+
+func TestComplexityScore_LengthAndParams(t *testing.T) {
+	// max_func_lines 80, max_params 5; penalty = min(excess/limit*10, 10)
+	cfg := config.Defaults()
+	cfg.Thresholds.MaxFuncLines = 80
+	cfg.Thresholds.MaxParams = 5
+	tests := []struct {
+		name   string
+		lines  int
+		params int
+		want   float64
+	}{
+		{"within limits", 80, 5, 100},
+		{"long", 120, 0, 95},         // 40/80*10 = 5
+		{"many params", 0, 10, 90},   // 5/5*10 = 10
+		{"both capped", 800, 50, 80}, // 10 + 10
+	}
+	r := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{{Complexity: 1, Lines: 800, Params: 50}}}
+	if got := newScorer().complexityScore(r); got != 100 {
+		t.Errorf("complexityScore with the default limits = %v, want 100", got)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{
+				{Complexity: 1, Lines: tt.lines, Params: tt.params},
+			}}
+			if got := NewScorer(cfg).complexityScore(r); !approx(got, tt.want) {
+				t.Errorf("complexityScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplexityScore_Nesting(t *testing.T) {
+	// default max_nesting 4: depth 6 is 50% over -> 5 points.
+	r := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{
+		{Complexity: 1, Nesting: 4},
+		{Complexity: 1, Nesting: 6},
+	}}
+	if got := newScorer().complexityScore(r); !approx(got, 95) {
+		t.Errorf("complexityScore = %v, want 95", got)
+	}
+}
+
+func TestDuplicationScore(t *testing.T) {
+	// 3 points per percent of redundant lines.
+	files := []analyzer.FileMetrics{{Lines: 600}, {Lines: 400}}
+	tests := []struct {
+		name string
+		dups []analyzer.DuplicateBlock
+		want float64
+	}{
+		{"none", nil, 100},
+		{"one copy", []analyzer.DuplicateBlock{{Lines: 10, Locations: make([]analyzer.DuplicateLocation, 2)}}, 97},     // 1%
+		{"three copies", []analyzer.DuplicateBlock{{Lines: 50, Locations: make([]analyzer.DuplicateLocation, 3)}}, 70}, // 10%
+		{"floored", []analyzer.DuplicateBlock{{Lines: 400, Locations: make([]analyzer.DuplicateLocation, 2)}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Files: files, Duplicates: tt.dups}
+			if got := newScorer().duplicationScore(r); !approx(got, tt.want) {
+				t.Errorf("duplicationScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculate_Maintainability(t *testing.T) {
+	r := &analyzer.Results{Files: []analyzer.FileMetrics{
+		{Lines: 100, Maintainability: 40, MaintainabilityMeasured: true},
+	}}
+
+	// Off by default: reported but not weighted.
+	got := newScorer().Calculate(r)
+	if !got.MaintainabilityMeasured || got.Maintainability != 40 {
+		t.Errorf("Maintainability = %v (measured %v), want 40", got.Maintainability, got.MaintainabilityMeasured)
+	}
+	if got.Total != 100 {
+		t.Errorf("Total = %v, want 100 with zero maintainability weight", got.Total)
+	}
+
+	cfg := config.Defaults()
+	cfg.Weights = config.WeightConfig{Complexity: 0.5, Maintainability: 0.5}
+	if got := NewScorer(cfg).Calculate(r).Total; got != 70 {
+		t.Errorf("weighted total = %v, want 70", got)
+	}
+}
+
+func TestDebtScore(t *testing.T) {
+	// 5 points per weighted marker per 1000 code lines; FIXME counts double.
+	files := []analyzer.FileMetrics{{CodeLines: 2000}}
+	tests := []struct {
+		name  string
+		kinds []string
+		want  float64
+	}{
+		{"none", nil, 100},
+		{"two todos", []string{"TODO", "TODO"}, 95},      // 1 per KLOC
+		{"fixme doubles", []string{"FIXME"}, 95},         // 2/2000 -> 1 per KLOC
+		{"mixed", []string{"TODO", "HACK", "XXX"}, 87.5}, // 5/2000 -> 2.5 per KLOC
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Files: files}
+			for _, k := range tt.kinds {
+				r.Debt = append(r.Debt, analyzer.DebtMarker{Kind: k})
+			}
+			if got := newScorer().debtScore(r); !approx(got, tt.want) {
+				t.Errorf("debtScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestingScore(t *testing.T) {
+	// default min_test_ratio 0.5: full marks at one test file per two sources.
+	tests := []struct {
+		name          string
+		sources, test int
+		want          float64
+	}{
+		{"no sources", 0, 0, 100},
+		{"untested", 10, 0, 0},
+		{"quarter", 8, 1, 25},
+		{"at target", 10, 5, 100},
+		{"above target", 2, 4, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Tests: []analyzer.PackageTests{{SourceFiles: tt.sources, TestFiles: tt.test}}}
+			if got := newScorer().testingScore(r); !approx(got, tt.want) {
+				t.Errorf("testingScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCouplingScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupling []analyzer.PackageCoupling
+		want     float64
+	}{
+		{"none", nil, 100},
+		{"stable core", []analyzer.PackageCoupling{{Afferent: 5, Efferent: 0, Instability: 0}}, 100},
+		// Ca 1, I 0.75: 1 * 0.5 * 20 = 10 points.
+		{"one unstable", []analyzer.PackageCoupling{{Afferent: 1, Efferent: 3, Instability: 0.75}}, 90},
+		// Ca 4, I 0.75 would charge 40, capped at 20.
+		{"capped", []analyzer.PackageCoupling{{Afferent: 4, Efferent: 12, Instability: 0.75}}, 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &analyzer.Results{Coupling: tt.coupling}
+			if got := newScorer().couplingScore(r); !approx(got, tt.want) {
+				t.Errorf("couplingScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	before := map[string]float64{"total": 80, "complexity": 90, "debt": 70, "coverage": 60}
+	current := Score{Total: 80.04, Complexity: 85, Debt: 75, Deps: 50}
+
+	got := Regressions(before, current)
+	// Coverage isn't measured now and deps wasn't recorded, so neither is
+	// compared; 80.04 rounds to the recorded 80.
+	if len(got) != 1 || got[0] != (Regression{Category: "complexity", Before: 90, After: 85}) {
+		t.Errorf("Regressions = %+v, want only complexity 90 → 85", got)
+	}
+}
+
+func TestScore_NormalizedBySize(t *testing.T) {
+	big := &analyzer.Results{
+		Files:         []analyzer.FileMetrics{{CodeLines: 50000}},
+		ExportedFuncs: 400,
+		Violations:    make([]analyzer.BoundaryViolation, 5),
+		DeadCode:      make([]analyzer.DeadFunction, 8),
+	}
+	s := newScorer()
+	// 50 KLOC is five times the reference: 5 violations cost what 1 would.
+	if got := s.boundariesScore(big); got != 90 {
+		t.Errorf("boundariesScore = %v, want 90", got)
+	}
+	// 400 exports is four times the reference: 8 dead cost what 2 would.
+	if got := s.deadCodeScore(big); got != 90 {
+		t.Errorf("deadCodeScore = %v, want 90", got)
+	}
+
+	cfg := config.Defaults()
+	cfg.Scoring.AbsoluteCounts = true
+	s = NewScorer(cfg)
+	if got := s.boundariesScore(big); got != 50 {
+		t.Errorf("absolute boundariesScore = %v, want 50", got)
+	}
+	if got := s.deadCodeScore(big); got != 60 {
+		t.Errorf("absolute deadCodeScore = %v, want 60", got)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Weights = config.WeightConfig{Complexity: 3, DeadCode: 1}
+	r := &analyzer.Results{
+		Complexity: []analyzer.FunctionComplexity{
+			{Path: "a.go", Name: "Big", Complexity: 30},  // 20 points
+			{Path: "a.go", Name: "Mid", Complexity: 21},  // 8 points
+			{Path: "a.go", Name: "Small", Complexity: 2}, // none
+		},
+		DeadCode: make([]analyzer.DeadFunction, 1),
+	}
+	s := NewScorer(cfg)
+	exps := s.Explain(r)
+	if len(exps) != 2 || exps[0].Category != "complexity" || exps[1].Category != "dead_code" {
+		t.Fatalf("categories = %+v, want complexity then dead_code", exps)
+	}
+
+	c := exps[0]
+	if c.Score != 72 || c.Weight != 0.75 || !approx(c.Lost(), 21) {
+		t.Errorf("complexity = %v/100 at weight %v, lost %v; want 72, 0.75, 21", c.Score, c.Weight, c.Lost())
+	}
+	if len(c.Penalties) != 2 || c.Penalties[0].Points != 20 || !approx(c.Penalties[1].Points, 8) {
+		t.Fatalf("penalties = %+v, want Big (20) then Mid (8)", c.Penalties)
+	}
+	// Fixing Big lifts complexity by 20, which is 15 points of the total.
+	if !approx(c.Penalties[0].Worth, 15) {
+		t.Errorf("Big worth = %v, want 15", c.Penalties[0].Worth)
+	}
+
+	// Explain must agree with Calculate and leave delta tracking alone.
+	score := s.Calculate(r)
+	if score.Complexity != c.Score || score.Delta != 0 {
+		t.Errorf("Calculate = %+v, want complexity %v and no delta", score, c.Score)
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for score, want := range map[float64]string{100: "A", 90: "A", 89.9: "B", 80: "B", 75: "C", 60: "D", 59.9: "F", 0: "F"} {
+		if got := Grade(score); got != want {
+			t.Errorf("Grade(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
+
+func TestBadgeJSON(t *testing.T) {
+	data, err := BadgeJSON("health", Score{Total: 84.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"schemaVersion": 1`, `"label": "health"`, `"message": "84 B"`, `"color": "green"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("badge JSON missing %s:\n%s", want, data)
+		}
+	}
+	if svg := string(BadgeSVG("a&b", Score{Total: 12})); !strings.Contains(svg, "a&amp;b: 12 F") || !strings.Contains(svg, "#e05d44") {
+		t.Errorf("badge SVG not escaped or wrong color:\n%s", svg)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	r := &analyzer.Results{
+		Files: []analyzer.FileMetrics{{Path: "internal/api/handler.go"}},
+		Complexity: []analyzer.FunctionComplexity{
+			{Path: "internal/api/handler.go", Name: "Serve", Line: 12, Complexity: 30},
+			{Path: "internal/api/handler.go", Name: "Simple", Line: 40, Complexity: 2},
+		},
+		Violations:      []analyzer.BoundaryViolation{{File: "handler.go", Line: 5, From: "api", To: "db", Import: "example.com/db"}},
+		DeadCode:        []analyzer.DeadFunction{{File: "handler.go", Path: "internal/api/handler.go", Name: "Old", Line: 60, Kind: "function"}},
+		Vulnerabilities: []analyzer.Vulnerability{{ID: "GO-2024-1", Severity: "medium", Module: "example.com/x", Language: analyzer.LangGo}},
+	}
+	data, err := SARIF(cfg, r, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want Serve, the violation, Old, and the vulnerability:\n%s", len(results), data)
+	}
+	for i, want := range []struct {
+		rule, level, uri string
+		line             int
+	}{
+		{"drift/complexity", "warning", "internal/api/handler.go", 12},
+		{"drift/boundary", "error", "internal/api/handler.go", 5},
+		{"drift/dead-code", "note", "internal/api/handler.go", 60},
+		{"drift/vulnerability", "warning", "go.mod", 0},
+	} {
+		got := results[i]
+		loc := got.Locations[0].PhysicalLocation
+		line := 0
+		if loc.Region != nil {
+			line = loc.Region.StartLine
+		}
+		if got.RuleID != want.rule || log.Runs[0].Tool.Driver.Rules[got.RuleIndex].ID != want.rule ||
+			got.Level != want.level || loc.ArtifactLocation.URI != want.uri || line != want.line {
+			t.Errorf("result %d = %+v at %s:%d, want %s %s at %s:%d", i, got, loc.ArtifactLocation.URI, line, want.rule, want.level, want.uri, want.line)
+		}
+	}
+}
+
+func TestSARIF_Languages(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	r := &analyzer.Results{
+		Language:  analyzer.LangGo,
+		Languages: []analyzer.Language{analyzer.LangGo, analyzer.LangTypeScript},
+		Files:     []analyzer.FileMetrics{{Path: "web/app.ts", Language: analyzer.LangTypeScript}},
+		Complexity: []analyzer.FunctionComplexity{
+			{Path: "api/handler.go", Name: "Serve", Line: 12, Complexity: 30, Language: analyzer.LangGo},
+		},
+		DeadCode: []analyzer.DeadFunction{{File: "app.ts", Path: "web/app.ts", Name: "old", Line: 3, Kind: "function"}},
+	}
+	data, err := SARIF(cfg, r, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 || results[0].Properties["language"] != "go" || results[1].Properties["language"] != "typescript" {
+		t.Errorf("results not tagged with their languages:\n%s", data)
+	}
+
+	r.Languages = r.Languages[:1]
+	if data, _ := SARIF(cfg, r, "1.2.3"); strings.Contains(string(data), "properties") {
+		t.Errorf("single-language results tagged:\n%s", data)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	by := now.Add(4 * week)
+	history := []Sample{
+		{Time: now.Add(-60 * 24 * time.Hour), Score: 50}, // outside the trend window
+		{Time: now.Add(-2 * week), Score: 70},
+		{Time: now.Add(-week), Score: 72},
+	}
+
+	g := Progress(85, by, 75, history, now)
+	if g.Remaining() != 10 || !approx(g.Needed, 2.5) {
+		t.Errorf("remaining %v, needed %v/week, want 10 and 2.5", g.Remaining(), g.Needed)
+	}
+	if !g.TrendKnown || !approx(g.Trend, 2.5) {
+		t.Errorf("trend = %v (known %v), want +2.5/week from the oldest sample in the window", g.Trend, g.TrendKnown)
+	}
+	if g.Overdue(now) || !g.Overdue(by) {
+		t.Error("goal should be overdue at the deadline and not before")
+	}
+
+	if g := Progress(85, time.Time{}, 90, nil, now); !g.Reached() || g.Needed != 0 || g.TrendKnown {
+		t.Errorf("reached goal without deadline or history = %+v", g)
+	}
+}
+
+func TestCalculate_Goal(t *testing.T) {
+	cfg := config.Defaults()
+	if s := NewScorer(cfg).Calculate(&analyzer.Results{}); s.Goal != nil {
+		t.Errorf("goal = %+v without one configured", s.Goal)
+	}
+	cfg.Goals = config.GoalsConfig{Total: 95, By: "2999-01-01"}
+	s := NewScorer(cfg).Calculate(&analyzer.Results{})
+	if s.Goal == nil || s.Goal.Target != 95 || s.Goal.By.Year() != 2999 {
+		t.Errorf("goal = %+v, want 95 by 2999-01-01", s.Goal)
+	}
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// StateFile is where drift remembers the last score between runs, relative
+// to the analysis root. It is local to the checkout and not meant to be
+// committed.
+const StateFile = ".drift/state.json"
+
+// maxSamples caps the recorded history at about a year of daily samples.
+const maxSamples = 366
+
+// Sample is the total score recorded on one day.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Score float64   `json:"score"`
+}
+
+// state is what StateFile holds.
+type state struct {
+	Score     float64   `json:"score"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// History keeps the last score of each day drift ran, oldest first,
+	// for the goal trend.
+	History []Sample `json:"history,omitempty"`
+}
+
+// LoadState reads the totals an earlier run saved in StateFile under root,
+// so Delta compares with the previous invocation of drift rather than only
+// the previous Calculate in this process, and goal progress has a history
+// to measure the trend from. SaveState writes them back. A missing or
+// unreadable state file leaves the first Delta at 0.
+func (s *Scorer) LoadState(root string) {
+	s.statePath = filepath.Join(root, StateFile)
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		return
+	}
+	var st state
+	if json.Unmarshal(data, &st) == nil {
+		s.previous = st.Score
+		s.history = st.History
+	}
+}
+
+// SaveState records total, the run's final score, in the state file
+// LoadState read, replacing the day's earlier sample. It does nothing if the
+// state wasn't loaded. Failing to save is not worth failing the run over, so
+// errors are dropped.
+func (s *Scorer) SaveState(total float64) {
+	if s.statePath == "" {
+		return
+	}
+	now := time.Now().UTC()
+	s.history = withSample(s.history, total, now)
+	data, err := json.MarshalIndent(state{Score: total, UpdatedAt: now, History: s.history}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(s.statePath, append(data, '\n'), 0o644)
+}
+
+// withSample returns a copy of history with total added as the sample for
+// now's day, replacing the day's earlier one.
+func withSample(history []Sample, total float64, now time.Time) []Sample {
+	sample := Sample{Time: now.UTC(), Score: total}
+	history = slices.Clone(history)
+	if n := len(history); n > 0 && sameDay(history[n-1].Time, sample.Time) {
+		history[n-1] = sample
+	} else {
+		history = append(history, sample)
+	}
+	if len(history) > maxSamples {
+		history = history[len(history)-maxSamples:]
+	}
+	return history
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,244 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// Metric reads a value bisect can track from rec: a score such as "total"
+// or "complexity", a count such as "violations", or "avg_complexity".
+// Scores regress by going down; the others by going up.
+func Metric(rec Record, name string) (value float64, higherIsBetter bool, ok bool) {
+	if v, ok := rec.Score[name]; ok {
+		return v, true, true
+	}
+	if v, ok := rec.Counts[name]; ok {
+		return float64(v), false, true
+	}
+	if name == "avg_complexity" {
+		return rec.AvgComplexity, false, true
+	}
+	return 0, false, false
+}
+
+// Metrics lists the names Metric accepts, sorted. Where a score and a
+// count share a name, such as "dead_code", it means the score.
+func Metrics() []string {
+	rec := NewRecord("", health.Score{CoverageMeasured: true, MaintainabilityMeasured: true},
+		&analyzer.Results{}, config.ThresholdConfig{})
+	names := []string{"avg_complexity"}
+	for name := range rec.Score {
+		names = append(names, name)
+	}
+	for name := range rec.Counts {
+		if _, ok := rec.Score[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Culprit is a commit after which a metric was past its threshold while
+// before it, on its first parent, it wasn't.
+type Culprit struct {
+	Commit  string    `json:"commit"` // full hash
+	Author  string    `json:"author"`
+	When    time.Time `json:"when"`
+	Subject string    `json:"subject"` // first line of the message
+
+	Before Record   `json:"before"` // the first parent
+	After  Record   `json:"after"`
+	Files  []string `json:"files"` // source files the commit changed
+}
+
+// Bisection is what Bisect found between two revisions.
+type Bisection struct {
+	From     Record    `json:"from"`
+	To       Record    `json:"to"`
+	Commits  int       `json:"commits"`  // commits in the range, including from
+	Analyzed int       `json:"analyzed"` // commits analyzed to find the culprits
+	Culprits []Culprit `json:"culprits"` // oldest first
+}
+
+// Bisect finds where a metric went bad on the first-parent history from
+// from, exclusive, to to. bad reports whether rec is past the threshold,
+// given the record of from, which must not be. Like `git bisect` it
+// assumes the history went bad once and analyzes about log2 of the
+// commits; with all it analyzes every commit and reports each time the
+// metric went from good to bad.
+func (a *Analyzer) Bisect(from, to string, bad func(rec, from Record) bool, all bool) (*Bisection, error) {
+	commits, err := a.firstParents(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	co, err := newCheckout(a.repo)
+	if err != nil {
+		return nil, err
+	}
+	defer co.remove()
+
+	// records[i] is commits[i]'s record once analyzed.
+	records := make([]*Record, len(commits))
+	analyzed := 0
+	at := func(i int) (Record, error) {
+		if records[i] == nil {
+			rec, err := a.record(co, commits[i])
+			if err != nil {
+				return Record{}, fmt.Errorf("analyzing %s: %w", commits[i].Hash.String()[:7], err)
+			}
+			records[i] = &rec
+			analyzed++
+		}
+		return *records[i], nil
+	}
+
+	last := len(commits) - 1
+	first, err := at(0)
+	if err != nil {
+		return nil, err
+	}
+	if bad(first, first) {
+		return nil, fmt.Errorf("the metric is already past the threshold at %s", from)
+	}
+	end, err := at(last)
+	if err != nil {
+		return nil, err
+	}
+	result := &Bisection{From: first, To: end, Commits: len(commits), Culprits: []Culprit{}}
+	if !bad(end, first) {
+		result.Analyzed = analyzed
+		return result, nil
+	}
+
+	var culprits []int
+	if all {
+		prev := false
+		for i := 1; i <= last; i++ {
+			rec, err := at(i)
+			if err != nil {
+				return nil, err
+			}
+			cur := bad(rec, first)
+			if cur && !prev {
+				culprits = append(culprits, i)
+			}
+			prev = cur
+		}
+	} else {
+		// commits[good] is good and commits[worse] bad; narrow them to
+		// neighbors.
+		good, worse := 0, last
+		for worse-good > 1 {
+			mid := (good + worse) / 2
+			rec, err := at(mid)
+			if err != nil {
+				return nil, err
+			}
+			if bad(rec, first) {
+				worse = mid
+			} else {
+				good = mid
+			}
+		}
+		culprits = append(culprits, worse)
+	}
+
+	source := a.sourceFilter()
+	for _, i := range culprits {
+		before, err := at(i - 1)
+		if err != nil {
+			return nil, err
+		}
+		c := commits[i]
+		files, err := changedFiles(commits[i-1], c, source)
+		if err != nil {
+			return nil, err
+		}
+		result.Culprits = append(result.Culprits, Culprit{
+			Commit:  c.Hash.String(),
+			Author:  c.Author.Name,
+			When:    c.Author.When,
+			Subject: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+			Before:  before,
+			After:   *records[i],
+			Files:   files,
+		})
+	}
+	result.Analyzed = analyzed
+	return result, nil
+}
+
+// firstParents lists the commits from from to to, oldest first, following
+// to's first parents back to from.
+func (a *Analyzer) firstParents(from, to string) ([]*object.Commit, error) {
+	var ends [2]*object.Commit
+	for i, rev := range []string{from, to} {
+		hash, err := a.repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", rev, err)
+		}
+		if ends[i], err = a.repo.CommitObject(*hash); err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", rev, err)
+		}
+	}
+
+	commits := []*object.Commit{ends[1]}
+	for c := ends[1]; c.Hash != ends[0].Hash; {
+		if c.NumParents() == 0 {
+			return nil, fmt.Errorf("%s is not on the first-parent history of %s", from, to)
+		}
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, parent)
+		c = parent
+	}
+	if len(commits) < 2 {
+		return nil, fmt.Errorf("%s and %s are the same commit", from, to)
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// changedFiles lists the files keep accepts that differ between parent and
+// c, sorted.
+func changedFiles(parent, c *object.Commit, keep func(name string) bool) ([]string, error) {
+	from, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+	to, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, ch := range changes {
+		name := ch.To.Name
+		if name == "" {
+			name = ch.From.Name // deleted file
+		}
+		if keep(name) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
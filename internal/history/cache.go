@@ -0,0 +1,97 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// CacheDir holds the record of each commit the sparklines and
+// `drift trend` have analyzed, relative to the analysis root, so no commit
+// is analyzed twice across runs.
+const CacheDir = ".drift/cache/commits"
+
+// configKey identifies what a commit's analysis depends on besides the
+// commit: the configuration and the build of drift, whose analyzers may
+// change between versions. Records cached under another key are ignored.
+func configKey(cfg *config.Config) string {
+	c := *cfg
+	c.Root = "" // the same checkout may be opened from different paths
+	data, _ := json.Marshal(c)
+	h := sha256.New()
+	h.Write(data)
+	// Subdirectories' thresholds aren't part of the config's JSON.
+	areas, _ := json.Marshal(c.Thresholds.Areas())
+	h.Write(areas)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		h.Write([]byte(info.Main.Version))
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+				h.Write([]byte(s.Value))
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// cachePath is where the record of commit hash is cached.
+func (a *Analyzer) cachePath(hash plumbing.Hash) string {
+	return filepath.Join(a.cfg.Root, CacheDir, hash.String()+"-"+a.key+".json")
+}
+
+// record summarizes commit as of when it was committed, analyzing it in co
+// unless it is cached.
+func (a *Analyzer) record(co *checkout, commit *object.Commit) (Record, error) {
+	path := a.cachePath(commit.Hash)
+	if data, err := os.ReadFile(path); err == nil {
+		var rec Record
+		if json.Unmarshal(data, &rec) == nil {
+			return rec, nil
+		}
+	}
+
+	results, err := a.analyzeCommit(co, commit, false)
+	if err != nil {
+		return Record{}, err
+	}
+	rec := NewRecord("commit", a.scorer.Calculate(results), results, a.cfg.Thresholds)
+	rec.Time = commit.Committer.When.UTC()
+	rec.Commit = commit.Hash.String()[:7]
+
+	// Caching is an optimization; failing to write just means analyzing
+	// again next time.
+	a.pruneCache()
+	if data, err := json.Marshal(rec); err == nil && os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+		_ = os.WriteFile(path, data, 0o644)
+	}
+	return rec, nil
+}
+
+// pruneCache removes the records cached under keys other than a's, once,
+// before the first record a caches. Those were made with another config
+// or build of drift and would only pile up.
+func (a *Analyzer) pruneCache() {
+	if a.pruned {
+		return
+	}
+	a.pruned = true
+	dir := filepath.Join(a.cfg.Root, CacheDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), "-"+a.key+".json") {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
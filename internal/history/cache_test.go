@@ -0,0 +1,87 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestConfigKey_Areas(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "legacy"), 0o755)
+	os.WriteFile(filepath.Join(root, ".drift.yaml"), []byte("thresholds:\n  max_complexity: 10\n"), 0o644)
+	area := filepath.Join(root, "legacy", ".drift.yaml")
+	load := func() *config.Config {
+		t.Helper()
+		cfg, err := config.Load(filepath.Join(root, ".drift.yaml"), config.Override{Key: "root", Value: root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	os.WriteFile(area, []byte("thresholds:\n  max_complexity: 30\n"), 0o644)
+	before := configKey(load())
+	if again := configKey(load()); again != before {
+		t.Errorf("key changed between loads of the same config: %s, %s", before, again)
+	}
+	os.WriteFile(area, []byte("thresholds:\n  max_complexity: 40\n"), 0o644)
+	if after := configKey(load()); after == before {
+		t.Error("changing a subdirectory's thresholds kept the cache key")
+	}
+}
+
+func TestRecord_Cache(t *testing.T) {
+	r := newTestRepo(t)
+	commit := r.commit("Alice", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		map[string]string{"a.go": "package a\n\nfunc A() {}\n"})
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	cfg.Language = "go"
+	a := &Analyzer{cfg: cfg, repo: r.repo, scorer: health.NewScorer(cfg), key: "current"}
+	co, err := newCheckout(r.repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer co.remove()
+
+	// A record cached under a's key is returned as it is, without
+	// analyzing the commit.
+	cached := Record{Command: "cached", Score: map[string]float64{"total": 42}}
+	data, _ := json.Marshal(cached)
+	os.MkdirAll(filepath.Join(cfg.Root, CacheDir), 0o755)
+	os.WriteFile(a.cachePath(commit.Hash), data, 0o644)
+	rec, err := a.record(co, commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Command != "cached" || rec.Score["total"] != 42 {
+		t.Errorf("record = %+v, want the cached one", rec)
+	}
+	if entries, _ := os.ReadDir(co.dir); len(entries) != 0 {
+		t.Error("the commit was extracted for a cached record")
+	}
+
+	// Under another key it misses, and the commit is analyzed and cached
+	// under that key, with the stale record pruned.
+	stale := a.cachePath(commit.Hash)
+	a.key = "changed"
+	rec, err = a.record(co, commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Command != "commit" || rec.Commit != commit.Hash.String()[:7] || rec.Counts["functions"] != 1 {
+		t.Errorf("record = %+v, want commit %s analyzed", rec, commit.Hash.String()[:7])
+	}
+	if _, err := os.Stat(a.cachePath(commit.Hash)); err != nil {
+		t.Errorf("analyzed record not cached: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("record cached under the old key not pruned: %v", err)
+	}
+}
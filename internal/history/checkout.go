@@ -0,0 +1,110 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// checkout is a scratch directory holding one commit's files at a time.
+// Moving it to another commit writes only the files whose content differs
+// and removes those that are gone, so walking consecutive commits, which
+// mostly share files, costs about as much disk I/O as their diffs rather
+// than a full extraction each. The analyzers read files from disk (the Go
+// analyzer through `go list`), so they can't read blobs from memory.
+type checkout struct {
+	repo  *git.Repository
+	dir   string
+	files map[string]plumbing.Hash // path → blob written there
+}
+
+func newCheckout(repo *git.Repository) (*checkout, error) {
+	dir, err := os.MkdirTemp("", "drift-history-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	return &checkout{repo: repo, dir: dir, files: make(map[string]plumbing.Hash)}, nil
+}
+
+// update makes the directory hold the files of tree that keep accepts.
+func (c *checkout) update(tree *object.Tree, keep func(name string) bool) error {
+	want := make(map[string]plumbing.Hash, len(c.files))
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if entry.Mode.IsFile() && keep(name) {
+			want[name] = entry.Hash
+		}
+	}
+
+	// Remove first, in case a file's path became a directory.
+	for name := range c.files {
+		if _, ok := want[name]; !ok {
+			if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			delete(c.files, name)
+		}
+	}
+	for name, hash := range want {
+		if c.files[name] == hash {
+			continue
+		}
+		if err := c.write(name, hash); err != nil {
+			return fmt.Errorf("extracting %s: %w", name, err)
+		}
+		c.files[name] = hash
+	}
+	return nil
+}
+
+// write copies blob hash to name.
+func (c *checkout) write(name string, hash plumbing.Hash) error {
+	blob, err := c.repo.BlobObject(hash)
+	if err != nil {
+		return err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	dest := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	// A directory left where a file now is; its files are already removed.
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		if err := os.RemoveAll(dest); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// remove deletes the directory.
+func (c *checkout) remove() {
+	os.RemoveAll(c.dir)
+}
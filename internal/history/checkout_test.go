@@ -0,0 +1,73 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCheckout_Update(t *testing.T) {
+	r := newTestRepo(t)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	commits := []*object.Commit{
+		r.commit("Alice", start, map[string]string{"a.go": "a1", "b.go": "b1", "pkg": "a file"}),
+		// b.go removed; pkg becomes a directory.
+		r.commit("Alice", start.Add(time.Hour), map[string]string{"b.go": "", "pkg": "", "pkg/x.go": "x1", "a.go": "a2"}),
+		// pkg becomes a file again; a.go unchanged.
+		r.commit("Alice", start.Add(2*time.Hour), map[string]string{"pkg/x.go": "", "pkg": "a file again", "c.go": "c1"}),
+	}
+
+	co, err := newCheckout(r.repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer co.remove()
+	moveTo := func(c *object.Commit) {
+		t.Helper()
+		tree, err := c.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := co.update(tree, func(string) bool { return true }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(step string, want map[string]string, gone ...string) {
+		t.Helper()
+		for name, content := range want {
+			data, err := os.ReadFile(filepath.Join(co.dir, name))
+			if err != nil || string(data) != content {
+				t.Errorf("%s: %s = %q, %v; want %q", step, name, data, err, content)
+			}
+		}
+		for _, name := range gone {
+			if info, err := os.Stat(filepath.Join(co.dir, name)); err == nil && !info.IsDir() {
+				t.Errorf("%s: %s still there", step, name)
+			}
+		}
+	}
+
+	moveTo(commits[0])
+	check("first", map[string]string{"a.go": "a1", "b.go": "b1", "pkg": "a file"})
+
+	moveTo(commits[1])
+	check("file to directory", map[string]string{"a.go": "a2", "pkg/x.go": "x1"}, "b.go")
+
+	// Files whose blob is unchanged aren't written again.
+	os.WriteFile(filepath.Join(co.dir, "a.go"), []byte("untouched"), 0o644)
+	moveTo(commits[2])
+	check("directory to file", map[string]string{"a.go": "untouched", "pkg": "a file again", "c.go": "c1"}, "b.go", "pkg/x.go")
+
+	// Back to an earlier commit, with a file filtered out.
+	tree, _ := commits[1].Tree()
+	if err := co.update(tree, func(name string) bool { return name != "a.go" }); err != nil {
+		t.Fatal(err)
+	}
+	check("back", map[string]string{"pkg/x.go": "x1"}, "a.go", "c.go")
+	if len(co.files) != 1 {
+		t.Errorf("tracking %v, want only pkg/x.go", co.files)
+	}
+}
@@ -2,9 +2,12 @@
 package history
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -26,6 +29,11 @@ type Analyzer struct {
 	cfg    *config.Config
 	repo   *git.Repository
 	scorer *health.Scorer
+	key    string // configKey(cfg), under which commit records are cached
+	pruned bool   // whether records cached under other keys are removed
+	// langs are the working tree's languages, which checkouts are analyzed
+	// as when none are configured; see analyzeCommit.
+	langs []string
 }
 
 func New(cfg *config.Config) (*Analyzer, error) {
@@ -35,60 +43,92 @@ func New(cfg *config.Config) (*Analyzer, error) {
 		return nil, fmt.Errorf("opening git repo: %w", err)
 	}
 
-	return &Analyzer{
+	a := &Analyzer{
 		cfg:    cfg,
 		repo:   repo,
 		scorer: health.NewScorer(cfg),
-	}, nil
+		key:    configKey(cfg),
+	}
+	if cfg.Language == "" && len(cfg.Languages) == 0 && len(cfg.Projects) == 0 && !cfg.DiscoverProjects {
+		for _, l := range analyzer.New(cfg).Languages() {
+			a.langs = append(a.langs, string(l))
+		}
+	}
+	return a, nil
 }
 
-func (a *Analyzer) Walk(maxCommits int) (*SparklineData, error) {
-	if maxCommits <= 0 {
-		maxCommits = 10
-	}
+// Walk summarizes the commits the history config picks for the
+// sparklines, oldest first: history.depth of them walking back from
+// history.branch, sampled as history.sample says.
+func (a *Analyzer) Walk() (*SparklineData, error) {
+	h := a.cfg.History
+	depth := max(h.Depth, 1)
 
-	ref, err := a.repo.Head()
+	tip, err := a.tip()
 	if err != nil {
-		return nil, fmt.Errorf("getting HEAD: %w", err)
+		return nil, err
 	}
 
-	commits, err := a.getCommits(ref.Hash(), maxCommits)
+	var commits []*object.Commit
+	switch h.Sample {
+	case config.SampleDaily:
+		commits, err = a.commitsPer(tip, depth, func(t time.Time) string { return t.Format(time.DateOnly) })
+	case config.SampleWeekly:
+		commits, err = a.commitsPer(tip, depth, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+	case config.SampleTags:
+		commits, err = a.taggedCommits(tip, depth)
+	default:
+		commits, err = a.getCommits(tip, depth, max(h.Every, 1))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getting commits: %w", err)
 	}
 
-	if len(commits) == 0 {
-		return &SparklineData{}, nil
-	}
-
-	data := &SparklineData{
-		HealthScore:    make([]float64, 0, len(commits)),
-		AvgComplexity:  make([]float64, 0, len(commits)),
-		ViolationCount: make([]float64, 0, len(commits)),
-		DeadCodeCount:  make([]float64, 0, len(commits)),
+	co, err := newCheckout(a.repo)
+	if err != nil {
+		return nil, err
 	}
+	defer co.remove()
 
 	// Analyze commits from oldest to newest
+	var records []Record
 	for i := len(commits) - 1; i >= 0; i-- {
-		commit := commits[i]
-
-		results, err := a.analyzeCommit(commit)
+		rec, err := a.record(co, commits[i])
 		if err != nil {
 			// Skip commits that fail to analyze
 			continue
 		}
-
-		score := a.scorer.Calculate(results)
-		data.HealthScore = append(data.HealthScore, score.Total)
-		data.AvgComplexity = append(data.AvgComplexity, avgComplexity(results.Complexity))
-		data.ViolationCount = append(data.ViolationCount, float64(len(results.Violations)))
-		data.DeadCodeCount = append(data.DeadCodeCount, float64(len(results.DeadCode)))
+		records = append(records, rec)
 	}
 
-	return data, nil
+	return Sparklines(records, len(records)), nil
+}
+
+// tip is the commit history.branch names, or HEAD when it's empty.
+func (a *Analyzer) tip() (plumbing.Hash, error) {
+	if branch := a.cfg.History.Branch; branch != "" {
+		hash, err := a.repo.ResolveRevision(plumbing.Revision(branch))
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("resolving history.branch %q: %w", branch, err)
+		}
+		return *hash, nil
+	}
+	ref, err := a.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("getting HEAD: %w", err)
+	}
+	return ref.Hash(), nil
 }
 
-func (a *Analyzer) getCommits(hash plumbing.Hash, max int) ([]*object.Commit, error) {
+// errEnough stops a log walk once it has found what it needs.
+var errEnough = errors.New("enough commits")
+
+// getCommits returns up to max commits walking back from hash, newest
+// first, taking every Nth.
+func (a *Analyzer) getCommits(hash plumbing.Hash, max, every int) ([]*object.Commit, error) {
 	cIter, err := a.repo.Log(&git.LogOptions{From: hash})
 	if err != nil {
 		return nil, err
@@ -96,82 +136,297 @@ func (a *Analyzer) getCommits(hash plumbing.Hash, max int) ([]*object.Commit, er
 	defer cIter.Close()
 
 	var commits []*object.Commit
-	count := 0
-
+	i := 0
 	err = cIter.ForEach(func(c *object.Commit) error {
-		if count >= max {
-			return fmt.Errorf("max reached")
+		if len(commits) >= max {
+			return errEnough
 		}
-		commits = append(commits, c)
-		count++
+		if i%every == 0 {
+			commits = append(commits, c)
+		}
+		i++
 		return nil
 	})
+	if err != nil && !errors.Is(err, errEnough) {
+		return nil, err
+	}
+	return commits, nil
+}
 
-	if err != nil && err.Error() != "max reached" {
+// commitsPer returns the newest commit of each of the last max periods
+// with commits walking back from hash, newest first. period names the
+// period a commit time, in UTC, falls in.
+func (a *Analyzer) commitsPer(hash plumbing.Hash, max int, period func(time.Time) string) ([]*object.Commit, error) {
+	cIter, err := a.repo.Log(&git.LogOptions{From: hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
 		return nil, err
 	}
+	defer cIter.Close()
 
+	seen := make(map[string]bool)
+	var commits []*object.Commit
+	err = cIter.ForEach(func(c *object.Commit) error {
+		p := period(c.Committer.When.UTC())
+		if seen[p] {
+			return nil
+		}
+		if len(commits) >= max {
+			return errEnough
+		}
+		seen[p] = true
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnough) {
+		return nil, err
+	}
 	return commits, nil
 }
 
-func (a *Analyzer) analyzeCommit(commit *object.Commit) (*analyzer.Results, error) {
-	tree, err := commit.Tree()
+// taggedCommits returns up to max of the tagged commits reachable from
+// hash, newest first.
+func (a *Analyzer) taggedCommits(hash plumbing.Hash, max int) ([]*object.Commit, error) {
+	releases, err := a.releases(hash)
 	if err != nil {
-		return nil, fmt.Errorf("getting tree: %w", err)
+		return nil, err
+	}
+	if len(releases) > max {
+		releases = releases[:max]
+	}
+	commits := make([]*object.Commit, len(releases))
+	for i, r := range releases {
+		commits[i] = r.commit
 	}
+	return commits, nil
+}
+
+// release is a tagged commit.
+type release struct {
+	tag    string // its tags, sorted and comma-separated
+	commit *object.Commit
+}
 
-	// Create a temporary directory for this commit's files
-	tmpDir, err := os.MkdirTemp("", "drift-history-*")
+// releases lists the tagged commits reachable from hash, newest first.
+func (a *Analyzer) releases(hash plumbing.Hash) ([]release, error) {
+	tip, err := a.repo.CommitObject(hash)
 	if err != nil {
-		return nil, fmt.Errorf("creating temp dir: %w", err)
+		return nil, err
 	}
-	defer os.RemoveAll(tmpDir)
-
-	// Extract source files from this commit using detected language
-	langAna := analyzer.NewLanguageAnalyzer(analyzer.DetectLanguage(a.cfg.Root))
-	extSet := make(map[string]bool)
-	for _, ext := range langAna.Extensions() {
-		extSet[ext] = true
+	tags, err := a.repo.Tags()
+	if err != nil {
+		return nil, err
 	}
+	defer tags.Close()
 
-	err = tree.Files().ForEach(func(f *object.File) error {
-		if !extSet[filepath.Ext(f.Name)] {
+	names := make(map[plumbing.Hash][]string)
+	commits := make(map[plumbing.Hash]*object.Commit)
+	reachable := make(map[plumbing.Hash]bool)
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		c, err := a.tagCommit(ref)
+		if err != nil {
+			// Tags of trees or blobs have no commit to analyze.
 			return nil
 		}
+		if _, ok := commits[c.Hash]; !ok {
+			commits[c.Hash] = c
+			ok, err := c.IsAncestor(tip)
+			reachable[c.Hash] = err == nil && (ok || c.Hash == tip.Hash)
+		}
+		names[c.Hash] = append(names[c.Hash], ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Skip excluded directories
-		for _, exclude := range a.cfg.Exclude {
-			if matched, _ := filepath.Match(exclude+"/*", f.Name); matched {
-				return nil
-			}
+	var releases []release
+	for hash, c := range commits {
+		if reachable[hash] {
+			sort.Strings(names[hash])
+			releases = append(releases, release{strings.Join(names[hash], ", "), c})
 		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		ci, cj := releases[i].commit, releases[j].commit
+		if !ci.Committer.When.Equal(cj.Committer.When) {
+			return ci.Committer.When.After(cj.Committer.When)
+		}
+		return releases[i].tag > releases[j].tag
+	})
+	return releases, nil
+}
 
-		content, err := f.Contents()
-		if err != nil {
-			return err
+// Releases summarizes the tagged commits on history.branch (HEAD by
+// default) made since since, oldest first, each record's Tag naming its
+// tags. With a positive max only the last max are analyzed.
+func (a *Analyzer) Releases(since time.Time, max int) ([]Record, error) {
+	tip, err := a.tip()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := a.releases(tip)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	for i, r := range releases {
+		if r.commit.Committer.When.Before(since) {
+			releases = releases[:i]
+			break
 		}
+	}
+	if max > 0 && len(releases) > max {
+		releases = releases[:max]
+	}
 
-		dest := filepath.Join(tmpDir, f.Name)
-		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-			return err
+	co, err := newCheckout(a.repo)
+	if err != nil {
+		return nil, err
+	}
+	defer co.remove()
+
+	var records []Record
+	for i := len(releases) - 1; i >= 0; i-- {
+		rec, err := a.record(co, releases[i].commit)
+		if err != nil {
+			return nil, fmt.Errorf("analyzing %s: %w", releases[i].tag, err)
 		}
+		rec.Tag = releases[i].tag
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// tagCommit is the commit ref, a lightweight or annotated tag, points to.
+func (a *Analyzer) tagCommit(ref *plumbing.Reference) (*object.Commit, error) {
+	tag, err := a.repo.TagObject(ref.Hash())
+	switch {
+	case err == nil:
+		return tag.Commit()
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return a.repo.CommitObject(ref.Hash())
+	}
+	return nil, err
+}
 
-		return os.WriteFile(dest, []byte(content), 0644)
+// WalkSince summarizes the commits on history.branch (HEAD by default)
+// made since since, oldest first, as records of the "commit" command timed
+// when they were committed. When there are more than max, max of them are
+// picked evenly spread over the range, so the trend still spans all of it.
+func (a *Analyzer) WalkSince(since time.Time, max int) ([]Record, error) {
+	tip, err := a.tip()
+	if err != nil {
+		return nil, err
+	}
+	cIter, err := a.repo.Log(&git.LogOptions{From: tip, Since: &since})
+	if err != nil {
+		return nil, fmt.Errorf("getting commits: %w", err)
+	}
+	var commits []*object.Commit
+	err = cIter.ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
+		return nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("getting commits: %w", err)
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Committer.When.Before(commits[j].Committer.When) })
+	switch {
+	case max == 1 && len(commits) > 1:
+		commits = commits[len(commits)-1:]
+	case max > 1 && len(commits) > max:
+		sampled := make([]*object.Commit, max)
+		for i := range sampled {
+			sampled[i] = commits[i*(len(commits)-1)/(max-1)]
+		}
+		commits = sampled
+	}
 
+	co, err := newCheckout(a.repo)
+	if err != nil {
+		return nil, err
+	}
+	defer co.remove()
+
+	var records []Record
+	for _, c := range commits {
+		rec, err := a.record(co, c)
+		if err != nil {
+			// Skip commits that fail to analyze
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// AnalyzeRef analyzes the tree at rev, any revision git understands such as
+// a branch, tag or "HEAD~3". Unlike the sparkline walk it extracts every file
+// that isn't excluded, so manifests are there for dependency analysis.
+func (a *Analyzer) AnalyzeRef(rev string) (*analyzer.Results, error) {
+	hash, err := a.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", rev, err)
+	}
+	commit, err := a.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", rev, err)
+	}
+	co, err := newCheckout(a.repo)
+	if err != nil {
+		return nil, err
+	}
+	defer co.remove()
+	return a.analyzeCommit(co, commit, true)
+}
+
+// analyzeCommit analyzes a commit's tree, moving co to it. Only source
+// files are extracted unless all is set.
+func (a *Analyzer) analyzeCommit(co *checkout, commit *object.Commit, all bool) (*analyzer.Results, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("getting tree: %w", err)
+	}
+
+	// Extract source files from this commit for every configured language
+	source := a.sourceFilter()
+	err = co.update(tree, func(name string) bool {
+		if all {
+			return !config.Excluded(a.cfg.Exclude, name)
+		}
+		return source(name)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("extracting files: %w", err)
 	}
 
-	// Create a temporary config pointing to the temp directory
+	// Create a temporary config pointing to the checkout
 	tmpCfg := *a.cfg
-	tmpCfg.Root = tmpDir
+	tmpCfg.Root = co.dir
+	tmpCfg.Coverage.Run = false // never run tests against historical snapshots
+	// Checkouts of source files alone lack the manifests the language is
+	// detected by, so they're analyzed as the working tree is.
+	if tmpCfg.Languages == nil {
+		tmpCfg.Languages = a.langs
+	}
 
 	// Analyze the extracted files
 	ana := analyzer.New(&tmpCfg)
 	return ana.Run()
 }
 
+// sourceFilter reports whether the analyzers read a path: a source file of
+// a configured language that isn't excluded.
+func (a *Analyzer) sourceFilter() func(name string) bool {
+	extSet := make(map[string]bool)
+	for _, ext := range analyzer.New(a.cfg).Extensions() {
+		extSet[ext] = true
+	}
+	return func(name string) bool {
+		return !config.Excluded(a.cfg.Exclude, name) &&
+			extSet[filepath.Ext(name)] && config.Included(a.cfg.Include, name)
+	}
+}
+
 func avgComplexity(complexities []analyzer.FunctionComplexity) float64 {
 	if len(complexities) == 0 {
 		return 0
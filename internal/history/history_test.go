@@ -0,0 +1,184 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// when names the commits by their times, to compare what a walk picked.
+func when(commits []*object.Commit) string {
+	var times []string
+	for _, c := range commits {
+		times = append(times, c.Committer.When.UTC().Format("Jan 2 15h"))
+	}
+	return strings.Join(times, ", ")
+}
+
+func TestGetCommits_Every(t *testing.T) {
+	r := newTestRepo(t)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var tip *object.Commit
+	for i := range 7 {
+		tip = r.commit("Alice", start.Add(time.Duration(i)*time.Hour), map[string]string{"a.go": fmt.Sprint(i)})
+	}
+	a := &Analyzer{cfg: config.Defaults(), repo: r.repo}
+
+	for _, tt := range []struct {
+		max, every int
+		want       string
+	}{
+		{10, 1, "Mar 1 06h, Mar 1 05h, Mar 1 04h, Mar 1 03h, Mar 1 02h, Mar 1 01h, Mar 1 00h"},
+		{3, 1, "Mar 1 06h, Mar 1 05h, Mar 1 04h"},
+		{10, 3, "Mar 1 06h, Mar 1 03h, Mar 1 00h"},
+		{2, 3, "Mar 1 06h, Mar 1 03h"},
+	} {
+		commits, err := a.getCommits(tip.Hash, tt.max, tt.every)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := when(commits); got != tt.want {
+			t.Errorf("max %d, every %d: got %s, want %s", tt.max, tt.every, got, tt.want)
+		}
+	}
+}
+
+func TestCommitsPer(t *testing.T) {
+	r := newTestRepo(t)
+	var tip *object.Commit
+	for i, at := range []string{
+		"2026-03-02T09:00:00Z", // Monday
+		"2026-03-02T17:00:00Z",
+		"2026-03-04T10:00:00Z",
+		"2026-03-09T08:00:00Z", // the next Monday
+		"2026-03-09T12:00:00Z",
+		"2026-03-10T11:00:00Z",
+	} {
+		ts, _ := time.Parse(time.RFC3339, at)
+		tip = r.commit("Alice", ts, map[string]string{"a.go": fmt.Sprint(i)})
+	}
+	a := &Analyzer{cfg: config.Defaults(), repo: r.repo}
+	day := func(t time.Time) string { return t.Format(time.DateOnly) }
+	week := func(t time.Time) string {
+		year, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, w)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		max    int
+		period func(time.Time) string
+		want   string
+	}{
+		{"daily", 10, day, "Mar 10 11h, Mar 9 12h, Mar 4 10h, Mar 2 17h"},
+		{"daily, capped", 2, day, "Mar 10 11h, Mar 9 12h"},
+		{"weekly", 10, week, "Mar 10 11h, Mar 4 10h"},
+		{"weekly, capped", 1, week, "Mar 10 11h"},
+	} {
+		commits, err := a.commitsPer(tip.Hash, tt.max, tt.period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := when(commits); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTaggedCommits(t *testing.T) {
+	r := newTestRepo(t)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	first := r.commit("Alice", start, map[string]string{"a.go": "1"})
+	second := r.commit("Alice", start.Add(time.Hour), map[string]string{"a.go": "2"})
+	third := r.commit("Alice", start.Add(2*time.Hour), map[string]string{"a.go": "3"})
+	// A commit no longer on the branch, tagged before it was reset away.
+	stray := r.commit("Alice", start.Add(3*time.Hour), map[string]string{"a.go": "4"})
+	wt, _ := r.repo.Worktree()
+	if err := wt.Reset(&git.ResetOptions{Commit: third.Hash, Mode: git.HardReset}); err != nil {
+		t.Fatal(err)
+	}
+
+	tagger := &object.Signature{Name: "Alice", When: start}
+	tags := []struct {
+		name      string
+		commit    *object.Commit
+		annotated bool
+	}{
+		{"v1.0.0", first, false},
+		{"v1.1.0", second, true},
+		{"v1.1.0-final", second, false},
+		{"v2.0.0-rc", stray, true},
+	}
+	for _, tag := range tags {
+		var opts *git.CreateTagOptions
+		if tag.annotated {
+			opts = &git.CreateTagOptions{Tagger: tagger, Message: tag.name}
+		}
+		if _, err := r.repo.CreateTag(tag.name, tag.commit.Hash, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &Analyzer{cfg: config.Defaults(), repo: r.repo}
+
+	releases, err := a.releases(third.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rel := range releases {
+		got = append(got, rel.tag)
+	}
+	if want := "v1.1.0, v1.1.0-final | v1.0.0"; strings.Join(got, " | ") != want {
+		t.Errorf("releases = %q, want %q: annotated and lightweight tags, newest first, without the unreachable one", strings.Join(got, " | "), want)
+	}
+
+	commits, err := a.taggedCommits(third.Hash, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Hash != second.Hash {
+		t.Errorf("taggedCommits(max 1) = %s, want the newest tagged commit", when(commits))
+	}
+
+	// From the stray commit, its tag is reachable too.
+	if releases, _ := a.releases(stray.Hash); len(releases) != 3 || releases[0].tag != "v2.0.0-rc" {
+		t.Errorf("releases from the stray commit = %+v, want v2.0.0-rc first", releases)
+	}
+
+	ref, err := r.repo.Tag("v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := a.tagCommit(ref); err != nil || c.Hash != second.Hash {
+		t.Errorf("tagCommit(annotated v1.1.0) = %v, %v; want the tagged commit", c, err)
+	}
+	if _, err := a.tagCommit(plumbing.NewHashReference("refs/tags/missing", plumbing.NewHash("1234"))); err == nil {
+		t.Error("tagCommit of a missing object succeeded")
+	}
+}
+
+func TestTip_Branch(t *testing.T) {
+	r := newTestRepo(t)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	base := r.commit("Alice", start, map[string]string{"a.go": "1"})
+	r.commit("Alice", start.Add(time.Hour), map[string]string{"a.go": "2"})
+	r.repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/release", base.Hash))
+
+	cfg := config.Defaults()
+	cfg.History.Branch = "release"
+	a := &Analyzer{cfg: cfg, repo: r.repo}
+	if tip, err := a.tip(); err != nil || tip != base.Hash {
+		t.Errorf("tip() = %s, %v; want the release branch's commit", tip, err)
+	}
+	cfg.History.Branch = "nope"
+	if _, err := a.tip(); err == nil || !strings.Contains(err.Error(), `history.branch "nope"`) {
+		t.Errorf("unknown branch: err = %v", err)
+	}
+}
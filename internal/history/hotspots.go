@@ -0,0 +1,97 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+)
+
+// Hotspot is a file that is both complex and frequently changed — the code
+// where refactoring pays off most.
+type Hotspot struct {
+	Path       string // relative to the repository root
+	Commits    int    // commits touching the file in the analyzed window
+	Complexity int    // summed cyclomatic complexity of its functions
+	Lines      int
+	Score      float64 // 0-100, relative to the worst file in the repo
+}
+
+// Churn counts, per file path, how many of the last maxCommits commits
+// changed it. Each commit is compared with its first parent; the root commit
+// counts every file it adds.
+func (a *Analyzer) Churn(maxCommits int) (map[string]int, error) {
+	if maxCommits <= 0 {
+		maxCommits = 100
+	}
+
+	ref, err := a.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("getting HEAD: %w", err)
+	}
+
+	commits, err := a.getCommits(ref.Hash(), maxCommits, 1)
+	if err != nil {
+		return nil, fmt.Errorf("getting commits: %w", err)
+	}
+
+	churn := make(map[string]int)
+	for _, c := range commits {
+		tree, err := c.Tree()
+		if err != nil {
+			continue
+		}
+		var parentTree *object.Tree
+		if c.NumParents() > 0 {
+			if parent, err := c.Parent(0); err == nil {
+				parentTree, _ = parent.Tree()
+			}
+		}
+
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			continue
+		}
+		for _, ch := range changes {
+			name := ch.To.Name
+			if name == "" {
+				name = ch.From.Name // deleted file
+			}
+			churn[name]++
+		}
+	}
+	return churn, nil
+}
+
+// Hotspots ranks files by churn multiplied by complexity, each normalized to
+// the highest value in the repository. Files that never changed or contain
+// no functions are left out.
+func Hotspots(churn map[string]int, files []analyzer.FileMetrics) []Hotspot {
+	var spots []Hotspot
+	maxCommits, maxComplexity := 0, 0
+	for _, f := range files {
+		commits := churn[f.Path]
+		complexity := int(f.AvgComplexity*float64(f.Functions) + 0.5)
+		if commits == 0 || complexity == 0 {
+			continue
+		}
+		spots = append(spots, Hotspot{
+			Path:       f.Path,
+			Commits:    commits,
+			Complexity: complexity,
+			Lines:      f.Lines,
+		})
+		maxCommits = max(maxCommits, commits)
+		maxComplexity = max(maxComplexity, complexity)
+	}
+
+	for i := range spots {
+		s := &spots[i]
+		s.Score = float64(s.Commits) / float64(maxCommits) *
+			float64(s.Complexity) / float64(maxComplexity) * 100
+	}
+	sort.SliceStable(spots, func(i, j int) bool { return spots[i].Score > spots[j].Score })
+	return spots
+}
@@ -0,0 +1,245 @@
+package history
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// Owners stand-ins for issues that can't be attributed.
+const (
+	Unowned     = "(unowned)"     // no CODEOWNERS rule matches
+	Uncommitted = "(uncommitted)" // the file isn't in HEAD
+)
+
+// OwnerHealth is the issues attributed to one author or team.
+type OwnerHealth struct {
+	Owner    string
+	Findings []health.Finding
+	Rules    map[string]int // findings per rule, e.g. "complexity"
+	Errors   int            // findings at the "error" level
+	Files    int            // files with findings
+}
+
+// Attribute groups findings by the owner owner names for each, most
+// findings first.
+func Attribute(findings []health.Finding, owner func(health.Finding) string) []OwnerHealth {
+	byOwner := make(map[string]*OwnerHealth)
+	files := make(map[string]map[string]bool)
+	for _, f := range findings {
+		name := owner(f)
+		o, ok := byOwner[name]
+		if !ok {
+			o = &OwnerHealth{Owner: name, Rules: make(map[string]int)}
+			byOwner[name] = o
+			files[name] = make(map[string]bool)
+		}
+		o.Findings = append(o.Findings, f)
+		o.Rules[f.Rule]++
+		if f.Level == "error" {
+			o.Errors++
+		}
+		files[name][f.Path] = true
+	}
+
+	owners := make([]OwnerHealth, 0, len(byOwner))
+	for name, o := range byOwner {
+		o.Files = len(files[name])
+		owners = append(owners, *o)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		a, b := owners[i], owners[j]
+		if len(a.Findings) != len(b.Findings) {
+			return len(a.Findings) > len(b.Findings)
+		}
+		if a.Errors != b.Errors {
+			return a.Errors > b.Errors
+		}
+		return a.Owner < b.Owner
+	})
+	return owners
+}
+
+// Blamer attributes lines to the authors who last changed them as of HEAD,
+// blaming each file once.
+type Blamer struct {
+	head  *object.Commit
+	files map[string]*git.BlameResult
+}
+
+// Blamer returns a Blamer for HEAD.
+func (a *Analyzer) Blamer() (*Blamer, error) {
+	ref, err := a.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("getting HEAD: %w", err)
+	}
+	head, err := a.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return &Blamer{head: head, files: make(map[string]*git.BlameResult)}, nil
+}
+
+// Author names who most recently changed lines from through to of path,
+// counting from 1; the whole file when from is 0. It returns Uncommitted
+// for files HEAD doesn't have.
+func (b *Blamer) Author(path string, from, to int) string {
+	path = filepath.ToSlash(path)
+	blame, ok := b.files[path]
+	if !ok {
+		blame, _ = git.Blame(b.head, path)
+		b.files[path] = blame
+	}
+	if blame == nil || len(blame.Lines) == 0 {
+		return Uncommitted
+	}
+
+	if from <= 0 {
+		from, to = 1, len(blame.Lines)
+	}
+	from, to = max(from, 1), min(max(to, from), len(blame.Lines))
+	if from > len(blame.Lines) {
+		// The line was added since HEAD.
+		return Uncommitted
+	}
+	latest := blame.Lines[from-1]
+	for _, l := range blame.Lines[from:to] {
+		if l.Date.After(latest.Date) {
+			latest = l
+		}
+	}
+	if latest.AuthorName != "" {
+		return latest.AuthorName
+	}
+	return latest.Author
+}
+
+// codeOwnersFiles are where GitHub and GitLab look for a CODEOWNERS file,
+// in the order they look.
+var codeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// CodeOwners maps paths to the teams or people that own them, as a
+// CODEOWNERS file does: the last rule matching a path wins.
+type CodeOwners []codeOwnersRule
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// LoadCodeOwners reads the CODEOWNERS file at path, relative to root, or
+// the first in the usual locations when path is empty.
+func LoadCodeOwners(root, path string) (CodeOwners, error) {
+	candidates := codeOwnersFiles
+	if path != "" {
+		candidates = []string{path}
+	}
+	for _, name := range candidates {
+		f, err := os.Open(filepath.Join(root, name))
+		if errors.Is(err, os.ErrNotExist) && path == "" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		owners, err := ParseCodeOwners(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return owners, nil
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file in %s", strings.Join(codeOwnersFiles, ", "))
+}
+
+// ParseCodeOwners reads CODEOWNERS rules: a path pattern followed by its
+// owners on each line, with # comments. GitLab's [Section] headers are
+// skipped.
+func ParseCodeOwners(r io.Reader) (CodeOwners, error) {
+	var rules CodeOwners
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 && (i == 0 || text[i-1] != '\\') {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		pattern, err := codeOwnersPattern(strings.ReplaceAll(fields[0], `\#`, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rules = append(rules, codeOwnersRule{pattern, fields[1:]})
+	}
+	return rules, scanner.Err()
+}
+
+// Owners lists the owners of path, relative to the repository root; none
+// when no rule matches or the matching rule names no one.
+func (c CodeOwners) Owners(path string) []string {
+	path = filepath.ToSlash(path)
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].pattern.MatchString(path) {
+			return c[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPattern compiles a gitignore-style pattern. One with a slash
+// other than at the end is relative to the root; otherwise it matches at
+// any depth. A pattern matching a directory matches everything in it,
+// except that one ending in /* matches only the directory's own files.
+func codeOwnersPattern(p string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.Trim(p, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored && p != "*" {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**") && i+3 == len(p):
+			b.WriteString("/.*")
+			i += 2
+		case c == '*' && i+1 < len(p) && p[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*"):
+		// docs/* owns the files in docs but not in its subdirectories.
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// testRepo is an in-memory repository for tests to commit to.
+type testRepo struct {
+	t    *testing.T
+	repo *git.Repository
+	fs   billy.Filesystem
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	fs := memfs.New()
+	repo, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testRepo{t: t, repo: repo, fs: fs}
+}
+
+// commit removes the files whose content is "", then writes the others,
+// and commits them as author at when.
+func (r *testRepo) commit(author string, when time.Time, files map[string]string) *object.Commit {
+	r.t.Helper()
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	for name, content := range files {
+		if content == "" {
+			if _, err := wt.Remove(name); err != nil {
+				r.t.Fatal(err)
+			}
+			r.fs.Remove(path.Dir(name)) // if left empty, so a file may take its place
+		}
+	}
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		f, err := r.fs.Create(name)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		f.Write([]byte(content))
+		f.Close()
+		if _, err := wt.Add(name); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	sig := &object.Signature{Name: author, Email: strings.ToLower(author) + "@example.com", When: when}
+	hash, err := wt.Commit("change by "+author, &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return commit
+}
+
+func TestCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		miss    []string
+	}{
+		{"*", []string{"main.go", "a/b/c.go"}, nil},
+		{"*.js", []string{"app.js", "web/src/app.js"}, []string{"app.jsx", "app.ts"}},
+		{"/docs/", []string{"docs/a.md", "docs/guide/b.md"}, []string{"docs", "web/docs/a.md"}},
+		{"docs/", []string{"docs/a.md", "web/docs/a.md"}, []string{"docs.md"}},
+		{"docs/*", []string{"docs/a.md"}, []string{"docs/guide/b.md", "web/docs/a.md"}},
+		{"docs/**", []string{"docs/a.md", "docs/guide/b.md"}, []string{"web/docs/a.md"}},
+		{"**/build", []string{"build", "build/out.js", "web/build/out.js"}, []string{"builder/x.js"}},
+		{"/build", []string{"build/out.js"}, []string{"web/build/out.js"}},
+		{"apps/", []string{"apps/a.go", "x/apps/b.go"}, []string{"myapps/c.go"}},
+		{"internal/*.go", []string{"internal/a.go"}, []string{"internal/x/a.go", "a/internal/a.go"}},
+		{"src/**/test", []string{"src/test/a.go", "src/x/y/test/a.go"}, []string{"test/a.go"}},
+		{"file?.txt", []string{"file1.txt"}, []string{"file10.txt", "file/.txt"}},
+	}
+	for _, tt := range tests {
+		re, err := codeOwnersPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.pattern, err)
+		}
+		for _, p := range tt.match {
+			if !re.MatchString(p) {
+				t.Errorf("%q doesn't match %s", tt.pattern, p)
+			}
+		}
+		for _, p := range tt.miss {
+			if re.MatchString(p) {
+				t.Errorf("%q matches %s", tt.pattern, p)
+			}
+		}
+	}
+}
+
+func TestParseCodeOwners(t *testing.T) {
+	owners, err := ParseCodeOwners(strings.NewReader(`# Default owners
+*           @org/everyone
+
+[Frontend]
+web/        @org/web     # the whole app
+web/*.md
+
+^[Optional] @org/docs
+docs/\#notes.md  @alice
+*.go        @org/go
+/cmd/       @bob @carol
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"README.md", "@org/everyone"},
+		{"web/app.ts", "@org/web"},
+		{"web/README.md", ""}, // a later rule without owners
+		{"web/main.go", "@org/go"},
+		{"docs/#notes.md", "@alice"},
+		{"cmd/drift/main.go", "@bob @carol"},
+		{"internal/cmd/x.go", "@org/go"},
+	} {
+		if got := strings.Join(owners.Owners(tt.path), " "); got != tt.want {
+			t.Errorf("Owners(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBlamer_Author(t *testing.T) {
+	r := newTestRepo(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.commit("Alice", start, map[string]string{"a.go": "one\ntwo\nthree\n"})
+	head := r.commit("Bob", start.Add(time.Hour), map[string]string{"a.go": "one\ntwo\nTHREE\n"})
+	b := &Blamer{head: head, files: make(map[string]*git.BlameResult)}
+
+	for _, tt := range []struct {
+		path     string
+		from, to int
+		want     string
+	}{
+		{"a.go", 1, 2, "Alice"},
+		{"a.go", 2, 3, "Bob"},
+		{"a.go", 0, 0, "Bob"},         // the whole file
+		{"a.go", 1, 0, "Alice"},       // to before from is just from
+		{"a.go", 3, 10, "Bob"},        // clamped to the end
+		{"a.go", 4, 6, Uncommitted},   // added since HEAD
+		{"new.go", 1, 1, Uncommitted}, // not in HEAD
+	} {
+		if got := b.Author(tt.path, tt.from, tt.to); got != tt.want {
+			t.Errorf("Author(%s, %d, %d) = %q, want %q", tt.path, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	findings := []health.Finding{
+		{Rule: "complexity", Level: "warning", Path: "a.go"},
+		{Rule: "boundary", Level: "error", Path: "b.go"},
+		{Rule: "complexity", Level: "warning", Path: "c.go"},
+		{Rule: "dead-code", Level: "note", Path: "c.go"},
+	}
+	owner := map[string]string{"a.go": "@x", "b.go": "@y", "c.go": "@z"}
+	owners := Attribute(findings, func(f health.Finding) string { return owner[f.Path] })
+
+	var got []string
+	for _, o := range owners {
+		got = append(got, o.Owner)
+	}
+	// Most findings first, then most errors, then by name.
+	if strings.Join(got, " ") != "@z @y @x" {
+		t.Fatalf("owners = %v, want @z @y @x", got)
+	}
+	if z := owners[0]; z.Files != 1 || z.Rules["complexity"] != 1 || z.Rules["dead-code"] != 1 || z.Errors != 0 {
+		t.Errorf("@z = %+v", z)
+	}
+	if owners[1].Errors != 1 {
+		t.Errorf("@y errors = %d, want 1", owners[1].Errors)
+	}
+}
@@ -0,0 +1,148 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// StoreFile is where every report, check, and snapshot run is recorded,
+// relative to the analysis root: one JSON object per line, oldest first.
+// Appending a line is cheap and never rewrites earlier runs. Like the state
+// file, it is local to the checkout and not meant to be committed.
+const StoreFile = ".drift/history.jsonl"
+
+// Record is one recorded run.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"` // "report", "check", or "snapshot"
+	Commit  string    `json:"commit,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Tag     string    `json:"tag,omitempty"` // set for releases; see Analyzer.Releases
+	// Language names the analyzed languages, as Results.LanguageLabel does.
+	Language string `json:"language,omitempty"`
+
+	// Score holds the total and category scores keyed as in
+	// `drift snapshot`.
+	Score map[string]float64 `json:"score"`
+	// Counts holds the number of each kind of issue, e.g. "violations".
+	Counts        map[string]int `json:"counts"`
+	AvgComplexity float64        `json:"avg_complexity"`
+}
+
+// NewRecord summarizes a run of command that scored results, judging
+// functions against t.
+func NewRecord(command string, score health.Score, results *analyzer.Results, t config.ThresholdConfig) Record {
+	complex := 0
+	for _, fc := range results.Complexity {
+		if fc.Complexity > t.For(fc.Path).MaxComplexity {
+			complex++
+		}
+	}
+	return Record{
+		Time:     time.Now().UTC(),
+		Command:  command,
+		Language: results.LanguageLabel(),
+		Score:    score.Categories(),
+		Counts: map[string]int{
+			"files":              results.FileCount,
+			"functions":          results.FuncCount,
+			"complex_functions":  complex,
+			"violations":         len(results.Violations),
+			"cycles":             len(results.Cycles),
+			"dead_code":          len(results.DeadCode),
+			"duplicates":         len(results.Duplicates),
+			"debt_markers":       len(results.Debt),
+			"naming_issues":      len(results.Naming),
+			"vulnerabilities":    len(results.Vulnerabilities),
+			"license_violations": len(results.LicenseViolations),
+			"deps":               len(results.Dependencies),
+		},
+		AvgComplexity: avgComplexity(results.Complexity),
+	}
+}
+
+// Append records rec under root.
+func Append(root string, rec Record) error {
+	return appendLine(filepath.Join(root, StoreFile), rec)
+}
+
+// Load reads the runs recorded under root, oldest first. A run's ID is its
+// position in the list, counting from 1. It returns no records and no error
+// when nothing has been recorded.
+func Load(root string) ([]Record, error) {
+	return loadLines[Record](root, StoreFile)
+}
+
+// appendLine appends v to the JSON Lines file at path, on a line of its
+// own even if the last one was cut short.
+func appendLine(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadLines reads the JSON Lines file under root, or nothing if it doesn't
+// exist. Lines that don't parse, such as one cut short by a run killed
+// while appending it, are skipped rather than making the file unreadable.
+func loadLines[T any](root, file string) ([]T, error) {
+	f, err := os.Open(filepath.Join(root, file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []T
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var item T
+		if json.Unmarshal(scanner.Bytes(), &item) != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// Sparklines charts the last n records.
+func Sparklines(records []Record, n int) *SparklineData {
+	if len(records) > n {
+		records = records[len(records)-n:]
+	}
+	data := &SparklineData{}
+	for _, rec := range records {
+		data.HealthScore = append(data.HealthScore, rec.Score["total"])
+		data.AvgComplexity = append(data.AvgComplexity, rec.AvgComplexity)
+		data.ViolationCount = append(data.ViolationCount, float64(rec.Counts["violations"]))
+		data.DeadCodeCount = append(data.DeadCodeCount, float64(rec.Counts["dead_code"]))
+	}
+	return data
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func TestNewRecord_Areas(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "legacy"), 0o755)
+	os.WriteFile(filepath.Join(root, ".drift.yaml"), []byte("thresholds:\n  max_complexity: 10\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "legacy", ".drift.yaml"), []byte("thresholds:\n  max_complexity: 30\n"), 0o644)
+	cfg, err := config.Load(filepath.Join(root, ".drift.yaml"), config.Override{Key: "root", Value: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{
+		{Path: "api/h.go", Complexity: 20},
+		{Path: "legacy/old.go", Complexity: 20},
+		{Path: "legacy/worse.go", Complexity: 40},
+	}}
+	rec := NewRecord("report", health.Score{}, results, cfg.Thresholds)
+	if got := rec.Counts["complex_functions"]; got != 2 {
+		t.Errorf("complex_functions = %d, want 2: legacy/old.go is within its directory's limit", got)
+	}
+}
+
+func TestAppendLoad(t *testing.T) {
+	root := t.TempDir()
+	if records, err := Load(root); err != nil || records != nil {
+		t.Fatalf("Load before any run = %v, %v; want nothing", records, err)
+	}
+
+	first := Record{
+		Time:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Command:  "report",
+		Commit:   "abc1234",
+		Language: "go",
+		Score:    map[string]float64{"total": 81.5},
+		Counts:   map[string]int{"violations": 2},
+	}
+	second := first
+	second.Command, second.Score = "check", map[string]float64{"total": 79}
+	for _, rec := range []Record{first, second} {
+		if err := Append(root, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	got := records[0]
+	if !got.Time.Equal(first.Time) || got.Command != "report" || got.Commit != "abc1234" || got.Language != "go" ||
+		got.Score["total"] != 81.5 || got.Counts["violations"] != 2 {
+		t.Errorf("first record = %+v, want %+v", got, first)
+	}
+	if records[1].Command != "check" || records[1].Score["total"] != 79 {
+		t.Errorf("second record = %+v, want the check run, oldest first", records[1])
+	}
+}
+
+func TestLoad_TruncatedLine(t *testing.T) {
+	root := t.TempDir()
+	Append(root, Record{Command: "report", Score: map[string]float64{"total": 80}})
+	Append(root, Record{Command: "check", Score: map[string]float64{"total": 75}})
+
+	// A run killed while appending leaves part of a line behind.
+	f, err := os.OpenFile(filepath.Join(root, StoreFile), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-03-01T12:00:00Z","command":"rep`)
+	f.Close()
+
+	records, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load with a truncated last line: %v", err)
+	}
+	if len(records) != 2 || records[1].Command != "check" {
+		t.Errorf("records = %+v, want the two complete runs", records)
+	}
+
+	// Runs appended after it still load.
+	Append(root, Record{Command: "snapshot"})
+	if records, _ := Load(root); len(records) != 3 || records[2].Command != "snapshot" {
+		t.Errorf("records after another run = %+v, want it third", records)
+	}
+}
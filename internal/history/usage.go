@@ -0,0 +1,78 @@
+package history
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// UsageFile is where every AI call is recorded with the tokens it used,
+// relative to the analysis root, one JSON object per line like StoreFile.
+const UsageFile = ".drift/ai-usage.jsonl"
+
+// Call is one recorded AI call.
+type Call struct {
+	Time         time.Time `json:"time"`
+	Command      string    `json:"command"` // "diagnose", "fix", or "dashboard"
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model,omitempty"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	// Estimated is set when drift counted the tokens because the provider
+	// doesn't report them.
+	Estimated bool    `json:"estimated,omitempty"`
+	Cost      float64 `json:"cost"`   // US dollars at the price when it was made
+	Priced    bool    `json:"priced"` // false when the model's price was unknown
+}
+
+// ModelLabel is "provider/model", or just the provider when it picked the
+// model.
+func (c Call) ModelLabel() string {
+	if c.Model == "" {
+		return c.Provider
+	}
+	return c.Provider + "/" + c.Model
+}
+
+// AppendCall records c under root.
+func AppendCall(root string, c Call) error {
+	return appendLine(filepath.Join(root, UsageFile), c)
+}
+
+// LoadCalls reads the AI calls recorded under root, oldest first.
+func LoadCalls(root string) ([]Call, error) {
+	return loadLines[Call](root, UsageFile)
+}
+
+// Usage totals AI calls.
+type Usage struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	Unpriced     int     `json:"unpriced"` // calls whose cost is unknown and left out
+}
+
+// Add counts c.
+func (u *Usage) Add(c Call) {
+	u.Calls++
+	u.InputTokens += c.InputTokens
+	u.OutputTokens += c.OutputTokens
+	if c.Priced {
+		u.Cost += c.Cost
+	} else {
+		u.Unpriced++
+	}
+}
+
+// UsageBy totals calls by key, such as the command or model.
+func UsageBy(calls []Call, key func(Call) string) map[string]*Usage {
+	by := map[string]*Usage{}
+	for _, c := range calls {
+		k := key(c)
+		if by[k] == nil {
+			by[k] = &Usage{}
+		}
+		by[k].Add(c)
+	}
+	return by
+}
@@ -0,0 +1,129 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Head describes the checked-out commit.
+type Head struct {
+	Branch  string // empty when HEAD is detached
+	Hash    string // abbreviated
+	Subject string // first line of the commit message
+}
+
+// Head reads where HEAD points. It only reads refs and one commit, so it is
+// cheap enough to poll.
+func (a *Analyzer) Head() (Head, error) {
+	ref, err := a.repo.Head()
+	if err != nil {
+		return Head{}, fmt.Errorf("getting HEAD: %w", err)
+	}
+	h := Head{Hash: ref.Hash().String()[:7]}
+	if ref.Name().IsBranch() {
+		h.Branch = ref.Name().Short()
+	}
+	if c, err := a.repo.CommitObject(ref.Hash()); err == nil {
+		h.Subject, _, _ = strings.Cut(strings.TrimSpace(c.Message), "\n")
+	}
+	return h, nil
+}
+
+// Dirty counts the files that differ from HEAD, staged or not, including
+// untracked files that aren't ignored. It hashes the working tree, so it is
+// slow on large repositories.
+func (a *Analyzer) Dirty() (int, error) {
+	wt, err := a.repo.Worktree()
+	if err != nil {
+		return 0, fmt.Errorf("opening worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return 0, fmt.Errorf("getting status: %w", err)
+	}
+	n := 0
+	for _, s := range status {
+		if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ChangedFiles lists the files that differ from base, a revision such as
+// "main": those changed by commits since HEAD branched off base, and those
+// changed in the working tree but not committed. Deleted files are left
+// out. When base doesn't resolve, "origin/"+base is tried, as CI checkouts
+// often only have the remote branch.
+func (a *Analyzer) ChangedFiles(base string) ([]string, error) {
+	hash, err := a.repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		var remoteErr error
+		if hash, remoteErr = a.repo.ResolveRevision(plumbing.Revision("origin/" + base)); remoteErr != nil {
+			return nil, fmt.Errorf("resolving %s: %w", base, err)
+		}
+	}
+	baseCommit, err := a.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", base, err)
+	}
+	ref, err := a.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("getting HEAD: %w", err)
+	}
+	headCommit, err := a.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD: %w", err)
+	}
+	if bases, err := headCommit.MergeBase(baseCommit); err == nil && len(bases) > 0 {
+		baseCommit = bases[0]
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("getting tree: %w", err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("getting tree: %w", err)
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("diffing %s: %w", base, err)
+	}
+	changed := make(map[string]bool)
+	for _, ch := range changes {
+		if ch.To.Name != "" {
+			changed[ch.To.Name] = true
+		}
+	}
+
+	wt, err := a.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("getting status: %w", err)
+	}
+	for path, s := range status {
+		switch {
+		case s.Worktree == git.Deleted || (s.Staging == git.Deleted && s.Worktree == git.Unmodified):
+			delete(changed, path)
+		case s.Staging != git.Unmodified || s.Worktree != git.Unmodified:
+			changed[path] = true
+		}
+	}
+
+	files := make([]string, 0, len(changed))
+	for path := range changed {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,366 @@
+// Package mcp serves drift's analysis to AI agents over the Model Context
+// Protocol: JSON-RPC messages, one per line, on stdin and stdout, with tools
+// for the health score, the issues, and a function's source.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/ai"
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/watcher"
+)
+
+// protocolVersions are the protocol revisions served, latest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// defaultIssues is how many issues list_issues returns without a limit.
+const defaultIssues = 50
+
+// maxSourceLines caps the source get_function_source returns.
+const maxSourceLines = 400
+
+// Server holds the latest analysis and answers tool calls about it.
+type Server struct {
+	cfg      *config.Config
+	ana      *analyzer.Analyzer
+	scorer   *health.Scorer
+	baseline *analyzer.Baseline
+	version  string
+
+	mu  sync.Mutex
+	raw *analyzer.Results // before the baseline is applied
+}
+
+// New creates a server for results, leaving out the issues baseline
+// grandfathers; baseline may be nil. version is reported to clients.
+func New(cfg *config.Config, ana *analyzer.Analyzer, scorer *health.Scorer, baseline *analyzer.Baseline, results *analyzer.Results, version string) *Server {
+	return &Server{cfg: cfg, ana: ana, scorer: scorer, baseline: baseline, version: version, raw: results}
+}
+
+// Watch keeps the results up to date with the changes w reports, and
+// re-analyzes everything every refresh if that is positive, so tools answer
+// about the code as it is now. It returns when w closes; w may be nil.
+func (s *Server) Watch(w *watcher.Watcher, refresh time.Duration) {
+	s.mu.Lock()
+	raw := s.raw
+	s.mu.Unlock()
+	s.ana.Follow(raw, w, refresh, func(results *analyzer.Results, _ watcher.Batch) {
+		s.mu.Lock()
+		s.raw = results
+		s.mu.Unlock()
+	})
+}
+
+// results is the latest analysis with the baseline applied.
+func (s *Server) results() *analyzer.Results {
+	s.mu.Lock()
+	raw := s.raw
+	s.mu.Unlock()
+	if s.baseline != nil {
+		return s.baseline.Apply(raw, s.cfg.Thresholds)
+	}
+	return raw
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve answers the requests read from in on out until in ends.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := enc.Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			continue // notifications get no response
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if rerr == nil && result == nil {
+			resp.Result = struct{}{}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one request.
+func (s *Server) handle(req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := protocolVersions[0]
+		if slices.Contains(protocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "drift", "version": s.version},
+			"instructions":    "drift measures the health of the codebase in " + filepath.Base(s.cfg.Root) + ". Ask for the score and issues, then a function's source, to plan refactors; results follow edits as files are saved.",
+		}, nil
+	case "ping", "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		call, ok := s.toolFuncs()[params.Name]
+		if !ok {
+			return nil, &rpcError{codeInvalidParams, "unknown tool " + params.Name}
+		}
+		if len(params.Arguments) == 0 {
+			params.Arguments = json.RawMessage("{}")
+		}
+		text, err := call(params.Arguments)
+		if err != nil {
+			// Tool failures go back to the agent, which can correct itself.
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+}
+
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// tool describes a tool to clients.
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+func object(properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+var tools = []tool{
+	{
+		Name:        "get_health_score",
+		Description: "The codebase's health score out of 100, its letter grade, and each category's score, with how many files and functions were analyzed.",
+		InputSchema: object(map[string]interface{}{}),
+	},
+	{
+		Name:        "list_issues",
+		Description: "The issues behind the score: functions over the complexity, length, parameter, or nesting limits, boundary violations, dead code, and vulnerable dependencies, each with its file and line.",
+		InputSchema: object(map[string]interface{}{
+			"rule":  map[string]interface{}{"type": "string", "enum": []string{"complexity", "boundary", "dead-code", "vulnerability"}, "description": "only issues of this kind"},
+			"path":  map[string]interface{}{"type": "string", "description": "only issues in this file or under this directory"},
+			"limit": map[string]interface{}{"type": "integer", "description": fmt.Sprintf("most issues to return (default %d)", defaultIssues)},
+		}),
+	},
+	{
+		Name:        "get_function_source",
+		Description: "A function's source with its complexity, length, parameters, and nesting and the project's limits for each. Name it, as Name or Type.Method, or give the path and a line inside it.",
+		InputSchema: object(map[string]interface{}{
+			"name": map[string]interface{}{"type": "string", "description": "function name, e.g. Update or model.Update"},
+			"path": map[string]interface{}{"type": "string", "description": "file relative to the root, to pick among functions of the same name or with line"},
+			"line": map[string]interface{}{"type": "integer", "description": "a line inside the function, with path"},
+		}),
+	},
+}
+
+// toolFuncs map tool names to what answers them, given the call's
+// arguments.
+func (s *Server) toolFuncs() map[string]func(json.RawMessage) (string, error) {
+	return map[string]func(json.RawMessage) (string, error){
+		"get_health_score":    s.healthScore,
+		"list_issues":         s.listIssues,
+		"get_function_source": s.functionSource,
+	}
+}
+
+func (s *Server) healthScore(json.RawMessage) (string, error) {
+	results := s.results()
+	score := s.scorer.Calculate(results)
+	return marshal(map[string]interface{}{
+		"score":     score.Total,
+		"grade":     score.Grade(),
+		"language":  results.LanguageLabel(),
+		"scores":    score.Categories(),
+		"files":     results.FileCount,
+		"functions": results.FuncCount,
+		"baselined": results.Baselined,
+	})
+}
+
+func (s *Server) listIssues(args json.RawMessage) (string, error) {
+	var a struct {
+		Rule  string `json:"rule"`
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", err
+	}
+	if a.Limit <= 0 {
+		a.Limit = defaultIssues
+	}
+	dir := strings.TrimSuffix(filepath.ToSlash(a.Path), "/")
+
+	type issue struct {
+		Rule    string `json:"rule"`
+		Level   string `json:"level"`
+		Path    string `json:"path"`
+		Line    int    `json:"line,omitempty"`
+		Message string `json:"message"`
+		// Language is set in multi-language mode.
+		Language analyzer.Language `json:"language,omitempty"`
+	}
+	issues := []issue{}
+	total := 0
+	for _, f := range health.Findings(s.cfg, s.results()) {
+		if a.Rule != "" && f.Rule != a.Rule || dir != "" && f.Path != dir && !strings.HasPrefix(f.Path, dir+"/") {
+			continue
+		}
+		total++
+		if len(issues) < a.Limit {
+			issues = append(issues, issue{f.Rule, f.Level, f.Path, f.Line, f.Message, f.Language})
+		}
+	}
+	return marshal(map[string]interface{}{"total": total, "issues": issues})
+}
+
+func (s *Server) functionSource(args json.RawMessage) (string, error) {
+	var a struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+		Line int    `json:"line"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", err
+	}
+	fc, err := s.results().FindFunction(a.Name, filepath.ToSlash(a.Path), a.Line, "pick one with path")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	t := s.cfg.Thresholds.For(fc.Path)
+	fmt.Fprintf(&b, "%s() in %s:%d\n", fc.Name, fc.Path, fc.Line)
+	for _, m := range []struct {
+		name         string
+		value, limit int
+	}{
+		{"cyclomatic complexity", fc.Complexity, t.MaxComplexity},
+		{"lines", fc.Lines, t.MaxFuncLines},
+		{"parameters", fc.Params, t.MaxParams},
+		{"nesting depth", fc.Nesting, t.MaxNesting},
+	} {
+		fmt.Fprintf(&b, "%s: %d", m.name, m.value)
+		if m.limit > 0 {
+			fmt.Fprintf(&b, " (limit %d)", m.limit)
+		}
+		b.WriteString("\n")
+	}
+
+	if s.cfg.AI.Redact.MetricsOnly {
+		b.WriteString("\n(source withheld: ai.redact.metrics_only is set)\n")
+		return s.masked(b.String()), nil
+	}
+	n := min(max(fc.Lines, 1), maxSourceLines)
+	src, err := readLines(filepath.Join(s.cfg.Root, fc.Path), fc.Line, n)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&b, "\n```%s\n%s\n```\n", fc.Language, ai.RedactCode(s.cfg.AI.Redact, fc.Path, src))
+	if fc.Lines > n {
+		fmt.Fprintf(&b, "(only the first %d of its %d lines are shown)\n", n, fc.Lines)
+	}
+	return s.masked(b.String()), nil
+}
+
+// masked hides the source file paths in text if ai.redact.paths is set, as
+// prompts sent to a provider do.
+func (s *Server) masked(text string) string {
+	if s.cfg.AI.Redact.Paths {
+		return ai.MaskPaths(text)
+	}
+	return text
+}
+
+// readLines reads n lines of the file at path from line start.
+func readLines(path string, start, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for i := 1; scanner.Scan() && len(lines) < n; i++ {
+		if i >= start {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	return strings.Join(lines, "\n"), scanner.Err()
+}
+
+func marshal(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	return string(data), err
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	src := "package a\n\nfunc Tangled(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	if err := os.WriteFile(filepath.Join(cfg.Root, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results := &analyzer.Results{
+		FileCount: 1,
+		FuncCount: 2,
+		Complexity: []analyzer.FunctionComplexity{
+			{File: "a.go", Path: "a.go", Name: "Tangled", Line: 3, Complexity: 40, Lines: 6, Params: 1, Language: analyzer.LangGo},
+			{File: "b.go", Path: "b/b.go", Name: "T.Tangled", Line: 10, Complexity: 2, Lines: 3, Language: analyzer.LangGo},
+		},
+	}
+	return New(cfg, analyzer.New(cfg), health.NewScorer(cfg), nil, results, "test")
+}
+
+type reply struct {
+	ID     int `json:"id"`
+	Result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Tools           []tool `json:"tools"`
+		Content         []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	} `json:"result"`
+	Error *rpcError `json:"error"`
+}
+
+// serve sends requests, one per line, and decodes the replies.
+func serve(t *testing.T, s *Server, requests ...string) []reply {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+	var replies []reply
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for dec.More() {
+		var r reply
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, r)
+	}
+	return replies
+}
+
+func TestServer_Handshake(t *testing.T) {
+	replies := serve(t, newServer(t),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(replies) != 4 {
+		t.Fatalf("got %d replies, want 4 (none for the notification)", len(replies))
+	}
+	if replies[0].Result.ProtocolVersion != "2025-03-26" {
+		t.Errorf("protocolVersion = %q", replies[0].Result.ProtocolVersion)
+	}
+	if len(replies[1].Result.Tools) != 3 {
+		t.Errorf("tools = %+v", replies[1].Result.Tools)
+	}
+	if replies[2].Error == nil || replies[2].Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method error = %+v", replies[2].Error)
+	}
+	if replies[3].Error == nil || replies[3].Error.Code != codeParseError {
+		t.Errorf("parse error = %+v", replies[3].Error)
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	replies := serve(t, newServer(t),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_health_score"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_issues","arguments":{"rule":"complexity","path":"a.go"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_function_source","arguments":{"path":"a.go","line":5}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_function_source","arguments":{"name":"Tangled"}}}`,
+	)
+	text := func(i int) string { return replies[i].Result.Content[0].Text }
+
+	var score struct {
+		Score float64 `json:"score"`
+		Files int     `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(text(0)), &score); err != nil || score.Files != 1 || score.Score <= 0 {
+		t.Errorf("get_health_score = %s", text(0))
+	}
+	if !strings.Contains(text(1), `"total": 1`) || !strings.Contains(text(1), "Tangled has cyclomatic complexity 40") {
+		t.Errorf("list_issues = %s", text(1))
+	}
+	for _, want := range []string{"Tangled() in a.go:3", "cyclomatic complexity: 40 (limit 15)", "func Tangled(x int) int {", "return 0\n}"} {
+		if !strings.Contains(text(2), want) {
+			t.Errorf("get_function_source missing %q:\n%s", want, text(2))
+		}
+	}
+	if !replies[3].Result.IsError || !strings.Contains(text(3), "2 functions match") {
+		t.Errorf("ambiguous name = %+v", replies[3].Result)
+	}
+}
+
+func TestServer_FunctionSourceRedacted(t *testing.T) {
+	s := newServer(t)
+	s.cfg.AI.Redact.Comments = true
+	s.cfg.AI.Redact.Paths = true
+	src := "package a\n\nfunc Tangled(x int) int {\n\t// secret plan\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	if err := os.WriteFile(filepath.Join(s.cfg.Root, "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replies := serve(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_function_source","arguments":{"path":"a.go","line":3}}}`)
+	text := replies[0].Result.Content[0].Text
+	if strings.Contains(text, "secret plan") || strings.Contains(text, "a.go") {
+		t.Errorf("comment or path sent despite ai.redact:\n%s", text)
+	}
+	if !strings.Contains(text, "Tangled() in file1.go:3") || !strings.Contains(text, "if x > 0 {") {
+		t.Errorf("redacted source lost the code:\n%s", text)
+	}
+}
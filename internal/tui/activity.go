@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// change is one difference a file's re-analysis made, for the activity feed.
+type change struct {
+	text string
+	mood int // 1 for better, -1 for worse, 0 for neither
+}
+
+// fileChanges compares the file analyzed in single, a RunSingle result, with
+// what before held for it: the complexity of its functions, the thresholds
+// they newly exceed, and its debt markers, worst first.
+func fileChanges(before, single *analyzer.Results, t config.ThresholdConfig) []change {
+	if len(single.Files) != 1 {
+		return nil
+	}
+	path := single.Files[0].Path
+	if len(single.Notices) > 0 {
+		return []change{{text: "doesn't parse", mood: -1}}
+	}
+
+	old := make(map[string]analyzer.FunctionComplexity)
+	for _, fc := range before.Complexity {
+		if fc.Path == path {
+			old[fc.Name] = fc
+		}
+	}
+
+	var out []change
+	for _, fc := range single.Complexity {
+		prev, existed := old[fc.Name]
+		delete(old, fc.Name)
+		switch {
+		case !existed:
+			out = append(out, change{text: fmt.Sprintf("+%s %d", fc.Name, fc.Complexity)})
+		case fc.Complexity > prev.Complexity:
+			out = append(out, change{text: fmt.Sprintf("%s %d→%d", fc.Name, prev.Complexity, fc.Complexity), mood: -1})
+		case fc.Complexity < prev.Complexity:
+			out = append(out, change{text: fmt.Sprintf("%s %d→%d", fc.Name, prev.Complexity, fc.Complexity), mood: 1})
+		}
+		for _, limit := range exceededLimits(fc, t) {
+			if !existed || !slices.Contains(exceededLimits(prev, t), limit) {
+				out = append(out, change{text: fc.Name + " over " + limit, mood: -1})
+			}
+		}
+	}
+
+	removed := make([]string, 0, len(old))
+	for name := range old {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		out = append(out, change{text: "−" + name})
+	}
+
+	debt := len(single.Debt)
+	for _, d := range before.Debt {
+		if d.Path == path {
+			debt--
+		}
+	}
+	if debt > 0 {
+		out = append(out, change{text: fmt.Sprintf("debt markers +%d", debt), mood: -1})
+	} else if debt < 0 {
+		out = append(out, change{text: fmt.Sprintf("debt markers −%d", -debt), mood: 1})
+	}
+	// Worse first, so they are what's left when the feed runs out of room.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].mood < out[j].mood })
+	return out
+}
+
+// exceededLimits names the function thresholds fc is over, e.g.
+// "complexity 15".
+func exceededLimits(fc analyzer.FunctionComplexity, t config.ThresholdConfig) []string {
+	t = t.For(fc.Path)
+	limits := []struct {
+		name         string
+		value, limit int
+	}{
+		{"complexity", fc.Complexity, t.MaxComplexity},
+		{"length", fc.Lines, t.MaxFuncLines},
+		{"params", fc.Params, t.MaxParams},
+		{"nesting", fc.Nesting, t.MaxNesting},
+	}
+	var out []string
+	for _, l := range limits {
+		if l.limit > 0 && l.value > l.limit {
+			out = append(out, fmt.Sprintf("%s %d", l.name, l.limit))
+		}
+	}
+	return out
+}
+
+// renderChanges lists changes in color, worse in red and better in green,
+// leaving out those that don't fit in width.
+func renderChanges(changes []change, width int) string {
+	if len(changes) == 0 {
+		return lipgloss.NewStyle().Foreground(colorDim).Render("no change")
+	}
+	var out string
+	for i, c := range changes {
+		style := lipgloss.NewStyle().Foreground(colorText)
+		if c.mood > 0 {
+			style = lipgloss.NewStyle().Foreground(colorGreen)
+		} else if c.mood < 0 {
+			style = lipgloss.NewStyle().Foreground(colorRed)
+		}
+		next := style.Render(c.text)
+		if i > 0 {
+			next = out + ", " + next
+		}
+		if width > 0 && lipgloss.Width(next) > width-2 {
+			return out + lipgloss.NewStyle().Foreground(colorDim).Render(" …")
+		}
+		out = next
+	}
+	return out
+}
+
+// changesText lists changes without styling.
+func changesText(changes []change) string {
+	texts := make([]string, len(changes))
+	for i, c := range changes {
+		texts[i] = c.text
+	}
+	return strings.Join(texts, ", ")
+}
@@ -2,14 +2,22 @@
 package tui
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -29,12 +37,21 @@ const (
 	panelDeps
 	panelBoundaries
 	panelActivity
+	panelFiles
+	panelDuplication
+	panelSecurity
+	panelHotspots
 	panelCount
 )
 
 type activityEntry struct {
 	file      string
 	timestamp time.Time
+	// Once the file is re-analyzed, changes lists what that changed.
+	analyzed bool
+	changes  []change
+	removed  bool // the file or directory was deleted or renamed away
+	count    int  // files changed together, for a batch re-analyzed in full
 }
 
 type model struct {
@@ -44,14 +61,45 @@ type model struct {
 	score   health.Score
 	results *analyzer.Results
 	watch   *watcher.Watcher
+	// projectScores are the totals of results.Projects, in order.
+	projectScores []float64
 
-	width  int
-	height int
-	focus  focusPanel
+	// raw holds the results before the baseline grandfathers issues, so
+	// the baseline can be applied again when it grows.
+	raw      *analyzer.Results
+	baseline *analyzer.Baseline
+
+	width    int
+	height   int
+	focus    focusPanel
+	selected int    // row of the focused panel that item actions apply to
+	status   string // outcome of the last item action, shown in the footer
 
 	activity []activityEntry
 	spinner  spinner.Model
 
+	// paused stops re-analysis on file changes and timed refreshes; the
+	// files changed meanwhile wait in pending. refresh is the interval of
+	// full re-analysis when there is no watcher.
+	paused  bool
+	pending map[string]bool
+	refresh time.Duration
+
+	// Notices: errors from the watcher, kept here, and files the analysis
+	// left out, in the results. Each is logged to logFile once.
+	watchErrors []activityEntry
+	logged      map[string]bool
+	showNotices bool
+
+	// Git state for the header; head is nil outside a git repository.
+	head  *history.Head
+	dirty int
+
+	// A full analysis in flight reports its progress through progressCh.
+	analyzing  bool
+	progress   analyzer.Progress
+	progressCh chan analyzer.Progress
+
 	// Animation
 	displayScore float64
 	targetScore  float64
@@ -61,49 +109,251 @@ type model struct {
 	showDiagnosis bool
 	diagnosisText string
 	diagnosing    bool
+	cancelDiag    context.CancelFunc // aborts the diagnosis in flight
+	diagEstimate  ai.Estimate        // the size and cost of the diagnosis in flight
+	// diagnosisFrom notes where the diagnosis came from, above its
+	// markdown, rendered into diagnosisView.
+	diagnosisFrom string
+	diagnosisView viewport.Model
+
+	// Score breakdown
+	showExplain   bool
+	explainOffset int
+
+	// Comparison against a git ref, analyzed when first shown; compare is
+	// nil until then.
+	compareRef    string
+	showCompare   bool
+	comparing     bool
+	compare       *comparison
+	compareErr    error
+	compareOffset int
+
+	// Full list behind the focused panel
+	showDetail   bool
+	detailOffset int
+
+	// Search narrows the panels to entries matching filter, and "s" picks
+	// the order of sortable panels, as indexes into sortOrders; see shown.
+	searching bool
+	filter    string
+	sortOrder map[focusPanel]int
+	display   *analyzer.Results
 
 	// Sparkline history
 	sparklineData *history.SparklineData
+	churn         map[string]int
 
 	quitting bool
 }
 
 type fileChangedMsg struct {
-	path      string
-	timestamp time.Time
+	batch watcher.Batch
 }
 
 type analysisCompleteMsg struct {
 	results *analyzer.Results
-	score   health.Score
+	err     error
+}
+
+type progressMsg analyzer.Progress
+
+// fileAnalyzedMsg carries a single-file re-analysis to merge into the
+// current results.
+type fileAnalyzedMsg struct {
+	path   string // as the watcher reported it
+	single *analyzer.Results
 }
 
 type animateTickMsg struct{}
 
+type refreshTickMsg struct{}
+
+type gitTickMsg struct{}
+
+type gitHeadMsg struct {
+	head history.Head
+	err  error
+}
+
+type gitDirtyMsg struct {
+	dirty int
+}
+
+type watchErrorMsg struct {
+	err       error
+	timestamp time.Time
+}
+
 type diagnosisCompleteMsg struct {
-	text string
+	from string // e.g. "Powered by anthropic"
+	text string // markdown
+}
+
+type copiedMsg struct {
+	err error
+}
+
+type editorClosedMsg struct {
+	err error
 }
 
 type historyCompleteMsg struct {
-	data *history.SparklineData
+	data  *history.SparklineData
+	churn map[string]int
 }
 
-func New(cfg *config.Config, ana *analyzer.Analyzer, scorer *health.Scorer, score health.Score, results *analyzer.Results, w *watcher.Watcher) *model {
+// New creates the dashboard for results, leaving out the issues baseline
+// grandfathers; baseline may be nil.
+func New(cfg *config.Config, ana *analyzer.Analyzer, scorer *health.Scorer, baseline *analyzer.Baseline, results *analyzer.Results, w *watcher.Watcher) *model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(colorCyan)
 
-	return &model{
-		cfg:          cfg,
-		ana:          ana,
-		scorer:       scorer,
-		score:        score,
-		results:      results,
-		watch:        w,
-		displayScore: score.Total,
-		targetScore:  score.Total,
-		spinner:      s,
+	m := &model{
+		cfg:        cfg,
+		ana:        ana,
+		scorer:     scorer,
+		baseline:   baseline,
+		raw:        results,
+		watch:      w,
+		spinner:    s,
+		pending:    make(map[string]bool),
+		logged:     make(map[string]bool),
+		progressCh: make(chan analyzer.Progress, 1),
+		sortOrder:  make(map[focusPanel]int),
+		compareRef: defaultCompareRef,
+	}
+	if ana != nil {
+		ana.OnProgress(func(p analyzer.Progress) {
+			select {
+			case m.progressCh <- p:
+			default: // the last update hasn't been drawn yet; skip this one
+			}
+		})
+	}
+	if w == nil {
+		// Load has checked the interval.
+		m.refresh, _ = cfg.Watch.Interval()
+	}
+	m.rescore()
+	m.displayScore = m.score.Total
+	m.targetScore = m.score.Total
+	return m
+}
+
+// rescore applies the baseline to the raw results and scores what's left.
+func (m *model) rescore() {
+	m.results = m.raw
+	if m.baseline != nil {
+		m.results = m.baseline.Apply(m.raw, m.cfg.Thresholds)
+	}
+	m.score = m.scorer.Calculate(m.results)
+	m.projectScores = make([]float64, len(m.results.Projects))
+	for i, p := range m.results.Projects {
+		m.projectScores[i] = health.NewScorer(m.cfg).Calculate(p.Results).Total
+	}
+	m.applyView()
+	m.updateComparison()
+	for _, n := range m.raw.Notices {
+		m.logNotice(time.Now(), n.String())
+	}
+}
+
+// shown is what the panels list: the results, narrowed by the search
+// filter and sorted as chosen. Scores always cover everything.
+func (m *model) shown() *analyzer.Results {
+	return m.display
+}
+
+// applyView derives what the panels list from the results.
+func (m *model) applyView() {
+	r := *m.results
+	if m.filter != "" {
+		r = *filterResults(m.results, m.filter)
+	}
+	r.Complexity = sortedFunctions(r.Complexity, m.order(panelComplexity))
+	r.Dependencies = sortedDeps(r.Dependencies, m.order(panelDeps))
+	m.display = &r
+	m.clampSelection()
+}
+
+// sortOrders lists the orders "s" cycles through in each sortable panel,
+// the default first.
+var sortOrders = map[focusPanel][]string{
+	panelComplexity: {"complexity", "name", "file"},
+	panelDeps:       {"staleness", "name"},
+}
+
+// order is the chosen order of a sortable panel.
+func (m *model) order(panel focusPanel) string {
+	return sortOrders[panel][m.sortOrder[panel]]
+}
+
+// cycleSort switches the focused panel to its next order.
+func (m *model) cycleSort() {
+	orders, ok := sortOrders[m.focus]
+	if !ok {
+		m.status = "only complexity and dependencies can be sorted"
+		return
+	}
+	m.sortOrder[m.focus] = (m.sortOrder[m.focus] + 1) % len(orders)
+	m.selected = 0
+	m.status = "sorted by " + m.order(m.focus)
+	m.applyView()
+}
+
+// sortHint notes a sortable panel's order in its title, unless it is the
+// default.
+func (m *model) sortHint(panel focusPanel) string {
+	if m.sortOrder[panel] == 0 {
+		return ""
 	}
+	return lipgloss.NewStyle().Foreground(colorDim).Render("  by " + m.order(panel))
+}
+
+// sortedFunctions returns a copy of funcs in order: by complexity, most
+// complex first, by name, or by file and line.
+func sortedFunctions(funcs []analyzer.FunctionComplexity, order string) []analyzer.FunctionComplexity {
+	out := slices.Clone(funcs)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch order {
+		case "name":
+			return a.Name < b.Name
+		case "file":
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return a.Line < b.Line
+		}
+		return a.Complexity > b.Complexity
+	})
+	return out
+}
+
+// sortedDeps returns a copy of deps in order: by staleness, furthest
+// behind first, or by name.
+func sortedDeps(deps []analyzer.DepStatus, order string) []analyzer.DepStatus {
+	out := slices.Clone(deps)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if order == "staleness" && a.StaleDays != b.StaleDays {
+			return a.StaleDays > b.StaleDays
+		}
+		return a.Module < b.Module
+	})
+	return out
+}
+
+// clampSelection keeps the selection on one of the focused panel's rows.
+func (m *model) clampSelection() {
+	m.selected = min(m.selected, max(0, len(m.panelItems())-1))
+}
+
+// Score is the health score the dashboard last showed.
+func (m *model) Score() health.Score {
+	return m.score
 }
 
 func (m *model) Run() error {
@@ -116,10 +366,19 @@ func (m *model) Run() error {
 }
 
 func (m *model) Init() tea.Cmd {
+	var compare tea.Cmd
+	if m.showCompare {
+		compare = m.openCompare()
+	}
 	return tea.Batch(
+		compare,
 		m.spinner.Tick,
 		m.listenForChanges(),
+		m.listenForErrors(),
 		m.loadHistory(),
+		m.refreshTick(),
+		m.listenForProgress(),
+		m.checkHead(),
 		tea.WindowSize(),
 	)
 }
@@ -130,55 +389,275 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if m.showDiagnosis {
+			m.status = ""
 			switch msg.String() {
 			case "esc", "q":
 				m.showDiagnosis = false
+			case "c", "y":
+				return m, copyToClipboard(m.diagnosisText)
+			default:
+				var cmd tea.Cmd
+				m.diagnosisView, cmd = m.diagnosisView.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+		if m.showExplain {
+			switch msg.String() {
+			case "esc", "q", "e":
+				m.showExplain = false
+			default:
+				m.explainOffset = m.scroll(m.explainOffset, msg.String())
+			}
+			return m, nil
+		}
+		if m.showCompare {
+			switch msg.String() {
+			case "esc", "q", "c":
+				m.showCompare = false
+			default:
+				m.compareOffset = m.scroll(m.compareOffset, msg.String())
+			}
+			return m, nil
+		}
+		if m.searching {
+			switch msg.Type {
+			case tea.KeyEnter:
+				m.searching = false
+			case tea.KeyEsc:
+				m.searching = false
+				m.filter = ""
+			case tea.KeyBackspace:
+				if r := []rune(m.filter); len(r) > 0 {
+					m.filter = string(r[:len(r)-1])
+				}
+			case tea.KeySpace:
+				m.filter += " "
+			case tea.KeyRunes:
+				m.filter += string(msg.Runes)
+			}
+			m.selected = 0
+			m.applyView()
+			return m, nil
+		}
+		if m.showDetail {
+			switch msg.String() {
+			case "esc", "q", "enter":
+				m.showDetail = false
+			default:
+				m.detailOffset = m.scroll(m.detailOffset, msg.String())
 			}
 			return m, nil
 		}
 
+		m.status = ""
 		switch msg.String() {
 		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
 		case "tab":
 			m.focus = (m.focus + 1) % panelCount
+			m.selected = 0
 		case "shift+tab":
 			m.focus = (m.focus - 1 + panelCount) % panelCount
+			m.selected = 0
+		case "down", "j":
+			m.selected++
+			m.clampSelection()
+		case "up", "k":
+			m.selected = max(0, m.selected-1)
+		case "/":
+			m.searching = true
+		case "esc":
+			if m.diagnosing {
+				m.cancelDiagnosis()
+				m.status = "diagnosis canceled"
+				break
+			}
+			m.filter = ""
+			m.applyView()
+		case "s":
+			m.cycleSort()
+		case "o":
+			cmds = append(cmds, m.openItem())
+		case "i":
+			m.ignoreItem()
+			cmds = append(cmds, m.animateToScore())
 		case "r":
 			cmds = append(cmds, m.runAnalysis())
+		case "p":
+			cmds = append(cmds, m.togglePause())
+		case "n":
+			m.showNotices = !m.showNotices
+		case "x":
+			cmds = append(cmds, m.export())
+		case "e":
+			m.showExplain = true
+			m.explainOffset = 0
+		case "c":
+			cmds = append(cmds, m.openCompare())
+		case "enter":
+			m.showDetail = true
+			m.detailOffset = 0
 		case "d":
 			if !m.diagnosing {
-				m.diagnosing = true
-				cmds = append(cmds, m.runDiagnosis())
+				if item, ok := m.selectedItem(); ok {
+					cmds = append(cmds, m.runItemDiagnosis(item))
+				} else {
+					cmds = append(cmds, m.runDiagnosis())
+				}
 			}
 		}
 
+	case tea.MouseMsg:
+		if m.showDiagnosis {
+			var cmd tea.Cmd
+			m.diagnosisView, cmd = m.diagnosisView.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.layoutDiagnosis()
 
 	case fileChangedMsg:
-		m.activity = append([]activityEntry{{
-			file:      msg.path,
-			timestamp: msg.timestamp,
-		}}, m.activity...)
+		var entries []activityEntry
+		if msg.batch.Large() {
+			entries = []activityEntry{{timestamp: msg.batch[0].Timestamp, count: len(msg.batch)}}
+		} else {
+			for _, event := range msg.batch {
+				entries = append(entries, activityEntry{file: event.Path, timestamp: event.Timestamp, removed: event.Removed})
+			}
+		}
+		m.activity = append(entries, m.activity...)
 		if len(m.activity) > 20 {
 			m.activity = m.activity[:20]
 		}
-		cmds = append(cmds, m.runAnalysis(), m.listenForChanges())
+		if m.paused {
+			for _, event := range msg.batch {
+				m.pending[event.Path] = true
+			}
+			cmds = append(cmds, m.listenForChanges())
+			break
+		}
+		if msg.batch.Large() {
+			// A branch switch or a formatter run; one full analysis catches
+			// up sooner than re-analyzing each file.
+			cmds = append(cmds, m.runAnalysis(), m.listenForChanges())
+			break
+		}
+		removed := false
+		for _, event := range msg.batch {
+			if !event.Removed {
+				cmds = append(cmds, m.runSingle(event.Path))
+				continue
+			}
+			if rel, err := filepath.Rel(m.cfg.Root, event.Path); err == nil {
+				m.raw = m.raw.RemovePath(rel)
+				removed = true
+			}
+		}
+		if removed {
+			m.rescore()
+			cmds = append(cmds, m.animateToScore())
+		}
+		cmds = append(cmds, m.listenForChanges())
+
+	case watchErrorMsg:
+		m.watchErrors = append([]activityEntry{{file: msg.err.Error(), timestamp: msg.timestamp}}, m.watchErrors...)
+		if len(m.watchErrors) > 50 {
+			m.watchErrors = m.watchErrors[:50]
+		}
+		m.logNotice(msg.timestamp, msg.err.Error())
+		cmds = append(cmds, m.listenForErrors())
+
+	case gitHeadMsg:
+		if msg.err != nil {
+			// Not a git repository, or no commits yet; stop polling.
+			break
+		}
+		moved := m.head != nil && *m.head != msg.head
+		if m.head == nil || moved {
+			cmds = append(cmds, m.checkDirty())
+		}
+		m.head = &msg.head
+		if moved {
+			m.status = "HEAD moved to " + headLabel(msg.head)
+			// The ref may have moved with it.
+			m.compare = nil
+			if m.showCompare && !m.comparing {
+				cmds = append(cmds, m.runCompare())
+			}
+			if m.paused {
+				m.pending["HEAD"] = true
+			} else {
+				cmds = append(cmds, m.runAnalysis(), m.loadHistory())
+			}
+		}
+		cmds = append(cmds, tea.Tick(gitPollInterval, func(time.Time) tea.Msg { return gitTickMsg{} }))
+
+	case gitTickMsg:
+		cmds = append(cmds, m.checkHead())
+
+	case gitDirtyMsg:
+		m.dirty = msg.dirty
+
+	case refreshTickMsg:
+		if !m.paused {
+			cmds = append(cmds, m.runAnalysis())
+		}
+		cmds = append(cmds, m.refreshTick())
 
 	case analysisCompleteMsg:
-		m.results = msg.results
-		m.score = msg.score
-		m.targetScore = msg.score.Total
-		if m.displayScore != m.targetScore {
-			m.animating = true
-			cmds = append(cmds, m.animateTick())
+		m.analyzing = false
+		if msg.err != nil {
+			m.status = "analysis: " + msg.err.Error()
+			break
+		}
+		m.raw = msg.results
+		m.rescore()
+		cmds = append(cmds, m.animateToScore())
+
+	case compareCompleteMsg:
+		m.comparing = false
+		if msg.err != nil {
+			m.compareErr = msg.err
+			break
+		}
+		m.setCompareBase(msg.base)
+
+	case progressMsg:
+		if m.analyzing {
+			m.progress = analyzer.Progress(msg)
+		}
+		cmds = append(cmds, m.listenForProgress())
+
+	case fileAnalyzedMsg:
+		for i := range m.activity {
+			if e := &m.activity[i]; e.file == msg.path && !e.analyzed {
+				e.analyzed = true
+				e.changes = fileChanges(m.raw, msg.single, m.cfg.Thresholds)
+				break
+			}
+		}
+		// Merged here rather than in the command so that bursts of changes
+		// never overwrite each other.
+		m.raw = m.raw.ReplaceFile(msg.single)
+		m.rescore()
+		cmds = append(cmds, m.animateToScore())
+		if m.head != nil {
+			cmds = append(cmds, m.checkDirty())
+		}
+
+	case editorClosedMsg:
+		if msg.err != nil {
+			m.status = "editor: " + msg.err.Error()
 		}
 
 	case historyCompleteMsg:
 		m.sparklineData = msg.data
+		m.churn = msg.churn
 
 	case animateTickMsg:
 		diff := m.targetScore - m.displayScore
@@ -191,9 +670,24 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case diagnosisCompleteMsg:
-		m.diagnosing = false
+		m.cancelDiagnosis()
 		m.showDiagnosis = true
+		m.diagnosisFrom = msg.from
 		m.diagnosisText = msg.text
+		m.diagnosisView = viewport.New(0, 0)
+		m.layoutDiagnosis()
+
+	case exportedMsg:
+		m.status = "exported " + msg.path + ".{json,md,html}"
+		if msg.err != nil {
+			m.status = "exporting: " + msg.err.Error()
+		}
+
+	case copiedMsg:
+		m.status = "copied to clipboard"
+		if msg.err != nil {
+			m.status = "copying: " + msg.err.Error()
+		}
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -212,52 +706,122 @@ func (m *model) View() string {
 	if m.width == 0 {
 		return "Loading..."
 	}
+	if m.width < minWidth {
+		return fmt.Sprintf("drift needs a terminal at least %d columns wide", minWidth)
+	}
 
 	if m.showDiagnosis {
 		return m.viewDiagnosis()
 	}
+	if m.showExplain {
+		return m.viewExplain()
+	}
+	if m.showCompare {
+		return m.viewCompare()
+	}
+	if m.showDetail {
+		return m.viewDetail()
+	}
 
 	var sections []string
 
 	sections = append(sections, m.viewHeader())
+	if notices := m.viewNotices(); notices != "" {
+		sections = append(sections, notices)
+	}
 	sections = append(sections, m.viewScore())
+	if len(m.results.Projects) > 0 {
+		sections = append(sections, m.viewProjects())
+	}
 
-	midLeft := m.viewComplexity()
-	midRight := m.viewDeps()
-	midSection := lipgloss.JoinHorizontal(lipgloss.Top, midLeft, midRight)
-	sections = append(sections, midSection)
+	panels := []string{
+		m.viewComplexity(), m.viewDeps(),
+		m.viewBoundaries(), m.viewActivity(),
+		m.viewFiles(), m.viewDuplication(),
+	}
+	for n := m.columns(); len(panels) > 0; {
+		row := panels[:min(n, len(panels))]
+		panels = panels[len(row):]
+		sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
 
-	botLeft := m.viewBoundaries()
-	botRight := m.viewActivity()
-	botSection := lipgloss.JoinHorizontal(lipgloss.Top, botLeft, botRight)
-	sections = append(sections, botSection)
+	sections = append(sections, m.viewSecurity())
+	sections = append(sections, m.viewHotspots())
 
 	sections = append(sections, m.viewFooter())
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
+// Layout breakpoints, in columns: below narrowWidth the panels stack in a
+// single column, and from wideWidth they sit three abreast instead of two.
+const (
+	minWidth    = 40
+	narrowWidth = 100
+	wideWidth   = 180
+)
+
+// columns is how many panels fit side by side.
+func (m *model) columns() int {
+	switch {
+	case m.width < narrowWidth:
+		return 1
+	case m.width >= wideWidth:
+		return 3
+	}
+	return 2
+}
+
+// columnWidth is the width of a panel in the grid, leaving room for the
+// borders of every panel in its row.
+func (m *model) columnWidth() int {
+	n := m.columns()
+	if n == 1 {
+		return m.width - 4 // as wide as the full-width panels
+	}
+	return (m.width - 2*n) / n
+}
+
 func (m *model) viewHeader() string {
 	logo := logoStyle.Render("◆ DRIFT")
 	subtitle := lipgloss.NewStyle().Foreground(colorDim).Render(" — codebase health monitor")
+	if git := m.viewGit(); git != "" {
+		subtitle = "  " + git
+	}
 	header := logo + subtitle
 
-	langLabel := string(m.results.Language)
+	langLabel := m.results.LanguageLabel()
 	if langLabel == "" || langLabel == "unknown" {
 		langLabel = "unknown"
 	}
 	fileInfo := lipgloss.NewStyle().Foreground(colorDim).Render(
 		fmt.Sprintf("%s · %d files · %d functions", langLabel, m.results.FileCount, m.results.FuncCount),
 	)
+	if m.analyzing {
+		fileInfo = m.viewProgress()
+	} else if state := m.watchState(); state != "" {
+		fileInfo = lipgloss.NewStyle().Foreground(colorYellow).Render(state) + lipgloss.NewStyle().Foreground(colorDim).Render(" · ") + fileInfo
+	}
 
 	padding := m.width - lipgloss.Width(header) - lipgloss.Width(fileInfo) - 4
 	if padding < 1 {
-		padding = 1
+		return "  " + header + "\n  " + fileInfo
 	}
 
 	return "  " + header + strings.Repeat(" ", padding) + fileInfo + "  "
 }
 
+// viewProgress shows the phase of the analysis in flight and, while it
+// analyzes files, how many are done.
+func (m *model) viewProgress() string {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	out := m.spinner.View() + dim.Render(m.progress.Phase)
+	if p := m.progress; p.Total > 0 {
+		out += " " + progressBar(p.Done, p.Total, 20) + " " + dim.Render(fmt.Sprintf("%d/%d", p.Done, p.Total))
+	}
+	return out
+}
+
 func (m *model) viewScore() string {
 	score := m.displayScore
 	total := 100.0
@@ -276,12 +840,12 @@ func (m *model) viewScore() string {
 			style = lipgloss.NewStyle().Foreground(colorGreen)
 		} else if position >= 60 {
 			// Transition zone from yellow-green
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#9ACD32")) // yellow-green
+			style = lipgloss.NewStyle().Foreground(colorLime)
 		} else if position >= 40 {
 			style = lipgloss.NewStyle().Foreground(colorYellow)
 		} else if position >= 20 {
 			// Transition zone from yellow to red
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8C00")) // dark orange
+			style = lipgloss.NewStyle().Foreground(colorOrange)
 		} else {
 			style = lipgloss.NewStyle().Foreground(colorRed)
 		}
@@ -293,7 +857,8 @@ func (m *model) viewScore() string {
 	}
 
 	scoreText := scoreStyle(score).Render(fmt.Sprintf("%.0f", score))
-	scoreLabel := lipgloss.NewStyle().Foreground(colorDim).Render("/100")
+	scoreLabel := lipgloss.NewStyle().Foreground(colorDim).Render("/100") +
+		" " + scoreStyle(score).Render(health.Grade(score))
 
 	delta := ""
 	if m.score.Delta > 0 {
@@ -318,17 +883,80 @@ func (m *model) viewScore() string {
 	} else {
 		content = line
 	}
+	if m.score.Goal != nil {
+		content += "\n  " + lipgloss.NewStyle().Foreground(colorDim).Render(" goal: ") + goalText(*m.score.Goal, time.Now())
+	}
 
 	width := m.width
 	style := panelStyle.Width(width - 4)
 	return style.Render(content)
 }
 
+// goalText describes progress toward the goal, e.g. "85 by 2026-06-01: 6.0
+// to go, +1.5/week needed, trend +0.4/week".
+func goalText(g health.GoalProgress, now time.Time) string {
+	target := fmt.Sprintf("%.0f", g.Target)
+	if !g.By.IsZero() {
+		target += " by " + g.By.Format(time.DateOnly)
+	}
+	if g.Reached() {
+		return target + ": reached"
+	}
+	if g.Overdue(now) {
+		return fmt.Sprintf("%s: missed, %.1f short", target, g.Remaining())
+	}
+
+	text := fmt.Sprintf("%s: %.1f to go", target, g.Remaining())
+	if g.Needed > 0 {
+		text += fmt.Sprintf(", %+.1f/week needed", g.Needed)
+	}
+	if g.TrendKnown {
+		text += fmt.Sprintf(", trend %+.1f/week", g.Trend)
+	}
+	return text
+}
+
+func (m *model) viewProjects() string {
+	style := panelStyle.Width(m.width - 4)
+
+	lines := []string{panelTitleStyle.Render("PROJECTS")}
+
+	count := 6
+	if len(m.results.Projects) < count {
+		count = len(m.results.Projects)
+	}
+	for i := 0; i < count; i++ {
+		p, score := m.results.Projects[i], m.projectScores[i]
+
+		icon := statusOK.String()
+		if score < 50 {
+			icon = statusBad.String()
+		} else if score < 80 {
+			icon = statusWarn.String()
+		}
+
+		line := fmt.Sprintf("  %s %-24s %-10s %4d files %5d functions  %s",
+			icon,
+			truncate(p.Project.Name, 24),
+			p.Project.Language,
+			p.Results.FileCount,
+			p.Results.FuncCount,
+			scoreStyle(score).Render(fmt.Sprintf("%.0f/100", score)),
+		)
+		lines = append(lines, line)
+	}
+	if extra := len(m.results.Projects) - count; extra > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  … and %d more", extra)))
+	}
+
+	return style.Render(strings.Join(lines, "\n"))
+}
+
 func (m *model) viewComplexity() string {
-	halfWidth := (m.width - 4) / 2
-	style := panelStyle.Width(halfWidth)
+	style := panelStyle.Width(m.columnWidth())
+	r := m.shown()
 
-	title := panelTitleStyle.Render("COMPLEXITY")
+	title := panelTitleStyle.Render("COMPLEXITY") + m.sortHint(panelComplexity)
 
 	var lines []string
 	lines = append(lines, title)
@@ -339,18 +967,17 @@ func (m *model) viewComplexity() string {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  "+spark))
 	}
 
-	count := 8
-	if len(m.results.Complexity) < count {
-		count = len(m.results.Complexity)
-	}
+	count := min(panelRows[panelComplexity], len(r.Complexity))
 
-	if count == 0 {
+	if count == 0 && m.filter != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  No matches for /"+m.filter))
+	} else if count == 0 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  No functions found"))
 	}
 
 	maxComplexity := 30
 	for i := 0; i < count; i++ {
-		fc := m.results.Complexity[i]
+		fc := r.Complexity[i]
 
 		var icon string
 		if fc.Complexity > 20 {
@@ -362,12 +989,15 @@ func (m *model) viewComplexity() string {
 		}
 
 		name := truncate(fc.Name, 18)
+		if r.MultiLanguage() {
+			name = truncate(langTag(fc.Language)+fc.Name, 18)
+		}
 		loc := fmt.Sprintf("%s:%d", fc.File, fc.Line)
 		loc = truncate(loc, 16)
 
 		bar := complexityBar(fc.Complexity, maxComplexity)
 
-		line := fmt.Sprintf("  %s %-18s %3d %s", icon, name, fc.Complexity, bar)
+		line := fmt.Sprintf("%s%s %-18s %3d %s", m.cursor(panelComplexity, i), icon, name, fc.Complexity, bar)
 		lines = append(lines, line)
 	}
 
@@ -380,25 +1010,31 @@ func (m *model) viewComplexity() string {
 }
 
 func (m *model) viewDeps() string {
-	halfWidth := (m.width - 4) / 2
-	style := panelStyle.Width(halfWidth)
+	style := panelStyle.Width(m.columnWidth())
+	r := m.shown()
+
+	runtime, dev := splitDeps(r.Dependencies)
+	deps := append(runtime, dev...)
 
-	title := panelTitleStyle.Render("DEPENDENCIES")
+	title := panelTitleStyle.Render("DEPENDENCIES") + m.sortHint(panelDeps)
+	if len(dev) > 0 {
+		title += lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  %d runtime · %d dev", len(runtime), len(dev)))
+	}
+	if n := r.TransitiveDeps; n > 0 {
+		title += lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  +%d transitive", n))
+	}
 
 	var lines []string
 	lines = append(lines, title)
 
-	if len(m.results.Dependencies) == 0 {
+	if len(deps) == 0 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  No dependencies found"))
 	}
 
-	count := 8
-	if len(m.results.Dependencies) < count {
-		count = len(m.results.Dependencies)
-	}
+	count := min(panelRows[panelDeps], len(deps))
 
 	for i := 0; i < count; i++ {
-		dep := m.results.Dependencies[i]
+		dep := deps[i]
 
 		var icon string
 		var staleText string
@@ -416,14 +1052,31 @@ func (m *model) viewDeps() string {
 			icon = lipgloss.NewStyle().Foreground(colorDim).Render("?")
 			staleText = lipgloss.NewStyle().Foreground(colorDim).Render("unknown")
 		}
+		if badge := behindBadge(dep); badge != "" {
+			staleText += " " + badge
+		}
+		if dep.Scope == "dev" {
+			staleText += lipgloss.NewStyle().Foreground(colorDim).Render(" dev")
+		}
 
 		name := truncate(dep.Module, 18)
+		if r.MultiLanguage() {
+			name = truncate(langTag(dep.Language)+dep.Module, 18)
+		}
 		ver := truncate(dep.CurrentVersion, 10)
 
-		line := fmt.Sprintf("  %s %-18s %-10s %s", icon, name, ver, staleText)
+		line := fmt.Sprintf("%s%s %-18s %-10s %s", m.cursor(panelDeps, i), icon, name, ver, staleText)
 		lines = append(lines, line)
 	}
 
+	if heavy := analyzer.HeaviestDependencies(deps, 3); len(heavy) > 0 {
+		var names []string
+		for _, d := range heavy {
+			names = append(names, fmt.Sprintf("%s +%d", d.Module, d.Transitive))
+		}
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  heaviest: "+strings.Join(names, ", ")))
+	}
+
 	focusStyle := style
 	if m.focus == panelDeps {
 		focusStyle = style.BorderForeground(colorCyan)
@@ -433,8 +1086,8 @@ func (m *model) viewDeps() string {
 }
 
 func (m *model) viewBoundaries() string {
-	halfWidth := (m.width - 4) / 2
-	style := panelStyle.Width(halfWidth)
+	style := panelStyle.Width(m.columnWidth())
+	r := m.shown()
 
 	title := panelTitleStyle.Render("BOUNDARIES")
 
@@ -447,23 +1100,32 @@ func (m *model) viewBoundaries() string {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  "+spark))
 	}
 
-	if len(m.cfg.Boundaries) == 0 && len(m.results.Violations) == 0 {
+	if len(m.cfg.Boundaries) == 0 && len(m.cfg.Layers) == 0 && len(r.Violations) == 0 && len(r.Cycles) == 0 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  No boundary rules defined"))
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  Add rules in .drift.yaml"))
 	}
 
-	if len(m.results.Violations) > 0 {
-		for _, v := range m.results.Violations {
-			line := fmt.Sprintf("  %s %s → %s (%s:%d)",
-				statusBad.String(),
-				v.From, v.To,
+	if len(r.Violations) > 0 {
+		for i, v := range r.Violations {
+			line := fmt.Sprintf("%s%s %s%s → %s (%s:%d)",
+				m.cursor(panelBoundaries, i), statusBad.String(),
+				ruleTag(v), v.From, v.To,
 				v.File, v.Line,
 			)
 			lines = append(lines, line)
 		}
-	} else if len(m.cfg.Boundaries) > 0 {
+	} else if m.filter != "" && len(r.Cycles) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  No matches for /"+m.filter))
+	} else if len(m.cfg.Boundaries) > 0 || len(m.cfg.Layers) > 0 {
 		lines = append(lines, fmt.Sprintf("  %s All boundaries clean", statusOK.String()))
 	}
+	if n := len(r.SuppressedViolations); n > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  %d suppressed (drift:ignore)", n)))
+	}
+
+	for i, c := range r.Cycles {
+		lines = append(lines, fmt.Sprintf("%s%s cycle: %s", m.cursor(panelBoundaries, len(r.Violations)+i), statusBad.String(), c))
+	}
 
 	focusStyle := style
 	if m.focus == panelBoundaries {
@@ -474,8 +1136,7 @@ func (m *model) viewBoundaries() string {
 }
 
 func (m *model) viewActivity() string {
-	halfWidth := (m.width - 4) / 2
-	style := panelStyle.Width(halfWidth)
+	style := panelStyle.Width(m.columnWidth())
 
 	title := panelTitleStyle.Render("ACTIVITY")
 
@@ -489,7 +1150,10 @@ func (m *model) viewActivity() string {
 		lines = append(lines, sparkLabel+spark)
 	}
 
-	if len(m.activity) == 0 {
+	if len(m.activity) == 0 && m.watch == nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  Not watching for changes"))
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  Press r to re-analyze"))
+	} else if len(m.activity) == 0 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  Watching for changes..."))
 		langHint := string(m.results.Language)
 		if langHint == "" {
@@ -498,16 +1162,21 @@ func (m *model) viewActivity() string {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  Edit "+langHint+" file to see updates"))
 	}
 
-	count := 6
-	if len(m.activity) < count {
-		count = len(m.activity)
-	}
+	count := min(panelRows[panelActivity], len(m.activity))
 
 	for i := 0; i < count; i++ {
 		entry := m.activity[i]
 		ts := activityTimeStyle.Render(entry.timestamp.Format("15:04:05"))
 		file := activityFileStyle.Render(filepath.Base(entry.file))
-		line := fmt.Sprintf("  %s  %s modified", ts, file)
+		line := fmt.Sprintf("%s%s  %s modified", m.cursor(panelActivity, i), ts, file)
+		if entry.count > 0 {
+			line = fmt.Sprintf("%s%s  %s", m.cursor(panelActivity, i), ts, activityFileStyle.Render(fmt.Sprintf("%d files changed", entry.count)))
+		} else if entry.removed {
+			line = fmt.Sprintf("%s%s  %s removed", m.cursor(panelActivity, i), ts, file)
+		} else if entry.analyzed {
+			line = fmt.Sprintf("%s%s  %s  ", m.cursor(panelActivity, i), ts, file)
+			line += renderChanges(entry.changes, m.columnWidth()-2-lipgloss.Width(line))
+		}
 		lines = append(lines, line)
 	}
 
@@ -519,104 +1188,1062 @@ func (m *model) viewActivity() string {
 	return focusStyle.Render(strings.Join(lines, "\n"))
 }
 
-func (m *model) viewFooter() string {
-	keys := []struct{ key, desc string }{
-		{"tab", "navigate"},
-		{"d", "diagnose"},
-		{"r", "refresh"},
-		{"q", "quit"},
+func (m *model) viewFiles() string {
+	style := panelStyle.Width(m.columnWidth())
+
+	title := panelTitleStyle.Render("GOD FILES")
+
+	var lines []string
+	lines = append(lines, title)
+
+	god := analyzer.GodFiles(m.shown().Files, m.cfg.Thresholds.MaxFileLines)
+	if len(god) == 0 {
+		lines = append(lines, fmt.Sprintf("  %s No files over %d lines", statusOK.String(), m.cfg.Thresholds.MaxFileLines))
 	}
 
-	var parts []string
-	for _, k := range keys {
-		part := footerKeyStyle.Render("["+k.key+"]") + " " + k.desc
-		parts = append(parts, part)
+	count := min(panelRows[panelFiles], len(god))
+	for i := 0; i < count; i++ {
+		f := god[i]
+		icon := statusWarn.String()
+		if f.Lines > 2*m.cfg.Thresholds.MaxFileLines {
+			icon = statusBad.String()
+		}
+		line := fmt.Sprintf("%s%s %-22s %5d lines %3d funcs  avg %.1f",
+			m.cursor(panelFiles, i), icon, truncate(f.Path, 22), f.Lines, f.Functions, f.AvgComplexity)
+		lines = append(lines, line)
+	}
+	if extra := len(god) - count; extra > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  … and %d more", extra)))
 	}
 
-	footer := strings.Join(parts, "  ")
-	return footerStyle.Width(m.width).Render(footer)
+	focusStyle := style
+	if m.focus == panelFiles {
+		focusStyle = style.BorderForeground(colorCyan)
+	}
+
+	return focusStyle.Render(strings.Join(lines, "\n"))
 }
 
-func (m *model) viewDiagnosis() string {
-	if m.diagnosing {
-		content := diagnosisTitleStyle.Render("AI DIAGNOSIS") + "\n\n" +
-			m.spinner.View() + " Analyzing codebase..."
+func (m *model) viewDuplication() string {
+	style := panelStyle.Width(m.columnWidth())
 
-		style := diagnosisStyle.Width(m.width - 8).Height(m.height - 6)
-		return lipgloss.Place(m.width, m.height,
-			lipgloss.Center, lipgloss.Center,
-			style.Render(content),
-		)
-	}
+	title := panelTitleStyle.Render(fmt.Sprintf("DUPLICATION  %.0f", m.score.Duplication))
 
-	content := diagnosisTitleStyle.Render("◆ AI DIAGNOSIS") + "\n\n" + m.diagnosisText +
-		"\n\n" + footerKeyStyle.Render("[esc]") + " close"
+	var lines []string
+	lines = append(lines, title)
 
-	style := diagnosisStyle.Width(m.width - 8).Height(m.height - 6)
-	return lipgloss.Place(m.width, m.height,
-		lipgloss.Center, lipgloss.Center,
-		style.Render(content),
-	)
-}
+	dups := m.shown().Duplicates
+	if len(dups) == 0 {
+		lines = append(lines, fmt.Sprintf("  %s No duplicated blocks", statusOK.String()))
+	}
 
-func (m *model) listenForChanges() tea.Cmd {
-	return func() tea.Msg {
-		if m.watch == nil {
-			select {}
-		}
-		event := <-m.watch.Events
-		return fileChangedMsg{
-			path:      event.Path,
-			timestamp: event.Timestamp,
+	count := min(panelRows[panelDuplication], len(dups))
+	for i := 0; i < count; i++ {
+		d := dups[i]
+		first := d.Locations[0]
+		icon := statusWarn.String()
+		if len(d.Locations) > 2 {
+			icon = statusBad.String()
 		}
+		line := fmt.Sprintf("%s%s %-22s %3d lines ×%d",
+			m.cursor(panelDuplication, i), icon, truncate(fmt.Sprintf("%s:%d", first.Path, first.Line), 22), d.Lines, len(d.Locations))
+		lines = append(lines, line)
+	}
+	if extra := len(dups) - count; extra > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  … and %d more", extra)))
 	}
-}
 
-func (m *model) runAnalysis() tea.Cmd {
-	return func() tea.Msg {
-		results, err := m.ana.Run()
-		if err != nil {
-			return nil
-		}
-		score := m.scorer.Calculate(results)
-		return analysisCompleteMsg{results: results, score: score}
+	focusStyle := style
+	if m.focus == panelDuplication {
+		focusStyle = style.BorderForeground(colorCyan)
 	}
+
+	return focusStyle.Render(strings.Join(lines, "\n"))
 }
 
-func (m *model) runDiagnosis() tea.Cmd {
-	return func() tea.Msg {
-		result, err := ai.RunDiagnosis(m.cfg, m.score, m.results)
-		if err != nil {
-			result = buildDiagnosisText(m.score, m.results, m.cfg)
-			result = lipgloss.NewStyle().Foreground(colorDim).Render("(AI unavailable, showing local analysis)") + "\n\n" + result
-		} else {
-			result = lipgloss.NewStyle().Foreground(colorPurple).Render("Powered by "+m.cfg.AI.Provider) + "\n\n" + result
-		}
-		return diagnosisCompleteMsg{text: result}
+// notices lists the watcher's errors, newest first, then the files the
+// analysis left out.
+func (m *model) notices() []string {
+	var out []string
+	for _, e := range m.watchErrors {
+		out = append(out, e.timestamp.Format("15:04:05")+"  "+e.file)
 	}
+	for _, n := range m.raw.Notices {
+		out = append(out, n.String())
+	}
+	return out
 }
 
-func (m *model) animateTick() tea.Cmd {
-	return tea.Tick(16*time.Millisecond, func(t time.Time) tea.Msg {
-		return animateTickMsg{}
+// viewNotices shows how many notices there are, or lists them once "n"
+// expands the area. Without notices it is empty.
+func (m *model) viewNotices() string {
+	notices := m.notices()
+	if len(notices) == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("%s %d notices", statusWarn.String(), len(notices))
+	if len(notices) == 1 {
+		summary = fmt.Sprintf("%s 1 notice", statusWarn.String())
+	}
+	if !m.showNotices {
+		return "  " + summary + lipgloss.NewStyle().Foreground(colorDim).Render("  [n] show")
+	}
+
+	lines := []string{panelTitleStyle.Render("NOTICES") + lipgloss.NewStyle().Foreground(colorDim).Render("  [n] hide")}
+	count := min(8, len(notices))
+	for _, n := range notices[:count] {
+		lines = append(lines, "  "+truncate(n, m.width-10))
+	}
+	if extra := len(notices) - count; extra > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  … and %d more in %s", extra, logFile)))
+	}
+	return panelStyle.Width(m.width - 4).BorderForeground(colorYellow).Render(strings.Join(lines, "\n"))
+}
+
+func (m *model) viewSecurity() string {
+	style := panelStyle.Width(m.width - 4)
+
+	lines := []string{panelTitleStyle.Render(fmt.Sprintf("SECURITY  %.0f", m.score.Security))}
+
+	vulns := m.results.Vulnerabilities
+	if len(vulns) == 0 {
+		lines = append(lines, fmt.Sprintf("  %s No known vulnerabilities in %d dependencies", statusOK.String(), len(m.results.Dependencies)))
+	}
+
+	count := min(panelRows[panelSecurity], len(vulns))
+	for i := 0; i < count; i++ {
+		v := vulns[i]
+		name := v.Module + "@" + v.Version
+		if m.results.MultiLanguage() {
+			name = langTag(v.Language) + name
+		}
+		line := fmt.Sprintf("%s%s %-8s %-28s %-36s",
+			m.cursor(panelSecurity, i), severityIcon(v.Severity), v.Severity, truncate(name, 28), truncate(v.Label(), 36))
+		// The summary gets whatever width is left, if that's enough to read.
+		if room := m.width - 6 - lipgloss.Width(line) - 1; room >= 12 {
+			line += " " + truncate(v.Summary, room)
+		}
+		lines = append(lines, line)
+	}
+	if extra := len(vulns) - count; extra > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  … and %d more", extra)))
+	}
+
+	focusStyle := style
+	if m.focus == panelSecurity {
+		focusStyle = style.BorderForeground(colorCyan)
+	}
+
+	return focusStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (m *model) viewHotspots() string {
+	style := panelStyle.Width(m.width - 4)
+
+	lines := []string{panelTitleStyle.Render("HOTSPOTS") +
+		lipgloss.NewStyle().Foreground(colorDim).Render("  complex × frequently changed")}
+
+	if m.churn == nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorDim).Render("  loading git history…"))
+	}
+	spots := history.Hotspots(m.churn, m.shown().Files)
+	if m.churn != nil && len(spots) == 0 {
+		lines = append(lines, fmt.Sprintf("  %s No hotspots", statusOK.String()))
+	}
+
+	count := min(panelRows[panelHotspots], len(spots))
+	pathWidth := max(16, min(60, m.width-50)) // leaves room for the counts
+	for i := 0; i < count; i++ {
+		s := spots[i]
+		icon := statusWarn.String()
+		if s.Score >= 50 {
+			icon = statusBad.String()
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %-*s %3d commits  complexity %4d  score %3.0f",
+			m.cursor(panelHotspots, i), icon, pathWidth, truncate(s.Path, pathWidth), s.Commits, s.Complexity, s.Score))
+	}
+
+	focusStyle := style
+	if m.focus == panelHotspots {
+		focusStyle = style.BorderForeground(colorCyan)
+	}
+
+	return focusStyle.Render(strings.Join(lines, "\n"))
+}
+
+// panelRows is how many rows each panel shows before truncating; the
+// detail view lists the rest. Panels missing here show every row.
+var panelRows = map[focusPanel]int{
+	panelComplexity:  8,
+	panelDeps:        8,
+	panelActivity:    6,
+	panelFiles:       5,
+	panelDuplication: 5,
+	panelSecurity:    5,
+	panelHotspots:    5,
+}
+
+// panelItem is a row of a panel that the item actions apply to.
+type panelItem struct {
+	finding string // what the row reports, for diagnosis
+	path    string // file to open, relative to the root; empty if none
+	line    int
+	issue   any // what ignoring grandfathers in the baseline; nil if it can't be
+}
+
+// panelItems lists the rows the focused panel shows, in order.
+func (m *model) panelItems() []panelItem {
+	r := m.shown()
+	var items []panelItem
+
+	switch m.focus {
+	case panelComplexity:
+		for _, fc := range r.Complexity {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("%s() in %s:%d has cyclomatic complexity %d, %d lines, %d params, and nesting depth %d.",
+					fc.Name, fc.Path, fc.Line, fc.Complexity, fc.Lines, fc.Params, fc.Nesting),
+				path:  fc.Path,
+				line:  fc.Line,
+				issue: fc,
+			})
+		}
+	case panelDeps:
+		runtime, dev := splitDeps(r.Dependencies)
+		for _, dep := range append(runtime, dev...) {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("Dependency %s is at %s; the latest version is %s (%d days behind).",
+					dep.Module, dep.CurrentVersion, dep.LatestVersion, dep.StaleDays),
+			})
+		}
+	case panelBoundaries:
+		for _, v := range r.Violations {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("%s:%d imports %s, breaking the %s → %s architecture boundary. %s",
+					v.File, v.Line, v.Import, v.From, v.To, v.Description),
+				path:  m.results.FindFile(v.File),
+				line:  v.Line,
+				issue: v,
+			})
+		}
+		for _, c := range r.Cycles {
+			items = append(items, panelItem{finding: "Import cycle: " + c.String(), issue: c})
+		}
+	case panelActivity:
+		for _, entry := range m.activity {
+			path := entry.file
+			if rel, err := filepath.Rel(m.cfg.Root, path); err == nil {
+				path = rel
+			}
+			finding := path + " was just modified."
+			if entry.count > 0 {
+				items = append(items, panelItem{finding: fmt.Sprintf("%d files just changed together, so everything was re-analyzed.", entry.count)})
+				continue
+			}
+			if len(entry.changes) > 0 {
+				finding = path + " was just modified: " + changesText(entry.changes) + "."
+			}
+			items = append(items, panelItem{finding: finding, path: path})
+		}
+	case panelFiles:
+		for _, f := range analyzer.GodFiles(r.Files, m.cfg.Thresholds.MaxFileLines) {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("%s has %d lines and %d functions (average complexity %.1f), over the %d-line limit.",
+					f.Path, f.Lines, f.Functions, f.AvgComplexity, m.cfg.Thresholds.MaxFileLines),
+				path: f.Path,
+			})
+		}
+	case panelDuplication:
+		for _, d := range r.Duplicates {
+			locs := make([]string, len(d.Locations))
+			for i, loc := range d.Locations {
+				locs[i] = fmt.Sprintf("%s:%d", loc.Path, loc.Line)
+			}
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("%d lines are duplicated at %s.", d.Lines, strings.Join(locs, ", ")),
+				path:    d.Locations[0].Path,
+				line:    d.Locations[0].Line,
+				issue:   d,
+			})
+		}
+	case panelSecurity:
+		for _, v := range r.Vulnerabilities {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("Dependency %s %s has known vulnerability %s (%s severity): %s",
+					v.Module, v.Version, v.Label(), v.Severity, v.Summary),
+				issue: v,
+			})
+		}
+	case panelHotspots:
+		for _, spot := range history.Hotspots(m.churn, r.Files) {
+			items = append(items, panelItem{
+				finding: fmt.Sprintf("%s changed in %d recent commits and its functions add up to complexity %d.",
+					spot.Path, spot.Commits, spot.Complexity),
+				path: spot.Path,
+			})
+		}
+	}
+
+	if limit, ok := panelRows[m.focus]; ok && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// filterResults narrows r's functions, dead code, boundary violations,
+// import cycles, files, and duplicated blocks to those whose file or name
+// fuzzily matches query. Everything else is kept.
+func filterResults(r *analyzer.Results, query string) *analyzer.Results {
+	out := *r
+	out.Complexity = matching(r.Complexity, query, func(fc analyzer.FunctionComplexity) string { return fc.Path + " " + fc.Name })
+	out.DeadCode = matching(r.DeadCode, query, func(d analyzer.DeadFunction) string { return d.File + " " + d.Name })
+	out.Violations = matching(r.Violations, query, func(v analyzer.BoundaryViolation) string { return v.File + " " + v.Import })
+	out.Cycles = matching(r.Cycles, query, analyzer.ImportCycle.String)
+	out.Files = matching(r.Files, query, func(f analyzer.FileMetrics) string { return f.Path })
+	out.Duplicates = matching(r.Duplicates, query, func(d analyzer.DuplicateBlock) string {
+		paths := make([]string, len(d.Locations))
+		for i, loc := range d.Locations {
+			paths[i] = loc.Path
+		}
+		return strings.Join(paths, " ")
+	})
+	return &out
+}
+
+func matching[T any](items []T, query string, text func(T) string) []T {
+	var kept []T
+	for _, item := range items {
+		if fuzzyMatch(query, text(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// fuzzyMatch reports whether the characters of query appear in s in order,
+// ignoring case, so "tuiview" matches "internal/tui/app.go viewScore".
+func fuzzyMatch(query, s string) bool {
+	s = strings.ToLower(s)
+	for _, c := range strings.ToLower(query) {
+		i := strings.IndexRune(s, c)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(c)):]
+	}
+	return true
+}
+
+// selectedItem is the focused panel's selected row, if it has any rows.
+func (m *model) selectedItem() (panelItem, bool) {
+	items := m.panelItems()
+	if len(items) == 0 {
+		return panelItem{}, false
+	}
+	return items[min(m.selected, len(items)-1)], true
+}
+
+// cursor starts row i of panel, marking it when it is the focused panel's
+// selected row.
+func (m *model) cursor(panel focusPanel, i int) string {
+	if m.focus == panel && i == m.selected {
+		return selectedRowStyle.Render("▸ ")
+	}
+	return "  "
+}
+
+// openItem opens the selected row's file in $VISUAL or $EDITOR, falling
+// back to vi, at the row's line.
+func (m *model) openItem() tea.Cmd {
+	item, ok := m.selectedItem()
+	if !ok || item.path == "" {
+		m.status = "no file to open"
+		return nil
+	}
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := editorArgs(editor, filepath.Join(m.cfg.Root, item.path), item.line)
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return editorClosedMsg{err: err}
+	})
+}
+
+// editorArgs builds the command line that opens path at line in editor,
+// which may carry arguments of its own, such as "code --wait". VS Code and
+// its forks take "-g path:line", Sublime Text and Zed "path:line", and most
+// terminal editors, like vi, nano, and emacs, "+line path".
+func editorArgs(editor, path string, line int) []string {
+	args := strings.Fields(editor)
+	if line <= 0 {
+		return append(args, path)
+	}
+	switch strings.TrimSuffix(filepath.Base(args[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		return append(args, "-g", fmt.Sprintf("%s:%d", path, line))
+	case "subl", "zed":
+		return append(args, fmt.Sprintf("%s:%d", path, line))
+	}
+	return append(args, fmt.Sprintf("+%d", line), path)
+}
+
+// ignoreItem grandfathers the selected row's issue in the baseline file,
+// which drops it from the dashboard, report, and check alike.
+func (m *model) ignoreItem() {
+	item, ok := m.selectedItem()
+	if !ok {
+		return
+	}
+	b := m.baseline
+	if b == nil {
+		b = &analyzer.Baseline{CreatedAt: time.Now().UTC()}
+	}
+	if item.issue == nil || !b.Grandfather(item.issue, m.cfg.Thresholds) {
+		m.status = "only issues can be ignored"
+		return
+	}
+	if err := b.Save(m.cfg.Root); err != nil {
+		m.status = "saving baseline: " + err.Error()
+		return
+	}
+	m.baseline = b
+	m.status = "ignored in " + analyzer.BaselineFile
+	m.rescore()
+}
+
+// detailLines returns the title and every line behind the focused panel.
+func (m *model) detailLines() (string, []string) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	r := m.shown()
+	var lines []string
+
+	switch m.focus {
+	case panelScore:
+		scores := m.score.Categories()
+		names := make([]string, 0, len(scores))
+		for name := range scores {
+			if name != "total" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  %-16s %s", name, scoreStyle(scores[name]).Render(fmt.Sprintf("%3.0f/100", scores[name]))))
+		}
+		if m.score.Goal != nil {
+			lines = append(lines, "", "  goal: "+goalText(*m.score.Goal, time.Now()))
+		}
+		return fmt.Sprintf("HEALTH  %.0f/100  %s", m.score.Total, m.score.Grade()), lines
+
+	case panelComplexity:
+		for _, fc := range r.Complexity {
+			icon := statusOK.String()
+			if fc.Complexity > 20 {
+				icon = statusBad.String()
+			} else if fc.Complexity > 10 {
+				icon = statusWarn.String()
+			}
+			lines = append(lines, fmt.Sprintf("  %s %3d  %s() %s", icon, fc.Complexity, fc.Name,
+				dim.Render(fmt.Sprintf("%s:%d — %d lines, %d params, nesting %d",
+					langPrefixed(r, fc.Language, fc.Path), fc.Line, fc.Lines, fc.Params, fc.Nesting))))
+		}
+		return fmt.Sprintf("COMPLEXITY  %d functions", len(r.Complexity)), lines
+
+	case panelDeps:
+		runtime, dev := splitDeps(r.Dependencies)
+		for _, dep := range append(runtime, dev...) {
+			icon := statusOK.String()
+			if dep.Status == "stale" {
+				icon = statusWarn.String()
+			} else if dep.Status == "outdated" {
+				icon = statusBad.String()
+			}
+			line := fmt.Sprintf("  %s %-36s %s → %s", icon, langPrefixed(r, dep.Language, dep.Module), dep.CurrentVersion, dep.LatestVersion)
+			if dep.StaleDays > 0 {
+				line += dim.Render(fmt.Sprintf("  %dd old", dep.StaleDays))
+			}
+			if badge := behindBadge(dep); badge != "" {
+				line += "  " + badge
+			}
+			if dep.Scope == "dev" {
+				line += dim.Render("  dev")
+			}
+			if dep.Transitive > 0 {
+				line += dim.Render(fmt.Sprintf("  +%d transitive", dep.Transitive))
+			}
+			lines = append(lines, line)
+		}
+		return fmt.Sprintf("DEPENDENCIES  %d runtime · %d dev", len(runtime), len(dev)), lines
+
+	case panelBoundaries:
+		for _, v := range r.Violations {
+			lines = append(lines, fmt.Sprintf("  %s %s%s → %s (%s:%d)", statusBad.String(), ruleTag(v), v.From, v.To, v.File, v.Line))
+			if v.Description != "" {
+				lines = append(lines, dim.Render("      "+v.Description))
+			}
+		}
+		for _, c := range r.Cycles {
+			lines = append(lines, fmt.Sprintf("  %s cycle: %s", statusBad.String(), c))
+		}
+		for _, v := range r.SuppressedViolations {
+			lines = append(lines, dim.Render(fmt.Sprintf("    suppressed: %s%s → %s (%s:%d)", ruleTag(v), v.From, v.To, v.File, v.Line)))
+		}
+		return fmt.Sprintf("BOUNDARIES  %d violations · %d cycles", len(r.Violations), len(r.Cycles)), lines
+
+	case panelActivity:
+		for _, entry := range m.activity {
+			line := fmt.Sprintf("  %s  %s modified",
+				activityTimeStyle.Render(entry.timestamp.Format("15:04:05")), activityFileStyle.Render(entry.file))
+			if entry.count > 0 {
+				line = fmt.Sprintf("  %s  %d files changed",
+					activityTimeStyle.Render(entry.timestamp.Format("15:04:05")), entry.count)
+			} else if entry.removed {
+				line = fmt.Sprintf("  %s  %s removed",
+					activityTimeStyle.Render(entry.timestamp.Format("15:04:05")), activityFileStyle.Render(entry.file))
+			}
+			if entry.analyzed {
+				line += "  " + renderChanges(entry.changes, 0)
+			}
+			lines = append(lines, line)
+		}
+		return "ACTIVITY", lines
+
+	case panelFiles:
+		for _, f := range analyzer.GodFiles(r.Files, m.cfg.Thresholds.MaxFileLines) {
+			lines = append(lines, fmt.Sprintf("  %s %s — %d lines, %d functions, avg complexity %.1f",
+				statusWarn.String(), f.Path, f.Lines, f.Functions, f.AvgComplexity))
+		}
+		return fmt.Sprintf("GOD FILES  over %d lines", m.cfg.Thresholds.MaxFileLines), lines
+
+	case panelDuplication:
+		for _, d := range r.Duplicates {
+			lines = append(lines, fmt.Sprintf("  %s %d lines ×%d", statusWarn.String(), d.Lines, len(d.Locations)))
+			for _, loc := range d.Locations {
+				lines = append(lines, dim.Render(fmt.Sprintf("      %s:%d", loc.Path, loc.Line)))
+			}
+		}
+		return fmt.Sprintf("DUPLICATION  %.0f/100", m.score.Duplication), lines
+
+	case panelSecurity:
+		for _, v := range r.Vulnerabilities {
+			lines = append(lines, fmt.Sprintf("  %s %-8s %s %s", severityIcon(v.Severity), v.Severity,
+				langPrefixed(r, v.Language, v.Module+"@"+v.Version), v.Label()))
+			if v.Summary != "" {
+				lines = append(lines, dim.Render("      "+v.Summary))
+			}
+		}
+		return fmt.Sprintf("SECURITY  %.0f/100", m.score.Security), lines
+
+	case panelHotspots:
+		for _, spot := range history.Hotspots(m.churn, r.Files) {
+			lines = append(lines, fmt.Sprintf("  %3.0f  %s %s", spot.Score, spot.Path,
+				dim.Render(fmt.Sprintf("— %d commits, complexity %d, %d lines", spot.Commits, spot.Complexity, spot.Lines))))
+		}
+		return "HOTSPOTS  complex × frequently changed", lines
+	}
+	return "", nil
+}
+
+func (m *model) viewFooter() string {
+	keys := []struct{ key, desc string }{
+		{"tab", "navigate"},
+		{"j/k", "select"},
+		{"/", "search"},
+		{"s", "sort"},
+		{"enter", "details"},
+		{"o", "open"},
+		{"i", "ignore"},
+		{"d", "diagnose"},
+		{"e", "explain"},
+		{"c", "compare"},
+		{"x", "export"},
+		{"r", "refresh"},
+		{"p", "pause"},
+		{"q", "quit"},
+	}
+	if m.paused {
+		keys[len(keys)-2].desc = "resume"
+	}
+	if len(m.notices()) > 0 {
+		keys = slices.Insert(keys, len(keys)-1, struct{ key, desc string }{"n", "notices"})
+	}
+
+	var prefix []string
+	if m.searching {
+		prefix = append(prefix, footerKeyStyle.Render("/"+m.filter+"▏"))
+	} else if m.filter != "" {
+		prefix = append(prefix, footerKeyStyle.Render("/"+m.filter))
+	}
+	if m.status != "" {
+		prefix = append(prefix, lipgloss.NewStyle().Foreground(colorYellow).Render(m.status))
+	}
+	if m.diagnosing {
+		prefix = append(prefix, m.spinner.View()+lipgloss.NewStyle().Foreground(colorPurple).Render("diagnosing")+
+			lipgloss.NewStyle().Foreground(colorDim).Render(" ("+m.diagEstimate.Short()+")"))
+	}
+	if m.searching {
+		keys = []struct{ key, desc string }{{"enter", "apply"}, {"esc", "clear"}}
+	} else if m.diagnosing {
+		keys = append([]struct{ key, desc string }{{"esc", "cancel diagnosis"}}, keys...)
+	} else if m.filter != "" {
+		keys = append([]struct{ key, desc string }{{"esc", "clear search"}}, keys...)
+	}
+
+	// Keys that don't fit on one line are left out, from the end.
+	footer := strings.Join(prefix, "    ")
+	for _, k := range keys {
+		part := footerKeyStyle.Render("["+k.key+"]") + " " + k.desc
+		next := part
+		if footer != "" {
+			next = footer + "  " + part
+		}
+		if lipgloss.Width(next) > m.width-2 {
+			break
+		}
+		footer = next
+	}
+	return footerStyle.Width(m.width).Render(footer)
+}
+
+func (m *model) viewDiagnosis() string {
+	if m.diagnosing {
+		content := diagnosisTitleStyle.Render("AI DIAGNOSIS") + "\n\n" +
+			m.spinner.View() + " Analyzing codebase...\n\n" +
+			lipgloss.NewStyle().Foreground(colorDim).Render(m.diagEstimate.String())
+
+		style := diagnosisStyle.Width(m.width - 8).Height(m.height - 6)
+		return lipgloss.Place(m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			style.Render(content),
+		)
+	}
+
+	footer := footerKeyStyle.Render("[↑/↓]") + " scroll  " + footerKeyStyle.Render("[c]") + " copy  " + footerKeyStyle.Render("[esc]") + " close"
+	if m.status != "" {
+		footer += "    " + lipgloss.NewStyle().Foreground(colorYellow).Render(m.status)
+	}
+	position := ""
+	if !m.diagnosisView.AtTop() || !m.diagnosisView.AtBottom() {
+		position = lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  %.0f%%", m.diagnosisView.ScrollPercent()*100))
+	}
+	content := diagnosisTitleStyle.Render("◆ AI DIAGNOSIS") + position + "\n\n" +
+		m.diagnosisView.View() + "\n\n" + footer
+
+	style := diagnosisStyle.Width(m.width - 8).Height(m.height - 6)
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		style.Render(content),
+	)
+}
+
+// layoutDiagnosis fits the diagnosis viewport inside the overlay box,
+// rendering the diagnosis to its width.
+func (m *model) layoutDiagnosis() {
+	if m.diagnosisText == "" {
+		return
+	}
+	// The box is m.width-8 by m.height-6 including its padding; the title
+	// and footer take two lines each.
+	m.diagnosisView.Width = max(1, m.width-12)
+	m.diagnosisView.Height = max(1, m.height-12)
+	m.diagnosisView.SetContent(m.diagnosisFrom + "\n\n" + renderMarkdown(m.diagnosisText, m.diagnosisView.Width))
+}
+
+// copyToClipboard puts text on the clipboard with the platform's clipboard
+// command, or else an OSC 52 escape sequence, which most terminals honor,
+// also over SSH.
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		commands := [][]string{{"pbcopy"}, {"clip.exe"}}
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			commands = append(commands, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			commands = append(commands, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+		}
+		for _, c := range commands {
+			if _, err := exec.LookPath(c[0]); err != nil {
+				continue
+			}
+			cmd := exec.Command(c[0], c[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			return copiedMsg{err: cmd.Run()}
+		}
+		_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+		return copiedMsg{err: err}
+	}
+}
+
+// viewExplain lists what each category's score lost points to, scrolled to
+// explainOffset.
+func (m *model) viewExplain() string {
+	lines := explanationLines(m.scorer.Explain(m.results), 0)
+	return m.viewScrolled(fmt.Sprintf("◆ SCORE BREAKDOWN  %.0f/100", m.score.Total), lines, &m.explainOffset)
+}
+
+// viewDetail lists everything behind the focused panel, which the panel
+// itself truncates, scrolled to detailOffset.
+func (m *model) viewDetail() string {
+	title, lines := m.detailLines()
+	if len(lines) == 0 {
+		lines = []string{lipgloss.NewStyle().Foreground(colorDim).Render("Nothing to show")}
+	}
+	return m.viewScrolled("◆ "+title, lines, &m.detailOffset)
+}
+
+// visibleLines is how many lines of a scrolled view fit on screen.
+func (m *model) visibleLines() int {
+	return max(1, m.height-12)
+}
+
+// scroll moves a scrolled view's offset for key. Views clamp the result to
+// their length when rendering.
+func (m *model) scroll(offset int, key string) int {
+	switch key {
+	case "down", "j":
+		offset++
+	case "up", "k":
+		offset--
+	case "pgdown", " ":
+		offset += m.visibleLines()
+	case "pgup":
+		offset -= m.visibleLines()
+	case "home", "g":
+		offset = 0
+	case "end", "G":
+		offset = math.MaxInt32
+	}
+	return max(0, offset)
+}
+
+// viewScrolled renders lines in a full-screen box, showing as many as fit
+// from *offset, which it clamps so the last page stays full.
+func (m *model) viewScrolled(title string, lines []string, offset *int) string {
+	visible := m.visibleLines()
+	*offset = min(*offset, max(0, len(lines)-visible))
+	end := min(len(lines), *offset+visible)
+
+	position := ""
+	if len(lines) > visible {
+		position = lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("  %d–%d of %d", *offset+1, end, len(lines)))
+	}
+	content := diagnosisTitleStyle.Render(title) + position + "\n\n" +
+		strings.Join(lines[*offset:end], "\n") +
+		"\n\n" + footerKeyStyle.Render("[↑/↓]") + " scroll  " + footerKeyStyle.Render("[esc]") + " close"
+
+	style := diagnosisStyle.Width(m.width - 8).Height(m.height - 6)
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		style.Render(content),
+	)
+}
+
+// explanationLines renders a score breakdown: each category with the points
+// it costs the total, then its penalties with what fixing each is worth.
+// A positive limit caps the penalties listed per category.
+func explanationLines(exps []health.Explanation, limit int) []string {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	var lines []string
+	for _, e := range exps {
+		lines = append(lines, fmt.Sprintf("%-16s %s  %s",
+			e.Category,
+			scoreStyle(e.Score).Render(fmt.Sprintf("%3.0f/100", e.Score)),
+			dim.Render(fmt.Sprintf("%.0f%% of total, costs %.1f points", e.Weight*100, e.Lost()))))
+		for i, p := range e.Penalties {
+			if limit > 0 && i == limit {
+				lines = append(lines, fmt.Sprintf("  … and %d more", len(e.Penalties)-limit))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s %s",
+				lipgloss.NewStyle().Foreground(colorYellow).Render(fmt.Sprintf("%6s", fmt.Sprintf("−%.1f", p.Points))), p.Item,
+				dim.Render(fmt.Sprintf("(fixing: +%.1f)", p.Worth))))
+		}
+	}
+	return lines
+}
+
+// PrintExplanation prints the score breakdown for `drift report --explain`.
+func PrintExplanation(exps []health.Explanation) {
+	fmt.Println(panelTitleStyle.Render("  SCORE BREAKDOWN"))
+	for _, line := range explanationLines(exps, 10) {
+		fmt.Println("    " + line)
+	}
+	fmt.Println()
+}
+
+// gitPollInterval is how often the dashboard checks whether HEAD moved,
+// which the watcher can't see since .git is excluded.
+const gitPollInterval = 2 * time.Second
+
+func (m *model) checkHead() tea.Cmd {
+	return func() tea.Msg {
+		repo, err := history.New(m.cfg)
+		if err != nil {
+			return gitHeadMsg{err: err}
+		}
+		head, err := repo.Head()
+		return gitHeadMsg{head: head, err: err}
+	}
+}
+
+func (m *model) checkDirty() tea.Cmd {
+	return func() tea.Msg {
+		repo, err := history.New(m.cfg)
+		if err != nil {
+			return nil
+		}
+		dirty, err := repo.Dirty()
+		if err != nil {
+			return nil
+		}
+		return gitDirtyMsg{dirty: dirty}
+	}
+}
+
+// headLabel names the checked-out branch, or the commit when detached.
+func headLabel(h history.Head) string {
+	if h.Branch == "" {
+		return h.Hash + " (detached)"
+	}
+	return h.Branch + " @ " + h.Hash
+}
+
+// viewGit shows the branch, last commit, and uncommitted changes.
+func (m *model) viewGit() string {
+	if m.head == nil {
+		return ""
+	}
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	out := lipgloss.NewStyle().Foreground(colorPurple).Render("⎇ "+headLabel(*m.head)) + " " + dim.Render(truncate(m.head.Subject, 24))
+	if m.dirty > 0 {
+		out += dim.Render(" · ") + lipgloss.NewStyle().Foreground(colorYellow).Render(fmt.Sprintf("%d uncommitted", m.dirty))
+	}
+	return out
+}
+
+func (m *model) listenForErrors() tea.Cmd {
+	if m.watch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return watchErrorMsg{err: <-m.watch.Errors, timestamp: time.Now()}
+	}
+}
+
+// logFile is where the dashboard appends its notices, relative to the
+// analysis root, next to the score history.
+const logFile = ".drift/drift.log"
+
+// logNotice appends text to logFile, once per run of the dashboard. The log
+// is a convenience, so failing to write it is ignored.
+func (m *model) logNotice(t time.Time, text string) {
+	if m.logged[text] {
+		return
+	}
+	m.logged[text] = true
+	path := filepath.Join(m.cfg.Root, logFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", t.Format(time.RFC3339), text)
+}
+
+func (m *model) listenForChanges() tea.Cmd {
+	return func() tea.Msg {
+		if m.watch == nil {
+			select {}
+		}
+		return fileChangedMsg{batch: <-m.watch.Events}
+	}
+}
+
+// runAnalysis re-analyzes everything, showing its progress in the header.
+func (m *model) runAnalysis() tea.Cmd {
+	m.analyzing = true
+	m.progress = analyzer.Progress{Phase: "analyzing"}
+	return func() tea.Msg {
+		results, err := m.ana.Run()
+		return analysisCompleteMsg{results: results, err: err}
+	}
+}
+
+func (m *model) listenForProgress() tea.Cmd {
+	return func() tea.Msg {
+		return progressMsg(<-m.progressCh)
+	}
+}
+
+// togglePause pauses or resumes automatic re-analysis. Resuming catches up
+// on files changed while paused with a full analysis, since a branch switch
+// or refactor tends to touch more than single-file updates handle well.
+func (m *model) togglePause() tea.Cmd {
+	m.paused = !m.paused
+	if m.paused {
+		m.status = "paused: changes wait until [p]"
+		return nil
+	}
+	m.status = "resumed"
+	if len(m.pending) == 0 {
+		return nil
+	}
+	m.status = fmt.Sprintf("resumed: %d changed while paused, re-analyzing", len(m.pending))
+	clear(m.pending)
+	return m.runAnalysis()
+}
+
+// refreshTick schedules the next timed refresh, if there is an interval.
+func (m *model) refreshTick() tea.Cmd {
+	if m.refresh <= 0 {
+		return nil
+	}
+	return tea.Tick(m.refresh, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+// watchState describes how the dashboard keeps up with changes, or is
+// empty while it watches files as usual.
+func (m *model) watchState() string {
+	switch {
+	case m.paused && len(m.pending) > 0:
+		return fmt.Sprintf("⏸ paused · %d changed", len(m.pending))
+	case m.paused:
+		return "⏸ paused"
+	case m.watch != nil && m.watch.Polling() > 0:
+		return "polling every " + m.watch.Polling().String()
+	case m.watch == nil && m.refresh > 0:
+		return "refreshing every " + m.refresh.String()
+	case m.watch == nil:
+		return "not watching"
+	}
+	return ""
+}
+
+// runSingle re-analyzes just the changed file so large repositories stay
+// responsive; press r for a full analysis.
+func (m *model) runSingle(path string) tea.Cmd {
+	return func() tea.Msg {
+		single, err := m.ana.RunSingle(path)
+		if err != nil {
+			return nil
+		}
+		return fileAnalyzedMsg{path: path, single: single}
+	}
+}
+
+// animateToScore starts the score animation toward the current score.
+func (m *model) animateToScore() tea.Cmd {
+	m.targetScore = m.score.Total
+	if m.displayScore == m.targetScore || m.animating {
+		return nil
+	}
+	m.animating = true
+	return m.animateTick()
+}
+
+// startDiagnosis marks a diagnosis of prompt in flight and returns the
+// context its request runs under, canceled by cancelDiagnosis.
+func (m *model) startDiagnosis(prompt string) context.Context {
+	m.diagnosing = true
+	m.diagEstimate = ai.EstimatePrompt(m.cfg.AI, prompt)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelDiag = cancel
+	return ctx
+}
+
+// cancelDiagnosis aborts the diagnosis in flight.
+func (m *model) cancelDiagnosis() {
+	if m.cancelDiag != nil {
+		m.cancelDiag()
+		m.cancelDiag = nil
+	}
+	m.diagnosing = false
+}
+
+// runItemDiagnosis asks the AI provider about a single panel row: for a
+// function, a refactoring plan from its full source; for a boundary
+// violation, how to remove the import. Without a provider there is nothing
+// to add to the row itself, so it just repeats the finding.
+func (m *model) runItemDiagnosis(item panelItem) tea.Cmd {
+	var prompt string
+	switch issue := item.issue.(type) {
+	case analyzer.FunctionComplexity:
+		prompt = ai.BuildFunctionPrompt(m.cfg, issue)
+	case analyzer.BoundaryViolation:
+		prompt = ai.BuildViolationPrompt(m.cfg, issue, item.path)
+	default:
+		prompt = ai.BuildFindingPrompt(m.cfg, item.finding, item.path, item.line)
+	}
+	ctx := m.startDiagnosis(prompt)
+	return func() tea.Msg {
+		result, err := ai.Ask(ctx, m.cfg, "dashboard", prompt)
+		if ctx.Err() != nil {
+			// Canceled with esc; the dashboard has moved on.
+			return nil
+		}
+		if err != nil {
+			from := lipgloss.NewStyle().Foreground(colorDim).Render("(AI unavailable: " + err.Error() + ")")
+			return diagnosisCompleteMsg{from: from, text: item.finding}
+		}
+		return diagnosisCompleteMsg{from: lipgloss.NewStyle().Foreground(colorPurple).Render("Powered by " + m.cfg.AI.Provider), text: result}
+	}
+}
+
+func (m *model) runDiagnosis() tea.Cmd {
+	prompt := ai.BuildDiagnosisPrompt(m.cfg, m.score, m.results)
+	ctx := m.startDiagnosis(prompt)
+	return func() tea.Msg {
+		result, err := ai.Ask(ctx, m.cfg, "dashboard", prompt)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			from := lipgloss.NewStyle().Foreground(colorDim).Render("(AI unavailable, showing local analysis)")
+			return diagnosisCompleteMsg{from: from, text: buildDiagnosisText(m.score, m.results, m.cfg)}
+		}
+		return diagnosisCompleteMsg{from: lipgloss.NewStyle().Foreground(colorPurple).Render("Powered by " + m.cfg.AI.Provider), text: result}
+	}
+}
+
+func (m *model) animateTick() tea.Cmd {
+	return tea.Tick(16*time.Millisecond, func(t time.Time) tea.Msg {
+		return animateTickMsg{}
 	})
 }
 
 func (m *model) loadHistory() tea.Cmd {
 	return func() tea.Msg {
+		// Recorded runs are cheap to read; re-analyzing past commits is the
+		// fallback until there are enough of them to draw a trend, or when
+		// the history config asks for particular commits.
+		records, _ := history.Load(m.cfg.Root)
+
 		histAna, err := history.New(m.cfg)
 		if err != nil {
 			// Not a git repo or error loading, skip history
-			return historyCompleteMsg{data: &history.SparklineData{}}
+			return historyCompleteMsg{data: history.Sparklines(records, m.cfg.History.Depth)}
 		}
 
-		data, err := histAna.Walk(10)
-		if err != nil {
+		var data *history.SparklineData
+		if len(records) >= 2 && !m.cfg.History.Sampled() {
+			data = history.Sparklines(records, m.cfg.History.Depth)
+		} else if data, err = histAna.Walk(); err != nil {
 			// Error walking commits, skip history
 			return historyCompleteMsg{data: &history.SparklineData{}}
 		}
 
-		return historyCompleteMsg{data: data}
+		// Churn is best-effort; the hotspot panel stays empty without it.
+		churn, _ := histAna.Churn(100)
+
+		return historyCompleteMsg{data: data, churn: churn}
 	}
 }
 
@@ -660,10 +2287,33 @@ func buildDiagnosisText(score health.Score, results *analyzer.Results, cfg *conf
 		lines = append(lines, "")
 	}
 
+	if len(results.Vulnerabilities) > 0 {
+		lines = append(lines, fmt.Sprintf("Vulnerabilities: %d known advisory(ies) affect dependencies", len(results.Vulnerabilities)))
+		for _, v := range results.Vulnerabilities {
+			if v.Severity == "critical" || v.Severity == "high" {
+				lines = append(lines, fmt.Sprintf("  · %s %s: %s (%s)", v.Module, v.Version, v.Label(), v.Severity))
+			}
+		}
+		lines = append(lines, "")
+	}
+
 	if len(results.Violations) > 0 {
 		lines = append(lines, fmt.Sprintf("Boundary Violations: %d import(s) cross architectural boundaries", len(results.Violations)))
 		for _, v := range results.Violations {
-			lines = append(lines, fmt.Sprintf("  · %s imports from %s (%s:%d)", v.From, v.To, v.File, v.Line))
+			lines = append(lines, fmt.Sprintf("  · %s%s imports from %s (%s:%d)", ruleTag(v), v.From, v.To, v.File, v.Line))
+			if v.Description != "" {
+				lines = append(lines, "    "+v.Description)
+			}
+		}
+	}
+	if n := len(results.SuppressedViolations); n > 0 {
+		lines = append(lines, fmt.Sprintf("Suppressed: %d boundary violation(s) silenced with drift:ignore comments (not scored)", n))
+	}
+
+	if len(results.Cycles) > 0 {
+		lines = append(lines, fmt.Sprintf("Import Cycles: %d loop(s) in the package graph", len(results.Cycles)))
+		for _, c := range results.Cycles {
+			lines = append(lines, fmt.Sprintf("  · %s", c))
 		}
 	}
 
@@ -674,6 +2324,48 @@ func buildDiagnosisText(score health.Score, results *analyzer.Results, cfg *conf
 	return strings.Join(lines, "\n")
 }
 
+// langTag is a short prefix that marks an entry's language in multi-language mode.
+func langTag(l analyzer.Language) string {
+	tag := string(l)
+	if len(tag) > 2 {
+		tag = tag[:2]
+	}
+	return tag + " "
+}
+
+// severityIcon marks critical and high vulnerabilities as failures and the
+// rest as warnings.
+func severityIcon(severity string) string {
+	if severity == "critical" || severity == "high" {
+		return statusBad.String()
+	}
+	return statusWarn.String()
+}
+
+// behindBadge renders how far a dependency trails its latest release:
+// majors in red, minors in yellow, patches dimmed.
+func behindBadge(dep analyzer.DepStatus) string {
+	color := colorDim
+	switch dep.Behind {
+	case "":
+		return ""
+	case "major":
+		color = colorRed
+	case "minor":
+		color = colorYellow
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(dep.BehindLabel())
+}
+
+// ruleTag prefixes a boundary violation with the name of the rule it
+// breaks, e.g. "[domain-is-pure] ", or nothing for unnamed rules.
+func ruleTag(v analyzer.BoundaryViolation) string {
+	if v.Rule == "" {
+		return ""
+	}
+	return "[" + v.Rule + "] "
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -688,14 +2380,48 @@ func abs(f float64) float64 {
 	return f
 }
 
-func PrintReport(cfg *config.Config, score health.Score, results *analyzer.Results) {
+func PrintReport(cfg *config.Config, score health.Score, results *analyzer.Results, ref string) {
 	fmt.Println()
 	fmt.Println(logoStyle.Render("◆ DRIFT REPORT"))
+	if ref != "" {
+		fmt.Println(lipgloss.NewStyle().Foreground(colorDim).Render("  at " + ref))
+	}
 	fmt.Println()
 
-	fmt.Printf("  Health Score: %s\n", scoreStyle(score.Total).Render(fmt.Sprintf("%.0f/100", score.Total)))
+	var delta string
+	if score.Delta > 0 {
+		delta = scoreDeltaUpStyle.Render(fmt.Sprintf("  ▲ +%.1f since last run", score.Delta))
+	} else if score.Delta < 0 {
+		delta = scoreDeltaDownStyle.Render(fmt.Sprintf("  ▼ %.1f since last run", score.Delta))
+	}
+	fmt.Printf("  Health Score: %s%s\n", scoreStyle(score.Total).Render(fmt.Sprintf("%.0f/100  %s", score.Total, score.Grade())), delta)
+	fmt.Printf("  Language:     %s\n", results.LanguageLabel())
+	if score.Goal != nil {
+		fmt.Printf("  Goal:         %s\n", goalText(*score.Goal, time.Now()))
+	}
+	if score.CoverageMeasured {
+		fmt.Printf("  Coverage:     %.1f%%\n", score.Coverage)
+	} else {
+		fmt.Printf("  Coverage:     %s\n", lipgloss.NewStyle().Foreground(colorDim).Render("no report (excluded from score)"))
+	}
+	if results.Baselined > 0 {
+		fmt.Printf("  Baseline:     %s\n", lipgloss.NewStyle().Foreground(colorDim).Render(
+			fmt.Sprintf("%d existing issues grandfathered by %s", results.Baselined, analyzer.BaselineFile)))
+	}
 	fmt.Println()
 
+	if len(results.Projects) > 0 {
+		fmt.Println(panelTitleStyle.Render("  PROJECTS"))
+		for _, p := range results.Projects {
+			ps := health.NewScorer(cfg).Calculate(p.Results).Total
+			fmt.Printf("    %-24s %-10s %s  (%d files, %d functions)\n",
+				p.Project.Name, p.Project.Language,
+				scoreStyle(ps).Render(fmt.Sprintf("%.0f/100", ps)),
+				p.Results.FileCount, p.Results.FuncCount)
+		}
+		fmt.Println()
+	}
+
 	fmt.Println(panelTitleStyle.Render("  COMPLEXITY"))
 	count := 10
 	if len(results.Complexity) < count {
@@ -703,7 +2429,7 @@ func PrintReport(cfg *config.Config, score health.Score, results *analyzer.Resul
 	}
 	for i := 0; i < count; i++ {
 		fc := results.Complexity[i]
-		fmt.Printf("    %s %s:%d %s() — complexity %d\n",
+		fmt.Printf("    %s %s:%d %s() — complexity %d, %d lines, %d params, nesting %d\n",
 			func() string {
 				if fc.Complexity > 20 {
 					return statusBad.String()
@@ -712,50 +2438,498 @@ func PrintReport(cfg *config.Config, score health.Score, results *analyzer.Resul
 				}
 				return statusOK.String()
 			}(),
-			fc.File, fc.Line, fc.Name, fc.Complexity)
+			langPrefixed(results, fc.Language, fc.File), fc.Line, fc.Name, fc.Complexity, fc.Lines, fc.Params, fc.Nesting)
 	}
 	fmt.Println()
 
+	if oversized := oversizedFunctions(cfg, results.Complexity); len(oversized) > 0 {
+		fmt.Println(panelTitleStyle.Render("  FUNCTION SIZE"))
+		for i, fc := range oversized {
+			if i == 10 {
+				fmt.Printf("    … and %d more\n", len(oversized)-10)
+				break
+			}
+			t := cfg.Thresholds.For(fc.Path)
+			fmt.Printf("    %s %s:%d %s() — %d lines (max %d), %d params (max %d)\n",
+				statusWarn.String(), fc.File, fc.Line, fc.Name,
+				fc.Lines, t.MaxFuncLines, fc.Params, t.MaxParams)
+		}
+		fmt.Println()
+	}
+
+	if nested := deeplyNested(cfg, results.Complexity); len(nested) > 0 {
+		fmt.Println(panelTitleStyle.Render("  NESTING"))
+		for i, fc := range nested {
+			if i == 10 {
+				fmt.Printf("    … and %d more\n", len(nested)-10)
+				break
+			}
+			fmt.Printf("    %s %s:%d %s() — nesting depth %d (max %d)\n",
+				statusWarn.String(), fc.File, fc.Line, fc.Name, fc.Nesting, cfg.Thresholds.For(fc.Path).MaxNesting)
+		}
+		fmt.Println()
+	}
+
+	if god := analyzer.GodFiles(results.Files, cfg.Thresholds.MaxFileLines); len(god) > 0 {
+		fmt.Println(panelTitleStyle.Render("  GOD FILES"))
+		for _, f := range god {
+			fmt.Printf("    %s %s — %d lines, %d functions, avg complexity %.1f\n",
+				statusWarn.String(), f.Path, f.Lines, f.Functions, f.AvgComplexity)
+		}
+		fmt.Println()
+	}
+
+	if god := analyzer.GodObjects(results.Types, cfg.Thresholds.MaxStructFields, cfg.Thresholds.MaxMethods); len(god) > 0 {
+		fmt.Println(panelTitleStyle.Render("  GOD OBJECTS"))
+		for _, ts := range god {
+			fmt.Printf("    %s %s (%s:%d) — %d fields, %d methods\n",
+				statusWarn.String(), ts.Name, ts.Path, ts.Line, ts.Fields, ts.Methods)
+		}
+		fmt.Println()
+	}
+
+	if mi, ok := results.Maintainability(); ok {
+		fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  MAINTAINABILITY  %.0f/100", mi)))
+		for _, f := range analyzer.LeastMaintainable(results.Files, 5) {
+			icon := statusOK.String()
+			if f.Maintainability < 10 {
+				icon = statusBad.String()
+			} else if f.Maintainability < 20 {
+				icon = statusWarn.String()
+			}
+			fmt.Printf("    %s %s — index %.0f (%d lines, avg complexity %.1f)\n",
+				icon, f.Path, f.Maintainability, f.Lines, f.AvgComplexity)
+		}
+		fmt.Println()
+	}
+
+	if len(results.Duplicates) > 0 {
+		fmt.Println(panelTitleStyle.Render("  DUPLICATION"))
+		for i, d := range results.Duplicates {
+			if i == 10 {
+				fmt.Printf("    … and %d more\n", len(results.Duplicates)-10)
+				break
+			}
+			var locs []string
+			for _, l := range d.Locations {
+				locs = append(locs, fmt.Sprintf("%s:%d", l.Path, l.Line))
+			}
+			fmt.Printf("    %s %d lines in %s\n", statusWarn.String(), d.Lines, strings.Join(locs, ", "))
+		}
+		fmt.Println()
+	}
+
+	runtime, dev := splitDeps(results.Dependencies)
 	fmt.Println(panelTitleStyle.Render("  DEPENDENCIES"))
-	for _, dep := range results.Dependencies {
+	printDeps(results, runtime)
+	fmt.Println()
+	if len(dev) > 0 {
+		fmt.Println(panelTitleStyle.Render("  DEV DEPENDENCIES"))
+		printDeps(results, dev)
+		fmt.Println()
+	}
+
+	if heavy := analyzer.HeaviestDependencies(results.Dependencies, 10); len(heavy) > 0 {
+		fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  HEAVIEST DEPENDENCIES  (%d transitive packages)", results.TransitiveDeps)))
+		for _, d := range heavy {
+			fmt.Printf("    %-30s +%d packages, %d levels deep\n", langPrefixed(results, d.Language, d.Module), d.Transitive, d.Depth)
+		}
+		fmt.Println()
+	}
+
+	if len(results.Vulnerabilities) > 0 {
+		fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  SECURITY  %.0f/100", score.Security)))
+		for _, v := range results.Vulnerabilities {
+			fmt.Printf("    %s %-8s %s %s  %s\n", severityIcon(v.Severity), v.Severity,
+				langPrefixed(results, v.Language, v.Module+"@"+v.Version), v.Label(), v.Summary)
+		}
+		fmt.Println()
+	}
+
+	if len(results.LicenseViolations) > 0 {
+		fmt.Println(panelTitleStyle.Render("  LICENSES"))
+		for _, v := range results.LicenseViolations {
+			fmt.Printf("    %s %s %s (denied: %s)\n", statusBad.String(),
+				langPrefixed(results, v.Language, v.Module+"@"+v.Version), v.License, v.Denied)
+		}
+		fmt.Println()
+	}
+
+	if len(results.Violations) > 0 {
+		fmt.Println(panelTitleStyle.Render("  BOUNDARY VIOLATIONS"))
+		for _, v := range results.Violations {
+			fmt.Printf("    %s %s%s → %s (%s:%d)\n", statusBad.String(), ruleTag(v), v.From, v.To, v.File, v.Line)
+			if v.Description != "" {
+				fmt.Println(lipgloss.NewStyle().Foreground(colorDim).Render("      " + v.Description))
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(results.SuppressedViolations) > 0 {
+		fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  SUPPRESSED BOUNDARY VIOLATIONS (%d)", len(results.SuppressedViolations))))
+		for _, v := range results.SuppressedViolations {
+			fmt.Println(lipgloss.NewStyle().Foreground(colorDim).Render(
+				fmt.Sprintf("    %s%s → %s (%s:%d)", ruleTag(v), v.From, v.To, v.File, v.Line)))
+		}
+		fmt.Println()
+	}
+
+	if len(results.Cycles) > 0 {
+		fmt.Println(panelTitleStyle.Render("  IMPORT CYCLES"))
+		for _, c := range results.Cycles {
+			fmt.Printf("    %s %s\n", statusBad.String(), c)
+		}
+		fmt.Println()
+	}
+
+	if unstable := analyzer.UnstableDependencies(results.Coupling); len(unstable) > 0 {
+		fmt.Println(panelTitleStyle.Render("  COUPLING  (unstable packages others depend on)"))
+		for i, pc := range unstable {
+			if i == 10 {
+				fmt.Printf("    … and %d more\n", len(unstable)-10)
+				break
+			}
+			fmt.Printf("    %s %-32s Ca %2d  Ce %2d  instability %.2f\n",
+				statusWarn.String(), langPrefixed(results, pc.Language, pc.Package), pc.Afferent, pc.Efferent, pc.Instability)
+		}
+		fmt.Println()
+	}
+
+	if len(results.DeadCode) > 0 {
+		groups := analyzer.DeadCodeByKind(results.DeadCode)
+		var counts []string
+		for _, kind := range analyzer.DeadCodeKinds {
+			if n := len(groups[kind]); n == 1 {
+				counts = append(counts, "1 "+kind)
+			} else if n > 1 {
+				counts = append(counts, fmt.Sprintf("%d %ss", n, kind))
+			}
+		}
+		fmt.Println(panelTitleStyle.Render("  DEAD CODE  (" + strings.Join(counts, ", ") + " never referenced)"))
+		for _, kind := range analyzer.DeadCodeKinds {
+			for i, d := range groups[kind] {
+				if i == 10 {
+					fmt.Printf("    … and %d more %ss\n", len(groups[kind])-10, kind)
+					break
+				}
+				fmt.Printf("    %s %-6s %s:%d %s\n", statusWarn.String(), kind, d.File, d.Line, d.Name)
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(results.OverExported) > 0 {
+		fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  OVER-EXPORTED  (%d exported identifiers used only in their own package)", len(results.OverExported))))
+		for i, o := range results.OverExported {
+			if i == 10 {
+				fmt.Printf("    … and %d more\n", len(results.OverExported)-10)
+				break
+			}
+			fmt.Printf("    %s %-8s %s:%d %s %s\n", statusWarn.String(), o.Kind, o.File, o.Line, o.Name,
+				lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("(%s, %d uses)", o.Package, o.Uses)))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  TESTS  %.2f test files per source file, %d test functions",
+		results.TestRatio(), results.TestFuncCount())))
+	untested := analyzer.UntestedPackages(results.Tests)
+	if len(untested) == 0 {
+		fmt.Printf("    %s Every package has tests\n", statusOK.String())
+	}
+	for i, pt := range untested {
+		if i == 10 {
+			fmt.Printf("    … and %d more untested packages\n", len(untested)-10)
+			break
+		}
+		fmt.Printf("    %s %s — %d source files, no tests\n", statusWarn.String(), langPrefixed(results, pt.Language, pt.Package), pt.SourceFiles)
+	}
+	fmt.Println()
+
+	fmt.Println(panelTitleStyle.Render(fmt.Sprintf("  DEBT MARKERS  (%.2f comment lines per line of code)", results.CommentRatio())))
+	if len(results.Debt) == 0 {
+		fmt.Printf("    %s No TODO/FIXME/HACK/XXX markers\n", statusOK.String())
+	}
+	for i, d := range results.Debt {
+		if i == 20 {
+			fmt.Printf("    … and %d more\n", len(results.Debt)-20)
+			break
+		}
+		icon := statusWarn.String()
+		if d.Weight() > 1 {
+			icon = statusBad.String()
+		}
+		fmt.Printf("    %s %s:%d %-5s %s\n", icon, d.Path, d.Line, d.Kind, d.Text)
+	}
+	fmt.Println()
+
+	if len(results.Naming) > 0 {
+		fmt.Println(panelTitleStyle.Render("  NAMING"))
+		for i, n := range results.Naming {
+			if i == 20 {
+				fmt.Printf("    … and %d more\n", len(results.Naming)-20)
+				break
+			}
+			fmt.Printf("    %s %s:%d %s\n", statusWarn.String(), langPrefixed(results, n.Language, n.File), n.Line, n.Name)
+		}
+		fmt.Println()
+	}
+
+	if len(results.Notices) > 0 {
+		fmt.Println(panelTitleStyle.Render("  NOTICES"))
+		for _, n := range results.Notices {
+			fmt.Printf("    %s %s\n", statusWarn.String(), n)
+		}
+		fmt.Println()
+	}
+}
+
+// oversizedFunctions returns functions over the configured length or
+// parameter-count limits.
+func printDeps(results *analyzer.Results, deps []analyzer.DepStatus) {
+	for _, dep := range deps {
 		icon := statusOK.String()
 		if dep.Status == "stale" {
 			icon = statusWarn.String()
 		} else if dep.Status == "outdated" {
 			icon = statusBad.String()
 		}
-		fmt.Printf("    %s %-20s %s → %s\n", icon, dep.Module, dep.CurrentVersion, dep.LatestVersion)
+		line := fmt.Sprintf("    %s %-20s %s → %s", icon, langPrefixed(results, dep.Language, dep.Module), dep.CurrentVersion, dep.LatestVersion)
+		if badge := behindBadge(dep); badge != "" {
+			line += "  " + badge
+		}
+		fmt.Println(line)
 	}
-	fmt.Println()
+}
 
-	if len(results.Violations) > 0 {
-		fmt.Println(panelTitleStyle.Render("  BOUNDARY VIOLATIONS"))
-		for _, v := range results.Violations {
-			fmt.Printf("    %s %s → %s (%s:%d)\n", statusBad.String(), v.From, v.To, v.File, v.Line)
+// deadCodeCounts tallies dead declarations per kind.
+func deadCodeCounts(dead []analyzer.DeadFunction) map[string]int {
+	counts := make(map[string]int)
+	for _, d := range dead {
+		counts[d.Kind]++
+	}
+	return counts
+}
+
+func devDepCount(deps []analyzer.DepStatus) int {
+	_, dev := splitDeps(deps)
+	return len(dev)
+}
+
+// splitDeps separates runtime dependencies from development and test ones,
+// keeping their order.
+func splitDeps(deps []analyzer.DepStatus) (runtime, dev []analyzer.DepStatus) {
+	for _, d := range deps {
+		if d.Scope == "dev" {
+			dev = append(dev, d)
+		} else {
+			runtime = append(runtime, d)
 		}
-		fmt.Println()
 	}
+	return runtime, dev
+}
+
+func oversizedFunctions(cfg *config.Config, funcs []analyzer.FunctionComplexity) []analyzer.FunctionComplexity {
+	var out []analyzer.FunctionComplexity
+	for _, fc := range funcs {
+		t := cfg.Thresholds.For(fc.Path)
+		if (t.MaxFuncLines > 0 && fc.Lines > t.MaxFuncLines) || (t.MaxParams > 0 && fc.Params > t.MaxParams) {
+			out = append(out, fc)
+		}
+	}
+	return out
+}
+
+// deeplyNested returns functions nested deeper than max_nesting, deepest
+// first.
+func deeplyNested(cfg *config.Config, funcs []analyzer.FunctionComplexity) []analyzer.FunctionComplexity {
+	var out []analyzer.FunctionComplexity
+	for _, fc := range funcs {
+		if limit := cfg.Thresholds.For(fc.Path).MaxNesting; limit > 0 && fc.Nesting > limit {
+			out = append(out, fc)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Nesting > out[j].Nesting })
+	return out
+}
+
+// langPrefixed prefixes name with its language in multi-language reports.
+func langPrefixed(results *analyzer.Results, lang analyzer.Language, name string) string {
+	if !results.MultiLanguage() {
+		return name
+	}
+	return "[" + string(lang) + "] " + name
+}
+
+// PrintHotspots prints the hotspot ranking produced by `drift hotspots`.
+func PrintHotspots(spots []history.Hotspot, commits int) {
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT HOTSPOTS"))
+	fmt.Println(lipgloss.NewStyle().Foreground(colorDim).Render(
+		fmt.Sprintf("  churn over the last %d commits × total cyclomatic complexity", commits)))
+	fmt.Println()
+
+	if len(spots) == 0 {
+		fmt.Printf("  %s No files are both complex and recently changed\n\n", statusOK.String())
+		return
+	}
+
+	fmt.Printf("  %-5s %-48s %7s %10s %6s\n", "SCORE", "FILE", "COMMITS", "COMPLEXITY", "LINES")
+	for _, s := range spots {
+		fmt.Printf("  %s %-48s %7d %10d %6d\n",
+			scoreStyle(100-s.Score).Render(fmt.Sprintf("%5.0f", s.Score)),
+			truncate(s.Path, 48), s.Commits, s.Complexity, s.Lines)
+	}
+	fmt.Println()
+}
+
+// PrintHotspotsJSON writes the hotspot ranking as JSON.
+func PrintHotspotsJSON(spots []history.Hotspot) error {
+	out := make([]map[string]interface{}, 0, len(spots))
+	for _, s := range spots {
+		out = append(out, map[string]interface{}{
+			"path":       s.Path,
+			"commits":    s.Commits,
+			"complexity": s.Complexity,
+			"lines":      s.Lines,
+			"score":      s.Score,
+		})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
 }
 
-func PrintSnapshot(score health.Score, results *analyzer.Results) error {
+// SnapshotVersion is the version of the `drift snapshot` JSON schema,
+// recorded in it as schema_version. It goes up when a field is removed or
+// changes meaning, not when one is added.
+const SnapshotVersion = 2
+
+// Entries of the snapshot's per-issue lists. Paths are relative to the
+// analysis root.
+type (
+	snapshotFunction struct {
+		Path       string `json:"path"`
+		Line       int    `json:"line"`
+		Name       string `json:"name"`
+		Language   string `json:"language"`
+		Complexity int    `json:"complexity"`
+		Lines      int    `json:"lines"`
+		Params     int    `json:"params"`
+		Nesting    int    `json:"nesting"`
+	}
+	snapshotDependency struct {
+		Module     string `json:"module"`
+		Path       string `json:"path,omitempty"` // the full identifier, when module is shortened
+		Language   string `json:"language"`
+		Scope      string `json:"scope"` // "runtime" or "dev"
+		Current    string `json:"current"`
+		Latest     string `json:"latest"`
+		Status     string `json:"status"`
+		StaleDays  int    `json:"stale_days"`
+		Behind     string `json:"behind,omitempty"`
+		Transitive int    `json:"transitive"`
+		License    string `json:"license,omitempty"`
+	}
+	snapshotViolation struct {
+		Path   string `json:"path"`
+		Line   int    `json:"line"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Import string `json:"import"`
+		Rule   string `json:"rule,omitempty"`
+	}
+	snapshotDeadCode struct {
+		Path string `json:"path"`
+		Line int    `json:"line"`
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+	}
+)
+
+// WriteSnapshot writes the JSON snapshot of `drift snapshot` to w.
+func WriteSnapshot(w io.Writer, cfg *config.Config, score health.Score, results *analyzer.Results) error {
+	functions := make([]snapshotFunction, 0, len(results.Complexity))
+	for _, fc := range results.Complexity {
+		functions = append(functions, snapshotFunction{fc.Path, fc.Line, fc.Name, string(fc.Language), fc.Complexity, fc.Lines, fc.Params, fc.Nesting})
+	}
+	deps := make([]snapshotDependency, 0, len(results.Dependencies))
+	for _, d := range results.Dependencies {
+		scope := d.Scope
+		if scope == "" {
+			scope = "runtime"
+		}
+		deps = append(deps, snapshotDependency{d.Module, d.Path, string(d.Language), scope, d.CurrentVersion, d.LatestVersion, d.Status, d.StaleDays, d.Behind, d.Transitive, d.License})
+	}
+	// Violations name just the file; the path is left as that when several
+	// files share the name.
+	path := func(name string) string {
+		if p := results.FindFile(name); p != "" {
+			return p
+		}
+		return name
+	}
+	violations := make([]snapshotViolation, 0, len(results.Violations))
+	for _, v := range results.Violations {
+		violations = append(violations, snapshotViolation{path(v.File), v.Line, v.From, v.To, v.Import, v.Rule})
+	}
+	dead := make([]snapshotDeadCode, 0, len(results.DeadCode))
+	for _, d := range results.DeadCode {
+		dead = append(dead, snapshotDeadCode{d.Path, d.Line, d.Name, d.Kind})
+	}
+
 	snapshot := map[string]interface{}{
-		"language": string(results.Language),
-		"score": map[string]interface{}{
-			"total":      score.Total,
-			"complexity": score.Complexity,
-			"deps":       score.Deps,
-			"boundaries": score.Boundaries,
-		},
+		"schema_version": SnapshotVersion,
+		"language":       string(results.Language),
+		"languages":      results.Languages,
+		"score":          score.Categories(),
+		"grade":          score.Grade(),
 		"summary": map[string]interface{}{
-			"files":      results.FileCount,
-			"functions":  results.FuncCount,
-			"violations": len(results.Violations),
-			"deps":       len(results.Dependencies),
+			"files":              results.FileCount,
+			"functions":          results.FuncCount,
+			"violations":         len(results.Violations),
+			"suppressed":         len(results.SuppressedViolations),
+			"cycles":             len(results.Cycles),
+			"naming_issues":      len(results.Naming),
+			"deps":               len(results.Dependencies),
+			"dev_deps":           devDepCount(results.Dependencies),
+			"transitive_deps":    results.TransitiveDeps,
+			"vulnerabilities":    len(results.Vulnerabilities),
+			"license_violations": len(results.LicenseViolations),
+			"dead_code":          deadCodeCounts(results.DeadCode),
+			"over_exported":      len(results.OverExported),
+			"god_files":          len(analyzer.GodFiles(results.Files, cfg.Thresholds.MaxFileLines)),
+			"god_objects":        len(analyzer.GodObjects(results.Types, cfg.Thresholds.MaxStructFields, cfg.Thresholds.MaxMethods)),
+			"duplicates":         len(results.Duplicates),
+			"debt_markers":       len(results.Debt),
+			"test_ratio":         results.TestRatio(),
+			"test_funcs":         results.TestFuncCount(),
+			"comment_ratio":      results.CommentRatio(),
 		},
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"timestamp":    time.Now().UTC().Format(time.RFC3339),
+		"functions":    functions,
+		"dependencies": deps,
+		"violations":   violations,
+		"dead_code":    dead,
 	}
 
-	enc := json.NewEncoder(os.Stdout)
+	if len(results.Projects) > 0 {
+		var projects []map[string]interface{}
+		for _, p := range results.Projects {
+			projects = append(projects, map[string]interface{}{
+				"name":      p.Project.Name,
+				"path":      p.Project.Path,
+				"language":  string(p.Project.Language),
+				"score":     health.NewScorer(cfg).Calculate(p.Results).Total,
+				"files":     p.Results.FileCount,
+				"functions": p.Results.FuncCount,
+			})
+		}
+		snapshot["projects"] = projects
+	}
+
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(snapshot)
 }
@@ -0,0 +1,285 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+// defaultCompareRef is what "c" compares the working tree against unless
+// --compare names another ref: the last commit.
+const defaultCompareRef = "HEAD"
+
+// comparison holds a git ref's analysis next to the working tree's.
+type comparison struct {
+	base  *analyzer.Results // with the baseline applied, like the results
+	score health.Score
+
+	// Issues the working tree has and the ref doesn't, and the reverse.
+	added, resolved []string
+}
+
+type compareCompleteMsg struct {
+	base *analyzer.Results
+	err  error
+}
+
+// CompareWith opens the dashboard on its compare view, comparing the
+// working tree against ref.
+func (m *model) CompareWith(ref string) {
+	m.compareRef = ref
+	m.showCompare = true
+}
+
+// openCompare shows the compare view, analyzing the ref unless it already
+// has been.
+func (m *model) openCompare() tea.Cmd {
+	m.showCompare = true
+	m.compareOffset = 0
+	if m.compare != nil || m.comparing {
+		return nil
+	}
+	return m.runCompare()
+}
+
+// runCompare analyzes the ref in the background.
+func (m *model) runCompare() tea.Cmd {
+	m.comparing = true
+	m.compareErr = nil
+	ref := m.compareRef
+	return func() tea.Msg {
+		repo, err := history.New(m.cfg)
+		if err != nil {
+			return compareCompleteMsg{err: err}
+		}
+		base, err := repo.AnalyzeRef(ref)
+		return compareCompleteMsg{base: base, err: err}
+	}
+}
+
+// setCompareBase scores the ref's analysis and compares it with the results.
+func (m *model) setCompareBase(base *analyzer.Results) {
+	if m.baseline != nil {
+		base = m.baseline.Apply(base, m.cfg.Thresholds)
+	}
+	m.compare = &comparison{base: base, score: health.NewScorer(m.cfg).Calculate(base)}
+	m.updateComparison()
+}
+
+// updateComparison lists the issues the results gained and lost against
+// the ref, after either changes.
+func (m *model) updateComparison() {
+	if m.compare == nil {
+		return
+	}
+	t := m.cfg.Thresholds
+	m.compare.added = issueLines(analyzer.NewIssues(m.compare.base, m.results, t), t)
+	m.compare.resolved = issueLines(analyzer.NewIssues(m.results, m.compare.base, t), t)
+}
+
+// issueLines describes the discrete issues in r, those a baseline can
+// grandfather, one per line.
+func issueLines(r *analyzer.Results, t config.ThresholdConfig) []string {
+	var lines []string
+	for _, fc := range r.Complexity {
+		if limits := exceededLimits(fc, t); len(limits) > 0 {
+			lines = append(lines, fmt.Sprintf("%s (%s) over %s", fc.Name, fc.Path, strings.Join(limits, ", ")))
+		}
+	}
+	for _, v := range r.Violations {
+		lines = append(lines, fmt.Sprintf("%s imports %s", v.File, v.Import))
+	}
+	for _, c := range r.Cycles {
+		lines = append(lines, "cycle "+strings.Join(c.Packages, " → "))
+	}
+	for _, d := range r.DeadCode {
+		lines = append(lines, fmt.Sprintf("unused %s (%s)", d.Name, d.File))
+	}
+	for _, d := range r.Duplicates {
+		paths := make([]string, len(d.Locations))
+		for i, loc := range d.Locations {
+			paths[i] = loc.Path
+		}
+		sort.Strings(paths)
+		lines = append(lines, fmt.Sprintf("%d duplicated lines in %s", d.Lines, strings.Join(slices.Compact(paths), ", ")))
+	}
+	for _, d := range r.Debt {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", d.Kind, d.Path, d.Text))
+	}
+	for _, n := range r.Naming {
+		lines = append(lines, fmt.Sprintf("naming %s (%s)", n.Name, n.File))
+	}
+	for _, v := range r.Vulnerabilities {
+		lines = append(lines, fmt.Sprintf("%s in %s", v.ID, v.Module))
+	}
+	for _, l := range r.LicenseViolations {
+		lines = append(lines, fmt.Sprintf("%s licensed %s", l.Module, l.License))
+	}
+	return lines
+}
+
+// viewCompare shows each category's score at the ref and in the working
+// tree, then the issues added and resolved side by side, scrolled to
+// compareOffset.
+func (m *model) viewCompare() string {
+	title := "◆ COMPARE  " + m.compareRef + " → working tree"
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	switch {
+	case m.comparing:
+		return m.viewScrolled(title, []string{m.spinner.View() + " Analyzing " + m.compareRef + "..."}, &m.compareOffset)
+	case m.compareErr != nil:
+		return m.viewScrolled(title, []string{lipgloss.NewStyle().Foreground(colorRed).Render(m.compareErr.Error())}, &m.compareOffset)
+	case m.compare == nil:
+		return m.viewScrolled(title, nil, &m.compareOffset)
+	}
+
+	before, after := m.compare.score.Categories(), m.score.Categories()
+	categories := sharedCategories(before, after)
+
+	lines := []string{dim.Render(fmt.Sprintf("%-16s %7s %7s %7s", "", truncate(m.compareRef, 7), "now", "Δ"))}
+	for _, c := range categories {
+		delta := after[c] - before[c]
+		deltaStyle := dim
+		if delta >= 0.5 {
+			deltaStyle = lipgloss.NewStyle().Foreground(colorGreen)
+		} else if delta <= -0.5 {
+			deltaStyle = lipgloss.NewStyle().Foreground(colorRed)
+		}
+		lines = append(lines, fmt.Sprintf("%-16s %s %s %s", c,
+			scoreStyle(before[c]).Render(fmt.Sprintf("%7.0f", before[c])),
+			scoreStyle(after[c]).Render(fmt.Sprintf("%7.0f", after[c])),
+			deltaStyle.Render(fmt.Sprintf("%+7.0f", delta))))
+	}
+	lines = append(lines, "")
+
+	added := issueColumn(fmt.Sprintf("NEW ISSUES (%d)", len(m.compare.added)), m.compare.added)
+	resolved := issueColumn(fmt.Sprintf("RESOLVED (%d)", len(m.compare.resolved)), m.compare.resolved)
+	// cell fits line i of a column in width, coloring its title.
+	cell := func(column []string, i, width int, color lipgloss.TerminalColor) string {
+		if i >= len(column) {
+			return ""
+		}
+		if i == 0 {
+			return lipgloss.NewStyle().Foreground(color).Bold(true).Render(column[0])
+		}
+		return truncate(column[i], width)
+	}
+	// The box's border and padding take 12 columns.
+	inner := m.width - 12
+	if m.width >= narrowWidth {
+		half := (inner - 2) / 2
+		for i := range max(len(added), len(resolved)) {
+			lines = append(lines, lipgloss.NewStyle().Width(half).Render(cell(added, i, half, colorRed))+"  "+cell(resolved, i, half, colorGreen))
+		}
+	} else {
+		for i := range added {
+			lines = append(lines, cell(added, i, inner, colorRed))
+		}
+		lines = append(lines, "")
+		for i := range resolved {
+			lines = append(lines, cell(resolved, i, inner, colorGreen))
+		}
+	}
+	return m.viewScrolled(title, lines, &m.compareOffset)
+}
+
+// sharedCategories lists the total, then the other categories scored both
+// before and after, sorted.
+func sharedCategories(before, after map[string]float64) []string {
+	categories := make([]string, 0, len(after))
+	for c := range after {
+		if _, ok := before[c]; ok && c != "total" {
+			categories = append(categories, c)
+		}
+	}
+	sort.Strings(categories)
+	return append([]string{"total"}, categories...)
+}
+
+// Comparison is the difference between the analyses of two revisions.
+type Comparison struct {
+	Base, Head      string // how they were named, e.g. "main"
+	Before, After   health.Score
+	Added, Resolved []string // issues Head has and Base doesn't, and the reverse
+}
+
+// Compare diffs the issues and scores of base and head, both with any
+// baseline already applied.
+func Compare(cfg *config.Config, baseName string, base *analyzer.Results, headName string, head *analyzer.Results) Comparison {
+	scorer := health.NewScorer(cfg)
+	t := cfg.Thresholds
+	return Comparison{
+		Base:     baseName,
+		Head:     headName,
+		Before:   scorer.Calculate(base),
+		After:    scorer.Calculate(head),
+		Added:    issueLines(analyzer.NewIssues(base, head, t), t),
+		Resolved: issueLines(analyzer.NewIssues(head, base, t), t),
+	}
+}
+
+// PrintComparison prints each category's score at both revisions and the
+// change, then every issue added and resolved.
+func PrintComparison(c Comparison) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT COMPARE  " + c.Base + " → " + c.Head))
+	fmt.Println()
+
+	before, after := c.Before.Categories(), c.After.Categories()
+	fmt.Println(dim.Render(fmt.Sprintf("  %-16s %9s %9s %7s", "", truncate(c.Base, 9), truncate(c.Head, 9), "Δ")))
+	for _, cat := range sharedCategories(before, after) {
+		delta := after[cat] - before[cat]
+		style := dim
+		if delta >= 0.05 {
+			style = scoreDeltaUpStyle
+		} else if delta <= -0.05 {
+			style = scoreDeltaDownStyle
+		}
+		fmt.Printf("  %-16s %s %s %s\n", cat,
+			scoreStyle(before[cat]).Render(fmt.Sprintf("%9.1f", before[cat])),
+			scoreStyle(after[cat]).Render(fmt.Sprintf("%9.1f", after[cat])),
+			style.Render(fmt.Sprintf("%+7.1f", delta)))
+	}
+	fmt.Printf("  %-16s %9s %9s\n", "grade", c.Before.Grade(), c.After.Grade())
+	fmt.Println()
+
+	for _, list := range []struct {
+		title  string
+		icon   string
+		issues []string
+	}{
+		{"NEW ISSUES", statusBad.String(), c.Added},
+		{"RESOLVED", statusOK.String(), c.Resolved},
+	} {
+		fmt.Printf("  %s (%d)\n", list.title, len(list.issues))
+		if len(list.issues) == 0 {
+			fmt.Println(dim.Render("    none"))
+		}
+		for _, issue := range list.issues {
+			fmt.Printf("    %s %s\n", list.icon, issue)
+		}
+		fmt.Println()
+	}
+}
+
+// issueColumn is a titled list of issues for the compare view.
+func issueColumn(title string, issues []string) []string {
+	lines := []string{title}
+	if len(issues) == 0 {
+		return append(lines, "  none")
+	}
+	for _, issue := range issues {
+		lines = append(lines, "  "+issue)
+	}
+	return lines
+}
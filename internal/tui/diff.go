@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PrintPatch prints a unified diff with its file headers in bold, hunk
+// headers in cyan, added lines in green and removed lines in red.
+func PrintPatch(diff string) {
+	bold := lipgloss.NewStyle().Bold(true)
+	hunk := lipgloss.NewStyle().Foreground(colorCyan)
+	added := lipgloss.NewStyle().Foreground(colorGreen)
+	removed := lipgloss.NewStyle().Foreground(colorRed)
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+
+	fmt.Println()
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			line = bold.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunk.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = added.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removed.Render(line)
+		default:
+			line = dim.Render(line)
+		}
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+}
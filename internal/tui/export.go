@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+// exportDir is where "x" writes reports, relative to the analysis root.
+const exportDir = "reports"
+
+type exportedMsg struct {
+	path string // without extension
+	err  error
+}
+
+// exportReport describes what the dashboard shows, for export.
+type exportReport struct {
+	project string
+	time    time.Time
+	summary string // language, files, and functions
+	filter  string
+	score   health.Score
+	// categories carry the overall scores; their penalties are those of
+	// the findings the dashboard lists, narrowed by any search.
+	categories []health.Explanation
+}
+
+// export writes the dashboard's current state to reports/ as a JSON
+// snapshot, like `drift snapshot`, and as Markdown and HTML reports listing
+// the findings behind each category score.
+func (m *model) export() tea.Cmd {
+	shown := m.scorer.Explain(m.shown())
+	penalties := make(map[string][]health.Penalty, len(shown))
+	for _, e := range shown {
+		penalties[e.Category] = e.Penalties
+	}
+	categories := m.scorer.Explain(m.results)
+	for i := range categories {
+		categories[i].Penalties = penalties[categories[i].Category]
+	}
+
+	report := exportReport{
+		project:    filepath.Base(m.cfg.Root),
+		time:       time.Now(),
+		summary:    fmt.Sprintf("%s · %d files · %d functions", m.results.LanguageLabel(), m.results.FileCount, m.results.FuncCount),
+		filter:     m.filter,
+		score:      m.score,
+		categories: categories,
+	}
+	cfg, score, results := m.cfg, m.score, m.results
+
+	return func() tea.Msg {
+		base := filepath.Join(cfg.Root, exportDir, "drift-"+report.time.Format("20060102-150405"))
+		if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+			return exportedMsg{err: err}
+		}
+
+		var snapshot bytes.Buffer
+		if err := WriteSnapshot(&snapshot, cfg, score, results); err != nil {
+			return exportedMsg{err: err}
+		}
+		files := map[string][]byte{
+			".json": snapshot.Bytes(),
+			".md":   report.markdown(),
+			".html": report.html(),
+		}
+		for ext, data := range files {
+			if err := os.WriteFile(base+ext, data, 0o644); err != nil {
+				return exportedMsg{err: err}
+			}
+		}
+		rel, err := filepath.Rel(cfg.Root, base)
+		if err != nil {
+			rel = base
+		}
+		return exportedMsg{path: rel}
+	}
+}
+
+func (r exportReport) title() string {
+	return "drift report: " + r.project
+}
+
+func (r exportReport) markdown() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.title())
+	fmt.Fprintf(&b, "%s · %s\n\n", r.time.Format("2006-01-02 15:04"), r.summary)
+	fmt.Fprintf(&b, "**Health score: %.0f/100 (%s)**\n\n", r.score.Total, r.score.Grade())
+	if r.filter != "" {
+		fmt.Fprintf(&b, "Findings are limited to those matching `%s`.\n\n", r.filter)
+	}
+
+	b.WriteString("| Category | Score | Weight | Points lost |\n|---|---:|---:|---:|\n")
+	for _, e := range r.categories {
+		fmt.Fprintf(&b, "| %s | %.0f | %.0f%% | %.1f |\n", e.Category, e.Score, e.Weight*100, e.Lost())
+	}
+
+	for _, e := range r.categories {
+		if len(e.Penalties) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", e.Category)
+		for _, p := range e.Penalties {
+			fmt.Fprintf(&b, "- %s (−%.1f, fixing: +%.1f)\n", p.Item, p.Points, p.Worth)
+		}
+	}
+	return []byte(b.String())
+}
+
+func (r exportReport) html() []byte {
+	esc := html.EscapeString
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #1c1c1c; }
+table { border-collapse: collapse; }
+th, td { padding: .25rem .75rem; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.muted { color: #6c6c6c; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p class="muted">%s · %s</p>
+<p><strong>Health score: %.0f/100 (%s)</strong></p>
+`, esc(r.title()), esc(r.title()), r.time.Format("2006-01-02 15:04"), esc(r.summary), r.score.Total, r.score.Grade())
+	if r.filter != "" {
+		fmt.Fprintf(&b, "<p>Findings are limited to those matching <code>%s</code>.</p>\n", esc(r.filter))
+	}
+
+	b.WriteString("<table>\n<tr><th>Category</th><th>Score</th><th>Weight</th><th>Points lost</th></tr>\n")
+	for _, e := range r.categories {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%.0f</td><td>%.0f%%</td><td>%.1f</td></tr>\n", esc(e.Category), e.Score, e.Weight*100, e.Lost())
+	}
+	b.WriteString("</table>\n")
+
+	for _, e := range r.categories {
+		if len(e.Penalties) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<h2>%s</h2>\n<ul>\n", esc(e.Category))
+		for _, p := range e.Penalties {
+			fmt.Fprintf(&b, "<li>%s <span class=\"muted\">(−%.1f, fixing: +%.1f)</span></li>\n", esc(p.Item), p.Points, p.Worth)
+		}
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+// PrintHistory lists the last limit recorded runs, newest first, with the
+// total score and its change from the run before.
+func PrintHistory(records []history.Record, limit int) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT HISTORY"))
+	fmt.Println(dim.Render(fmt.Sprintf("  %d runs recorded in %s", len(records), history.StoreFile)))
+	fmt.Println()
+
+	if len(records) == 0 {
+		fmt.Println("  No runs recorded yet; drift report, check, and snapshot record each run.")
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("  %4s  %-16s  %-8s  %-24s  %5s  %6s\n", "ID", "TIME", "COMMAND", "COMMIT", "SCORE", "Δ")
+	first := 0
+	if limit > 0 && len(records) > limit {
+		first = len(records) - limit
+	}
+	for i := len(records) - 1; i >= first; i-- {
+		rec := records[i]
+		total := rec.Score["total"]
+		delta := dim.Render(fmt.Sprintf("%6s", "–"))
+		if i > 0 {
+			d := total - records[i-1].Score["total"]
+			style := dim
+			if d >= 0.05 {
+				style = scoreDeltaUpStyle
+			} else if d <= -0.05 {
+				style = scoreDeltaDownStyle
+			}
+			delta = style.Render(fmt.Sprintf("%+6.1f", d))
+		}
+		fmt.Printf("  %4d  %-16s  %-8s  %-24s  %s  %s\n",
+			i+1, rec.Time.Local().Format("2006-01-02 15:04"), rec.Command, truncate(commitLabel(rec), 24),
+			scoreStyle(total).Render(fmt.Sprintf("%5.1f", total)), delta)
+	}
+	fmt.Println()
+}
+
+// PrintRecord shows every score and count of run id.
+func PrintRecord(id int, rec history.Record) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render(fmt.Sprintf("◆ DRIFT RUN %d", id)))
+	fmt.Println(dim.Render(fmt.Sprintf("  drift %s at %s on %s", rec.Command, rec.Time.Local().Format(time.DateTime), commitLabel(rec))))
+	fmt.Println()
+
+	fmt.Println("  SCORES")
+	fmt.Printf("  %-20s %s\n", "total", scoreStyle(rec.Score["total"]).Render(fmt.Sprintf("%5.1f", rec.Score["total"])))
+	for _, c := range sortedKeys(rec.Score) {
+		if c != "total" {
+			fmt.Printf("  %-20s %s\n", c, scoreStyle(rec.Score[c]).Render(fmt.Sprintf("%5.1f", rec.Score[c])))
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("  COUNTS")
+	for _, c := range sortedKeys(rec.Counts) {
+		fmt.Printf("  %-20s %5d\n", c, rec.Counts[c])
+	}
+	fmt.Printf("  %-20s %5.1f\n", "avg_complexity", rec.AvgComplexity)
+	fmt.Println()
+}
+
+// commitLabel names the commit a run analyzed, if it was in a git repository.
+func commitLabel(rec history.Record) string {
+	switch {
+	case rec.Commit == "":
+		return "–"
+	case rec.Branch == "":
+		return rec.Commit
+	}
+	return rec.Branch + " @ " + rec.Commit
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintBisection reports the commits after which metric regressed between
+// revisions from and to, with the value before and after each.
+func PrintBisection(b *history.Bisection, metric, from, to string) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	value := func(rec history.Record) string {
+		v, _, _ := history.Metric(rec, metric)
+		if v == math.Trunc(v) {
+			return fmt.Sprintf("%.0f", v)
+		}
+		return fmt.Sprintf("%.1f", v)
+	}
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT BISECT"))
+	fmt.Println(dim.Render(fmt.Sprintf("  %s from %s (%s) to %s (%s): %s → %s, %d of %d commits analyzed",
+		metric, from, b.From.Commit, to, b.To.Commit, value(b.From), value(b.To), b.Analyzed, b.Commits)))
+	fmt.Println()
+
+	if len(b.Culprits) == 0 {
+		fmt.Printf("  %s %s isn't past the threshold at %s\n\n", statusOK.String(), metric, to)
+		return
+	}
+	for _, c := range b.Culprits {
+		fmt.Printf("  %s %s %s\n", statusBad.String(), c.Commit[:7], c.Subject)
+		fmt.Println(dim.Render(fmt.Sprintf("    %s, %s", c.Author, c.When.Local().Format(time.DateOnly))))
+		fmt.Printf("    %s %s → %s\n", metric, value(c.Before), scoreDeltaDownStyle.Render(value(c.After)))
+		for _, f := range c.Files {
+			fmt.Println("    " + dim.Render(f))
+		}
+		fmt.Println()
+	}
+}
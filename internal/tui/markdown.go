@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	mdBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdNumbered = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdRule     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+
+	mdCode   = regexp.MustCompile("`([^`]+)`")
+	mdBold   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic = regexp.MustCompile(`\*([^*\s][^*]*)\*|_([^_\s][^_]*)_`)
+
+	mdBoldStyle   = lipgloss.NewStyle().Bold(true)
+	mdItalicStyle = lipgloss.NewStyle().Italic(true)
+)
+
+// renderMarkdown renders the markdown AI providers answer in, wrapped to
+// width: headings, bullet and numbered lists, block quotes, rules, fenced
+// code, and inline code, bold, and italics. Anything else passes through as
+// text.
+func renderMarkdown(src string, width int) string {
+	width = max(width, 20)
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, mdCodeStyle.Render("  "+line))
+			continue
+		}
+
+		switch {
+		case mdHeading.MatchString(line):
+			out = append(out, mdHeadingStyle.Render(mdHeading.FindStringSubmatch(line)[1]))
+		case mdRule.MatchString(line):
+			out = append(out, mdDimStyle.Render(strings.Repeat("─", min(width, 40))))
+		case mdBullet.MatchString(line):
+			m := mdBullet.FindStringSubmatch(line)
+			out = append(out, hangingIndent(m[1]+"• ", renderInline(m[2]), width))
+		case mdNumbered.MatchString(line):
+			m := mdNumbered.FindStringSubmatch(line)
+			out = append(out, hangingIndent(m[1]+m[2]+" ", renderInline(m[3]), width))
+		case strings.HasPrefix(line, ">"):
+			text := strings.TrimSpace(strings.TrimPrefix(line, ">"))
+			out = append(out, mdDimStyle.Render(hangingIndent("│ ", text, width)))
+		default:
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			out = append(out, hangingIndent(indent, renderInline(strings.TrimSpace(line)), width))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderInline styles inline code, bold, and italic spans.
+func renderInline(s string) string {
+	// Code spans are styled last, so their contents are taken out first to
+	// keep emphasis markers inside them literal.
+	var spans []string
+	s = mdCode.ReplaceAllStringFunc(s, func(m string) string {
+		spans = append(spans, m[1:len(m)-1])
+		return "\x00"
+	})
+	s = emphasize(s, mdBold, mdBoldStyle)
+	s = emphasize(s, mdItalic, mdItalicStyle)
+	for _, span := range spans {
+		s = strings.Replace(s, "\x00", mdCodeStyle.Render(span), 1)
+	}
+	return s
+}
+
+// emphasize styles the spans of s that re matches, whose text is in
+// either of its groups. A span must start at a word boundary, and one
+// marked by underscores end at one too, so that snake_case identifiers
+// keep their underscores.
+func emphasize(s string, re *regexp.Regexp, style lipgloss.Style) string {
+	var b strings.Builder
+	i := 0
+	for i < len(s) {
+		m := re.FindStringSubmatchIndex(s[i:])
+		if m == nil {
+			break
+		}
+		start, end := i+m[0], i+m[1]
+		if isWordByte(s, start-1) || s[start] == '_' && isWordByte(s, end) {
+			b.WriteString(s[i : start+1])
+			i = start + 1
+			continue
+		}
+		text := m[2:4]
+		if text[0] < 0 {
+			text = m[4:6]
+		}
+		b.WriteString(s[i:start])
+		b.WriteString(style.Render(s[i+text[0] : i+text[1]]))
+		i = end
+	}
+	b.WriteString(s[i:])
+	return b.String()
+}
+
+// isWordByte reports whether s has a letter, digit, or underscore at i.
+func isWordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// hangingIndent wraps text to width after prefix, indenting the wrapped
+// lines to line up under the text.
+func hangingIndent(prefix, text string, width int) string {
+	w := lipgloss.Width(prefix)
+	wrapped := lipgloss.NewStyle().Width(max(width-w, 10)).Render(text)
+	lines := strings.Split(wrapped, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = strings.Repeat(" ", w) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PrintDiagnosis prints what provider answered about subject, rendered from
+// markdown.
+func PrintDiagnosis(subject, provider, text string) {
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ AI DIAGNOSIS") + lipgloss.NewStyle().Foreground(colorDim).Render("  "+subject))
+	fmt.Println(lipgloss.NewStyle().Foreground(colorPurple).Render("  Powered by " + provider))
+	fmt.Println()
+	for _, line := range strings.Split(renderMarkdown(text, 96), "\n") {
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+}
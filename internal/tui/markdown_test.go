@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		width int
+		want  string
+	}{
+		{"heading", "## Root cause\nThe loop", 80, "Root cause\nThe loop"},
+		{"bullets", "- one\n* two\n+ three", 80, "• one\n• two\n• three"},
+		{"nested list", "- outer\n  - inner\n    1. first", 80, "• outer\n  • inner\n    1. first"},
+		{"numbered", "1. first\n2) second", 80, "1. first\n2) second"},
+		{"fenced code", "Before\n```go\nif x_y_z {\n    return **p\n}\n```\nAfter", 80,
+			"Before\n  if x_y_z {\n      return **p\n  }\nAfter"},
+		{"bold", "This is **very** bad and __so__ is this", 80, "This is very bad and so is this"},
+		{"italic", "An *emphasized* and _stressed_ word", 80, "An emphasized and stressed word"},
+		{"snake_case", "Lower max_func_lines and my_var_name", 80, "Lower max_func_lines and my_var_name"},
+		{"snake_case after italic", "_note_ that a_b _c_ stays", 80, "note that a_b c stays"},
+		{"inline code", "Call `do_it(**kw)` now", 80, "Call do_it(**kw) now"},
+		{"quote", "> careful", 80, "│ careful"},
+		{"rule", "---", 80, strings.Repeat("─", 40)},
+		{"wrap", "one two three four five six seven eight nine ten eleven twelve", 20,
+			"one two three four\nfive six seven eight\nnine ten eleven\ntwelve"},
+		{"wrapped bullet", "- one two three four five six seven eight", 20,
+			"• one two three four\n  five six seven\n  eight"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderMarkdown(tt.src, tt.width)
+			if got != tt.want {
+				t.Errorf("renderMarkdown(%q, %d) =\n%s\nwant\n%s", tt.src, tt.width, got, tt.want)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if w := lipgloss.Width(line); w > max(tt.width, 20) {
+					t.Errorf("line %q is %d wide, over %d", line, w, tt.width)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+// PrintOwners lists each owner's issues by kind, most issues first. by says
+// how they were attributed: "author" or "team".
+func PrintOwners(owners []history.OwnerHealth, by string) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT OWNERS"))
+	how := "last author of the lines as of HEAD"
+	if by == "team" {
+		how = "CODEOWNERS"
+	}
+	fmt.Println(dim.Render("  issues attributed by " + how))
+	fmt.Println()
+
+	if len(owners) == 0 {
+		fmt.Printf("  %s No issues to attribute\n\n", statusOK.String())
+		return
+	}
+
+	fmt.Printf("  %-28s %6s %6s %10s %8s %9s %5s %6s\n",
+		"OWNER", "ISSUES", "ERRORS", "COMPLEXITY", "BOUNDARY", "DEAD CODE", "VULNS", "FILES")
+	for _, o := range owners {
+		errs := fmt.Sprintf("%6d", o.Errors)
+		if o.Errors > 0 {
+			errs = scoreDeltaDownStyle.Render(errs)
+		}
+		fmt.Printf("  %-28s %6d %s %10d %8d %9d %5d %6d\n",
+			truncate(o.Owner, 28), len(o.Findings), errs,
+			o.Rules["complexity"], o.Rules["boundary"], o.Rules["dead-code"], o.Rules["vulnerability"], o.Files)
+	}
+	fmt.Println()
+	fmt.Println(dim.Render("  drift owners --owner <name> lists one owner's issues"))
+	fmt.Println()
+}
+
+// PrintOwner lists the issues attributed to o, in the order of
+// health.Findings.
+func PrintOwner(o history.OwnerHealth) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT OWNER " + o.Owner))
+	fmt.Println(dim.Render(fmt.Sprintf("  %d issues in %d files", len(o.Findings), o.Files)))
+	fmt.Println()
+	for _, f := range o.Findings {
+		icon := statusWarn.String()
+		if f.Level == "error" {
+			icon = statusBad.String()
+		}
+		fmt.Printf("  %s %-13s %s %s\n", icon, f.Rule, findingLocation(f), dim.Render(f.Message))
+	}
+	fmt.Println()
+}
+
+// findingLocation is path:line, or just the path for a whole file.
+func findingLocation(f health.Finding) string {
+	if f.Line == 0 {
+		return f.Path
+	}
+	return fmt.Sprintf("%s:%d", f.Path, f.Line)
+}
+
+// PrintOwnersJSON writes each owner's counts and issues as JSON.
+func PrintOwnersJSON(owners []history.OwnerHealth) error {
+	type finding struct {
+		Rule    string `json:"rule"`
+		Level   string `json:"level"`
+		Path    string `json:"path"`
+		Line    int    `json:"line,omitempty"`
+		Message string `json:"message"`
+	}
+	type owner struct {
+		Owner    string         `json:"owner"`
+		Issues   int            `json:"issues"`
+		Errors   int            `json:"errors"`
+		Files    int            `json:"files"`
+		Rules    map[string]int `json:"rules"`
+		Findings []finding      `json:"findings"`
+	}
+	out := make([]owner, 0, len(owners))
+	for _, o := range owners {
+		e := owner{Owner: o.Owner, Issues: len(o.Findings), Errors: o.Errors, Files: o.Files, Rules: o.Rules}
+		for _, f := range o.Findings {
+			e.Findings = append(e.Findings, finding{f.Rule, f.Level, f.Path, f.Line, f.Message})
+		}
+		out = append(out, e)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
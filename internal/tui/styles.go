@@ -1,99 +1,153 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 var (
-	// Color palette
-	colorGreen  = lipgloss.Color("#00FF87")
-	colorYellow = lipgloss.Color("#FFD700")
-	colorRed    = lipgloss.Color("#FF6B6B")
-	colorCyan   = lipgloss.Color("#00E5FF")
-	colorDim    = lipgloss.Color("#666666")
-	colorWhite  = lipgloss.Color("#FAFAFA")
-	colorBg     = lipgloss.Color("#1A1A2E")
-	colorPanel  = lipgloss.Color("#16213E")
-	colorAccent = lipgloss.Color("#E94560")
-	colorPurple = lipgloss.Color("#A855F7")
-	colorBorder = lipgloss.Color("#333366")
+	// Color palette, set by SetTheme
+	colorGreen  lipgloss.TerminalColor
+	colorLime   lipgloss.TerminalColor
+	colorYellow lipgloss.TerminalColor
+	colorOrange lipgloss.TerminalColor
+	colorRed    lipgloss.TerminalColor
+	colorCyan   lipgloss.TerminalColor
+	colorDim    lipgloss.TerminalColor
+	colorText   lipgloss.TerminalColor
+	colorAccent lipgloss.TerminalColor
+	colorPurple lipgloss.TerminalColor
+	colorBorder lipgloss.TerminalColor
+
+	titleStyle lipgloss.Style
+
+	scoreHighStyle      lipgloss.Style
+	scoreMedStyle       lipgloss.Style
+	scoreLowStyle       lipgloss.Style
+	scoreDeltaUpStyle   lipgloss.Style
+	scoreDeltaDownStyle lipgloss.Style
+
+	panelStyle      lipgloss.Style
+	panelTitleStyle lipgloss.Style
+
+	statusOK   lipgloss.Style
+	statusWarn lipgloss.Style
+	statusBad  lipgloss.Style
+
+	selectedRowStyle lipgloss.Style
+
+	activityTimeStyle lipgloss.Style
+	activityFileStyle lipgloss.Style
+
+	footerStyle    lipgloss.Style
+	footerKeyStyle lipgloss.Style
+
+	diagnosisStyle      lipgloss.Style
+	diagnosisTitleStyle lipgloss.Style
+
+	complexityBarFull  lipgloss.Style
+	complexityBarWarn  lipgloss.Style
+	complexityBarGood  lipgloss.Style
+	complexityBarEmpty lipgloss.Style
+
+	logoStyle lipgloss.Style
+
+	mdHeadingStyle lipgloss.Style
+	mdCodeStyle    lipgloss.Style
+	mdDimStyle     lipgloss.Style
+)
 
+// buildStyles derives the styles from the color palette.
+func buildStyles() {
 	// Title
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorCyan).
-			Align(lipgloss.Center)
+		Bold(true).
+		Foreground(colorCyan).
+		Align(lipgloss.Center)
 
 	// Score
 	scoreHighStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorGreen)
+		Bold(true).
+		Foreground(colorGreen)
 
 	scoreMedStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorYellow)
+		Bold(true).
+		Foreground(colorYellow)
 
 	scoreLowStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorRed)
+		Bold(true).
+		Foreground(colorRed)
 
 	scoreDeltaUpStyle = lipgloss.NewStyle().
-				Foreground(colorGreen)
+		Foreground(colorGreen)
 
 	scoreDeltaDownStyle = lipgloss.NewStyle().
-				Foreground(colorRed)
+		Foreground(colorRed)
 
 	// Panels
 	panelStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorBorder).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorBorder).
+		Padding(0, 1)
 
 	panelTitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorCyan).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(colorCyan).
+		MarginBottom(1)
 
 	// Status indicators
-	statusOK   = lipgloss.NewStyle().Foreground(colorGreen).SetString("✓")
+	statusOK = lipgloss.NewStyle().Foreground(colorGreen).SetString("✓")
 	statusWarn = lipgloss.NewStyle().Foreground(colorYellow).SetString("⚠")
-	statusBad  = lipgloss.NewStyle().Foreground(colorRed).SetString("✗")
+	statusBad = lipgloss.NewStyle().Foreground(colorRed).SetString("✗")
+
+	// Selected row of the focused panel
+	selectedRowStyle = lipgloss.NewStyle().
+		Foreground(colorCyan).
+		Bold(true)
 
 	// Activity feed
 	activityTimeStyle = lipgloss.NewStyle().
-				Foreground(colorDim)
+		Foreground(colorDim)
 
 	activityFileStyle = lipgloss.NewStyle().
-				Foreground(colorWhite)
+		Foreground(colorText)
 
 	// Footer
 	footerStyle = lipgloss.NewStyle().
-			Foreground(colorDim).
-			Align(lipgloss.Center)
+		Foreground(colorDim).
+		Align(lipgloss.Center)
 
 	footerKeyStyle = lipgloss.NewStyle().
-			Foreground(colorCyan).
-			Bold(true)
+		Foreground(colorCyan).
+		Bold(true)
 
 	// AI Diagnosis
 	diagnosisStyle = lipgloss.NewStyle().
-			Border(lipgloss.DoubleBorder()).
-			BorderForeground(colorPurple).
-			Padding(1, 2)
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(colorPurple).
+		Padding(1, 2)
 
 	diagnosisTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(colorPurple)
+		Bold(true).
+		Foreground(colorPurple)
 
 	// Complexity bar
-	complexityBarFull  = lipgloss.NewStyle().Foreground(colorRed).SetString("█")
-	complexityBarWarn  = lipgloss.NewStyle().Foreground(colorYellow).SetString("█")
-	complexityBarGood  = lipgloss.NewStyle().Foreground(colorGreen).SetString("█")
+	complexityBarFull = lipgloss.NewStyle().Foreground(colorRed).SetString("█")
+	complexityBarWarn = lipgloss.NewStyle().Foreground(colorYellow).SetString("█")
+	complexityBarGood = lipgloss.NewStyle().Foreground(colorGreen).SetString("█")
 	complexityBarEmpty = lipgloss.NewStyle().Foreground(colorDim).SetString("░")
 
 	// Logo
 	logoStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorAccent)
-)
+		Bold(true).
+		Foreground(colorAccent)
+
+	// Markdown in the AI diagnosis
+	mdHeadingStyle = lipgloss.NewStyle().Bold(true).Foreground(colorCyan)
+	mdCodeStyle = lipgloss.NewStyle().Foreground(colorGreen)
+	mdDimStyle = lipgloss.NewStyle().Foreground(colorDim)
+}
 
 func scoreStyle(score float64) lipgloss.Style {
 	if score >= 80 {
@@ -131,6 +185,16 @@ func complexityBar(complexity, maxDisplay int) string {
 	return bar
 }
 
+// progressBar draws done out of total as a bar width cells wide.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, done*width/total)
+	}
+	return lipgloss.NewStyle().Foreground(colorCyan).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(colorDim).Render(strings.Repeat("░", width-filled))
+}
+
 func sparkline(data []float64) string {
 	if len(data) == 0 {
 		return ""
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// palette holds a theme's colors by config.ThemeColors name.
+type palette map[string]string
+
+var themes = map[string]palette{
+	"dark": {
+		"green": "#00FF87", "lime": "#9ACD32", "yellow": "#FFD700", "orange": "#FF8C00", "red": "#FF6B6B",
+		"cyan": "#00E5FF", "dim": "#666666", "text": "#FAFAFA", "accent": "#E94560", "purple": "#A855F7",
+		"border": "#333366",
+	},
+	// light keeps to darker shades that read on a white background.
+	"light": {
+		"green": "#008700", "lime": "#5F8700", "yellow": "#AF8700", "orange": "#D75F00", "red": "#D70000",
+		"cyan": "#005FAF", "dim": "#6C6C6C", "text": "#1C1C1C", "accent": "#D7005F", "purple": "#8700AF",
+		"border": "#BCBCBC",
+	},
+	// high-contrast uses saturated colors and a light gray for dim text.
+	"high-contrast": {
+		"green": "#00FF00", "lime": "#AFFF00", "yellow": "#FFFF00", "orange": "#FF8700", "red": "#FF0000",
+		"cyan": "#00FFFF", "dim": "#C0C0C0", "text": "#FFFFFF", "accent": "#FF00FF", "purple": "#FF87FF",
+		"border": "#FFFFFF",
+	},
+}
+
+func init() {
+	SetTheme(config.ThemeConfig{})
+}
+
+// SetTheme colors the dashboard and printed reports with the configured
+// theme, dark by default, and its overrides. Setting NO_COLOR
+// (https://no-color.org) turns colors off whatever the theme.
+func SetTheme(t config.ThemeConfig) {
+	p, ok := themes[t.Name]
+	if !ok {
+		p = themes["dark"]
+	}
+	color := func(name string) lipgloss.TerminalColor {
+		if os.Getenv("NO_COLOR") != "" {
+			return lipgloss.NoColor{}
+		}
+		if hex, ok := t.Colors[name]; ok {
+			return lipgloss.Color(hex)
+		}
+		return lipgloss.Color(p[name])
+	}
+
+	colorGreen = color("green")
+	colorLime = color("lime")
+	colorYellow = color("yellow")
+	colorOrange = color("orange")
+	colorRed = color("red")
+	colorCyan = color("cyan")
+	colorDim = color("dim")
+	colorText = color("text")
+	colorAccent = color("accent")
+	colorPurple = color("purple")
+	colorBorder = color("border")
+	buildStyles()
+}
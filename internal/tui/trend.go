@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+// Size of the total score chart in `drift trend`.
+const (
+	trendChartHeight = 10
+	trendChartWidth  = 60
+)
+
+// PrintTrend charts the total score of records over time, then each
+// category's trend with its change over the range. source says where the
+// records came from, e.g. "recorded runs".
+func PrintTrend(records []history.Record, since time.Time, source string) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT TREND"))
+	fmt.Println(dim.Render(fmt.Sprintf("  %d %s since %s", len(records), source, since.Local().Format(time.DateOnly))))
+	fmt.Println()
+
+	if len(records) == 0 {
+		fmt.Println("  Nothing to chart in this range.")
+		fmt.Println()
+		return
+	}
+
+	totals := make([]float64, len(records))
+	for i, rec := range records {
+		totals[i] = rec.Score["total"]
+	}
+	for _, line := range trendChart(totals, trendChartWidth, trendChartHeight) {
+		fmt.Println("  " + line)
+	}
+	first, last := records[0].Time.Local().Format(time.DateOnly), records[len(records)-1].Time.Local().Format(time.DateOnly)
+	width := min(len(totals), trendChartWidth)
+	fmt.Println(dim.Render(fmt.Sprintf("  %5s  %-*s%s", "", max(width-len(last), len(first)+1), first, last)))
+	fmt.Println()
+
+	fmt.Printf("  %-16s %-24s %6s %6s %7s\n", "CATEGORY", "TREND", "FIRST", "LAST", "Δ")
+	for _, c := range append([]string{"total"}, trendCategories(records)...) {
+		var values []float64
+		for _, rec := range records {
+			if v, ok := rec.Score[c]; ok {
+				values = append(values, v)
+			}
+		}
+		from, to := values[0], values[len(values)-1]
+		delta := to - from
+		style := dim
+		if delta >= 0.05 {
+			style = scoreDeltaUpStyle
+		} else if delta <= -0.05 {
+			style = scoreDeltaDownStyle
+		}
+		spark := sparkline(sample(values, 24))
+		pad := strings.Repeat(" ", 24-lipgloss.Width(spark))
+		fmt.Printf("  %-16s %s%s %s %s %s\n", c, spark, pad,
+			scoreStyle(from).Render(fmt.Sprintf("%6.1f", from)),
+			scoreStyle(to).Render(fmt.Sprintf("%6.1f", to)),
+			style.Render(fmt.Sprintf("%+7.1f", delta)))
+	}
+	fmt.Println()
+}
+
+// trendCategories lists the categories, other than the total, that any
+// record scores, sorted.
+func trendCategories(records []history.Record) []string {
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		for c := range rec.Score {
+			if c != "total" {
+				seen[c] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// sample picks at most n of values, evenly spread and keeping the first and
+// last.
+func sample(values []float64, n int) []float64 {
+	if len(values) <= n {
+		return values
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = values[i*(len(values)-1)/(n-1)]
+	}
+	return out
+}
+
+// trendChart draws values as columns of block characters height rows high,
+// at most width wide, with the score axis labeled on the left. Scores are
+// charted from the lowest value rounded down to a multiple of ten, so small
+// changes stay visible.
+func trendChart(values []float64, width, height int) []string {
+	values = sample(values, width)
+	low, high := 100.0, 0.0
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	low = math.Floor(low/10) * 10
+	high = math.Max(math.Ceil(high/10)*10, low+10)
+
+	blocks := []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	lines := make([]string, height)
+	for row := range height {
+		// Rows count down from the top; each covers an equal share of the range.
+		bottom := low + (high-low)*float64(height-1-row)/float64(height)
+		step := (high - low) / float64(height)
+		label := "     "
+		if row == 0 {
+			label = fmt.Sprintf("%5.0f", high)
+		} else if row == height-1 {
+			label = fmt.Sprintf("%5.0f", low)
+		}
+		var b strings.Builder
+		for _, v := range values {
+			eighths := int(math.Round((v - bottom) / step * 8))
+			eighths = max(0, min(8, eighths))
+			b.WriteString(scoreStyle(v).Render(blocks[eighths]))
+		}
+		lines[row] = dim.Render(label+" ┤") + b.String()
+	}
+	return lines
+}
+
+// releaseCounts are the issue counts a release changelog reports changes
+// in, with how to describe them.
+var releaseCounts = []struct{ key, label string }{
+	{"complex_functions", "complex functions"},
+	{"violations", "boundary violations"},
+	{"cycles", "cycles"},
+	{"dead_code", "dead declarations"},
+	{"duplicates", "duplicates"},
+	{"vulnerabilities", "vulnerabilities"},
+}
+
+// PrintReleases prints a health changelog of tagged releases, newest
+// first: each one's total score and grade, then how its categories and
+// issue counts changed since the release before.
+func PrintReleases(records []history.Record) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT RELEASES"))
+	fmt.Println(dim.Render(fmt.Sprintf("  %d tagged releases", len(records))))
+	fmt.Println()
+
+	if len(records) == 0 {
+		fmt.Println("  No tags to report on.")
+		fmt.Println()
+		return
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		total := rec.Score["total"]
+		delta := ""
+		if i > 0 {
+			d := total - records[i-1].Score["total"]
+			style := dim
+			if d >= 0.05 {
+				style = scoreDeltaUpStyle
+			} else if d <= -0.05 {
+				style = scoreDeltaDownStyle
+			}
+			delta = " " + style.Render(fmt.Sprintf("%+6.1f", d))
+		}
+		fmt.Printf("  %-20s %s  %s  %s%s\n", truncate(rec.Tag, 20),
+			dim.Render(rec.Time.Local().Format(time.DateOnly)), dim.Render(rec.Commit),
+			scoreStyle(total).Render(fmt.Sprintf("%5.1f %s", total, health.Grade(total))), delta)
+		if i == 0 {
+			fmt.Println(dim.Render("    first release"))
+			fmt.Println()
+			continue
+		}
+
+		prev := records[i-1]
+		var changes []string
+		for _, c := range trendCategories([]history.Record{prev, rec}) {
+			if d := rec.Score[c] - prev.Score[c]; math.Abs(d) >= 0.5 {
+				style := scoreDeltaUpStyle
+				if d < 0 {
+					style = scoreDeltaDownStyle
+				}
+				changes = append(changes, c+" "+style.Render(fmt.Sprintf("%+.1f", d)))
+			}
+		}
+		for _, c := range releaseCounts {
+			if d := rec.Counts[c.key] - prev.Counts[c.key]; d != 0 {
+				style := scoreDeltaDownStyle
+				if d < 0 {
+					style = scoreDeltaUpStyle
+				}
+				changes = append(changes, style.Render(fmt.Sprintf("%+d", d))+" "+c.label)
+			}
+		}
+		if len(changes) == 0 {
+			fmt.Println(dim.Render("    no change"))
+		} else {
+			fmt.Println("    " + strings.Join(changes, dim.Render(", ")))
+		}
+		fmt.Println()
+	}
+}
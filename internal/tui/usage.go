@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/greatnessinabox/drift/internal/history"
+)
+
+// PrintUsage totals the recorded AI calls, then breaks them down by command
+// and by model, costliest first. period describes the calls, e.g. "since
+// 2026-01-01".
+func PrintUsage(calls []history.Call, period string) {
+	dim := lipgloss.NewStyle().Foreground(colorDim)
+	fmt.Println()
+	fmt.Println(logoStyle.Render("◆ DRIFT AI USAGE"))
+	fmt.Println(dim.Render(fmt.Sprintf("  %d calls %s, recorded in %s", len(calls), period, history.UsageFile)))
+	fmt.Println()
+
+	if len(calls) == 0 {
+		fmt.Println("  No AI calls recorded; drift diagnose, drift fix, and the dashboard's d record each one.")
+		fmt.Println()
+		return
+	}
+
+	var total history.Usage
+	estimated := 0
+	for _, c := range calls {
+		total.Add(c)
+		if c.Estimated {
+			estimated++
+		}
+	}
+	fmt.Printf("  %s  %s input + %s output tokens\n",
+		lipgloss.NewStyle().Bold(true).Render(usd(total.Cost)), tokenCount(total.InputTokens), tokenCount(total.OutputTokens))
+	if total.Unpriced > 0 {
+		fmt.Println(dim.Render(fmt.Sprintf("  calls to models without a known price aren't in the cost: %d", total.Unpriced)))
+	}
+	if estimated > 0 {
+		fmt.Println(dim.Render(fmt.Sprintf("  calls whose tokens are estimates, not reported by the provider: %d", estimated)))
+	}
+
+	printUsageTable("COMMAND", history.UsageBy(calls, func(c history.Call) string { return c.Command }))
+	printUsageTable("MODEL", history.UsageBy(calls, history.Call.ModelLabel))
+	fmt.Println()
+}
+
+func printUsageTable(title string, by map[string]*history.Usage) {
+	keys := sortedKeys(by)
+	sort.SliceStable(keys, func(i, j int) bool { return by[keys[i]].Cost > by[keys[j]].Cost })
+	fmt.Println()
+	fmt.Printf("  %-36s %6s %10s %10s %10s\n", title, "CALLS", "INPUT", "OUTPUT", "COST")
+	for _, k := range keys {
+		u := by[k]
+		fmt.Printf("  %-36s %6d %10s %10s %10s\n",
+			truncate(k, 36), u.Calls, tokenCount(u.InputTokens), tokenCount(u.OutputTokens), usd(u.Cost))
+	}
+}
+
+// tokenCount abbreviates thousands and millions: 850, 12.4k, 3.1M.
+func tokenCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
+
+// usd shows dollars, with more decimals for amounts under a cent.
+func usd(v float64) string {
+	if v > 0 && v < 0.01 {
+		return fmt.Sprintf("$%.4f", v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// atLimit reports whether err from adding a watch means the system's limit
+// on watches is reached: fs.inotify.max_user_watches on Linux, or open
+// files where each watch takes a file descriptor.
+func atLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// limitError reports n directories left unwatched at the watch limit, and
+// how to raise it.
+func (w *Watcher) limitError(n int, err error) error {
+	raise := "raise the open files limit with ulimit -n"
+	if runtime.GOOS == "linux" {
+		raise = "raise it with: sudo sysctl fs.inotify.max_user_watches=524288 (add it to /etc/sysctl.conf to keep it)"
+	}
+	return fmt.Errorf("%d directories not watched, polling them every %s instead: the system's limit on watches is reached (%w); %s",
+		n, w.fallback, err, raise)
+}
+
+// unwatch polls the tree under dir, which can't be watched at the limit,
+// and returns how many directories it holds. With existing, its files are
+// reported as changed on the first scan, as addDirs reports those of new
+// directories.
+func (w *Watcher) unwatch(dir string, existing bool) int {
+	n := 0
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			if path != dir && config.Excluded(w.exclude, w.rel(path)) {
+				return filepath.SkipDir
+			}
+			n++
+		}
+		return nil
+	})
+	files := make(map[string]stamp)
+	if !existing {
+		w.scanTree(dir, false, files)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unwatched[dir] = files
+	if !w.polling {
+		w.polling = true
+		go w.pollUnwatched()
+	}
+	return n
+}
+
+// pollUnwatched scans the unwatched trees every fallback interval and
+// queues the files added, changed, and removed, so they join the batches
+// of watched files.
+func (w *Watcher) pollUnwatched() {
+	ticker := time.NewTicker(w.fallback)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		w.mu.Lock()
+		dirs := maps.Clone(w.unwatched)
+		w.mu.Unlock()
+
+		for dir, files := range dirs {
+			now := make(map[string]stamp)
+			w.scanTree(dir, false, now)
+			for path, st := range now {
+				if old, ok := files[path]; !ok || !old.modTime.Equal(st.modTime) || old.size != st.size {
+					w.queue(path)
+				}
+			}
+			for path := range files {
+				if _, ok := now[path]; !ok {
+					w.queue(path)
+				}
+			}
+			w.mu.Lock()
+			w.unwatched[dir] = now
+			w.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAtLimit(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{syscall.ENOSPC, true},
+		{fmt.Errorf("adding watch: %w", syscall.EMFILE), true},
+		{syscall.EACCES, false},
+		{os.ErrNotExist, false},
+	} {
+		if got := atLimit(tt.err); got != tt.want {
+			t.Errorf("atLimit(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWatcher_Unwatched(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "pkg")
+	kept := filepath.Join(dir, "kept.go")
+	for _, f := range []string{kept, filepath.Join(dir, "vendor", "v.go")} {
+		os.MkdirAll(filepath.Dir(f), 0o755)
+		if err := os.WriteFile(f, []byte("package a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(dir, "deep"), 0o755)
+
+	w := newWatcher(root, []string{"pkg/vendor"}, nil, []string{".go"})
+	w.fallback = 20 * time.Millisecond
+	defer w.Close()
+	if n := w.unwatch(dir, false); n != 2 {
+		t.Errorf("unwatch = %d directories, want 2", n)
+	}
+	if err := w.limitError(2, syscall.ENOSPC); !strings.Contains(err.Error(), "2 directories not watched") {
+		t.Errorf("limitError = %q", err)
+	}
+
+	added := filepath.Join(dir, "deep", "added.go")
+	for _, f := range []string{added, kept} {
+		if err := os.WriteFile(f, []byte("package a\n\nfunc F() {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := map[string]bool{}
+	for len(got) < 2 {
+		for _, e := range next(t, w) {
+			got[e.Path] = e.Removed
+		}
+	}
+	if len(got) != 2 || got[added] || got[kept] {
+		t.Errorf("events = %v, want %s and %s changed", got, added, kept)
+	}
+}
@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/greatnessinabox/drift/internal/config"
+)
+
+// stamp is what polling compares to tell a file changed.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewPolling watches the same files as New by scanning the tree every
+// interval and comparing modification times and sizes, for filesystems
+// that don't deliver events, such as NFS, Docker bind mounts, and WSL2
+// mounts of Windows drives. Each scan's changes are reported as one batch,
+// in which a file written several times since the last scan appears once.
+func NewPolling(root string, exclude, include []string, extensions []string, interval time.Duration) *Watcher {
+	w := newWatcher(root, exclude, include, extensions)
+	w.poll = interval
+	files := w.scan(true)
+	go w.pollLoop(files)
+	return w
+}
+
+// pollLoop scans every interval, starting from files, reporting the files
+// added, changed, and removed since the last scan.
+func (w *Watcher) pollLoop(files map[string]stamp) {
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		now := w.scan(false)
+		var changed, removed []string
+		for path, st := range now {
+			if old, ok := files[path]; !ok || !old.modTime.Equal(st.modTime) || old.size != st.size {
+				changed = append(changed, path)
+			}
+		}
+		for path := range files {
+			if _, ok := now[path]; !ok {
+				removed = append(removed, path)
+			}
+		}
+		files = now
+		if len(changed)+len(removed) == 0 {
+			continue
+		}
+
+		sort.Strings(changed)
+		sort.Strings(removed)
+		stamp := time.Now()
+		var batch Batch
+		for _, path := range changed {
+			batch = append(batch, Event{Path: path, Timestamp: stamp})
+		}
+		for _, path := range removed {
+			batch = append(batch, Event{Path: path, Timestamp: stamp, Removed: true})
+		}
+		if !w.emit(batch) {
+			return
+		}
+	}
+}
+
+// emit sends b, giving up if the watcher is closed first.
+func (w *Watcher) emit(b Batch) bool {
+	select {
+	case w.Events <- b:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// scan stamps the watched files under the root. Unreadable directories are
+// skipped, and reported on Errors if report is set, so they aren't reported
+// again on every scan.
+func (w *Watcher) scan(report bool) map[string]stamp {
+	files := make(map[string]stamp)
+	w.scanTree(w.root, report, files)
+	return files
+}
+
+// scanTree adds the stamps of the watched files under dir to files, as
+// scan does for the root.
+func (w *Watcher) scanTree(dir string, report bool, files map[string]stamp) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if report {
+				w.fail(fmt.Errorf("not watching %s: %w", w.rel(path), err))
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != w.root && config.Excluded(w.exclude, w.rel(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !w.matchesExtension(path) || !w.matchesGlobs(path) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = stamp{info.ModTime(), info.Size()}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPolling(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept.go")
+	gone := filepath.Join(root, "gone.go")
+	for _, f := range []string{kept, gone, filepath.Join(root, "vendor", "v.go")} {
+		os.MkdirAll(filepath.Dir(f), 0o755)
+		if err := os.WriteFile(f, []byte("package a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := NewPolling(root, []string{"vendor"}, nil, []string{".go"}, 20*time.Millisecond)
+	defer w.Close()
+	if w.Polling() != 20*time.Millisecond {
+		t.Errorf("Polling() = %s", w.Polling())
+	}
+
+	added := filepath.Join(root, "sub", "added.go")
+	os.MkdirAll(filepath.Dir(added), 0o755)
+	for _, f := range []string{added, kept, filepath.Join(root, "vendor", "v.go"), filepath.Join(root, "notes.txt")} {
+		if err := os.WriteFile(f, []byte("package a\n\nfunc F() {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{} // path → removed
+	for len(got) < 3 {
+		for _, e := range next(t, w) {
+			got[e.Path] = e.Removed
+		}
+	}
+	if len(got) != 3 || got[added] || got[kept] || !got[gone] {
+		t.Errorf("events = %v, want %s and %s changed and %s removed", got, added, kept, gone)
+	}
+	select {
+	case b := <-w.Events:
+		t.Errorf("unexpected batch %+v", b)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -1,64 +1,160 @@
-// Package watcher monitors the filesystem and emits debounced change events for source files.
+// Package watcher monitors the filesystem and emits debounced batches of change events for source files.
 package watcher
 
 import (
+	"fmt"
 	"io/fs"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"github.com/greatnessinabox/drift/internal/config"
 )
 
 type Event struct {
 	Path      string
 	Timestamp time.Time
+	// Removed is set when the file, or the directory with everything under
+	// it, was deleted or renamed away.
+	Removed bool
+}
+
+// Batch is the changes of one burst, such as a save, a branch switch, or a
+// formatter run over the tree, reported together once the files have been
+// quiet for a moment.
+type Batch []Event
+
+// largeBatch is how many files a batch may change before re-analyzing
+// everything beats re-analyzing them one by one.
+const largeBatch = 10
+
+// Large reports whether the batch changes enough files that a full
+// analysis is the quicker way to catch up, as after a branch switch.
+func (b Batch) Large() bool {
+	return len(b) > largeBatch
 }
 
+// How long the files must be quiet for their batch to be reported, and
+// how long a batch may be held while changes keep coming.
+const (
+	quietPeriod = 200 * time.Millisecond
+	maxHold     = 2 * time.Second
+)
+
 type Watcher struct {
 	inner      *fsnotify.Watcher
 	root       string
 	exclude    []string
+	include    []string
 	extensions []string
-	Events     chan Event
-	Errors     chan error
-	done       chan struct{}
+	Events     chan Batch
+	// Errors reports directories that can't be watched and failures of the
+	// underlying watcher. Errors nobody receives in time are dropped.
+	Errors chan error
+	done   chan struct{}
+
+	// poll is the scan interval when polling instead of watching events;
+	// inner is then nil.
+	poll time.Duration
+
+	dirs map[string]bool // watched directories; used by loop only
+
+	// fallback is how often trees left unwatched at the system's limit on
+	// watches are polled instead.
+	fallback time.Duration
+
+	mu      sync.Mutex
+	pending map[string]bool // paths changed since the last batch
+	since   time.Time       // when the first of them changed
+	timer   *time.Timer
+
+	unwatched map[string]map[string]stamp // polled trees → their files' stamps
+	polling   bool                        // whether pollUnwatched runs
 }
 
-func New(root string, exclude []string, extensions []string) (*Watcher, error) {
+// New watches every non-excluded directory under root. Events are only
+// emitted for files with one of extensions that pass the include and
+// exclude globs.
+func New(root string, exclude, include []string, extensions []string) (*Watcher, error) {
+	return newEvents(root, exclude, include, extensions, defaultFallback)
+}
+
+// defaultFallback is how often New polls the trees it can't watch.
+const defaultFallback = 2 * time.Second
+
+// newEvents is New polling the trees it can't watch every fallback.
+func newEvents(root string, exclude, include []string, extensions []string, fallback time.Duration) (*Watcher, error) {
 	inner, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
 
-	w := &Watcher{
-		inner:      inner,
+	w := newWatcher(root, exclude, include, extensions)
+	w.inner = inner
+	w.fallback = fallback
+
+	w.addDirs(root, false)
+
+	go w.loop()
+
+	return w, nil
+}
+
+// Open starts the watcher cfg.Watch asks for on the files extensions name:
+// one that polls with watch.mode poll, or one that watches filesystem
+// events, falling back to polling where those aren't available or the
+// system's limit on watches is reached.
+func Open(cfg *config.Config, extensions []string) (*Watcher, error) {
+	interval, err := cfg.Watch.PollEvery()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Watch.Mode == config.WatchPoll {
+		return NewPolling(cfg.Root, cfg.Exclude, cfg.Include, extensions, interval), nil
+	}
+	w, err := newEvents(cfg.Root, cfg.Exclude, cfg.Include, extensions, interval)
+	if err != nil {
+		w = NewPolling(cfg.Root, cfg.Exclude, cfg.Include, extensions, interval)
+		w.fail(fmt.Errorf("can't watch filesystem events, polling every %s instead: %w", interval, err))
+	}
+	return w, nil
+}
+
+func newWatcher(root string, exclude, include []string, extensions []string) *Watcher {
+	return &Watcher{
 		root:       root,
 		exclude:    exclude,
+		include:    include,
 		extensions: extensions,
-		Events:     make(chan Event, 100),
-		Errors:     make(chan error, 10),
+		Events:     make(chan Batch, 100),
+		Errors:     make(chan error, 100),
 		done:       make(chan struct{}),
+		dirs:       make(map[string]bool),
+		pending:    make(map[string]bool),
+		unwatched:  make(map[string]map[string]stamp),
 	}
+}
 
-	if err := w.addDirs(root); err != nil {
-		inner.Close()
-		return nil, err
-	}
-
-	go w.loop()
-
-	return w, nil
+// Polling returns how often the watcher scans for changes, or 0 when it
+// watches filesystem events.
+func (w *Watcher) Polling() time.Duration {
+	return w.poll
 }
 
 func (w *Watcher) Close() {
 	close(w.done)
-	w.inner.Close()
+	if w.inner != nil {
+		w.inner.Close()
+	}
 }
 
 func (w *Watcher) loop() {
-	debounce := make(map[string]*time.Timer)
-
 	for {
 		select {
 		case <-w.done:
@@ -67,60 +163,149 @@ func (w *Watcher) loop() {
 			if !ok {
 				return
 			}
+			w.handle(event)
 
-			if !w.matchesExtension(event.Name) {
-				continue
+		case err, ok := <-w.inner.Errors:
+			if !ok {
+				return
 			}
+			w.fail(err)
+		}
+	}
+}
 
-			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
-				continue
+// handle queues the changes event brings: a new directory is watched and
+// the files already in it reported, and a removed or renamed directory
+// reported once for everything under it.
+func (w *Watcher) handle(event fsnotify.Event) {
+	path := event.Name
+	switch {
+	case event.Op&fsnotify.Create != 0 && !w.dirs[path]:
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if !config.Excluded(w.exclude, w.rel(path)) {
+				w.addDirs(path, true)
+			}
+			return
+		}
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && w.dirs[path]:
+		for dir := range w.dirs {
+			if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+				delete(w.dirs, dir)
+				w.inner.Remove(dir) // already gone for a deleted directory
 			}
+		}
+		w.queue(path)
+		return
+	}
 
-			path := event.Name
+	if !w.matchesExtension(path) || !w.matchesGlobs(path) {
+		return
+	}
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return
+	}
+	w.queue(path)
+}
 
-			if timer, exists := debounce[path]; exists {
-				timer.Stop()
-			}
+// queue adds path to the next batch, which is reported once no file has
+// changed for quietPeriod, or maxHold after its first change.
+func (w *Watcher) queue(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		w.since = time.Now()
+	}
+	w.pending[path] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	wait := min(quietPeriod, time.Until(w.since.Add(maxHold)))
+	w.timer = time.AfterFunc(max(wait, 0), w.flush)
+}
 
-			debounce[path] = time.AfterFunc(200*time.Millisecond, func() {
-				w.Events <- Event{
-					Path:      path,
-					Timestamp: time.Now(),
-				}
-				delete(debounce, path)
-			})
+// flush reports the pending paths as a batch. Whether each was removed is
+// decided now: editors that save by renaming briefly remove the file they
+// replace.
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	paths := slices.Sorted(maps.Keys(w.pending))
+	clear(w.pending)
+	w.mu.Unlock()
 
-		case err, ok := <-w.inner.Errors:
-			if !ok {
-				return
-			}
-			w.Errors <- err
+	now := time.Now()
+	var batch Batch
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			continue // recreated; its files were queued when it was added
 		}
+		batch = append(batch, Event{Path: path, Timestamp: now, Removed: os.IsNotExist(err)})
+	}
+	if len(batch) > 0 {
+		w.emit(batch)
 	}
 }
 
-func (w *Watcher) addDirs(root string) error {
-	return filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+// addDirs watches root and the directories below it. Directories that
+// can't be read or watched are reported on Errors and skipped, except that
+// once the system's limit on watches is reached, the trees left are polled
+// instead, with one error saying how many directories that is. With
+// existing, the source files found are reported as changed, for
+// directories created after the watcher started whose files may have
+// arrived before the watch did.
+func (w *Watcher) addDirs(root string, existing bool) {
+	unwatched := 0
+	var limit error
+	filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
+			w.fail(fmt.Errorf("not watching %s: %w", w.rel(path), err))
+			return nil
+		}
+		if !info.IsDir() {
+			if existing && w.matchesExtension(path) && w.matchesGlobs(path) {
+				w.queue(path)
+			}
 			return nil
 		}
-		if info.IsDir() {
-			if w.isExcluded(info.Name()) {
+		if path != w.root && config.Excluded(w.exclude, w.rel(path)) {
+			return filepath.SkipDir
+		}
+		if err := w.inner.Add(path); err != nil {
+			if atLimit(err) {
+				unwatched += w.unwatch(path, existing)
+				limit = err
 				return filepath.SkipDir
 			}
-			return w.inner.Add(path)
+			w.fail(fmt.Errorf("not watching %s: %w", w.rel(path), err))
+			return nil
 		}
+		w.dirs[path] = true
 		return nil
 	})
+	if unwatched > 0 {
+		w.fail(w.limitError(unwatched, limit))
+	}
 }
 
-func (w *Watcher) isExcluded(name string) bool {
-	for _, ex := range w.exclude {
-		if name == ex {
-			return true
-		}
+// fail reports err without blocking the watcher.
+func (w *Watcher) fail(err error) {
+	select {
+	case w.Errors <- err:
+	default:
 	}
-	return false
+}
+
+func (w *Watcher) matchesGlobs(path string) bool {
+	rel := w.rel(path)
+	return !config.Excluded(w.exclude, rel) && config.Included(w.include, rel)
+}
+
+func (w *Watcher) rel(path string) string {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
 }
 
 func (w *Watcher) matchesExtension(path string) bool {
@@ -0,0 +1,134 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// next waits for the watcher's next batch.
+func next(t *testing.T, w *Watcher) Batch {
+	t.Helper()
+	select {
+	case b := <-w.Events:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch")
+		return nil
+	}
+}
+
+func TestWatcher_NewDirectories(t *testing.T) {
+	root := t.TempDir()
+	w, err := New(root, nil, nil, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	dir := filepath.Join(root, "pkg", "sub")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond) // let the watcher add it
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte("package sub\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if b := next(t, w); len(b) != 1 || b[0].Path != file || b[0].Removed {
+		t.Errorf("batch = %+v, want a change to %s", b, file)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if b := next(t, w); len(b) != 1 || b[0].Path != file || !b[0].Removed {
+		t.Errorf("batch = %+v, want %s removed", b, file)
+	}
+}
+
+func TestWatcher_RemovedDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "pkg")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(root, nil, nil, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.Rename(dir, filepath.Join(t.TempDir(), "moved")); err != nil {
+		t.Fatal(err)
+	}
+	if b := next(t, w); len(b) != 1 || b[0].Path != dir || !b[0].Removed {
+		t.Errorf("batch = %+v, want %s removed", b, dir)
+	}
+}
+
+func TestWatcher_Batches(t *testing.T) {
+	root := t.TempDir()
+	w, err := New(root, nil, nil, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// A burst, as from a branch switch: each file written twice.
+	var want []string
+	for i := range largeBatch + 5 {
+		file := filepath.Join(root, fmt.Sprintf("f%02d.go", i))
+		want = append(want, file)
+		for range 2 {
+			if err := os.WriteFile(file, []byte("package a\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	b := next(t, w)
+	var got []string
+	for _, e := range b {
+		got = append(got, e.Path)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("batch = %v, want %v", got, want)
+	}
+	if !b.Large() {
+		t.Errorf("Large() = false for %d files", len(b))
+	}
+	select {
+	case b := <-w.Events:
+		t.Errorf("unexpected batch %+v", b)
+	case <-time.After(quietPeriod + 100*time.Millisecond):
+	}
+}
+
+func TestWatcher_Excluded(t *testing.T) {
+	root := t.TempDir()
+	w, err := New(root, []string{"gen-*", "web/legacy/**"}, nil, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, dir := range []string{"out/gen-1a2b", "web/legacy"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond) // let the watcher see them
+	kept := filepath.Join(root, "out", "kept.go")
+	for _, f := range []string{filepath.Join(root, "out", "gen-1a2b", "a.go"), filepath.Join(root, "web", "legacy", "b.go"), kept} {
+		if err := os.WriteFile(f, []byte("package a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b := next(t, w); len(b) != 1 || b[0].Path != kept {
+		t.Errorf("batch = %+v, want only %s", b, kept)
+	}
+}
@@ -0,0 +1,215 @@
+// Package web serves the health dashboard to browsers: a single page
+// mirroring the terminal dashboard's panels, kept current over a websocket,
+// for a wall monitor or a shared URL.
+package web
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+	"github.com/greatnessinabox/drift/internal/tui"
+	"github.com/greatnessinabox/drift/internal/watcher"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// maxActivity is how many recent file changes the page lists.
+const maxActivity = 20
+
+// writeTimeout bounds sending an update to one browser, so a stalled
+// connection can't hold up the others.
+const writeTimeout = 10 * time.Second
+
+// Server holds the latest analysis and pushes it to connected browsers as
+// it changes.
+type Server struct {
+	cfg      *config.Config
+	ana      *analyzer.Analyzer
+	scorer   *health.Scorer
+	baseline *analyzer.Baseline
+
+	mu       sync.Mutex
+	raw      *analyzer.Results // before the baseline is applied
+	state    []byte            // the JSON message last sent
+	activity []activity
+	clients  map[*websocket.Conn]bool
+}
+
+type activity struct {
+	File    string    `json:"file"`
+	Time    time.Time `json:"time"`
+	Removed bool      `json:"removed,omitempty"`
+	Count   int       `json:"count,omitempty"` // files changed together, re-analyzed in full
+}
+
+// message is what the page receives: the `drift snapshot` JSON and the
+// recent file changes.
+type message struct {
+	Snapshot json.RawMessage `json:"snapshot"`
+	Activity []activity      `json:"activity"`
+	Root     string          `json:"root"`
+}
+
+// New creates a server for results, leaving out the issues baseline
+// grandfathers; baseline may be nil.
+func New(cfg *config.Config, ana *analyzer.Analyzer, scorer *health.Scorer, baseline *analyzer.Baseline, results *analyzer.Results) (*Server, error) {
+	s := &Server{
+		cfg:      cfg,
+		ana:      ana,
+		scorer:   scorer,
+		baseline: baseline,
+		activity: []activity{},
+		clients:  make(map[*websocket.Conn]bool),
+	}
+	if err := s.update(results); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Handler serves the page at /, the current state as JSON at /api/state,
+// and its updates over a websocket at /ws. The state and updates are only
+// served to the page itself, not to other sites' pages.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+	mux.HandleFunc("GET /api/state", func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, errCrossOrigin.Error(), http.StatusForbidden)
+			return
+		}
+		s.mu.Lock()
+		state := s.state
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(state)
+	})
+	mux.Handle("GET /ws", websocket.Server{
+		Handler: s.serveWebsocket,
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !sameOrigin(r) {
+				return errCrossOrigin
+			}
+			return nil
+		},
+	})
+	return mux
+}
+
+var errCrossOrigin = errors.New("cross-origin request")
+
+// sameOrigin reports whether r comes from the dashboard's own page, or from
+// no page at all, as from curl. Pages on other sites the browser has open
+// must not read the analysis.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// serveWebsocket sends the current state, then every update until the
+// browser goes away.
+func (s *Server) serveWebsocket(ws *websocket.Conn) {
+	s.mu.Lock()
+	ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	err := websocket.Message.Send(ws, string(s.state))
+	if err == nil {
+		s.clients[ws] = true
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	// The page sends nothing; reading just notices when it disconnects.
+	var discard string
+	for websocket.Message.Receive(ws, &discard) == nil {
+	}
+	s.mu.Lock()
+	delete(s.clients, ws)
+	s.mu.Unlock()
+}
+
+// Watch keeps the results up to date with the changes w reports, and
+// re-analyzes everything every refresh if that is positive, pushing each
+// result to the browsers. It returns when w closes; w may be nil.
+func (s *Server) Watch(w *watcher.Watcher, refresh time.Duration) {
+	s.mu.Lock()
+	raw := s.raw
+	s.mu.Unlock()
+	s.ana.Follow(raw, w, refresh, func(results *analyzer.Results, changes watcher.Batch) {
+		if changes.Large() {
+			s.update(results, activity{Time: changes[0].Timestamp, Count: len(changes)})
+			return
+		}
+		changed := make([]activity, len(changes))
+		for i, e := range changes {
+			changed[i] = activity{File: s.rel(e.Path), Time: e.Timestamp, Removed: e.Removed}
+		}
+		s.update(results, changed...)
+	})
+}
+
+// rel makes path relative to the root for display.
+func (s *Server) rel(path string) string {
+	if rel, err := filepath.Rel(s.cfg.Root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// update scores raw, noting changed in the activity, and sends the result
+// to every browser.
+func (s *Server) update(raw *analyzer.Results, changed ...activity) error {
+	results := raw
+	if s.baseline != nil {
+		results = s.baseline.Apply(raw, s.cfg.Thresholds)
+	}
+	var snapshot bytes.Buffer
+	if err := tui.WriteSnapshot(&snapshot, s.cfg, s.scorer.Calculate(results), results); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw = raw
+	if len(changed) > 0 {
+		s.activity = append(slices.Clone(changed), s.activity...)
+		if len(s.activity) > maxActivity {
+			s.activity = s.activity[:maxActivity]
+		}
+	}
+	state, err := json.Marshal(message{Snapshot: snapshot.Bytes(), Activity: s.activity, Root: filepath.Base(s.cfg.Root)})
+	if err != nil {
+		return err
+	}
+	s.state = state
+	for ws := range s.clients {
+		ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if websocket.Message.Send(ws, string(state)) != nil {
+			ws.Close()
+			delete(s.clients, ws)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/greatnessinabox/drift/internal/analyzer"
+	"github.com/greatnessinabox/drift/internal/config"
+	"github.com/greatnessinabox/drift/internal/health"
+)
+
+type state struct {
+	Snapshot struct {
+		Score     map[string]float64 `json:"score"`
+		Functions []struct {
+			Name string `json:"name"`
+		} `json:"functions"`
+	} `json:"snapshot"`
+	Activity []activity `json:"activity"`
+}
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.Root = t.TempDir()
+	s, err := New(cfg, analyzer.New(cfg), health.NewScorer(cfg), nil, &analyzer.Results{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestServer_Page(t *testing.T) {
+	srv := httptest.NewServer(newServer(t).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "/ws") {
+		t.Errorf("GET / = %d, %d bytes", resp.StatusCode, len(body))
+	}
+
+	resp, err = http.Get(srv.URL + "/api/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got state
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Snapshot.Score["total"] != 100 || got.Activity == nil {
+		t.Errorf("GET /api/state = %+v", got)
+	}
+}
+
+func TestServer_Websocket(t *testing.T) {
+	s := newServer(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var got state
+	if err := websocket.JSON.Receive(ws, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Snapshot.Functions) != 0 {
+		t.Errorf("initial functions = %v, want none", got.Snapshot.Functions)
+	}
+
+	results := &analyzer.Results{Complexity: []analyzer.FunctionComplexity{{Name: "Handle", Path: "a.go", Complexity: 30}}}
+	if err := s.update(results, activity{File: "a.go", Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := websocket.JSON.Receive(ws, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Snapshot.Functions) != 1 || got.Snapshot.Functions[0].Name != "Handle" {
+		t.Errorf("updated functions = %v, want Handle", got.Snapshot.Functions)
+	}
+	if len(got.Activity) != 1 || got.Activity[0].File != "a.go" {
+		t.Errorf("activity = %v, want a.go", got.Activity)
+	}
+	if got.Snapshot.Score["total"] >= 100 {
+		t.Errorf("total = %v, want the complex function counted", got.Snapshot.Score["total"])
+	}
+}
+
+func TestServer_CrossOrigin(t *testing.T) {
+	srv := httptest.NewServer(newServer(t).Handler())
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/api/state", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin GET /api/state = %d, want 403", resp.StatusCode)
+	}
+
+	if ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", "", "https://evil.example"); err == nil {
+		ws.Close()
+		t.Error("cross-origin websocket was accepted")
+	}
+}